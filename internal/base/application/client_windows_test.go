@@ -0,0 +1,122 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/pkg/client"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// newTestServers serves the real routers of the desktop service on a fresh database.
+func newTestServers(t *testing.T) (common, admin *httptest.Server) {
+	workDir := t.TempDir()
+	c := conf.NewDefaultConf()
+	c.StartMode = conf.ServiceMode
+	c.SetWorkdir(workDir)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	ctx = context.WithValue(ctx, constants.CancelFuncKey, cancel)
+	ctx = context.WithValue(ctx, constants.ConfKey, c)
+
+	connection := "file:" + filepath.Join(workDir, "config.db") + "?cache=shared&mode=rwc&_journal_mode=WAL"
+	routers, err := initDesktopServiceRouters(ctx, &conf.DatabaseConf{Driver: "sqlite", Connection: connection, MaxIdleConnection: 1, MaxOpenConnection: 1})
+	if err != nil {
+		t.Fatalf("failed to build the routers: %v", err)
+	}
+	if err := routers.di.Start(); err != nil {
+		t.Fatalf("failed to initialize the database: %v", err)
+	}
+	routers.common.Register()
+	routers.admin.Register()
+	common = httptest.NewServer(routers.common.GetRouter())
+	admin = httptest.NewServer(routers.admin.GetRouter())
+	t.Cleanup(common.Close)
+	t.Cleanup(admin.Close)
+	return common, admin
+}
+
+func TestClientAgainstRouters(t *testing.T) {
+	commonServer, adminServer := newTestServers(t)
+	ctx := context.Background()
+	c, err := client.NewClientBuilder(commonServer.URL + "/api/v1").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.ListPendingActions(ctx); !errors.Is(err, exception.ErrUserUnauthorizedAccess) {
+		t.Fatalf("expected an unauthorized error before login, got %v", err)
+	}
+	if _, err := c.Login(ctx, "root", "wrong password", ""); !errors.Is(err, exception.ErrUserWrongPassword) {
+		t.Fatalf("expected a wrong password error, got %v", err)
+	}
+	if _, err := c.Login(ctx, "root", conf.RootPassword, ""); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	if err := c.Ping(ctx); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+	if info, err := c.SoftwareInfo(ctx); err != nil {
+		t.Errorf("SoftwareInfo() = %+v, %v", info, err)
+	}
+	if pending, err := c.ListPendingActions(ctx); err != nil || len(pending) != 0 {
+		t.Errorf("ListPendingActions() = %v, %v", pending, err)
+	}
+	_, err = c.GetPendingAction(ctx, "missing")
+	var apiErr *client.Error
+	if !errors.Is(err, exception.ErrUserResourceNotFound) || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+
+	certs, err := c.Certificates(ctx)
+	if err != nil || certs.CA == nil {
+		t.Fatalf("Certificates() = %+v, %v", certs, err)
+	}
+	ca, err := c.CACertificate(ctx)
+	if err != nil || !ca.IsCA {
+		t.Errorf("CACertificate() = %v, %v", ca, err)
+	}
+
+	if err := c.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if _, err := c.ListPendingActions(ctx); err != nil {
+		t.Errorf("request with the refreshed token failed: %v", err)
+	}
+
+	admin, err := client.NewClientBuilder(adminServer.URL + "/admin/api/v1").SetToken(c.Token()).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config, err := admin.HttpsConfig(ctx); err != nil || config.AutoCert == nil || !*config.AutoCert {
+		t.Errorf("HttpsConfig() = %+v, %v", config, err)
+	}
+	if _, err := admin.HttpConfig(ctx); err != nil {
+		t.Errorf("HttpConfig failed: %v", err)
+	}
+	if _, err := admin.DiscoveryConfig(ctx); err != nil {
+		t.Errorf("DiscoveryConfig failed: %v", err)
+	}
+	if _, err := admin.RemoteConfig(ctx); err != nil {
+		t.Errorf("RemoteConfig failed: %v", err)
+	}
+	if _, err := admin.Language(ctx); err != nil {
+		t.Errorf("Language failed: %v", err)
+	}
+	if err := admin.PatchHttpConfig(ctx, client.HttpsServiceApi, map[string]interface{}{"client_auth_mode": "sometimes"}); !errors.Is(err, exception.ErrUserParameterError) {
+		t.Errorf("expected a parameter error for an invalid client auth mode, got %v", err)
+	}
+
+	if err := c.Logout(ctx); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+	if _, err := admin.ListPendingActions(ctx); !errors.Is(err, exception.ErrUserUnauthorizedAccess) {
+		t.Errorf("expected the token to be revoked after logout, got %v", err)
+	}
+}
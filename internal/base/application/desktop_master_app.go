@@ -48,13 +48,13 @@ func DesktopServiceMain(debug bool, mode conf.StartMode, workDir string) {
 	c.StartMode = mode
 	c.SetWorkdir(workDir)
 
-	configPath, err := c.ReadConfigFromYml(filepath.Join(workDir, "config.yml"))
+	configPath, err := c.ReadConfigFile(workDir)
 	if err != nil {
-		configPath, err = c.ReadConfigFromYml("config.yml")
-		if err != nil {
-			logger.Info("no config file found,use default config")
-		}
-
+		logger.Errorf("invalid config file:\n%v", err)
+		return
+	}
+	if configPath == "" {
+		logger.Info("no config file found,use default config")
 	}
 	c.SetPath(configPath)
 	c.Debug = c.Debug || debug
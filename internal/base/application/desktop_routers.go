@@ -0,0 +1,19 @@
+package application
+
+import (
+	"fadacontrol/internal/base/bootstrap"
+	"fadacontrol/internal/router/admin_router"
+	"fadacontrol/internal/router/common_router"
+)
+
+// desktopServiceRouters are the routers of the desktop service with the bootstrap that prepares their database,
+// they let the API be served by test servers.
+type desktopServiceRouters struct {
+	di     *bootstrap.DataInitBootstrap
+	common *common_router.CommonRouter
+	admin  *admin_router.AdminRouter
+}
+
+func newDesktopServiceRouters(di *bootstrap.DataInitBootstrap, common *common_router.CommonRouter, admin *admin_router.AdminRouter) *desktopServiceRouters {
+	return &desktopServiceRouters{di: di, common: common, admin: admin}
+}
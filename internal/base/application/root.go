@@ -3,6 +3,7 @@ package application
 import (
 	"fadacontrol/internal/base/conf"
 	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/ctl"
 	"github.com/spf13/cobra"
 	"os"
 	"path/filepath"
@@ -80,5 +81,6 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&dryRunMode, "dry-run", "", false, "dry-run mode")
 	rootCmd.PersistentFlags().StringVarP(&rootPassword, "root-password", "", "", "reset root password")
 	//err := rootCmd.MarkPersistentFlagRequired("config")
+	rootCmd.AddCommand(ctl.NewCommand(&workDir))
 
 }
@@ -14,19 +14,32 @@ import (
 	"fadacontrol/internal/controller/common_controller"
 	"fadacontrol/internal/router/admin_router"
 	"fadacontrol/internal/router/common_router"
+	"fadacontrol/internal/service/audit_service"
 	"fadacontrol/internal/service/auth_service"
+	"fadacontrol/internal/service/backup_service"
+	"fadacontrol/internal/service/cert_service"
 	"fadacontrol/internal/service/control_pc"
 	"fadacontrol/internal/service/credential_provider_service"
 	"fadacontrol/internal/service/custom_command_service"
 	"fadacontrol/internal/service/discovery_service"
+	"fadacontrol/internal/service/health_service"
 	"fadacontrol/internal/service/http_service"
 	"fadacontrol/internal/service/internal_master_service"
 	"fadacontrol/internal/service/internal_slave_service"
 	"fadacontrol/internal/service/jwt_service"
+	"fadacontrol/internal/service/log_service"
+	"fadacontrol/internal/service/macro_service"
+	"fadacontrol/internal/service/policy_service"
+	"fadacontrol/internal/service/reload_service"
 	"fadacontrol/internal/service/remote_service"
+	"fadacontrol/internal/service/scheduler_service"
+	"fadacontrol/internal/service/throttle_service"
+	"fadacontrol/internal/service/token_service"
+	"fadacontrol/internal/service/two_factor_service"
 	"fadacontrol/internal/service/unlock"
 	"fadacontrol/internal/service/update_service"
 	"fadacontrol/internal/service/user_service"
+	"fadacontrol/internal/service/webhook_service"
 	"github.com/google/wire"
 )
 
@@ -39,13 +52,51 @@ func initDesktopServiceApplication(ctx context.Context, db *conf.DatabaseConf) (
 		admin_controller.NewRemoteController, bootstrap.NewRemoteConnectBootstrap, admin_controller.NewDiscoverController, credential_provider_service.NewCredentialProviderService,
 		bootstrap.NewDataInitBootstrap, data.NewAdapterByDB, data.NewEnforcer, common_controller.NewAuthController,
 		middleware.NewJwtMiddleware, jwt_service.NewJwtService, auth_service.NewAuthService, user_service.NewUserService, discovery_service.NewDiscoverService,
-		common_controller.NewSystemController, admin_controller.NewHttpController, http_service.NewHttpService, bootstrap.NewProfilingBootstrap, update_service.NewUpdateService, common_controller.NewDebugController,
+		common_controller.NewSystemController, log_service.NewLogService, admin_controller.NewHttpController, http_service.NewHttpService, bootstrap.NewProfilingBootstrap, update_service.NewUpdateService, common_controller.NewDebugController,
+		admin_controller.NewTerminalController, admin_controller.NewScheduleController, scheduler_service.NewSchedulerService, bootstrap.NewSchedulerBootstrap,
+		macro_service.NewMacroService, admin_controller.NewMacroController, admin_controller.NewUserController,
+		policy_service.NewPolicyService, admin_controller.NewPolicyController,
+		token_service.NewTokenService, common_controller.NewTokenController, admin_controller.NewJwtKeyController,
+		throttle_service.NewThrottleService, two_factor_service.NewTwoFactorService, common_controller.NewTwoFactorController,
+		cert_service.NewCertService, admin_controller.NewClientCertController, common_controller.NewCertController,
+		bootstrap.NewLocalTokenBootstrap, health_service.NewHealthService, common_controller.NewHealthController,
+		audit_service.NewAuditService, admin_controller.NewAuditController, bootstrap.NewAuditBootstrap,
+		webhook_service.NewWebhookService, admin_controller.NewWebhookController, admin_controller.NewEventController,
+		backup_service.NewBackupService, admin_controller.NewBackupController, bootstrap.NewWebhookBootstrap,
+		reload_service.NewReloadService, admin_controller.NewReloadController, bootstrap.NewReloadBootstrap,
 	)
 	return &DesktopServiceApp{ctx: ctx, db: db}, nil
 }
+
+// initDesktopServiceRouters builds the routers of the desktop service without starting its servers.
+func initDesktopServiceRouters(ctx context.Context, db *conf.DatabaseConf) (*desktopServiceRouters, error) {
+	wire.Build(newDesktopServiceRouters, bootstrap.NewDataInitBootstrap, data.NewDB, data.NewAdapterByDB, data.NewEnforcer,
+		common_router.NewCommonRouter, admin_router.NewAdminRouter, unlock.NewUnLockService,
+		control_pc.NewControlPCService, common_controller.NewControlPCController, common_controller.NewUnlockController,
+		common_controller.NewCustomCommandController, internal_master_service.NewInternalMasterService,
+		custom_command_service.NewCustomCommandService, remote_service.NewRemoteService,
+		admin_controller.NewRemoteController, admin_controller.NewDiscoverController, credential_provider_service.NewCredentialProviderService,
+		common_controller.NewAuthController, middleware.NewJwtMiddleware, jwt_service.NewJwtService, auth_service.NewAuthService, user_service.NewUserService, discovery_service.NewDiscoverService,
+		common_controller.NewSystemController, log_service.NewLogService, admin_controller.NewHttpController, http_service.NewHttpService, update_service.NewUpdateService, common_controller.NewDebugController,
+		admin_controller.NewTerminalController, admin_controller.NewScheduleController, scheduler_service.NewSchedulerService,
+		macro_service.NewMacroService, admin_controller.NewMacroController, admin_controller.NewUserController,
+		policy_service.NewPolicyService, admin_controller.NewPolicyController,
+		token_service.NewTokenService, common_controller.NewTokenController, admin_controller.NewJwtKeyController,
+		throttle_service.NewThrottleService, two_factor_service.NewTwoFactorService, common_controller.NewTwoFactorController,
+		cert_service.NewCertService, admin_controller.NewClientCertController, common_controller.NewCertController,
+		health_service.NewHealthService, common_controller.NewHealthController,
+		audit_service.NewAuditService, admin_controller.NewAuditController,
+		webhook_service.NewWebhookService, admin_controller.NewWebhookController, admin_controller.NewEventController,
+		backup_service.NewBackupService, admin_controller.NewBackupController,
+		reload_service.NewReloadService, admin_controller.NewReloadController,
+	)
+	return &desktopServiceRouters{}, nil
+}
 func initDesktopSlaveApplication(ctx context.Context) (*DesktopSlaveServiceApp, error) {
 	wire.Build(NewDesktopSlaveServiceApp, bootstrap.NewDesktopSlaveServiceBootstrap, internal_slave_service.NewInternalSlaveService,
 		custom_command_service.NewCustomCommandService, logger.NewLogger, control_pc.NewControlPCService, bootstrap.NewProfilingBootstrap, internal_master_service.NewInternalMasterService,
+		// the slave runs the commands sent by the service and never loads their auth rules
+		wire.Value((*auth_service.AuthService)(nil)),
 	)
 
 	return &DesktopSlaveServiceApp{ctx: ctx}, nil
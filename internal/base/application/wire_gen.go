@@ -17,19 +17,32 @@ import (
 	"fadacontrol/internal/controller/common_controller"
 	"fadacontrol/internal/router/admin_router"
 	"fadacontrol/internal/router/common_router"
+	"fadacontrol/internal/service/audit_service"
 	"fadacontrol/internal/service/auth_service"
+	"fadacontrol/internal/service/backup_service"
+	"fadacontrol/internal/service/cert_service"
 	"fadacontrol/internal/service/control_pc"
 	"fadacontrol/internal/service/credential_provider_service"
 	"fadacontrol/internal/service/custom_command_service"
 	"fadacontrol/internal/service/discovery_service"
+	"fadacontrol/internal/service/health_service"
 	"fadacontrol/internal/service/http_service"
 	"fadacontrol/internal/service/internal_master_service"
 	"fadacontrol/internal/service/internal_slave_service"
 	"fadacontrol/internal/service/jwt_service"
+	"fadacontrol/internal/service/log_service"
+	"fadacontrol/internal/service/macro_service"
+	"fadacontrol/internal/service/policy_service"
+	"fadacontrol/internal/service/reload_service"
 	"fadacontrol/internal/service/remote_service"
+	"fadacontrol/internal/service/scheduler_service"
+	"fadacontrol/internal/service/throttle_service"
+	"fadacontrol/internal/service/token_service"
+	"fadacontrol/internal/service/two_factor_service"
 	"fadacontrol/internal/service/unlock"
 	"fadacontrol/internal/service/update_service"
 	"fadacontrol/internal/service/user_service"
+	"fadacontrol/internal/service/webhook_service"
 )
 
 // Injectors from wire.go:
@@ -50,47 +63,157 @@ func initDesktopServiceApplication(ctx context.Context, db *conf.DatabaseConf) (
 	if err != nil {
 		return nil, err
 	}
-	dataInitBootstrap := bootstrap.NewDataInitBootstrap(ctx, adapter, enforcer, gormDB)
+	auditService := audit_service.NewAuditService(ctx, gormDB)
 	credentialProviderService := credential_provider_service.NewCredentialProviderService(gormDB)
-	unLockService := unlock.NewUnLockService(credentialProviderService)
-	remoteService := remote_service.NewRemoteService(controlPCService, unLockService, ctx, gormDB)
+	throttleService := throttle_service.NewThrottleService()
+	webhookService := webhook_service.NewWebhookService(ctx, gormDB, controlPCService, internalMasterService)
+	unLockService := unlock.NewUnLockService(webhookService, credentialProviderService, throttleService)
+	authService := auth_service.NewAuthService(enforcer)
+	customCommandService := custom_command_service.NewCustomCommandService(authService, ctx)
+	macroService := macro_service.NewMacroService(ctx, gormDB, controlPCService, customCommandService, authService)
+	remoteService := remote_service.NewRemoteService(auditService, macroService, controlPCService, unLockService, ctx, gormDB)
 	remoteConnectBootstrap := bootstrap.NewRemoteConnectBootstrap(ctx, gormDB, remoteService)
 	dataData := data.NewData(gormDB)
 	loggerLogger := logger.NewLogger(ctx)
 	discoverService := discovery_service.NewDiscoverService(gormDB, ctx)
 	discoverBootstrap := bootstrap.NewDiscoverBootstrap(discoverService)
 	jwtService := jwt_service.NewJwtService(gormDB)
-	httpService := http_service.NewHttpService(gormDB, ctx)
+	certService := cert_service.NewCertService(gormDB)
+	httpService := http_service.NewHttpService(gormDB, ctx, certService)
+	policyService := policy_service.NewPolicyService(ctx, gormDB, authService)
+	reloadService := reload_service.NewReloadService(policyService, ctx, gormDB, authService, httpService, discoverService, remoteService)
+	dataInitBootstrap := bootstrap.NewDataInitBootstrap(reloadService, ctx, adapter, enforcer, gormDB)
 	debugController := common_controller.NewDebugController(internalMasterService, ctx)
 	updateService := update_service.NewUpdateService(gormDB)
-	systemController := common_controller.NewSystemController(controlPCService, ctx, updateService)
-	authService := auth_service.NewAuthService(enforcer)
-	jwtMiddleware := middleware.NewJwtMiddleware(jwtService, authService)
-	userService := user_service.NewUserService(gormDB)
-	authController := common_controller.NewAuthController(userService, jwtService)
-	customCommandService := custom_command_service.NewCustomCommandService(ctx)
-	customCommandController := common_controller.NewCustomCommandController(ctx, customCommandService)
-	unlockController := common_controller.NewUnlockController(unLockService)
-	controlPCController := common_controller.NewControlPCController(ctx, controlPCService)
-	commonRouter := common_router.NewCommonRouter(debugController, systemController, jwtMiddleware, authController, customCommandController, unlockController, controlPCController)
+	logService := log_service.NewLogService(ctx)
+	systemController := common_controller.NewSystemController(logService, controlPCService, ctx, updateService)
+	tokenService := token_service.NewTokenService(gormDB)
+	twoFactorService := two_factor_service.NewTwoFactorService(gormDB, authService)
+	userService := user_service.NewUserService(ctx, gormDB, authService, policyService, tokenService, jwtService, twoFactorService, certService)
+	jwtMiddleware := middleware.NewJwtMiddleware(jwtService, authService, userService, tokenService, twoFactorService, certService)
+	authController := common_controller.NewAuthController(userService, jwtService, throttleService, twoFactorService)
+	customCommandController := common_controller.NewCustomCommandController(auditService, ctx, customCommandService, authService)
+	unlockController := common_controller.NewUnlockController(auditService, unLockService)
+	controlPCController := common_controller.NewControlPCController(auditService, ctx, controlPCService)
+	tokenController := common_controller.NewTokenController(tokenService, twoFactorService)
+	twoFactorController := common_controller.NewTwoFactorController(twoFactorService, jwtService)
+	certController := common_controller.NewCertController(httpService, certService)
+	healthService := health_service.NewHealthService(ctx, gormDB, httpService, discoverService, remoteService, credentialProviderService, internalMasterService)
+	healthController := common_controller.NewHealthController(healthService)
+	commonRouter := common_router.NewCommonRouter(healthController, certController, twoFactorController, tokenController, debugController, systemController, jwtMiddleware, authController, customCommandController, unlockController, controlPCController)
 	httpController := admin_controller.NewHttpController(ctx, gormDB, httpService)
 	remoteController := admin_controller.NewRemoteController(gormDB, remoteService)
 	discoverController := admin_controller.NewDiscoverController(discoverService)
-	adminRouter := admin_router.NewAdminRouter(debugController, httpController, systemController, jwtMiddleware, remoteController, unlockController, controlPCController, discoverController, authController)
+	terminalController := admin_controller.NewTerminalController(auditService, ctx, customCommandService, authService)
+	schedulerService := scheduler_service.NewSchedulerService(auditService, ctx, gormDB, controlPCService, macroService)
+	scheduleController := admin_controller.NewScheduleController(schedulerService)
+	macroController := admin_controller.NewMacroController(auditService, macroService)
+	userController := admin_controller.NewUserController(userService)
+	policyController := admin_controller.NewPolicyController(policyService)
+	jwtKeyController := admin_controller.NewJwtKeyController(jwtService)
+	clientCertController := admin_controller.NewClientCertController(certService)
+	auditController := admin_controller.NewAuditController(auditService)
+	webhookController := admin_controller.NewWebhookController(webhookService)
+	eventController := admin_controller.NewEventController(ctx)
+	backupService := backup_service.NewBackupService(policyService, ctx, gormDB, authService)
+	backupController := admin_controller.NewBackupController(backupService)
+	reloadController := admin_controller.NewReloadController(reloadService)
+	adminRouter := admin_router.NewAdminRouter(reloadController, backupController, eventController, webhookController, auditController, healthController, certController, clientCertController, twoFactorController, jwtKeyController, tokenController, policyController, userController, macroController, scheduleController, terminalController, debugController, httpController, systemController, jwtMiddleware, remoteController, unlockController, controlPCController, discoverController, authController)
 	httpBootstrap := bootstrap.NewHttpBootstrap(jwtService, ctx, httpService, commonRouter, adminRouter)
-	desktopMasterServiceBootstrap := bootstrap.NewDesktopMasterServiceBootstrap(profilingBootstrap, controlPCService, dataInitBootstrap, credentialProviderService, remoteConnectBootstrap, internalMasterService, ctx, dataData, loggerLogger, discoverBootstrap, httpBootstrap)
+	localTokenBootstrap := bootstrap.NewLocalTokenBootstrap(ctx, tokenService)
+	schedulerBootstrap := bootstrap.NewSchedulerBootstrap(schedulerService)
+	auditBootstrap := bootstrap.NewAuditBootstrap(auditService)
+	webhookBootstrap := bootstrap.NewWebhookBootstrap(webhookService)
+	reloadBootstrap := bootstrap.NewReloadBootstrap(reloadService)
+	desktopMasterServiceBootstrap := bootstrap.NewDesktopMasterServiceBootstrap(reloadBootstrap, webhookBootstrap, auditBootstrap, localTokenBootstrap, schedulerBootstrap, profilingBootstrap, controlPCService, dataInitBootstrap, credentialProviderService, remoteConnectBootstrap, internalMasterService, ctx, dataData, loggerLogger, discoverBootstrap, httpBootstrap)
 	desktopServiceApp := NewDesktopServiceApp(ctx, db, desktopMasterServiceBootstrap)
 	return desktopServiceApp, nil
 }
 
+func initDesktopServiceRouters(ctx context.Context, db *conf.DatabaseConf) (*desktopServiceRouters, error) {
+	gormDB, err := data.NewDB(db)
+	if err != nil {
+		return nil, err
+	}
+	adapter, err := data.NewAdapterByDB(gormDB)
+	if err != nil {
+		return nil, err
+	}
+	enforcer, err := data.NewEnforcer(adapter)
+	if err != nil {
+		return nil, err
+	}
+	auditService := audit_service.NewAuditService(ctx, gormDB)
+	certService := cert_service.NewCertService(gormDB)
+	httpService := http_service.NewHttpService(gormDB, ctx, certService)
+	certController := common_controller.NewCertController(httpService, certService)
+	authService := auth_service.NewAuthService(enforcer)
+	twoFactorService := two_factor_service.NewTwoFactorService(gormDB, authService)
+	jwtService := jwt_service.NewJwtService(gormDB)
+	twoFactorController := common_controller.NewTwoFactorController(twoFactorService, jwtService)
+	tokenService := token_service.NewTokenService(gormDB)
+	tokenController := common_controller.NewTokenController(tokenService, twoFactorService)
+	internalMasterService := internal_master_service.NewInternalMasterService(ctx)
+	debugController := common_controller.NewDebugController(internalMasterService, ctx)
+	controlPCService := control_pc.NewControlPCService(internalMasterService)
+	updateService := update_service.NewUpdateService(gormDB)
+	logService := log_service.NewLogService(ctx)
+	systemController := common_controller.NewSystemController(logService, controlPCService, ctx, updateService)
+	policyService := policy_service.NewPolicyService(ctx, gormDB, authService)
+	userService := user_service.NewUserService(ctx, gormDB, authService, policyService, tokenService, jwtService, twoFactorService, certService)
+	jwtMiddleware := middleware.NewJwtMiddleware(jwtService, authService, userService, tokenService, twoFactorService, certService)
+	throttleService := throttle_service.NewThrottleService()
+	authController := common_controller.NewAuthController(userService, jwtService, throttleService, twoFactorService)
+	customCommandService := custom_command_service.NewCustomCommandService(authService, ctx)
+	customCommandController := common_controller.NewCustomCommandController(auditService, ctx, customCommandService, authService)
+	credentialProviderService := credential_provider_service.NewCredentialProviderService(gormDB)
+	webhookService := webhook_service.NewWebhookService(ctx, gormDB, controlPCService, internalMasterService)
+	unLockService := unlock.NewUnLockService(webhookService, credentialProviderService, throttleService)
+	unlockController := common_controller.NewUnlockController(auditService, unLockService)
+	controlPCController := common_controller.NewControlPCController(auditService, ctx, controlPCService)
+	macroService := macro_service.NewMacroService(ctx, gormDB, controlPCService, customCommandService, authService)
+	remoteService := remote_service.NewRemoteService(auditService, macroService, controlPCService, unLockService, ctx, gormDB)
+	discoverService := discovery_service.NewDiscoverService(gormDB, ctx)
+	reloadService := reload_service.NewReloadService(policyService, ctx, gormDB, authService, httpService, discoverService, remoteService)
+	dataInitBootstrap := bootstrap.NewDataInitBootstrap(reloadService, ctx, adapter, enforcer, gormDB)
+	healthService := health_service.NewHealthService(ctx, gormDB, httpService, discoverService, remoteService, credentialProviderService, internalMasterService)
+	healthController := common_controller.NewHealthController(healthService)
+	commonRouter := common_router.NewCommonRouter(healthController, certController, twoFactorController, tokenController, debugController, systemController, jwtMiddleware, authController, customCommandController, unlockController, controlPCController)
+	clientCertController := admin_controller.NewClientCertController(certService)
+	jwtKeyController := admin_controller.NewJwtKeyController(jwtService)
+	policyController := admin_controller.NewPolicyController(policyService)
+	userController := admin_controller.NewUserController(userService)
+	macroController := admin_controller.NewMacroController(auditService, macroService)
+	schedulerService := scheduler_service.NewSchedulerService(auditService, ctx, gormDB, controlPCService, macroService)
+	scheduleController := admin_controller.NewScheduleController(schedulerService)
+	terminalController := admin_controller.NewTerminalController(auditService, ctx, customCommandService, authService)
+	httpController := admin_controller.NewHttpController(ctx, gormDB, httpService)
+	remoteController := admin_controller.NewRemoteController(gormDB, remoteService)
+	discoverController := admin_controller.NewDiscoverController(discoverService)
+	auditController := admin_controller.NewAuditController(auditService)
+	webhookController := admin_controller.NewWebhookController(webhookService)
+	eventController := admin_controller.NewEventController(ctx)
+	backupService := backup_service.NewBackupService(policyService, ctx, gormDB, authService)
+	backupController := admin_controller.NewBackupController(backupService)
+	reloadController := admin_controller.NewReloadController(reloadService)
+	adminRouter := admin_router.NewAdminRouter(reloadController, backupController, eventController, webhookController, auditController, healthController, certController, clientCertController, twoFactorController, jwtKeyController, tokenController, policyController, userController, macroController, scheduleController, terminalController, debugController, httpController, systemController, jwtMiddleware, remoteController, unlockController, controlPCController, discoverController, authController)
+	applicationDesktopServiceRouters := newDesktopServiceRouters(dataInitBootstrap, commonRouter, adminRouter)
+	return applicationDesktopServiceRouters, nil
+}
+
 func initDesktopSlaveApplication(ctx context.Context) (*DesktopSlaveServiceApp, error) {
 	loggerLogger := logger.NewLogger(ctx)
 	profilingBootstrap := bootstrap.NewProfilingBootstrap(ctx)
 	internalMasterService := internal_master_service.NewInternalMasterService(ctx)
 	controlPCService := control_pc.NewControlPCService(internalMasterService)
-	customCommandService := custom_command_service.NewCustomCommandService(ctx)
+	authService := _wireAuthServiceValue
+	customCommandService := custom_command_service.NewCustomCommandService(authService, ctx)
 	internalSlaveService := internal_slave_service.NewInternalSlaveService(customCommandService, controlPCService, ctx)
 	desktopSlaveServiceBootstrap := bootstrap.NewDesktopSlaveServiceBootstrap(ctx, profilingBootstrap, controlPCService, loggerLogger, internalSlaveService)
 	desktopSlaveServiceApp := NewDesktopSlaveServiceApp(loggerLogger, ctx, desktopSlaveServiceBootstrap)
 	return desktopSlaveServiceApp, nil
 }
+
+var (
+	_wireAuthServiceValue = (*auth_service.AuthService)(nil)
+)
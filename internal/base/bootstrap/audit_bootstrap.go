@@ -0,0 +1,19 @@
+package bootstrap
+
+import (
+	"fadacontrol/internal/service/audit_service"
+)
+
+type AuditBootstrap struct {
+	_as *audit_service.AuditService
+}
+
+func NewAuditBootstrap(_as *audit_service.AuditService) *AuditBootstrap {
+	return &AuditBootstrap{_as: _as}
+}
+func (a *AuditBootstrap) Start() error {
+	return a._as.StartService()
+}
+func (a *AuditBootstrap) Stop() error {
+	return a._as.StopService()
+}
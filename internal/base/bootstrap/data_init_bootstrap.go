@@ -2,13 +2,14 @@ package bootstrap
 
 import (
 	"context"
-	"encoding/base64"
 	"fadacontrol/internal/base/conf"
 	"fadacontrol/internal/base/constants"
 	_log "fadacontrol/internal/base/log"
 	"fadacontrol/internal/base/logger"
 	"fadacontrol/internal/base/version"
 	"fadacontrol/internal/entity"
+	"fadacontrol/internal/service/auth_service"
+	"fadacontrol/internal/service/reload_service"
 	"fadacontrol/pkg/goroutine"
 	"fadacontrol/pkg/secure"
 	"fadacontrol/pkg/utils"
@@ -25,6 +26,7 @@ type DataInitBootstrap struct {
 	_db      *gorm.DB
 	adapter  *gormadapter.Adapter
 	enforcer *casbin.Enforcer
+	rl       *reload_service.ReloadService
 
 	startOnce sync.Once
 	ctx       context.Context
@@ -33,8 +35,8 @@ type DataInitBootstrap struct {
 const HttpsServiceApi = "HTTPS_SERVICE_API"
 const HttpServiceAdmin = "HTTP_SERVICE_ADMIN"
 
-func NewDataInitBootstrap(ctx context.Context, adapter *gormadapter.Adapter, enforcer *casbin.Enforcer, _db *gorm.DB) *DataInitBootstrap {
-	return &DataInitBootstrap{ctx: ctx, _db: _db, adapter: adapter, enforcer: enforcer}
+func NewDataInitBootstrap(rl *reload_service.ReloadService, ctx context.Context, adapter *gormadapter.Adapter, enforcer *casbin.Enforcer, _db *gorm.DB) *DataInitBootstrap {
+	return &DataInitBootstrap{rl: rl, ctx: ctx, _db: _db, adapter: adapter, enforcer: enforcer}
 
 }
 func (d *DataInitBootstrap) Stop() error {
@@ -48,6 +50,14 @@ func (d *DataInitBootstrap) Start() error {
 	d.initRemoteConfig()
 	d.initUdpConfig()
 	d.initCasbinConfig()
+	d.initScheduledTask()
+	d.initMacro()
+	d.initAuthToken()
+	d.initAudit()
+	d.initWebhook()
+	if err := d.rl.ApplyConfigFile(); err != nil {
+		logger.Errorf("failed to apply the config file: %v", err)
+	}
 	return nil
 }
 func (d *DataInitBootstrap) initLogReport() {
@@ -175,22 +185,17 @@ func (d *DataInitBootstrap) initHttpConfig() {
 
 		logger.Infof("Table recreated successfully.")
 
-		cert, key, err := secure.GenerateX509Cert()
-		if err != nil {
-			logger.Errorf("failed to generate x509 cert: %v", err)
-			return
-		}
-		strCert := base64.StdEncoding.EncodeToString(cert)
-		strKey := base64.StdEncoding.EncodeToString(key)
-
+		// the certificate is issued by the local authority when the service starts
+		autoCert := true
 		httpsConfig := entity.HttpConfig{
 			ServiceName: HttpsServiceApi, //https service api
 			Enable:      true,
 			Host:        "0.0.0.0",
 			Port:        2091,
-			Cer:         strCert,
-			Key:         strKey,
+			Cer:         "",
+			Key:         "",
 			EnableHttp3: false,
+			AutoCert:    &autoCert,
 		}
 
 		d._db.Save(&httpsConfig)
@@ -250,6 +255,41 @@ func (d *DataInitBootstrap) initUdpConfig() {
 	}
 
 }
+func (d *DataInitBootstrap) initScheduledTask() {
+	err := d._db.AutoMigrate(&entity.ScheduledTask{}, &entity.ScheduledTaskRun{})
+	if err != nil {
+		logger.Errorf("failed to migrate database")
+		return
+	}
+}
+func (d *DataInitBootstrap) initMacro() {
+	err := d._db.AutoMigrate(&entity.Macro{}, &entity.MacroStep{}, &entity.MacroRun{}, &entity.MacroStepRun{})
+	if err != nil {
+		logger.Errorf("failed to migrate database")
+		return
+	}
+}
+func (d *DataInitBootstrap) initAuthToken() {
+	err := d._db.AutoMigrate(&entity.ApiToken{}, &entity.JwtKey{}, &entity.UserSession{}, &entity.TwoFactor{}, &entity.RecoveryCode{}, &entity.CertAuthority{}, &entity.ClientCert{})
+	if err != nil {
+		logger.Errorf("failed to migrate database")
+		return
+	}
+}
+func (d *DataInitBootstrap) initAudit() {
+	err := d._db.AutoMigrate(&entity.AuditEvent{})
+	if err != nil {
+		logger.Errorf("failed to migrate database")
+		return
+	}
+}
+func (d *DataInitBootstrap) initWebhook() {
+	err := d._db.AutoMigrate(&entity.Webhook{}, &entity.WebhookDelivery{})
+	if err != nil {
+		logger.Errorf("failed to migrate database")
+		return
+	}
+}
 func (d *DataInitBootstrap) initCasbinConfig() {
 	_, err := d.enforcer.AddPolicy("root", "*", "*")
 	if err != nil {
@@ -261,6 +301,11 @@ func (d *DataInitBootstrap) initCasbinConfig() {
 		logger.Error("failed to add default policy", err)
 	}
 
+	// existing rules are skipped so that the rules added by an update are saved
+	if _, err = d.enforcer.AddPoliciesEx(auth_service.BuiltinRolePolicies()); err != nil {
+		logger.Error("failed to add built-in role policies", err)
+	}
+
 	d._db.Exec("UPDATE sqlite_sequence SET seq = 0 WHERE name = 'casbin_rule'")
 	err = d.enforcer.SavePolicy()
 	if err != nil {
@@ -279,20 +324,27 @@ func (d *DataInitBootstrap) initUser() {
 
 	d._db.Model(&entity.User{}).Count(&count)
 	if count == 0 {
-		salt, _ := secure.GenerateSaltBase64(10)
+		hash, err := secure.HashPassword(conf.RootPassword)
+		if err != nil {
+			logger.Errorf("failed to hash the root password: %v", err)
+			return
+		}
 		user := entity.User{
 			Username: "root",
-			Password: secure.HashPasswordByKDFBase64(conf.RootPassword, salt),
-			Salt:     salt,
+			Password: hash,
 		}
 		d._db.Create(&user)
 	}
 	if conf.ResetPassword {
 		user := entity.User{}
 		d._db.First(&user)
-		salt, _ := secure.GenerateSaltBase64(10)
-		user.Password = secure.HashPasswordByKDFBase64(conf.RootPassword, salt)
-		user.Salt = salt
+		hash, err := secure.HashPassword(conf.RootPassword)
+		if err != nil {
+			logger.Errorf("failed to hash the root password: %v", err)
+			return
+		}
+		user.Password = hash
+		user.Salt = ""
 		d._db.Save(&user)
 		logger.Info("root password reset")
 		goroutine.RecoverGO(
@@ -5,6 +5,7 @@ import (
 	"fadacontrol/internal/base/conf"
 	"fadacontrol/internal/base/constants"
 	"fadacontrol/internal/base/data"
+	"fadacontrol/internal/base/events"
 	"fadacontrol/internal/base/logger"
 	"fadacontrol/internal/service/control_pc"
 	"fadacontrol/internal/service/credential_provider_service"
@@ -30,13 +31,18 @@ type DesktopMasterServiceBootstrap struct {
 	di        *DataInitBootstrap
 	_co       *control_pc.ControlPCService
 	pf        *ProfilingBootstrap
+	sch       *SchedulerBootstrap
+	lt        *LocalTokenBootstrap
+	audit     *AuditBootstrap
+	wh        *WebhookBootstrap
+	rl        *ReloadBootstrap
 	startOnce sync.Once
 	stopOnce  sync.Once
 	cancel    context.CancelFunc
 }
 
-func NewDesktopMasterServiceBootstrap(pf *ProfilingBootstrap, _co *control_pc.ControlPCService, di *DataInitBootstrap, cp *credential_provider_service.CredentialProviderService, rcb *RemoteConnectBootstrap, master *internal_master_service.InternalMasterService, _context context.Context, _db *data.Data, lo *logger.Logger, d *DiscoverBootstrap, http_ *HttpBootstrap) *DesktopMasterServiceBootstrap {
-	return &DesktopMasterServiceBootstrap{pf: pf, _co: _co, di: di, cp: cp, rcb: rcb, master: master, ctx: _context, _db: _db, lo: lo, discover: d, _http: http_}
+func NewDesktopMasterServiceBootstrap(rl *ReloadBootstrap, wh *WebhookBootstrap, audit *AuditBootstrap, lt *LocalTokenBootstrap, sch *SchedulerBootstrap, pf *ProfilingBootstrap, _co *control_pc.ControlPCService, di *DataInitBootstrap, cp *credential_provider_service.CredentialProviderService, rcb *RemoteConnectBootstrap, master *internal_master_service.InternalMasterService, _context context.Context, _db *data.Data, lo *logger.Logger, d *DiscoverBootstrap, http_ *HttpBootstrap) *DesktopMasterServiceBootstrap {
+	return &DesktopMasterServiceBootstrap{rl: rl, wh: wh, audit: audit, lt: lt, sch: sch, pf: pf, _co: _co, di: di, cp: cp, rcb: rcb, master: master, ctx: _context, _db: _db, lo: lo, discover: d, _http: http_}
 }
 func (r *DesktopMasterServiceBootstrap) Start() {
 	r.startOnce.Do(func() {
@@ -63,6 +69,12 @@ func (r *DesktopMasterServiceBootstrap) Start() {
 		r.di.Start()
 		if !conf.ResetPassword {
 			r._co.RunPowerSavingMode()
+			if err := r.lt.Start(); err != nil {
+				logger.Errorf("failed to issue the local token: %v", err)
+			}
+			if err := r.wh.Start(); err != nil {
+				logger.Errorf("failed to start webhooks: %v", err)
+			}
 			r._http.Start()
 
 			if _conf.StartMode == conf.ServiceMode {
@@ -71,6 +83,15 @@ func (r *DesktopMasterServiceBootstrap) Start() {
 			}
 
 			r.rcb.Start()
+			if err := r.sch.Start(); err != nil {
+				logger.Errorf("failed to start scheduler: %v", err)
+			}
+			if err := r.audit.Start(); err != nil {
+				logger.Errorf("failed to start the audit log: %v", err)
+			}
+			if err := r.rl.Start(); err != nil {
+				logger.Errorf("failed to watch the config file: %v", err)
+			}
 			goroutine.RecoverGO(func() {
 				r.discover.Start()
 			})
@@ -80,6 +101,7 @@ func (r *DesktopMasterServiceBootstrap) Start() {
 			})
 			utils.AddNetworkChangeCallback(func() {
 				logger.Warn("network change")
+				events.Publish(events.NetworkChanged, nil)
 				r.rcb.Restart()
 				r.discover.Restart()
 			})
@@ -124,8 +146,16 @@ func (r *DesktopMasterServiceBootstrap) Stop() {
 					if !conf.ResetPassword {
 						r.master.Stop()
 						r.discover.Stop()
-						r._http.Stop()
 						r.rcb.Stop()
+						r.sch.Stop()
+						r.audit.Stop()
+						r.wh.Stop()
+						r.rl.Stop()
+						if err := r.lt.Stop(); err != nil {
+							logger.Errorf("failed to remove the local token: %v", err)
+						}
+						// the requests in flight are drained last
+						r._http.Stop()
 
 					}
 
@@ -48,8 +48,11 @@ func (s *HttpBootstrap) Start() error {
 
 }
 func (s *HttpBootstrap) Stop() error {
-	//use context to stop
-	return nil
+	var err error
+	s.stopOnce.Do(func() {
+		err = s._http.StopServers()
+	})
+	return err
 
 }
 func (s *HttpBootstrap) killOther() error {
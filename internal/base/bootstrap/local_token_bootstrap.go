@@ -0,0 +1,50 @@
+package bootstrap
+
+import (
+	"context"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/service/token_service"
+	"fadacontrol/pkg/utils"
+	"os"
+	"path/filepath"
+)
+
+// localTokenUser owns the token of the ctl commands.
+const localTokenUser = "root"
+
+// LocalTokenBootstrap issues the token of the ctl commands and writes it to a file only the service account and
+// the administrators can read.
+type LocalTokenBootstrap struct {
+	ctx context.Context
+	tok *token_service.TokenService
+}
+
+func NewLocalTokenBootstrap(ctx context.Context, tok *token_service.TokenService) *LocalTokenBootstrap {
+	return &LocalTokenBootstrap{ctx: ctx, tok: tok}
+}
+func (l *LocalTokenBootstrap) path() string {
+	return utils.GetValueFromContext(l.ctx, constants.ConfKey, conf.NewDefaultConf()).LocalTokenPath()
+}
+func (l *LocalTokenBootstrap) Start() error {
+	token, err := l.tok.IssueLocalToken(localTokenUser)
+	if err != nil {
+		return err
+	}
+	path := l.path()
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	if err := utils.WritePrivateFile(path, []byte(token)); err != nil {
+		return err
+	}
+	logger.Infof("local token written to %s", path)
+	return nil
+}
+func (l *LocalTokenBootstrap) Stop() error {
+	if err := os.Remove(l.path()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return l.tok.RevokeLocalToken(localTokenUser)
+}
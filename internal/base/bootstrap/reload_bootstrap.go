@@ -0,0 +1,19 @@
+package bootstrap
+
+import (
+	"fadacontrol/internal/service/reload_service"
+)
+
+type ReloadBootstrap struct {
+	_rs *reload_service.ReloadService
+}
+
+func NewReloadBootstrap(_rs *reload_service.ReloadService) *ReloadBootstrap {
+	return &ReloadBootstrap{_rs: _rs}
+}
+func (r *ReloadBootstrap) Start() error {
+	return r._rs.StartService()
+}
+func (r *ReloadBootstrap) Stop() error {
+	return r._rs.StopService()
+}
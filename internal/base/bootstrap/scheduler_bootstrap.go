@@ -0,0 +1,19 @@
+package bootstrap
+
+import (
+	"fadacontrol/internal/service/scheduler_service"
+)
+
+type SchedulerBootstrap struct {
+	_sch *scheduler_service.SchedulerService
+}
+
+func NewSchedulerBootstrap(_sch *scheduler_service.SchedulerService) *SchedulerBootstrap {
+	return &SchedulerBootstrap{_sch: _sch}
+}
+func (s *SchedulerBootstrap) Start() error {
+	return s._sch.StartService()
+}
+func (s *SchedulerBootstrap) Stop() error {
+	return s._sch.StopService()
+}
@@ -0,0 +1,19 @@
+package bootstrap
+
+import (
+	"fadacontrol/internal/service/webhook_service"
+)
+
+type WebhookBootstrap struct {
+	_wh *webhook_service.WebhookService
+}
+
+func NewWebhookBootstrap(_wh *webhook_service.WebhookService) *WebhookBootstrap {
+	return &WebhookBootstrap{_wh: _wh}
+}
+func (w *WebhookBootstrap) Start() error {
+	return w._wh.StartService()
+}
+func (w *WebhookBootstrap) Stop() error {
+	return w._wh.StopService()
+}
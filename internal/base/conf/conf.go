@@ -1,10 +1,12 @@
 package conf
 
 import (
+	"errors"
 	_log "fadacontrol/internal/base/log"
-	"gopkg.in/yaml.v3"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
 type StartMode uint8
@@ -17,14 +19,22 @@ const (
 )
 
 type Conf struct {
+	// mu guards the settings replaced by Reload.
+	mu              sync.RWMutex
 	workdir         string
 	Debug           bool   `yaml:"debug"`
 	LogName         string `yaml:"log_name"`
 	LogLevel        string `yaml:"log_level"`
 	EnableProfiling bool   `yaml:"enable_profiling"`
-	StartMode       StartMode
-	path            string
-	LogReporterOpt  *_log.SentryOptions
+	// Http is keyed by api and admin, see HttpServiceNames.
+	Http           map[string]*HttpServiceConf `yaml:"http"`
+	Discovery      *DiscoveryConf              `yaml:"discovery"`
+	Remote         *RemoteConf                 `yaml:"remote"`
+	Users          []UserConf                  `yaml:"users"`
+	Policies       []PolicyConf                `yaml:"policies"`
+	StartMode      StartMode
+	path           string
+	LogReporterOpt *_log.SentryOptions
 }
 
 func NewDefaultConf() *Conf {
@@ -45,12 +55,26 @@ func (c *Conf) ReadConfigFromYml(filePath string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	err = yaml.Unmarshal(bytes, &c)
+	err = c.parseConfig(filePath, bytes)
 	if err != nil {
 		return "", err
 	}
 	return filePath, nil
 }
+
+// ReadConfigFile reads config.yml from the work directory or else from the current directory. The returned path is
+// empty when there is no config file, an invalid file is an error.
+func (c *Conf) ReadConfigFile(workDir string) (string, error) {
+	for _, path := range []string{filepath.Join(workDir, "config.yml"), "config.yml"} {
+		configPath, err := c.ReadConfigFromYml(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return configPath, err
+	}
+	return "", nil
+}
+
 func (c *Conf) SetWorkdir(path string) {
 	var err error
 	c.workdir, err = filepath.Abs(path)
@@ -62,6 +86,47 @@ func (c *Conf) SetWorkdir(path string) {
 func (c *Conf) GetWorkdir() string {
 	return c.workdir
 }
+
+// GetCommandDir returns the directory of the command config files that are backed up and restored.
+func (c *Conf) GetCommandDir() string {
+	return filepath.Join(c.workdir, "commands")
+}
+
+// LocalTokenPath returns the path of the token file of the ctl commands.
+func (c *Conf) LocalTokenPath() string {
+	return LocalTokenPath(c.workdir)
+}
+
+// LocalTokenPath returns the path of the token file of the ctl commands of a service running in workdir.
+func LocalTokenPath(workdir string) string {
+	return filepath.Join(workdir, "data", LocalTokenFileName)
+}
 func (c *Conf) SetPath(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.path = path
 }
+
+// GetPath returns the path of the config file, it is empty when the defaults are used.
+func (c *Conf) GetPath() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.path
+}
+
+// Reload replaces the settings that can change while the service runs with the ones read into from. The replaced
+// slices and maps are not modified, so the values returned before stay valid.
+func (c *Conf) Reload(from *Conf) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.LogLevel = from.LogLevel
+	if from.Debug {
+		c.LogLevel = "debug"
+	}
+	c.Http = from.Http
+	c.Discovery = from.Discovery
+	c.Remote = from.Remote
+	c.Users = from.Users
+	c.Policies = from.Policies
+	c.path = from.path
+}
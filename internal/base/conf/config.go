@@ -7,6 +7,14 @@ const DefaultMasterLogName = "service.log"
 const DefaultSlaveLogName = "slave.log"
 const NetWorkChangeServiceRestartInterval = 10 * time.Second
 
+// ConfigWatchInterval is how often config.yml is checked for changes.
+const ConfigWatchInterval = 5 * time.Second
+const TerminalIdleTimeout = 10 * time.Minute
+const MaxTerminalSessions = 2
+
+// LocalTokenFileName is the file below the data directory that holds the token of the ctl commands.
+const LocalTokenFileName = "ctl.token"
+
 var RootPassword = "1234"
 var ResetPassword = false
 var Http3Enabled = false
@@ -19,10 +27,25 @@ var IgnoredPaths = []string{
 	"/admin/api/v1/ping",
 	"/admin/api/v1/unlock",
 	"/admin/api/v1/login",
+	"/api/v1/login/2fa",
+	"/admin/api/v1/login/2fa",
+	"/api/v1/auth/refresh",
+	"/admin/api/v1/auth/refresh",
+	"^(/admin)?/api/v1/certs(/ca)?$",
+	"^(/admin)?/api/v1/(healthz|readyz)$",
 	"/swagger/*",
 	"/info/language",
 }
 
+// SelfServicePaths are patterns of the endpoints available to every authenticated user regardless of casbin policies.
+var SelfServicePaths = []string{
+	"^(/admin)?/api/v1/user/password$",
+	"^(/admin)?/api/v1/user/tokens(/[^/]+)?$",
+	"^(/admin)?/api/v1/user/sessions(/[^/]+)?$",
+	"^(/admin)?/api/v1/auth/logout$",
+	"^(/admin)?/api/v1/user/2fa(/[^/]+)?$",
+}
+
 type ProductLanguage string
 
 const (
@@ -0,0 +1,422 @@
+package conf
+
+import (
+	"fadacontrol/internal/base/exception"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HttpServiceNames maps the keys of the http section to the services they configure.
+var HttpServiceNames = map[string]string{
+	"api":   "HTTPS_SERVICE_API",
+	"admin": "HTTP_SERVICE_ADMIN",
+}
+
+var clientAuthModes = []string{"none", "optional", "required"}
+
+var policyActions = []string{"r", "w", "x", "*"}
+
+// builtinRoles are the roles defined by auth_service, they can't be used as usernames.
+var builtinRoles = []string{"admin", "operator", "viewer"}
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,32}$`)
+
+// The settings below are applied to the database on every start. A field that is set in the file is managed by
+// it: the file wins over the database and the API rejects changes to the field. Fields that are not set keep the
+// value in the database, which is seeded with the built-in defaults.
+
+type HttpServiceConf struct {
+	Enable         *bool   `yaml:"enable"`
+	Host           *string `yaml:"host"`
+	Port           *int    `yaml:"port"`
+	EnableHttp3    *bool   `yaml:"enable_http3"`
+	ClientAuthMode *string `yaml:"client_auth_mode"`
+	// CertFile and KeyFile replace the certificate issued by the local authority with PEM files.
+	CertFile *string `yaml:"cert_file"`
+	KeyFile  *string `yaml:"key_file"`
+}
+
+type DiscoveryConf struct {
+	Enabled *bool `yaml:"enabled"`
+}
+
+type RemoteConf struct {
+	Enable         *bool   `yaml:"enable"`
+	ClientId       *string `yaml:"client_id"`
+	TimeStampCheck *bool   `yaml:"time_stamp_check"`
+	ApiServerUrl   *string `yaml:"api_server_url"`
+	// MsgServerUrls replaces the message servers when it is set.
+	MsgServerUrls []string `yaml:"msg_server_urls"`
+}
+
+type UserConf struct {
+	Username string `yaml:"username"`
+	// Password is only used to create the user, it can be changed through the API afterwards.
+	Password string `yaml:"password"`
+	// Roles replaces the roles of the user when it is set.
+	Roles    []string `yaml:"roles"`
+	Disabled *bool    `yaml:"disabled"`
+}
+
+type PolicyConf struct {
+	Subject string `yaml:"subject"`
+	Object  string `yaml:"object"`
+	Action  string `yaml:"action"`
+}
+
+// managedSections are the keys of the sections checked for unknown fields.
+var managedSections = map[string]reflect.Type{
+	"http":      reflect.TypeOf(map[string]*HttpServiceConf{}),
+	"discovery": reflect.TypeOf(DiscoveryConf{}),
+	"remote":    reflect.TypeOf(RemoteConf{}),
+	"users":     reflect.TypeOf([]UserConf{}),
+	"policies":  reflect.TypeOf([]PolicyConf{}),
+}
+
+// FieldError is an invalid value of the config file.
+type FieldError struct {
+	Line  int
+	Field string
+	Msg   string
+}
+
+// ConfigError lists the invalid values of a config file.
+type ConfigError struct {
+	Path   string
+	Errors []FieldError
+}
+
+func (e *ConfigError) Error() string {
+	lines := make([]string, 0, len(e.Errors))
+	for _, f := range e.Errors {
+		lines = append(lines, fmt.Sprintf("%s:%d: %s: %s", e.Path, f.Line, f.Field, f.Msg))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseConfig decodes the file into c and validates the managed sections.
+func (c *Conf) parseConfig(path string, data []byte) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	if len(root.Content) == 0 {
+		return nil
+	}
+	if err := root.Decode(c); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	v := &validator{lines: make(map[string]int)}
+	v.index(root.Content[0], "")
+	v.checkUnknown(root.Content[0])
+	c.validate(v)
+	if len(v.errors) > 0 {
+		sort.SliceStable(v.errors, func(i, j int) bool { return v.errors[i].Line < v.errors[j].Line })
+		return &ConfigError{Path: path, Errors: v.errors}
+	}
+	return nil
+}
+
+type validator struct {
+	// lines maps the path of every value, such as users[1].roles, to its line
+	lines  map[string]int
+	errors []FieldError
+}
+
+func joinPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+func (v *validator) index(n *yaml.Node, path string) {
+	v.lines[path] = n.Line
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := joinPath(path, n.Content[i].Value)
+			v.index(n.Content[i+1], key)
+			// errors about a key point to the key rather than to a nested value
+			v.lines[key] = n.Content[i].Line
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			v.index(item, path+"["+strconv.Itoa(i)+"]")
+		}
+	}
+}
+
+// line returns the line of the path or of its closest parent.
+func (v *validator) line(path string) int {
+	for path != "" {
+		if line, ok := v.lines[path]; ok {
+			return line
+		}
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	return v.lines[""]
+}
+
+func (v *validator) fail(path, format string, args ...interface{}) {
+	v.errors = append(v.errors, FieldError{Line: v.line(path), Field: path, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) checkUnknown(root *yaml.Node) {
+	if root.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if t, ok := managedSections[root.Content[i].Value]; ok {
+			v.checkFields(root.Content[i+1], t, root.Content[i].Value)
+		}
+	}
+}
+
+// checkFields reports the keys of mappings that do not match a yaml tag of the struct they are decoded into.
+func (v *validator) checkFields(n *yaml.Node, t reflect.Type, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Struct && n.Kind == yaml.MappingNode:
+		known := make(map[string]reflect.Type)
+		for i := 0; i < t.NumField(); i++ {
+			known[strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]] = t.Field(i).Type
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			ft, ok := known[key]
+			if !ok {
+				v.fail(joinPath(path, key), "unknown field")
+				continue
+			}
+			v.checkFields(n.Content[i+1], ft, joinPath(path, key))
+		}
+	case t.Kind() == reflect.Map && n.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			v.checkFields(n.Content[i+1], t.Elem(), joinPath(path, n.Content[i].Value))
+		}
+	case t.Kind() == reflect.Slice && n.Kind == yaml.SequenceNode:
+		for i, item := range n.Content {
+			v.checkFields(item, t.Elem(), path+"["+strconv.Itoa(i)+"]")
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func validUrl(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func (c *Conf) validate(v *validator) {
+	ports := make(map[int]string)
+	for key, h := range c.Http {
+		path := "http." + key
+		if _, ok := HttpServiceNames[key]; !ok {
+			v.fail(path, "unknown http service, use api or admin")
+			continue
+		}
+		if h == nil {
+			continue
+		}
+		if h.Host != nil && net.ParseIP(*h.Host) == nil {
+			v.fail(path+".host", "%q is not an IP address", *h.Host)
+		}
+		if h.Port != nil {
+			if *h.Port < 1 || *h.Port > 65535 {
+				v.fail(path+".port", "must be between 1 and 65535")
+			} else if other, ok := ports[*h.Port]; ok {
+				v.fail(path+".port", "port %d is also used by http.%s", *h.Port, other)
+			} else {
+				ports[*h.Port] = key
+			}
+		}
+		if h.ClientAuthMode != nil && !contains(clientAuthModes, *h.ClientAuthMode) {
+			v.fail(path+".client_auth_mode", "must be one of %s", strings.Join(clientAuthModes, ", "))
+		}
+		if (h.CertFile == nil) != (h.KeyFile == nil) {
+			v.fail(path, "cert_file and key_file must be set together")
+		}
+		for field, file := range map[string]*string{"cert_file": h.CertFile, "key_file": h.KeyFile} {
+			if file == nil {
+				continue
+			}
+			if _, err := os.Stat(*file); err != nil {
+				v.fail(path+"."+field, "%v", err)
+			}
+		}
+	}
+
+	if r := c.Remote; r != nil {
+		if r.ApiServerUrl != nil && *r.ApiServerUrl != "" && !validUrl(*r.ApiServerUrl) {
+			v.fail("remote.api_server_url", "%q is not a URL", *r.ApiServerUrl)
+		}
+		for i, s := range r.MsgServerUrls {
+			if !validUrl(s) {
+				v.fail("remote.msg_server_urls["+strconv.Itoa(i)+"]", "%q is not a URL", s)
+			}
+		}
+	}
+
+	usernames := make(map[string]bool)
+	roles := make(map[string]bool)
+	for _, u := range c.Users {
+		for _, role := range u.Roles {
+			roles[role] = true
+		}
+	}
+	for i, u := range c.Users {
+		path := "users[" + strconv.Itoa(i) + "]"
+		if !namePattern.MatchString(u.Username) {
+			v.fail(path+".username", "may only contain letters, digits, '_', '.' and '-'")
+		} else if usernames[u.Username] {
+			v.fail(path+".username", "user %s is listed twice", u.Username)
+		} else if contains(builtinRoles, u.Username) {
+			v.fail(path+".username", "%s is a built-in role", u.Username)
+		} else if roles[u.Username] {
+			v.fail(path+".username", "%s is used as a role", u.Username)
+		}
+		usernames[u.Username] = true
+		if u.Password != "" && (len(u.Password) < 8 || len(u.Password) > 128) {
+			v.fail(path+".password", "must be 8 to 128 characters long")
+		}
+		if u.Username == "root" && u.Disabled != nil && *u.Disabled {
+			v.fail(path+".disabled", "the root account can not be disabled")
+		}
+		for j, role := range u.Roles {
+			if !namePattern.MatchString(role) || role == u.Username {
+				v.fail(path+".roles["+strconv.Itoa(j)+"]", "invalid role %q", role)
+			}
+		}
+	}
+
+	for i, p := range c.Policies {
+		path := "policies[" + strconv.Itoa(i) + "]"
+		if p.Subject != "*" && !namePattern.MatchString(p.Subject) {
+			v.fail(path+".subject", "must be * or a user or role name")
+		}
+		if p.Object != "*" && !strings.HasPrefix(p.Object, "api:/") && !strings.HasPrefix(p.Object, "cmd:") {
+			v.fail(path+".object", "must be *, or start with api:/ or cmd:")
+		}
+		if !contains(policyActions, p.Action) {
+			v.fail(path+".action", "must be one of %s", strings.Join(policyActions, ", "))
+		}
+	}
+}
+
+// ManagedHttpFields returns the fields of the http configuration request of the service that are set by the file.
+func (c *Conf) ManagedHttpFields(serviceName string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var h *HttpServiceConf
+	for key, name := range HttpServiceNames {
+		if name == serviceName {
+			h = c.Http[key]
+		}
+	}
+	if h == nil {
+		return nil
+	}
+	var fields []string
+	add := func(set bool, names ...string) {
+		if set {
+			fields = append(fields, names...)
+		}
+	}
+	add(h.Enable != nil, "enable")
+	add(h.Host != nil, "host")
+	add(h.Port != nil, "port")
+	add(h.EnableHttp3 != nil, "enable_http3")
+	add(h.ClientAuthMode != nil, "client_auth_mode")
+	add(h.CertFile != nil, "cer", "key", "auto_cert")
+	return fields
+}
+
+// ManagedDiscoveryFields returns the fields of the discovery configuration that are set by the file.
+func (c *Conf) ManagedDiscoveryFields() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Discovery == nil || c.Discovery.Enabled == nil {
+		return nil
+	}
+	return []string{"enabled"}
+}
+
+// ManagedRemoteFields returns the fields of the remote configuration request that are set by the file.
+func (c *Conf) ManagedRemoteFields() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	r := c.Remote
+	if r == nil {
+		return nil
+	}
+	var fields []string
+	add := func(set bool, name string) {
+		if set {
+			fields = append(fields, name)
+		}
+	}
+	add(r.Enable != nil, "enable")
+	add(r.ClientId != nil, "client_id")
+	add(r.TimeStampCheck != nil, "time_stamp_check")
+	add(r.ApiServerUrl != nil, "api_server_url")
+	add(r.MsgServerUrls != nil, "msg_server_urls")
+	return fields
+}
+
+// ManagedUser returns the entry of the user in the file, or nil.
+func (c *Conf) ManagedUser(username string) *UserConf {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for i := range c.Users {
+		if c.Users[i].Username == username {
+			return &c.Users[i]
+		}
+	}
+	return nil
+}
+
+// IsManagedPolicy reports whether the policy is listed in the file.
+func (c *Conf) IsManagedPolicy(subject, object, action string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, p := range c.Policies {
+		if p.Subject == subject && p.Object == object && p.Action == action {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckManaged returns ErrUserConfigManaged when one of the keys of changed is a managed field.
+func CheckManaged(managed []string, changed map[string]interface{}) error {
+	for _, field := range managed {
+		if _, ok := changed[field]; ok {
+			return exception.ErrUserConfigManaged.SetMsg(field + " is managed by config.yml")
+		}
+	}
+	return nil
+}
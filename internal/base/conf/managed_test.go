@@ -0,0 +1,198 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadManagedConfig(t *testing.T) {
+	path := writeConfig(t, `log_level: debug
+http:
+  api:
+    port: 8443
+    client_auth_mode: optional
+discovery:
+  enabled: false
+remote:
+  enable: true
+  api_server_url: https://api.example.com
+  msg_server_urls:
+    - tcp://msg.example.com:1883
+users:
+  - username: alice
+    password: correct horse
+    roles: [operator]
+policies:
+  - subject: operator
+    object: "cmd:backup"
+    action: x
+`)
+	c := NewDefaultConf()
+	if _, err := c.ReadConfigFromYml(path); err != nil {
+		t.Fatal(err)
+	}
+	if c.LogLevel != "debug" || *c.Http["api"].Port != 8443 || *c.Discovery.Enabled {
+		t.Errorf("unexpected config %+v", c)
+	}
+	if got, want := c.ManagedHttpFields("HTTPS_SERVICE_API"), []string{"port", "client_auth_mode"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("managed http fields = %v, want %v", got, want)
+	}
+	if got := c.ManagedHttpFields("HTTP_SERVICE_ADMIN"); got != nil {
+		t.Errorf("managed admin fields = %v, want none", got)
+	}
+	if got, want := c.ManagedRemoteFields(), []string{"enable", "api_server_url", "msg_server_urls"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("managed remote fields = %v, want %v", got, want)
+	}
+	if u := c.ManagedUser("alice"); u == nil || u.Roles[0] != "operator" {
+		t.Errorf("expected alice to be managed")
+	}
+	if !c.IsManagedPolicy("operator", "cmd:backup", "x") || c.IsManagedPolicy("operator", "cmd:backup", "r") {
+		t.Errorf("unexpected managed policies")
+	}
+	if err := CheckManaged(c.ManagedDiscoveryFields(), map[string]interface{}{"enabled": true}); err == nil {
+		t.Errorf("expected the discovery state to be read-only")
+	}
+	if err := CheckManaged(c.ManagedHttpFields("HTTPS_SERVICE_API"), map[string]interface{}{"host": "::"}); err != nil {
+		t.Errorf("expected the host to be writable, got %v", err)
+	}
+}
+
+func TestReadInvalidConfig(t *testing.T) {
+	path := writeConfig(t, `debug: true
+http:
+  api:
+    port: 70000
+    hots: 0.0.0.0
+  admin:
+    port: 2093
+    cert_file: missing.pem
+  web:
+    port: 80
+remote:
+  msg_server_urls: [not a url]
+users:
+  - username: alice
+    password: short
+  - username: alice
+    roles: [alice]
+policies:
+  - subject: operator
+    object: backup
+    action: run
+`)
+	_, err := NewDefaultConf().ReadConfigFromYml(path)
+	var ce *ConfigError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected a ConfigError, got %v", err)
+	}
+	got := make(map[string]int)
+	for _, e := range ce.Errors {
+		got[e.Field] = e.Line
+	}
+	want := map[string]int{
+		"http.api.port":             4,
+		"http.api.hots":             5,
+		"http.admin":                6,
+		"http.web":                  9,
+		"remote.msg_server_urls[0]": 12,
+		"users[0].password":         15,
+		"users[1].username":         16,
+		"users[1].roles[0]":         17,
+		"policies[0].object":        20,
+		"policies[0].action":        21,
+	}
+	for field, line := range want {
+		if got[field] != line {
+			t.Errorf("%s: line %d, want %d", field, got[field], line)
+		}
+	}
+	if _, ok := got["http.admin.cert_file"]; !ok {
+		t.Errorf("expected the missing certificate to be reported, got %v", err)
+	}
+}
+
+func TestReadConfigRoles(t *testing.T) {
+	path := writeConfig(t, `users:
+  - username: admin
+    password: correct horse
+  - username: bob
+    roles: [carol, operator]
+  - username: carol
+    roles: [backup]
+`)
+	_, err := NewDefaultConf().ReadConfigFromYml(path)
+	var ce *ConfigError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected a ConfigError, got %v", err)
+	}
+	got := make(map[string]int)
+	for _, e := range ce.Errors {
+		got[e.Field] = e.Line
+	}
+	// a built-in role and a role of another user can't be usernames
+	want := map[string]int{
+		"users[0].username": 2,
+		"users[2].username": 6,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got errors %v, want %v", got, want)
+	}
+}
+
+func TestReadConfigFile(t *testing.T) {
+	c := NewDefaultConf()
+	path, err := c.ReadConfigFile(t.TempDir())
+	if err != nil || path != "" {
+		t.Errorf("got %q, %v", path, err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yml"), []byte("users: {}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ReadConfigFile(dir); err == nil {
+		t.Errorf("expected an error for an invalid file")
+	}
+}
+
+func TestReloadConcurrentReaders(t *testing.T) {
+	c := NewDefaultConf()
+	enabled := true
+	from := []*Conf{
+		{LogLevel: "info", Users: []UserConf{{Username: "alice"}}, Policies: []PolicyConf{{Subject: "admin", Object: "*", Action: "*"}}},
+		{LogLevel: "debug", Discovery: &DiscoveryConf{Enabled: &enabled}, Remote: &RemoteConf{Enable: &enabled}},
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			c.Reload(from[i%2])
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if u := c.ManagedUser("alice"); u != nil && u.Username != "alice" {
+			t.Fatalf("got user %s", u.Username)
+		}
+		c.IsManagedPolicy("admin", "*", "*")
+		c.ManagedDiscoveryFields()
+		c.ManagedRemoteFields()
+		c.ManagedHttpFields("http_api")
+		c.GetPath()
+	}
+}
@@ -18,3 +18,6 @@ const ServiceName = "FadaControlService"
 const ConfKey = "conf"
 const ClientIdKey = "client_id"
 const CancelFuncKey = "cancel_func"
+
+// RequestIdKey is the key of the id of a HTTP request in the gin context and the field of its log entries.
+const RequestIdKey = "request_id"
@@ -21,8 +21,9 @@ func NewEnforcer(adapter *gormadapter.Adapter) (*casbin.Enforcer, error) {
 	m := model.NewModel()
 	m.AddDef("r", "r", "sub, obj, act")
 	m.AddDef("p", "p", "sub, obj, act")
+	m.AddDef("g", "g", "_, _")
 	m.AddDef("e", "e", "some(where (p.eft == allow))")
-	m.AddDef("m", "m", "(r.sub == p.sub || p.sub == '*') && (r.obj == p.obj || p.obj == '*'||keyMatch2(r.obj, p.obj)) && (r.act == p.act || p.act == '*')")
+	m.AddDef("m", "m", "(g(r.sub, p.sub) || p.sub == '*') && (r.obj == p.obj || p.obj == '*'||keyMatch2(r.obj, p.obj)) && (r.act == p.act || p.act == '*')")
 
 	enforcer, err := casbin.NewEnforcer(m, adapter)
 	if err != nil {
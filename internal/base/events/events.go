@@ -0,0 +1,75 @@
+package events
+
+import (
+	"fadacontrol/pkg/eventbus"
+)
+
+// Topics published on the bus.
+const (
+	ActionRequested    = "action.requested"
+	ActionCompleted    = "action.completed"
+	SlaveConnected     = "slave.connected"
+	SlaveDisconnected  = "slave.disconnected"
+	RemoteConnected    = "remote.connected"
+	RemoteDisconnected = "remote.disconnected"
+	ConfigChanged      = "config.changed"
+	NetworkChanged     = "network.changed"
+)
+
+// Topics lists the topics that can be subscribed to.
+var Topics = []string{
+	ActionRequested, ActionCompleted, SlaveConnected, SlaveDisconnected,
+	RemoteConnected, RemoteDisconnected, ConfigChanged, NetworkChanged,
+}
+
+// Sections of the configuration in ConfigChangedData.
+const (
+	SectionHttp      = "http"
+	SectionRemote    = "remote"
+	SectionDiscovery = "discovery"
+	SectionTwoFactor = "two_factor"
+	SectionAudit     = "audit"
+	SectionUpdate    = "update"
+	// SectionBackup is published when a backup is restored, it can change every section.
+	SectionBackup = "backup"
+	// SectionReload is published when config.yml is reloaded, the name is the path of the file.
+	SectionReload = "reload"
+)
+
+type ActionData struct {
+	Action string `json:"action"`
+	// Code and Msg describe the failure of a completed action.
+	Code    int    `json:"code,omitempty"`
+	Msg     string `json:"msg,omitempty"`
+	Success bool   `json:"success"`
+}
+
+type SlaveData struct {
+	ClientId string `json:"client_id"`
+	Username string `json:"username"`
+}
+
+type RemoteData struct {
+	ClientId string `json:"client_id"`
+}
+
+type ConfigChangedData struct {
+	Section string `json:"section"`
+	// Name is the instance of the section, such as the name of the http server.
+	Name string `json:"name,omitempty"`
+}
+
+// Bus is the in-process bus shared by the services.
+var Bus = eventbus.New()
+
+func Publish(topic string, data interface{}) {
+	Bus.Publish(topic, data)
+}
+
+// PublishConfigChanged publishes ConfigChanged when the change succeeded and passes the error through.
+func PublishConfigChanged(section, name string, err error) error {
+	if err == nil {
+		Publish(ConfigChanged, ConfigChangedData{Section: section, Name: name})
+	}
+	return err
+}
@@ -0,0 +1,26 @@
+package events
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPublishConfigChanged(t *testing.T) {
+	sub := Bus.Subscribe(10, ConfigChanged)
+	defer sub.Close()
+
+	failed := errors.New("failed")
+	if err := PublishConfigChanged(SectionHttp, "api", failed); err != failed {
+		t.Fatalf("expected the error to be passed through, got %v", err)
+	}
+	if err := PublishConfigChanged(SectionHttp, "api", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(sub.C()) != 1 {
+		t.Fatalf("got %d events, want 1", len(sub.C()))
+	}
+	e := <-sub.C()
+	if data, ok := e.Data.(ConfigChangedData); !ok || data.Section != SectionHttp || data.Name != "api" {
+		t.Errorf("unexpected data %+v", e.Data)
+	}
+}
@@ -119,6 +119,54 @@ var (
 		Code: 10020,
 		Msg:  "The verification of the username and password was successful, but there's no need to unlock it on the non-lock screen interface!",
 	}
+	ErrUserCommandNotFound = &Exception{
+		Code: 10021,
+		Msg:  "Command not found",
+	}
+	ErrUserCommandConcurrencyLimit = &Exception{
+		Code: 10022,
+		Msg:  "The maximum number of concurrent instances of this command has been reached",
+	}
+	ErrUserCommandCoolingDown = &Exception{
+		Code: 10023,
+		Msg:  "The command is cooling down, please try again later",
+	}
+	ErrUserPendingActionAlreadyStarted = &Exception{
+		Code: 10024,
+		Msg:  "The pending action has already started",
+	}
+	ErrUserMacroAlreadyRunning = &Exception{
+		Code: 10025,
+		Msg:  "The macro is already running, please try again later",
+	}
+	ErrUserAlreadyExists = &Exception{
+		Code: 10026,
+		Msg:  "User already exists",
+	}
+	ErrUserPasswordChangeRequired = &Exception{
+		Code: 10027,
+		Msg:  "The password must be changed before continuing",
+	}
+	ErrUserRootAccountProtected = &Exception{
+		Code: 10028,
+		Msg:  "The root account cannot be deleted or disabled",
+	}
+	ErrUserLastAdmin = &Exception{
+		Code: 10029,
+		Msg:  "This change would leave no enabled administrator",
+	}
+	ErrUserTwoFactorRequired = &Exception{
+		Code: 10030,
+		Msg:  "Two-factor authentication is required for this account",
+	}
+	ErrUserInvalidTwoFactorCode = &Exception{
+		Code: 10031,
+		Msg:  "Invalid two-factor authentication code",
+	}
+	ErrUserConfigManaged = &Exception{
+		Code: 10032,
+		Msg:  "The setting is managed by config.yml",
+	}
 
 	ErrUserTooManyRequests = &Exception{
 		Code: 11205,
@@ -190,6 +238,14 @@ var (
 		Code: 20019,
 		Msg:  "Request timeout!",
 	}
+	ErrSystemCommandUnavailableInServiceMode = &Exception{
+		Code: 20020,
+		Msg:  "Custom commands can not be executed in service mode",
+	}
+	ErrSystemServiceUnhealthy = &Exception{
+		Code: 20021,
+		Msg:  "One or more components of the service are down",
+	}
 	//9xx
 	ErrUnknownLoginFailure = &Exception{
 		Code: 90001,
@@ -219,6 +275,18 @@ var errorMap = map[int]*Exception{
 	10018: ErrUserCertificateFormatError,
 	10019: ErrUserMethodNotAllowed,
 	10020: ErrUserUnlockNotInLockScreenState,
+	10021: ErrUserCommandNotFound,
+	10022: ErrUserCommandConcurrencyLimit,
+	10023: ErrUserCommandCoolingDown,
+	10024: ErrUserPendingActionAlreadyStarted,
+	10025: ErrUserMacroAlreadyRunning,
+	10026: ErrUserAlreadyExists,
+	10027: ErrUserPasswordChangeRequired,
+	10028: ErrUserRootAccountProtected,
+	10029: ErrUserLastAdmin,
+	10030: ErrUserTwoFactorRequired,
+	10031: ErrUserInvalidTwoFactorCode,
+	10032: ErrUserConfigManaged,
 	11205: ErrUserTooManyRequests,
 	11206: ErrUserAlreadyExistsOneSlave,
 
@@ -239,6 +307,8 @@ var errorMap = map[int]*Exception{
 	20017: ErrSystemInvalidAlgoKeyLen,
 	20018: ErrSystemServiceNotFullyStarted,
 	20019: ErrSystemRequestTimeout,
+	20020: ErrSystemCommandUnavailableInServiceMode,
+	20021: ErrSystemServiceUnhealthy,
 	//9xx
 	90001: ErrUnknownLoginFailure,
 }
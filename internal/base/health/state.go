@@ -0,0 +1,60 @@
+package health
+
+import (
+	"fadacontrol/internal/schema/health_schema"
+	"sync"
+	"time"
+)
+
+// State tracks the status of a component, the zero value is starting.
+type State struct {
+	mu     sync.RWMutex
+	status string
+	err    error
+	since  time.Time
+}
+
+// Set changes the status, err is the reason a component is down.
+func (s *State) Set(status string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status != status {
+		s.since = time.Now()
+	}
+	s.status = status
+	s.err = err
+}
+
+// Component describes the state as the component name.
+func (s *State) Component(name string) health_schema.ComponentHealth {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c := health_schema.ComponentHealth{Name: name, Status: s.status, Since: s.since}
+	if c.Status == "" {
+		c.Status = health_schema.StatusStarting
+	}
+	if s.err != nil {
+		c.Error = s.err.Error()
+	}
+	return c
+}
+
+// Liveness is down when one of the components is down.
+func Liveness(components []health_schema.ComponentHealth) string {
+	for _, c := range components {
+		if c.Status == health_schema.StatusDown {
+			return health_schema.StatusDown
+		}
+	}
+	return health_schema.StatusUp
+}
+
+// Readiness is up when every component is up or disabled.
+func Readiness(components []health_schema.ComponentHealth) string {
+	for _, c := range components {
+		if c.Status != health_schema.StatusUp && c.Status != health_schema.StatusDisabled {
+			return health_schema.StatusDown
+		}
+	}
+	return health_schema.StatusUp
+}
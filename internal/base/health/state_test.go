@@ -0,0 +1,56 @@
+package health
+
+import (
+	"errors"
+	"fadacontrol/internal/schema/health_schema"
+	"testing"
+)
+
+func TestState(t *testing.T) {
+	var s State
+	if c := s.Component("db"); c.Status != health_schema.StatusStarting || !c.Since.IsZero() {
+		t.Errorf("zero state = %+v, want starting", c)
+	}
+	s.Set(health_schema.StatusDown, errors.New("locked"))
+	c := s.Component("db")
+	if c.Name != "db" || c.Status != health_schema.StatusDown || c.Error != "locked" {
+		t.Errorf("down state = %+v", c)
+	}
+	since := c.Since
+	s.Set(health_schema.StatusDown, errors.New("still locked"))
+	if c = s.Component("db"); !c.Since.Equal(since) {
+		t.Errorf("since changed without a status change")
+	}
+	s.Set(health_schema.StatusUp, nil)
+	if c = s.Component("db"); c.Status != health_schema.StatusUp || c.Error != "" {
+		t.Errorf("up state = %+v", c)
+	}
+}
+
+func TestProbes(t *testing.T) {
+	component := func(status string) health_schema.ComponentHealth {
+		return health_schema.ComponentHealth{Status: status}
+	}
+	tests := []struct {
+		name       string
+		components []health_schema.ComponentHealth
+		liveness   string
+		readiness  string
+	}{
+		{"empty", nil, health_schema.StatusUp, health_schema.StatusUp},
+		{"up and disabled", []health_schema.ComponentHealth{component(health_schema.StatusUp), component(health_schema.StatusDisabled)}, health_schema.StatusUp, health_schema.StatusUp},
+		{"starting", []health_schema.ComponentHealth{component(health_schema.StatusUp), component(health_schema.StatusStarting)}, health_schema.StatusUp, health_schema.StatusDown},
+		{"stopped", []health_schema.ComponentHealth{component(health_schema.StatusStopped)}, health_schema.StatusUp, health_schema.StatusDown},
+		{"down", []health_schema.ComponentHealth{component(health_schema.StatusUp), component(health_schema.StatusDown)}, health_schema.StatusDown, health_schema.StatusDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Liveness(tt.components); got != tt.liveness {
+				t.Errorf("Liveness() = %v, want %v", got, tt.liveness)
+			}
+			if got := Readiness(tt.components); got != tt.readiness {
+				t.Errorf("Readiness() = %v, want %v", got, tt.readiness)
+			}
+		})
+	}
+}
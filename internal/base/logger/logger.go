@@ -29,6 +29,8 @@ type Logger struct {
 	logger          *zap.Logger
 	sugar           *zap.SugaredLogger
 	level           _log.Loglevel
+	levelMu         sync.RWMutex
+	atomicLevel     zap.AtomicLevel
 	reportLevel     _log.Loglevel
 	ctx             context.Context
 	logPath         string
@@ -96,9 +98,29 @@ func GetLogLevel() string {
 	if logger == nil {
 		return ""
 	}
+	logger.levelMu.RLock()
+	defer logger.levelMu.RUnlock()
 	return logger.logLevel
 }
 
+// SetLogLevel changes the level of the running logger.
+func SetLogLevel(level string) error {
+	if logger == nil {
+		return errors.New("the logger is not initialized")
+	}
+	switch level {
+	case "debug", "info", "warn", "error", "fatal":
+	default:
+		return fmt.Errorf("invalid log level %s", level)
+	}
+	logger.levelMu.Lock()
+	defer logger.levelMu.Unlock()
+	logger.level = str2Loglevel(level)
+	logger.logLevel = level
+	logger.atomicLevel.SetLevel(logger.level.ZapLevel())
+	return nil
+}
+
 func (l *Logger) Init(logPath string, loglevel _log.Loglevel) error {
 
 	_, err := os.Stat(logPath)
@@ -127,6 +149,7 @@ func (l *Logger) Init(logPath string, loglevel _log.Loglevel) error {
 	l.level = loglevel
 	atomicLevel := zap.NewAtomicLevel()
 	atomicLevel.SetLevel(l.level.ZapLevel())
+	l.atomicLevel = atomicLevel
 	logHook := lumberjack.Logger{
 		Filename:   logPath,
 		MaxSize:    1,
@@ -256,9 +279,8 @@ func (l *Logger) Fatalf(format string, v ...interface{}) {
 	l.ReportFatalMsg(fmt.Sprintf(format, v...))
 }
 
-var loglevel _log.Loglevel = _log.InfoLevel
-
 func str2Loglevel(level string) _log.Loglevel {
+	var loglevel _log.Loglevel
 	switch level {
 	case "debug":
 		loglevel = _log.DebugLevel
@@ -373,11 +395,25 @@ func Errorf(format string, v ...interface{}) {
 
 }
 
+// With returns a logger that adds the key value pairs as fields of its entries.
+func With(keysAndValues ...interface{}) *zap.SugaredLogger {
+	if logger == nil {
+		return zap.NewNop().Sugar()
+	}
+	// the caller skip of the logger is for the wrappers of this package
+	return logger.logger.WithOptions(zap.AddCallerSkip(-2)).Sugar().With(keysAndValues...)
+}
+
 func GetLogger() *Logger {
 	return logger
 }
+func (l *Logger) getLevel() _log.Loglevel {
+	l.levelMu.RLock()
+	defer l.levelMu.RUnlock()
+	return l.level
+}
 func (l *Logger) Println(v ...interface{}) {
-	switch l.level {
+	switch l.getLevel() {
 	case _log.DebugLevel:
 		l.Debug(v...)
 		break
@@ -399,7 +435,7 @@ func (l *Logger) Println(v ...interface{}) {
 	}
 }
 func (l *Logger) Printf(format string, v ...interface{}) {
-	switch l.level {
+	switch l.getLevel() {
 	case _log.DebugLevel:
 		l.Debugf(format, v...)
 		break
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "fadacontrol"
+
+// Results of the counted operations.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Directions of the discovery packets.
+const (
+	DirectionSent     = "sent"
+	DirectionReceived = "received"
+)
+
+// Registry holds the metrics of the service and the Go runtime and process collectors.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by server, method, route and status code.",
+	}, []string{"server", "method", "route", "status"})
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the HTTP requests by server, method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"server", "method", "route"})
+	controlActions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "control_actions_total",
+		Help:      "Control actions such as lock, shutdown and standby by type and result.",
+	}, []string{"action", "result"})
+	unlockFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "unlock_failures_total",
+		Help:      "Failed unlock attempts by source.",
+	}, []string{"source"})
+	discoveryPackets = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "discovery_packets_total",
+		Help:      "UDP discovery packets by direction.",
+	}, []string{"direction"})
+	remoteMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "remote_messages_total",
+		Help:      "Messages received from the remote servers by type and result.",
+	}, []string{"type", "result"})
+	connectedSlaves = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "connected_slaves",
+		Help:      "Slave programs connected to the service.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{Namespace: namespace}),
+		httpRequests, httpRequestDuration, controlActions, unlockFailures, discoveryPackets, remoteMessages, connectedSlaves,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Result maps an error to the result label.
+func Result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+	return ResultSuccess
+}
+
+// ObserveRequest counts a request and records its latency, route is the pattern of the matched route.
+func ObserveRequest(server, method, route string, status int, elapsed time.Duration) {
+	httpRequests.WithLabelValues(server, method, route, strconv.Itoa(status)).Inc()
+	httpRequestDuration.WithLabelValues(server, method, route).Observe(elapsed.Seconds())
+}
+
+func ControlAction(action, result string) {
+	controlActions.WithLabelValues(action, result).Inc()
+}
+
+func UnlockFailure(source string) {
+	unlockFailures.WithLabelValues(source).Inc()
+}
+
+func DiscoveryPacket(direction string) {
+	discoveryPackets.WithLabelValues(direction).Inc()
+}
+
+func RemoteMessage(tpe, result string) {
+	remoteMessages.WithLabelValues(tpe, result).Inc()
+}
+
+func SetConnectedSlaves(n int) {
+	connectedSlaves.Set(float64(n))
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	ControlAction("lock", Result(nil))
+	ControlAction("shutdown", Result(errors.New("denied")))
+	ObserveRequest("api", http.MethodGet, "/api/v1/ping", http.StatusOK, 10*time.Millisecond)
+	SetConnectedSlaves(2)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	for _, want := range []string{
+		`fadacontrol_control_actions_total{action="lock",result="success"} 1`,
+		`fadacontrol_control_actions_total{action="shutdown",result="failure"} 1`,
+		`fadacontrol_http_requests_total{method="GET",route="/api/v1/ping",server="api",status="200"} 1`,
+		`fadacontrol_connected_slaves 2`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics output does not contain %q", want)
+		}
+	}
+}
@@ -36,13 +36,13 @@ func ErrorHandler() gin.HandlerFunc {
 			status := http.StatusInternalServerError //The default is 500
 			if code == exception.ErrUserResourceNotFound.Code {
 				status = http.StatusNotFound
+			} else if code == exception.ErrUserTooManyRequests.Code {
+				status = http.StatusTooManyRequests
 			} else if (code >= exception.UserErrorStart) && (code <= exception.UserErrorEnd) {
 
 				status = http.StatusBadRequest
 			} else if code == exception.ErrSuccess.Code {
 				status = http.StatusOK
-			} else if code == exception.ErrUserTooManyRequests.Code {
-				status = http.StatusTooManyRequests
 			} else {
 
 			}
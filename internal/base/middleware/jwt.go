@@ -2,11 +2,17 @@ package middleware
 
 import "C"
 import (
+	"crypto/x509"
 	"fadacontrol/internal/base/conf"
 	"fadacontrol/internal/base/exception"
 	"fadacontrol/internal/controller"
+	"fadacontrol/internal/entity"
 	"fadacontrol/internal/service/auth_service"
+	"fadacontrol/internal/service/cert_service"
 	"fadacontrol/internal/service/jwt_service"
+	"fadacontrol/internal/service/token_service"
+	"fadacontrol/internal/service/two_factor_service"
+	"fadacontrol/internal/service/user_service"
 	"fadacontrol/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"net/http"
@@ -17,10 +23,14 @@ import (
 type JwtMiddleware struct {
 	jw   *jwt_service.JwtService
 	auth *auth_service.AuthService
+	us   *user_service.UserService
+	ts   *token_service.TokenService
+	tf   *two_factor_service.TwoFactorService
+	cs   *cert_service.CertService
 }
 
-func NewJwtMiddleware(jw *jwt_service.JwtService, auth *auth_service.AuthService) *JwtMiddleware {
-	return &JwtMiddleware{jw: jw, auth: auth}
+func NewJwtMiddleware(jw *jwt_service.JwtService, auth *auth_service.AuthService, us *user_service.UserService, ts *token_service.TokenService, tf *two_factor_service.TwoFactorService, cs *cert_service.CertService) *JwtMiddleware {
+	return &JwtMiddleware{jw: jw, auth: auth, us: us, ts: ts, tf: tf, cs: cs}
 }
 
 func (j *JwtMiddleware) JWTAuthMiddleware() gin.HandlerFunc {
@@ -32,6 +42,10 @@ func (j *JwtMiddleware) JWTAuthMiddleware() gin.HandlerFunc {
 		}
 		tokenString := c.GetHeader("Authorization")
 		if tokenString == "" || !strings.HasPrefix(tokenString, "Bearer ") {
+			if c.Request.TLS != nil && len(c.Request.TLS.VerifiedChains) > 0 {
+				j.authenticateClientCert(c, c.Request.TLS.VerifiedChains[0][0])
+				return
+			}
 			if j.auth.CheckHttpPermission("", c.Request.URL.Path, j.RequestMethodToAuthAction(c.Request.Method)) {
 				c.Next()
 				return
@@ -50,18 +64,121 @@ func (j *JwtMiddleware) JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		claims, err := j.jw.ValidateToken(strings.TrimPrefix(tokenString, "Bearer "))
+		tokenString = strings.TrimPrefix(tokenString, "Bearer ")
+		if strings.HasPrefix(tokenString, token_service.TokenPrefix) {
+			j.authenticateApiToken(c, tokenString)
+			return
+		}
+		claims, err := j.jw.ValidateToken(tokenString)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, controller.GetGinError(c, exception.ErrUserUnauthorizedAccess))
+			c.Abort()
+			return
+		}
+
+		user, err := j.us.GetActiveUser(claims.Username)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, controller.GetGinError(c, exception.ErrUserUnauthorizedAccess))
 			c.Abort()
 			return
 		}
+		if !j.isSelfServicePath(c.Request.URL.Path) {
+			if !claims.TwoFactor && j.tf.Applies(user.Username) {
+				c.JSON(http.StatusForbidden, controller.GetGinError(c, exception.ErrUserTwoFactorRequired))
+				c.Abort()
+				return
+			}
+			if !j.authorize(c, user) {
+				return
+			}
+		}
 
 		c.Set("username", claims.Username)
+		c.Set("session_id", claims.SessionId)
+		c.Set("two_factor", claims.TwoFactor)
 
 		c.Next()
 	}
 }
+
+// authenticateApiToken handles requests carrying an api token, they are limited to the scopes of the token
+// and never reach the self-service endpoints.
+func (j *JwtMiddleware) authenticateApiToken(c *gin.Context, raw string) {
+	// the address of the peer is used on purpose, forwarded headers could be forged to bypass the IP restriction
+	token, err := j.ts.Authenticate(raw, c.RemoteIP())
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, controller.GetGinError(c, exception.ErrUserUnauthorizedAccess))
+		c.Abort()
+		return
+	}
+	user, err := j.us.GetActiveUser(token.Username)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, controller.GetGinError(c, exception.ErrUserUnauthorizedAccess))
+		c.Abort()
+		return
+	}
+	if j.isSelfServicePath(c.Request.URL.Path) || !token_service.ScopesPermit(j.ts.Scopes(token), c.Request.URL.Path, j.RequestMethodToAuthAction(c.Request.Method)) {
+		c.JSON(http.StatusForbidden, controller.GetGinError(c, exception.ErrUserUnauthorizedAccess))
+		c.Abort()
+		return
+	}
+	// tokens are created from two-factor sessions, but may predate the enrollment
+	if j.tf.Applies(user.Username) && !j.tf.IsEnabled(user.Username) {
+		c.JSON(http.StatusForbidden, controller.GetGinError(c, exception.ErrUserTwoFactorRequired))
+		c.Abort()
+		return
+	}
+	if !j.authorize(c, user) {
+		return
+	}
+	c.Set("username", user.Username)
+	c.Set("api_token_id", token.ID)
+	c.Next()
+}
+
+// authenticateClientCert handles requests without a bearer token over a TLS connection with a verified client
+// certificate. The subject of the certificate is mapped to the user it was issued for, like api tokens the requests
+// have no session and never reach the self-service endpoints.
+func (j *JwtMiddleware) authenticateClientCert(c *gin.Context, peer *x509.Certificate) {
+	cert, err := j.cs.AuthenticateClientCert(peer)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, controller.GetGinError(c, exception.ErrUserUnauthorizedAccess))
+		c.Abort()
+		return
+	}
+	user, err := j.us.GetActiveUser(cert.Username)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, controller.GetGinError(c, exception.ErrUserUnauthorizedAccess))
+		c.Abort()
+		return
+	}
+	if j.isSelfServicePath(c.Request.URL.Path) {
+		c.JSON(http.StatusForbidden, controller.GetGinError(c, exception.ErrUserUnauthorizedAccess))
+		c.Abort()
+		return
+	}
+	if !j.authorize(c, user) {
+		return
+	}
+	c.Set("username", user.Username)
+	c.Set("client_cert_id", cert.ID)
+	c.Next()
+}
+
+// authorize checks the casbin policies of user and aborts the request when it is not allowed.
+func (j *JwtMiddleware) authorize(c *gin.Context, user *entity.User) bool {
+	if user.MustChangePassword {
+		c.JSON(http.StatusForbidden, controller.GetGinError(c, exception.ErrUserPasswordChangeRequired))
+		c.Abort()
+		return false
+	}
+	if !j.auth.CheckHttpPermission(user.Username, c.Request.URL.Path, j.RequestMethodToAuthAction(c.Request.Method)) {
+		c.JSON(http.StatusForbidden, controller.GetGinError(c, exception.ErrUserUnauthorizedAccess))
+		c.Abort()
+		return false
+	}
+	return true
+}
 func (j *JwtMiddleware) RequestMethodToAuthAction(method string) auth_service.Action {
 	switch method {
 	case http.MethodGet:
@@ -79,6 +196,15 @@ func (j *JwtMiddleware) RequestMethodToAuthAction(method string) auth_service.Ac
 	}
 
 }
+func (j *JwtMiddleware) isSelfServicePath(path string) bool {
+	for _, pattern := range conf.SelfServicePaths {
+		matched, _ := regexp.MatchString(pattern, path)
+		if matched {
+			return true
+		}
+	}
+	return false
+}
 func (j *JwtMiddleware) isIgnoredPath(path string) bool {
 	for _, pattern := range conf.IgnoredPaths {
 		matched, _ := regexp.MatchString(pattern, path)
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"fadacontrol/internal/base/metrics"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// Metrics records the count and latency of the requests of server by route pattern, requests that match no
+// route share one label so that scanning the API does not create new series.
+func Metrics(server string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveRequest(server, c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
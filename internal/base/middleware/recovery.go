@@ -2,7 +2,6 @@ package middleware
 
 import (
 	"fadacontrol/internal/base/exception"
-	"fadacontrol/internal/base/logger"
 	"fadacontrol/internal/controller"
 	"fmt"
 	"github.com/gin-gonic/gin"
@@ -14,7 +13,7 @@ func Recovery() gin.HandlerFunc {
 		defer func() {
 
 			if err := recover(); err != nil {
-				logger.Errorf("panic recover: %v", err)
+				RequestLogger(c).Errorf("panic recover: %v", err)
 				c.JSON(http.StatusInternalServerError, controller.GetGinErrorWithData(c, exception.ErrUnknownException, fmt.Sprintf("%v", err)))
 			}
 		}()
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// RequestIdHeader is the header of the id of a request, an id sent by the client is kept.
+const RequestIdHeader = "X-Request-Id"
+
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestLog gives every request an id, returned in the X-Request-Id header, and logs the completed request with the
+// id in the request_id field. Successful requests are logged at the debug level.
+func RequestLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIdHeader)
+		if !requestIdPattern.MatchString(id) {
+			id = uuid.NewString()
+		}
+		c.Set(constants.RequestIdKey, id)
+		c.Header(RequestIdHeader, id)
+		start := time.Now()
+		c.Next()
+
+		l := RequestLogger(c).With("status", c.Writer.Status(), "latency", time.Since(start).String(), "client_ip", c.ClientIP())
+		if username := c.GetString("username"); username != "" {
+			l = l.With("username", username)
+		}
+		if err := c.Errors.Last(); err != nil {
+			l = l.With("error", err.Error())
+		}
+		switch status := c.Writer.Status(); {
+		case status >= http.StatusInternalServerError:
+			l.Warnf("%s %s", c.Request.Method, c.Request.URL.Path)
+		case status >= http.StatusBadRequest:
+			l.Infof("%s %s", c.Request.Method, c.Request.URL.Path)
+		default:
+			l.Debugf("%s %s", c.Request.Method, c.Request.URL.Path)
+		}
+	}
+}
+
+// RequestLogger returns the logger of the request, its entries have the request_id field.
+func RequestLogger(c *gin.Context) *zap.SugaredLogger {
+	return logger.With(constants.RequestIdKey, c.GetString(constants.RequestIdKey))
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/pkg/logsearch"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestLog(t *testing.T) {
+	c := conf.NewDefaultConf()
+	c.SetWorkdir(t.TempDir())
+	c.LogLevel = "debug"
+	logger.InitLog(context.WithValue(context.Background(), constants.ConfKey, c))
+	if logger.GetLogPath() == "" {
+		t.Fatal("the logger was not initialized")
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestLog())
+	r.GET("/unlock", func(c *gin.Context) {
+		RequestLogger(c).Info("unlocking")
+		c.Status(http.StatusBadRequest)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/unlock", nil)
+	req.Header.Set(RequestIdHeader, "client-id.1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if got := w.Header().Get(RequestIdHeader); got != "client-id.1" {
+		t.Errorf("request id = %q, want the one of the client", got)
+	}
+
+	// an invalid id is replaced
+	req = httptest.NewRequest(http.MethodGet, "/unlock", nil)
+	req.Header.Set(RequestIdHeader, "bad id\n")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	generated := w.Header().Get(RequestIdHeader)
+	if generated == "" || generated == "bad id\n" {
+		t.Fatalf("request id = %q, want a generated one", generated)
+	}
+
+	logger.Sync()
+	for _, id := range []string{"client-id.1", generated} {
+		entries, total, err := logsearch.Search(logger.GetLogPath(), &logsearch.Filter{Level: zapcore.DebugLevel, RequestId: id}, 0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 2 {
+			t.Fatalf("found %d entries of %s, want the entry of the handler and of the request", total, id)
+		}
+		// newest first
+		if entries[0].Message != "GET /unlock" || entries[0].Level != zapcore.InfoLevel || entries[0].Fields["status"] != float64(http.StatusBadRequest) {
+			t.Errorf("request entry = %+v", entries[0])
+		}
+		if entries[1].Message != "unlocking" || entries[1].Module() != "middleware" {
+			t.Errorf("handler entry = %+v", entries[1])
+		}
+	}
+}
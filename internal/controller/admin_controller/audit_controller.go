@@ -0,0 +1,140 @@
+package admin_controller
+
+import (
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/controller"
+	"fadacontrol/internal/schema/audit_schema"
+	"fadacontrol/internal/service/audit_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type AuditController struct {
+	as *audit_service.AuditService
+}
+
+func NewAuditController(as *audit_service.AuditService) *AuditController {
+	return &AuditController{as: as}
+}
+
+// @Summary List Audit Events
+// @Description Retrieve the recorded control actions, newest first. Secrets in the parameters are redacted.
+// @Tags Audit
+// @Produce json
+// @Security ApiKeyAuth
+// @Param from query string false "Only events at or after this RFC 3339 time"
+// @Param to query string false "Only events before this RFC 3339 time"
+// @Param actor_type query string false "Actor type" Enums(user, token, device, remote, scheduler, anonymous)
+// @Param actor query string false "Username, remote client or scheduled task name"
+// @Param source query string false "Source" Enums(lan_http, admin, relay, scheduler)
+// @Param action query string false "Action" Enums(shutdown, standby, lock, unlock, pending_cancel, pending_postpone, command, macro)
+// @Param result query string false "Result" Enums(success, failure)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} schema.ResponseData "Successfully retrieved events."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /audit [get]
+func (a *AuditController) ListEvents(c *gin.Context) {
+	q := audit_schema.AuditQuery{Page: 1, PageSize: 20}
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.Error(exception.ErrUserParameterError.SetMsg(err.Error()))
+		return
+	}
+	if q.Page < 1 || q.PageSize < 1 || q.PageSize > 100 {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	events, total, err := a.as.ListEvents(&q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, audit_schema.AuditEventListResponse{Total: total, Events: events}))
+}
+
+// @Summary Export Audit Events
+// @Description Download every recorded control action matching the filters as CSV or as a JSON array, oldest first.
+// @Tags Audit
+// @Produce text/csv,json
+// @Security ApiKeyAuth
+// @Param format query string false "Export format" Enums(csv, json) default(csv)
+// @Param from query string false "Only events at or after this RFC 3339 time"
+// @Param to query string false "Only events before this RFC 3339 time"
+// @Param actor_type query string false "Actor type"
+// @Param actor query string false "Username, remote client or scheduled task name"
+// @Param source query string false "Source"
+// @Param action query string false "Action"
+// @Param result query string false "Result" Enums(success, failure)
+// @Success 200 {file} file "The events."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /audit/export [get]
+func (a *AuditController) ExportEvents(c *gin.Context) {
+	var q audit_schema.AuditQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.Error(exception.ErrUserParameterError.SetMsg(err.Error()))
+		return
+	}
+	format := c.DefaultQuery("format", audit_schema.ExportCsv)
+	switch format {
+	case audit_schema.ExportCsv:
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+	case audit_schema.ExportJson:
+		c.Header("Content-Type", "application/json; charset=utf-8")
+	default:
+		c.Error(exception.ErrUserParameterError.SetMsg("format must be csv or json"))
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename=fadacontrol-audit."+format)
+	if err := a.as.Export(&q, format, c.Writer); err != nil {
+		if c.Writer.Written() {
+			logger.Errorf("failed to export the audit log: %v", err)
+			return
+		}
+		c.Writer.Header().Del("Content-Disposition")
+		c.Error(err)
+	}
+}
+
+// @Summary Get Audit Retention
+// @Description Retrieve how long and how many audit events are kept, zero means no limit.
+// @Tags Audit
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved the retention policy."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /audit/retention [get]
+func (a *AuditController) GetRetention(c *gin.Context) {
+	resp, err := a.as.GetRetention()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Set Audit Retention
+// @Description Set how many days and how many audit events are kept, zero means no limit. Events outside the new policy are deleted.
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param retention body audit_schema.AuditRetention true "Retention policy"
+// @Success 200 {object} schema.ResponseData "Successfully saved the retention policy."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /audit/retention [put]
+func (a *AuditController) SetRetention(c *gin.Context) {
+	var req audit_schema.AuditRetention
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError.SetMsg(err.Error()))
+		return
+	}
+	if err := a.as.SetRetention(&req); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
@@ -0,0 +1,68 @@
+package admin_controller
+
+import (
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/controller"
+	"fadacontrol/internal/schema/backup_schema"
+	"fadacontrol/internal/service/backup_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type BackupController struct {
+	bs *backup_service.BackupService
+}
+
+func NewBackupController(bs *backup_service.BackupService) *BackupController {
+	return &BackupController{bs: bs}
+}
+
+// @Summary Export Backup
+// @Description Export the configuration, users, policies and the command files of the macros and scheduled tasks that are in the commands directory of the workdir. The private keys and password hashes are encrypted with a key derived from the passphrase.
+// @Tags Backup
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body backup_schema.ExportRequest true "Passphrase of at least 8 characters"
+// @Success 200 {object} schema.ResponseData "The archive."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /backup/export [post]
+func (b *BackupController) Export(c *gin.Context) {
+	var req backup_schema.ExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError.SetMsg(err.Error()))
+		return
+	}
+	archive, err := b.bs.Export(req.Passphrase)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, archive))
+}
+
+// @Summary Import Backup
+// @Description Restore an archive, older archives are migrated first. The entries of the archive are added or updated, the other entries are kept. Command files must be in the commands directory of the workdir. A dry run only returns the changes.
+// @Tags Backup
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body backup_schema.ImportRequest true "Archive and passphrase"
+// @Success 200 {object} schema.ResponseData "The changes of the import."
+// @Failure 400 {object} schema.ResponseData "Invalid archive or wrong passphrase."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /backup/import [post]
+func (b *BackupController) Import(c *gin.Context) {
+	var req backup_schema.ImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError.SetMsg(err.Error()))
+		return
+	}
+	resp, err := b.bs.Import(&req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
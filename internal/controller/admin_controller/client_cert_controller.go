@@ -0,0 +1,103 @@
+package admin_controller
+
+import (
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/controller"
+	"fadacontrol/internal/schema/cert_schema"
+	"fadacontrol/internal/service/cert_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type ClientCertController struct {
+	cs *cert_service.CertService
+}
+
+func NewClientCertController(cs *cert_service.CertService) *ClientCertController {
+	return &ClientCertController{cs: cs}
+}
+
+// @Summary List Client Certificates
+// @Description List the client certificates issued by the local authority, of every user or of one user.
+// @Tags Certificate
+// @Produce json
+// @Security ApiKeyAuth
+// @Param username query string false "Only list the certificates of this user"
+// @Success 200 {object} schema.ResponseData "Successfully retrieved certificates."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /certs/clients [get]
+func (cc *ClientCertController) ListClientCerts(c *gin.Context) {
+	resp, err := cc.cs.ListClientCerts(c.Query("username"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Issue Client Certificate
+// @Description Issue a client certificate that authenticates as the user on the HTTPS API, the private key is only returned in this response.
+// @Tags Certificate
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body cert_schema.IssueClientCertRequest true "Certificate to issue"
+// @Success 200 {object} schema.ResponseData "Successfully issued certificate."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters or the user does not exist."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /certs/clients [post]
+func (cc *ClientCertController) IssueClientCert(c *gin.Context) {
+	var req cert_schema.IssueClientCertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	resp, err := cc.cs.IssueClientCert(&req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Revoke Client Certificate
+// @Description Revoke a client certificate, it is rejected immediately and listed in the revocation list.
+// @Tags Certificate
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Certificate id"
+// @Success 200 {object} schema.ResponseData "Successfully revoked certificate."
+// @Failure 400 {object} schema.ResponseData "The certificate does not exist."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /certs/clients/{id} [delete]
+func (cc *ClientCertController) RevokeClientCert(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	if err := cc.cs.RevokeClientCert(uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary Get Certificate Revocation List
+// @Description Download the PEM encoded revocation list signed by the local authority.
+// @Tags Certificate
+// @Produce application/x-pem-file
+// @Security ApiKeyAuth
+// @Success 200 {string} string "The revocation list."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /certs/crl [get]
+func (cc *ClientCertController) GetCRL(c *gin.Context) {
+	crl, err := cc.cs.CRL()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename=fadacontrol.crl")
+	c.Data(http.StatusOK, "application/x-pem-file", crl)
+}
@@ -0,0 +1,168 @@
+package admin_controller
+
+import (
+	"context"
+	"fadacontrol/internal/base/events"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/controller"
+	"fadacontrol/pkg/eventbus"
+	"fadacontrol/pkg/goroutine"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// eventBufferSize is the number of events buffered for a slow stream before they are dropped.
+const eventBufferSize = 64
+
+const (
+	eventHeartbeat    = 30 * time.Second
+	eventWriteTimeout = 10 * time.Second
+)
+
+type EventController struct {
+	ctx context.Context
+}
+
+func NewEventController(ctx context.Context) *EventController {
+	return &EventController{ctx: ctx}
+}
+
+var eventUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// @Summary List Event Topics
+// @Description Retrieve the topics that can be subscribed to, * subscribes to every topic and name.* to the topics starting with name.
+// @Tags Event
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved topics."
+// @Router /events/topics [get]
+func (e *EventController) ListTopics(c *gin.Context) {
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, events.Topics))
+}
+
+// @Summary Stream Events
+// @Description Stream the events of the service as Server-Sent Events, or as JSON text frames when the request is a websocket upgrade. The id of an event increases by one, a gap means the stream was too slow and events were dropped.
+// @Tags Event
+// @Produce text/event-stream
+// @Security ApiKeyAuth
+// @Param topics query string false "Comma separated topics, every topic when empty"
+// @Success 200 {string} string "The event stream."
+// @Success 101 {string} string "Switching protocols"
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Router /events [get]
+func (e *EventController) Stream(c *gin.Context) {
+	var topics []string
+	if query := c.Query("topics"); query != "" {
+		topics = strings.Split(query, ",")
+		for _, topic := range topics {
+			if !isValidTopic(topic) {
+				c.Error(exception.ErrUserParameterError.SetMsg("unknown topic " + topic))
+				return
+			}
+		}
+	}
+	sub := events.Bus.Subscribe(eventBufferSize, topics...)
+	defer sub.Close()
+
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		e.streamWebsocket(c, sub)
+		return
+	}
+	e.streamSSE(c, sub)
+}
+
+func isValidTopic(pattern string) bool {
+	for _, topic := range events.Topics {
+		if eventbus.Match(pattern, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *EventController) streamSSE(c *gin.Context, sub *eventbus.Subscription) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-e.ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+		case ev, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			if err := eventbus.WriteSSE(c.Writer, ev); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+func (e *EventController) streamWebsocket(c *gin.Context, sub *eventbus.Subscription) {
+	conn, err := eventUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logger.Warnf("event stream upgrade error: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// the client only sends control frames, reading detects when it goes away
+	closed := make(chan struct{})
+	goroutine.RecoverGO(func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-e.ctx.Done():
+			conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(eventWriteTimeout))
+			return
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eventWriteTimeout)); err != nil {
+				return
+			}
+		case ev, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(eventWriteTimeout))
+			if err := conn.WriteJSON(ev); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -141,6 +141,34 @@ func (h *HttpController) PatchHttpConfig(c *gin.Context) {
 	c.Error(exception.ErrUserParameterError)
 }
 
+// @Summary Renew Server Certificate
+// @Description Reissue the certificate of the HTTPS API with the local authority and turn on its automatic renewal, new connections use it right away.
+// @Tags HTTP
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully renewed certificate."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /http/cert/renew [post]
+func (h *HttpController) RenewServerCert(c *gin.Context) {
+	resp, err := h.hs.RenewServerCert()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Get HTTP Bindings
+// @Description Retrieve the addresses the HTTP servers are listening on.
+// @Tags HTTP
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved bindings."
+// @Router /http/bindings [get]
+func (h *HttpController) GetBindings(c *gin.Context) {
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, h.hs.Bindings()))
+}
+
 // @Summary Exit Service
 // @Description Exit the server
 // @Tags HTTP
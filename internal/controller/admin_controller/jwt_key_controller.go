@@ -0,0 +1,52 @@
+package admin_controller
+
+import (
+	"fadacontrol/internal/controller"
+	"fadacontrol/internal/service/jwt_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type JwtKeyController struct {
+	jw *jwt_service.JwtService
+}
+
+func NewJwtKeyController(jw *jwt_service.JwtService) *JwtKeyController {
+	return &JwtKeyController{jw: jw}
+}
+
+// @Summary List Signing Keys
+// @Description List the keys that sign or still verify access tokens, secrets are never returned.
+// @Tags Auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved keys."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /auth/keys [get]
+func (k *JwtKeyController) ListKeys(c *gin.Context) {
+	resp, err := k.jw.ListKeys()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Rotate Signing Key
+// @Description Replace the active signing key. Tokens signed with the old key stay valid until they expire.
+// @Tags Auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Param algorithm query string false "Algorithm of the new key, defaults to the algorithm of the current key" Enums(HS256, EdDSA)
+// @Success 200 {object} schema.ResponseData "Successfully rotated key."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /auth/keys/rotate [post]
+func (k *JwtKeyController) RotateKey(c *gin.Context) {
+	resp, err := k.jw.RotateKey(c.Query("algorithm"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
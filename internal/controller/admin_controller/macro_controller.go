@@ -0,0 +1,235 @@
+package admin_controller
+
+import (
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/controller"
+	"fadacontrol/internal/schema/audit_schema"
+	"fadacontrol/internal/schema/custom_command_schema"
+	"fadacontrol/internal/schema/macro_schema"
+	"fadacontrol/internal/service/audit_service"
+	"fadacontrol/internal/service/macro_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type MacroController struct {
+	ms *macro_service.MacroService
+	as *audit_service.AuditService
+}
+
+func NewMacroController(as *audit_service.AuditService, ms *macro_service.MacroService) *MacroController {
+	return &MacroController{ms: ms, as: as}
+}
+
+func getMacroId(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// @Summary List Macros
+// @Description Retrieve all macros with their steps.
+// @Tags Macro
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved macros."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /macros [get]
+func (m *MacroController) ListMacros(c *gin.Context) {
+	resp, err := m.ms.ListMacros()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Get Macro
+// @Description Retrieve a macro by id.
+// @Tags Macro
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Macro id"
+// @Success 200 {object} schema.ResponseData "Successfully retrieved macro."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /macros/{id} [get]
+func (m *MacroController) GetMacro(c *gin.Context) {
+	id, ok := getMacroId(c)
+	if !ok {
+		return
+	}
+	resp, err := m.ms.GetMacro(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Create Macro
+// @Description Create a named sequence of steps. Each step runs when its condition matches the result of the previous executed step. Command steps run with the permissions of the creating user.
+// @Tags Macro
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param macro body macro_schema.MacroRequest true "Macro"
+// @Success 200 {object} schema.ResponseData "Successfully created macro."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /macros [post]
+func (m *MacroController) CreateMacro(c *gin.Context) {
+	var req macro_schema.MacroRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError.SetMsg(err.Error()))
+		return
+	}
+	resp, err := m.ms.CreateMacro(&req, c.GetString("username"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Update Macro
+// @Description Replace the name, description and steps of a macro.
+// @Tags Macro
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Macro id"
+// @Param macro body macro_schema.MacroRequest true "Macro"
+// @Success 200 {object} schema.ResponseData "Successfully updated macro."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /macros/{id} [put]
+func (m *MacroController) UpdateMacro(c *gin.Context) {
+	id, ok := getMacroId(c)
+	if !ok {
+		return
+	}
+	var req macro_schema.MacroRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError.SetMsg(err.Error()))
+		return
+	}
+	resp, err := m.ms.UpdateMacro(id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Delete Macro
+// @Description Delete a macro, its execution log is kept.
+// @Tags Macro
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Macro id"
+// @Success 200 {object} schema.ResponseData "Successfully deleted macro."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /macros/{id} [delete]
+func (m *MacroController) DeleteMacro(c *gin.Context) {
+	id, ok := getMacroId(c)
+	if !ok {
+		return
+	}
+	if err := m.ms.DeleteMacro(id); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary Run Macro
+// @Description Start a macro in the background. A macro runs once at a time, poll the returned run for the step-by-step result.
+// @Tags Macro
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Macro id"
+// @Success 200 {object} schema.ResponseData "Macro started."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters or another macro is running."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /macros/{id}/run [post]
+func (m *MacroController) RunMacro(c *gin.Context) {
+	id, ok := getMacroId(c)
+	if !ok {
+		return
+	}
+	resp, err := m.ms.RunMacro(id, c.GetString("username"), custom_command_schema.LanSource)
+	params := map[string]interface{}{"macro_id": id}
+	if resp != nil {
+		params["run_id"] = resp.Id
+	}
+	m.as.RecordHttp(c, audit_schema.ActionMacro, params, err)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary List Macro Runs
+// @Description Retrieve the runs of a macro, newest first.
+// @Tags Macro
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Macro id"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} schema.ResponseData "Successfully retrieved runs."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /macros/{id}/runs [get]
+func (m *MacroController) ListRuns(c *gin.Context) {
+	id, ok := getMacroId(c)
+	if !ok {
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	runs, total, err := m.ms.ListRuns(id, page, pageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, macro_schema.MacroRunListResponse{Total: total, Runs: runs}))
+}
+
+// @Summary Get Macro Run
+// @Description Retrieve a macro run with its step-by-step execution log.
+// @Tags Macro
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Run id"
+// @Success 200 {object} schema.ResponseData "Successfully retrieved run."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /macro-runs/{id} [get]
+func (m *MacroController) GetRun(c *gin.Context) {
+	id, ok := getMacroId(c)
+	if !ok {
+		return
+	}
+	resp, err := m.ms.GetRun(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
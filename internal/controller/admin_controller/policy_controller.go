@@ -0,0 +1,165 @@
+package admin_controller
+
+import (
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/controller"
+	"fadacontrol/internal/schema/policy_schema"
+	"fadacontrol/internal/service/policy_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type PolicyController struct {
+	ps *policy_service.PolicyService
+}
+
+func NewPolicyController(ps *policy_service.PolicyService) *PolicyController {
+	return &PolicyController{ps: ps}
+}
+
+// @Summary List Policies
+// @Description Retrieve all casbin policies as subject, object and action.
+// @Tags Policy
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved policies."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /policies [get]
+func (p *PolicyController) ListPolicies(c *gin.Context) {
+	resp, err := p.ps.ListPolicies()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Add Policy
+// @Description Allow a user or role to perform an action on an object. Objects are *, api:<path>, cmd:<command name> or cmd:/<config file>/<command name>, actions are r, w, x or *.
+// @Tags Policy
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param policy body policy_schema.Policy true "Policy"
+// @Success 200 {object} schema.ResponseData "Successfully added policy."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /policies [post]
+func (p *PolicyController) AddPolicy(c *gin.Context) {
+	var req policy_schema.Policy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	if err := p.ps.AddPolicy(&req); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary Remove Policy
+// @Description Remove a policy. The request is rejected when no enabled administrator would be left.
+// @Tags Policy
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param policy body policy_schema.Policy true "Policy"
+// @Success 200 {object} schema.ResponseData "Successfully removed policy."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /policies [delete]
+func (p *PolicyController) RemovePolicy(c *gin.Context) {
+	var req policy_schema.Policy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	if err := p.ps.RemovePolicy(&req); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary List Roles
+// @Description Retrieve the built-in roles and the roles in use, with their policies and members.
+// @Tags Policy
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved roles."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /roles [get]
+func (p *PolicyController) ListRoles(c *gin.Context) {
+	resp, err := p.ps.ListRoles()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary List Role Assignments
+// @Description Retrieve all user to role assignments.
+// @Tags Policy
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved role assignments."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /roles/assignments [get]
+func (p *PolicyController) ListRoleAssignments(c *gin.Context) {
+	resp, err := p.ps.ListRoleAssignments()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Assign Role
+// @Description Assign a role to a user.
+// @Tags Policy
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param assignment body policy_schema.RoleAssignment true "Role assignment"
+// @Success 200 {object} schema.ResponseData "Successfully assigned role."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /roles/assignments [post]
+func (p *PolicyController) AddRoleAssignment(c *gin.Context) {
+	var req policy_schema.RoleAssignment
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	if err := p.ps.AddRoleAssignment(&req); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary Unassign Role
+// @Description Remove a role from a user. The request is rejected when no enabled administrator would be left.
+// @Tags Policy
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param assignment body policy_schema.RoleAssignment true "Role assignment"
+// @Success 200 {object} schema.ResponseData "Successfully removed role."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /roles/assignments [delete]
+func (p *PolicyController) RemoveRoleAssignment(c *gin.Context) {
+	var req policy_schema.RoleAssignment
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	if err := p.ps.RemoveRoleAssignment(&req); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
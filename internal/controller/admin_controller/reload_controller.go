@@ -0,0 +1,35 @@
+package admin_controller
+
+import (
+	"fadacontrol/internal/controller"
+	"fadacontrol/internal/service/reload_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type ReloadController struct {
+	rs *reload_service.ReloadService
+}
+
+func NewReloadController(rs *reload_service.ReloadService) *ReloadController {
+	return &ReloadController{rs: rs}
+}
+
+// @Summary Reload Configuration
+// @Description Read config.yml again and apply the log level, the discovery and remote settings and the HTTP listeners without restarting the service. The discovery and remote services and the HTTP listeners are only restarted when their configuration changed.
+// @Tags System
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "The result of every component."
+// @Failure 400 {object} schema.ResponseData "The config file is invalid, nothing was changed."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /sys/reload [post]
+func (r *ReloadController) Reload(c *gin.Context) {
+	resp, err := r.rs.Reload()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
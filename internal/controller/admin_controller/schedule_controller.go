@@ -0,0 +1,251 @@
+package admin_controller
+
+import (
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/controller"
+	"fadacontrol/internal/schema/schedule_schema"
+	"fadacontrol/internal/service/scheduler_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type ScheduleController struct {
+	sch *scheduler_service.SchedulerService
+}
+
+func NewScheduleController(sch *scheduler_service.SchedulerService) *ScheduleController {
+	return &ScheduleController{sch: sch}
+}
+
+func getTaskId(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// @Summary List Scheduled Tasks
+// @Description Retrieve all scheduled tasks with their next activation time.
+// @Tags Schedule
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved tasks."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /schedule/tasks [get]
+func (s *ScheduleController) ListTasks(c *gin.Context) {
+	resp, err := s.sch.ListTasks()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Get Scheduled Task
+// @Description Retrieve a scheduled task by id.
+// @Tags Schedule
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Task id"
+// @Success 200 {object} schema.ResponseData "Successfully retrieved task."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /schedule/tasks/{id} [get]
+func (s *ScheduleController) GetTask(c *gin.Context) {
+	id, ok := getTaskId(c)
+	if !ok {
+		return
+	}
+	resp, err := s.sch.GetTask(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Create Scheduled Task
+// @Description Create a cron or one-shot task. Exactly one of cron_expr and run_at must be set. Command tasks run with the permissions of the creating user.
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param task body schedule_schema.ScheduledTaskRequest true "Task"
+// @Success 200 {object} schema.ResponseData "Successfully created task."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /schedule/tasks [post]
+func (s *ScheduleController) CreateTask(c *gin.Context) {
+	var req schedule_schema.ScheduledTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError.SetMsg(err.Error()))
+		return
+	}
+	resp, err := s.sch.CreateTask(&req, c.GetString("username"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Update Scheduled Task
+// @Description Replace a scheduled task, the next activation is recomputed.
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Task id"
+// @Param task body schedule_schema.ScheduledTaskRequest true "Task"
+// @Success 200 {object} schema.ResponseData "Successfully updated task."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /schedule/tasks/{id} [put]
+func (s *ScheduleController) UpdateTask(c *gin.Context) {
+	id, ok := getTaskId(c)
+	if !ok {
+		return
+	}
+	var req schedule_schema.ScheduledTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError.SetMsg(err.Error()))
+		return
+	}
+	resp, err := s.sch.UpdateTask(id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Delete Scheduled Task
+// @Description Delete a scheduled task and its execution history.
+// @Tags Schedule
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Task id"
+// @Success 200 {object} schema.ResponseData "Successfully deleted task."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /schedule/tasks/{id} [delete]
+func (s *ScheduleController) DeleteTask(c *gin.Context) {
+	id, ok := getTaskId(c)
+	if !ok {
+		return
+	}
+	if err := s.sch.DeleteTask(id); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary Run Scheduled Task
+// @Description Run a scheduled task immediately and wait for its result.
+// @Tags Schedule
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Task id"
+// @Success 200 {object} schema.ResponseData "Task finished successfully."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /schedule/tasks/{id}/run [post]
+func (s *ScheduleController) RunTask(c *gin.Context) {
+	id, ok := getTaskId(c)
+	if !ok {
+		return
+	}
+	if ex := s.sch.RunTask(id); ex != nil && ex.Code != exception.ErrSuccess.Code {
+		c.Error(ex)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary Preview Task Activations
+// @Description Retrieve the next activations of a scheduled task.
+// @Tags Schedule
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Task id"
+// @Param count query int false "Number of activations" default(5)
+// @Success 200 {object} schema.ResponseData "Successfully computed activations."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /schedule/tasks/{id}/next-runs [get]
+func (s *ScheduleController) TaskNextRuns(c *gin.Context) {
+	id, ok := getTaskId(c)
+	if !ok {
+		return
+	}
+	count, _ := strconv.Atoi(c.DefaultQuery("count", "5"))
+	runs, err := s.sch.TaskNextRuns(id, count)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, schedule_schema.NextRunsResponse{NextRuns: runs}))
+}
+
+// @Summary Preview Schedule
+// @Description Compute the next activations of a cron expression or one-shot time without saving a task.
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param schedule body schedule_schema.NextRunsRequest true "Schedule"
+// @Success 200 {object} schema.ResponseData "Successfully computed activations."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Router /schedule/preview [post]
+func (s *ScheduleController) PreviewNextRuns(c *gin.Context) {
+	var req schedule_schema.NextRunsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError.SetMsg(err.Error()))
+		return
+	}
+	runs, err := s.sch.PreviewNextRuns(&req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, schedule_schema.NextRunsResponse{NextRuns: runs}))
+}
+
+// @Summary Get Task History
+// @Description Retrieve the execution history of a scheduled task, newest first. Missed activations are included.
+// @Tags Schedule
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Task id"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} schema.ResponseData "Successfully retrieved history."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /schedule/tasks/{id}/history [get]
+func (s *ScheduleController) ListRuns(c *gin.Context) {
+	id, ok := getTaskId(c)
+	if !ok {
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	runs, total, err := s.sch.ListRuns(id, page, pageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, schedule_schema.ScheduledTaskRunListResponse{Total: total, Runs: runs}))
+}
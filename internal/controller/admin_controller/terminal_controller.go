@@ -0,0 +1,223 @@
+package admin_controller
+
+import (
+	"context"
+	"encoding/json"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/schema/audit_schema"
+	"fadacontrol/internal/schema/custom_command_schema"
+	"fadacontrol/internal/service/audit_service"
+	"fadacontrol/internal/service/auth_service"
+	"fadacontrol/internal/service/custom_command_service"
+	"fadacontrol/pkg/goroutine"
+	"fadacontrol/pkg/pty"
+	"fadacontrol/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type TerminalController struct {
+	as   *audit_service.AuditService
+	ctx  context.Context
+	cu   *custom_command_service.CustomCommandService
+	auth *auth_service.AuthService
+}
+
+func NewTerminalController(as *audit_service.AuditService, ctx context.Context, cu *custom_command_service.CustomCommandService, auth *auth_service.AuthService) *TerminalController {
+	return &TerminalController{as: as, ctx: ctx, cu: cu, auth: auth}
+}
+
+var terminalUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// @Summary Open Terminal
+// @Description Spawn a shell or a registered command in a pseudo-terminal and relay it over a websocket. Input and resize events are sent as JSON text frames, output is sent as binary frames. The transcript is recorded under log/terminal.
+// @Tags Terminal
+// @Security ApiKeyAuth
+// @Param path query string false "Path of the command config file, required when name is set"
+// @Param name query string false "Name of the registered command, the default shell is used when empty"
+// @Param rows query int false "Terminal rows" default(24)
+// @Param cols query int false "Terminal columns" default(80)
+// @Success 101 {string} string "Switching protocols"
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /terminal [get]
+func (t *TerminalController) OpenTerminal(c *gin.Context) {
+	username := c.GetString("username")
+	cmd, term, err := t.startTerminal(c, username)
+	if err != nil {
+		t.as.RecordHttp(c, audit_schema.ActionTerminal, map[string]interface{}{"path": c.Query("path"), "name": c.Query("name")}, err)
+		c.Error(err)
+		return
+	}
+
+	conn, err := terminalUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logger.Warnf("terminal upgrade error: %v", err)
+		_ = term.Close()
+		t.as.RecordHttp(c, audit_schema.ActionTerminal, map[string]interface{}{"command": cmd.Name}, exception.ErrUserParameterError.SetMsg(err.Error()))
+		return
+	}
+	defer conn.Close()
+
+	sessionId := uuid.New().String()
+	transcript, err := t.openTranscript(sessionId)
+	if err != nil {
+		logger.Warnf("failed to open terminal transcript: %v", err)
+	} else {
+		defer transcript.Close()
+	}
+	logger.Infof("terminal session %s opened by %s from %s, command %s, transcript %s", sessionId, username, c.ClientIP(), cmd.Name, t.transcriptPath(sessionId))
+	start := time.Now()
+	defer func() {
+		// the exit code is set once the process was killed or exited
+		_ = term.Close()
+		code := term.ExitCode()
+		logger.Infof("terminal session %s closed, exit code %d", sessionId, code)
+		t.as.RecordHttp(c, audit_schema.ActionTerminal, map[string]interface{}{
+			"session_id": sessionId,
+			"command":    cmd.Name,
+			"transcript": t.transcriptPath(sessionId),
+			"exit_code":  code,
+			"duration":   time.Since(start).Round(time.Second).String(),
+		}, nil)
+	}()
+
+	s := &terminalSession{conn: conn, term: term, transcript: transcript}
+	s.run(conf.TerminalIdleTimeout)
+}
+
+// startTerminal checks the permissions of username and spawns the requested command in a pseudo-terminal.
+func (t *TerminalController) startTerminal(c *gin.Context, username string) (custom_command_schema.Command, *pty.Terminal, error) {
+	if !t.auth.CheckHttpPermission(username, c.Request.URL.Path, auth_service.Write) {
+		return custom_command_schema.Command{}, nil, exception.ErrUserUnauthorizedAccess
+	}
+
+	cmd := custom_command_service.DefaultShellCommand()
+	if name := c.Query("name"); name != "" {
+		cmds, err := t.cu.ReadConfig(c.Query("path"))
+		if err != nil {
+			return cmd, nil, exception.ErrUserParameterError.SetMsg(err.Error())
+		}
+		var ok bool
+		cmd, ok = cmds[name]
+		if !ok {
+			return cmd, nil, exception.ErrUserCommandNotFound
+		}
+		if !t.auth.CheckCommandPermission(username, cmd, custom_command_schema.LanSource) {
+			return cmd, nil, exception.ErrUserUnauthorizedAccess
+		}
+	}
+	rows, _ := strconv.Atoi(c.DefaultQuery("rows", "24"))
+	cols, _ := strconv.Atoi(c.DefaultQuery("cols", "80"))
+
+	term, err := t.cu.StartTerminal(cmd, uint16(rows), uint16(cols))
+	if err != nil {
+		return cmd, nil, err
+	}
+	return cmd, term, nil
+}
+func (t *TerminalController) transcriptPath(sessionId string) string {
+	_conf := utils.GetValueFromContext(t.ctx, constants.ConfKey, conf.NewDefaultConf())
+	return filepath.Join(_conf.GetWorkdir(), "log", "terminal", sessionId+".log")
+}
+func (t *TerminalController) openTranscript(sessionId string) (*os.File, error) {
+	path := t.transcriptPath(sessionId)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+}
+
+type terminalSession struct {
+	conn       *websocket.Conn
+	term       *pty.Terminal
+	transcript *os.File
+	writeMu    sync.Mutex
+	closeOnce  sync.Once
+}
+
+func (s *terminalSession) writeMessage(messageType int, data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.conn.WriteMessage(messageType, data)
+}
+func (s *terminalSession) sendExit(code int, msg string) {
+	s.closeOnce.Do(func() {
+		data, _ := json.Marshal(custom_command_schema.TerminalMessage{Type: custom_command_schema.TerminalExit, Code: code, Msg: msg})
+		_ = s.writeMessage(websocket.TextMessage, data)
+		s.writeMu.Lock()
+		_ = s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, msg), time.Now().Add(time.Second))
+		s.writeMu.Unlock()
+		_ = s.conn.Close()
+		_ = s.term.Close()
+	})
+}
+func (s *terminalSession) run(idleTimeout time.Duration) {
+	idle := time.AfterFunc(idleTimeout, func() {
+		s.sendExit(-1, "idle timeout")
+	})
+	defer idle.Stop()
+
+	goroutine.RecoverGO(func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := s.term.Read(buf)
+			if n > 0 {
+				idle.Reset(idleTimeout)
+				if s.transcript != nil {
+					_, _ = s.transcript.Write(buf[:n])
+				}
+				if err := s.writeMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+					break
+				}
+			}
+			if err != nil {
+				break
+			}
+		}
+		<-s.term.Done()
+		s.sendExit(s.term.ExitCode(), "process exited")
+	})
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			s.sendExit(-1, "connection closed")
+			return
+		}
+		idle.Reset(idleTimeout)
+		var msg custom_command_schema.TerminalMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			logger.Debugf("invalid terminal message: %v", err)
+			continue
+		}
+		switch msg.Type {
+		case custom_command_schema.TerminalInput:
+			if _, err := s.term.Write([]byte(msg.Data)); err != nil {
+				s.sendExit(-1, err.Error())
+				return
+			}
+		case custom_command_schema.TerminalResize:
+			if err := s.term.Resize(msg.Rows, msg.Cols); err != nil {
+				logger.Debugf("terminal resize failed: %v", err)
+			}
+		}
+	}
+}
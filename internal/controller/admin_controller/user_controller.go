@@ -0,0 +1,219 @@
+package admin_controller
+
+import (
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/controller"
+	"fadacontrol/internal/schema/user_schema"
+	"fadacontrol/internal/service/user_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type UserController struct {
+	us *user_service.UserService
+}
+
+func NewUserController(us *user_service.UserService) *UserController {
+	return &UserController{us: us}
+}
+
+// @Summary List Users
+// @Description Retrieve all users with their roles.
+// @Tags User
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved users."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /users [get]
+func (u *UserController) ListUsers(c *gin.Context) {
+	resp, err := u.us.ListUsers()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Get User
+// @Description Retrieve a user by name.
+// @Tags User
+// @Produce json
+// @Security ApiKeyAuth
+// @Param username path string true "Username"
+// @Success 200 {object} schema.ResponseData "Successfully retrieved user."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /users/{username} [get]
+func (u *UserController) GetUser(c *gin.Context) {
+	resp, err := u.us.GetUser(c.Param("username"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Create User
+// @Description Create a user and assign its roles.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user body user_schema.CreateUserRequest true "User"
+// @Success 200 {object} schema.ResponseData "Successfully created user."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /users [post]
+func (u *UserController) CreateUser(c *gin.Context) {
+	var req user_schema.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	resp, err := u.us.CreateUser(&req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Delete User
+// @Description Delete a user together with its roles and policies. The root account cannot be deleted.
+// @Tags User
+// @Produce json
+// @Security ApiKeyAuth
+// @Param username path string true "Username"
+// @Success 200 {object} schema.ResponseData "Successfully deleted user."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /users/{username} [delete]
+func (u *UserController) DeleteUser(c *gin.Context) {
+	if err := u.us.DeleteUser(c.Param("username")); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary Disable User
+// @Description Disable a user, its existing tokens stop working immediately. The root account cannot be disabled.
+// @Tags User
+// @Produce json
+// @Security ApiKeyAuth
+// @Param username path string true "Username"
+// @Success 200 {object} schema.ResponseData "Successfully disabled user."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /users/{username}/disable [post]
+func (u *UserController) DisableUser(c *gin.Context) {
+	if err := u.us.SetDisabled(c.Param("username"), true); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary Enable User
+// @Description Enable a disabled user.
+// @Tags User
+// @Produce json
+// @Security ApiKeyAuth
+// @Param username path string true "Username"
+// @Success 200 {object} schema.ResponseData "Successfully enabled user."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /users/{username}/enable [post]
+func (u *UserController) EnableUser(c *gin.Context) {
+	if err := u.us.SetDisabled(c.Param("username"), false); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary Reset Password
+// @Description Set a new password for a user, the user must change it after the next login.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param username path string true "Username"
+// @Param request body user_schema.ResetPasswordRequest true "New password"
+// @Success 200 {object} schema.ResponseData "Successfully reset password."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /users/{username}/reset-password [post]
+func (u *UserController) ResetPassword(c *gin.Context) {
+	var req user_schema.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	if err := u.us.ResetPassword(c.Param("username"), req.Password); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary Set User Roles
+// @Description Replace the roles assigned to a user.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param username path string true "Username"
+// @Param request body user_schema.SetRolesRequest true "Roles"
+// @Success 200 {object} schema.ResponseData "Successfully updated roles."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /users/{username}/roles [put]
+func (u *UserController) SetRoles(c *gin.Context) {
+	var req user_schema.SetRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	if err := u.us.SetRoles(c.Param("username"), req.Roles); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary Revoke User Sessions
+// @Description Sign out every session of a user, api tokens are not affected.
+// @Tags User
+// @Produce json
+// @Security ApiKeyAuth
+// @Param username path string true "Username"
+// @Success 200 {object} schema.ResponseData "Successfully revoked sessions."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /users/{username}/sessions [delete]
+func (u *UserController) RevokeSessions(c *gin.Context) {
+	if err := u.us.RevokeSessions(c.Param("username")); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary Reset Two-Factor Authentication
+// @Description Turn off two-factor authentication of a user who lost the authenticator and the recovery codes, the sessions of the user are signed out.
+// @Tags User
+// @Produce json
+// @Security ApiKeyAuth
+// @Param username path string true "Username"
+// @Success 200 {object} schema.ResponseData "Successfully reset."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /users/{username}/2fa [delete]
+func (u *UserController) ResetTwoFactor(c *gin.Context) {
+	if err := u.us.ResetTwoFactor(c.Param("username")); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
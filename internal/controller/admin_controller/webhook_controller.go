@@ -0,0 +1,211 @@
+package admin_controller
+
+import (
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/controller"
+	"fadacontrol/internal/schema/webhook_schema"
+	"fadacontrol/internal/service/webhook_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type WebhookController struct {
+	wh *webhook_service.WebhookService
+}
+
+func NewWebhookController(wh *webhook_service.WebhookService) *WebhookController {
+	return &WebhookController{wh: wh}
+}
+
+func getWebhookId(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// @Summary List Webhook Events
+// @Description Retrieve the events a webhook can subscribe to, * subscribes to every event.
+// @Tags Webhook
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved events."
+// @Router /webhooks/events [get]
+func (w *WebhookController) ListEvents(c *gin.Context) {
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, webhook_schema.Events))
+}
+
+// @Summary List Webhooks
+// @Description Retrieve all webhooks, the secrets are not returned.
+// @Tags Webhook
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved webhooks."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /webhooks [get]
+func (w *WebhookController) ListWebhooks(c *gin.Context) {
+	resp, err := w.wh.ListWebhooks()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Create Webhook
+// @Description Create a webhook, the deliveries are signed with the HMAC-SHA256 of the timestamp and the body. The secret is only returned in this response.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param webhook body webhook_schema.WebhookRequest true "Webhook"
+// @Success 200 {object} schema.ResponseData "Successfully created webhook."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /webhooks [post]
+func (w *WebhookController) CreateWebhook(c *gin.Context) {
+	var req webhook_schema.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError.SetMsg(err.Error()))
+		return
+	}
+	resp, err := w.wh.CreateWebhook(&req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Get Webhook
+// @Description Retrieve a webhook by id.
+// @Tags Webhook
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Webhook id"
+// @Success 200 {object} schema.ResponseData "Successfully retrieved webhook."
+// @Failure 404 {object} schema.ResponseData "The webhook does not exist."
+// @Router /webhooks/{id} [get]
+func (w *WebhookController) GetWebhook(c *gin.Context) {
+	id, ok := getWebhookId(c)
+	if !ok {
+		return
+	}
+	resp, err := w.wh.GetWebhook(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Update Webhook
+// @Description Replace a webhook, an empty secret keeps the current one.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Webhook id"
+// @Param webhook body webhook_schema.WebhookRequest true "Webhook"
+// @Success 200 {object} schema.ResponseData "Successfully updated webhook."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 404 {object} schema.ResponseData "The webhook does not exist."
+// @Router /webhooks/{id} [put]
+func (w *WebhookController) UpdateWebhook(c *gin.Context) {
+	id, ok := getWebhookId(c)
+	if !ok {
+		return
+	}
+	var req webhook_schema.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError.SetMsg(err.Error()))
+		return
+	}
+	resp, err := w.wh.UpdateWebhook(id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Delete Webhook
+// @Description Delete a webhook and its delivery log.
+// @Tags Webhook
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Webhook id"
+// @Success 200 {object} schema.ResponseData "Successfully deleted webhook."
+// @Failure 404 {object} schema.ResponseData "The webhook does not exist."
+// @Router /webhooks/{id} [delete]
+func (w *WebhookController) DeleteWebhook(c *gin.Context) {
+	id, ok := getWebhookId(c)
+	if !ok {
+		return
+	}
+	if err := w.wh.DeleteWebhook(id); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary Test Webhook
+// @Description Send the webhook.test event once, also to a disabled webhook, and return the result of the attempt.
+// @Tags Webhook
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Webhook id"
+// @Success 200 {object} schema.ResponseData "The attempt, check success for the result of the delivery."
+// @Failure 404 {object} schema.ResponseData "The webhook does not exist."
+// @Router /webhooks/{id}/test [post]
+func (w *WebhookController) TestWebhook(c *gin.Context) {
+	id, ok := getWebhookId(c)
+	if !ok {
+		return
+	}
+	resp, err := w.wh.TestWebhook(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary List Webhook Deliveries
+// @Description Retrieve the delivery attempts of a webhook, newest first.
+// @Tags Webhook
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Webhook id"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} schema.ResponseData "Successfully retrieved deliveries."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 404 {object} schema.ResponseData "The webhook does not exist."
+// @Router /webhooks/{id}/deliveries [get]
+func (w *WebhookController) ListDeliveries(c *gin.Context) {
+	id, ok := getWebhookId(c)
+	if !ok {
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	deliveries, total, err := w.wh.ListDeliveries(id, page, pageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, webhook_schema.WebhookDeliveryListResponse{Total: total, Deliveries: deliveries}))
+}
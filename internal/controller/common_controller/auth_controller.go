@@ -3,8 +3,13 @@ package common_controller
 import (
 	"fadacontrol/internal/base/exception"
 	"fadacontrol/internal/controller"
+	"fadacontrol/internal/entity"
 	"fadacontrol/internal/schema"
+	"fadacontrol/internal/schema/two_factor_schema"
+	"fadacontrol/internal/schema/user_schema"
 	"fadacontrol/internal/service/jwt_service"
+	"fadacontrol/internal/service/throttle_service"
+	"fadacontrol/internal/service/two_factor_service"
 	"fadacontrol/internal/service/user_service"
 	"github.com/gin-gonic/gin"
 	"net/http"
@@ -13,14 +18,27 @@ import (
 type AuthController struct {
 	s   *user_service.UserService
 	jwt *jwt_service.JwtService
+	th  *throttle_service.ThrottleService
+	tf  *two_factor_service.TwoFactorService
 }
 
-func NewAuthController(s *user_service.UserService, jwt *jwt_service.JwtService) *AuthController {
-	return &AuthController{s: s, jwt: jwt}
+func NewAuthController(s *user_service.UserService, jwt *jwt_service.JwtService, th *throttle_service.ThrottleService, tf *two_factor_service.TwoFactorService) *AuthController {
+	return &AuthController{s: s, jwt: jwt, th: th, tf: tf}
+}
+
+func (u *AuthController) createSession(c *gin.Context, user *entity.User, twoFactor bool) {
+	resp, err := u.jwt.CreateSession(user.Username, c.ClientIP(), c.Request.UserAgent(), twoFactor)
+	if err != nil {
+		c.Error(exception.ErrSystemGenTokenErr)
+		return
+	}
+	resp.MustChangePassword = user.MustChangePassword
+	resp.TwoFactorEnrollmentRequired = !twoFactor && u.tf.Applies(user.Username)
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
 }
 
 // @Summary User Login
-// @Description Authenticate user with username and password to obtain a JWT token.
+// @Description Authenticate user with username and password to obtain a short-lived JWT access token and a refresh token. When two-factor authentication is enabled, either pass the code along or complete the returned challenge with /login/2fa.
 // @Tags User
 // @Accept json
 // @Produce json
@@ -28,6 +46,7 @@ func NewAuthController(s *user_service.UserService, jwt *jwt_service.JwtService)
 // @Param loginData body schema.LoginRequest true "User login credentials"
 // @Success 200 {object} schema.ResponseData "Successfully authenticated."
 // @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 429 {object} schema.ResponseData "Too many failed attempts."
 // @Failure 500 {object} schema.ResponseData "Internal Server Error"
 // @Router /login [post]
 func (u *AuthController) Login(c *gin.Context) {
@@ -39,15 +58,204 @@ func (u *AuthController) Login(c *gin.Context) {
 		return
 	}
 
+	source := c.RemoteIP()
+	if ex := u.th.Check(throttle_service.KindLogin, source, loginData.Username); ex != nil {
+		c.Error(ex)
+		return
+	}
 	user, err := u.s.Login(loginData.Username, loginData.Password)
 	if err != nil {
+		if exception.ErrUserAccountDisabled.Equal(err) {
+			c.Error(exception.ErrUserAccountDisabled)
+			return
+		}
+		u.th.Failure(throttle_service.KindLogin, source, loginData.Username)
 		c.Error(exception.ErrUserLogonFailure)
 		return
 	}
-	token, err := u.jwt.GenerateToken(user.Username)
+	twoFactor := false
+	if u.tf.IsEnabled(user.Username) {
+		if loginData.Code == "" {
+			challenge, err := u.tf.NewChallenge(user.Username)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, schema.TokenResponse{TwoFactorRequired: true, Challenge: challenge}))
+			return
+		}
+		if err := u.tf.Verify(user.Username, loginData.Code); err != nil {
+			u.th.Failure(throttle_service.KindLogin, source, user.Username)
+			c.Error(err)
+			return
+		}
+		twoFactor = true
+	}
+	u.th.Success(throttle_service.KindLogin, user.Username)
+	u.createSession(c, user, twoFactor)
+}
+
+// @Summary Complete Two-Factor Login
+// @Description Complete a login challenge with a TOTP code or a recovery code.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body two_factor_schema.LoginChallengeRequest true "Challenge and code"
+// @Success 200 {object} schema.ResponseData "Successfully authenticated."
+// @Failure 400 {object} schema.ResponseData "Invalid code or challenge."
+// @Failure 429 {object} schema.ResponseData "Too many failed attempts."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /login/2fa [post]
+func (u *AuthController) CompleteTwoFactor(c *gin.Context) {
+	var req two_factor_schema.LoginChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	username, err := u.tf.ChallengeUser(req.Challenge)
 	if err != nil {
-		c.Error(exception.ErrSystemGenTokenErr)
+		c.Error(err)
+		return
+	}
+	source := c.RemoteIP()
+	if ex := u.th.Check(throttle_service.KindLogin, source, username); ex != nil {
+		c.Error(ex)
+		return
+	}
+	if _, err := u.tf.CompleteChallenge(req.Challenge, req.Code); err != nil {
+		u.th.Failure(throttle_service.KindLogin, source, username)
+		c.Error(err)
+		return
+	}
+	user, err := u.s.GetActiveUser(username)
+	if err != nil {
+		c.Error(exception.ErrUserLogonFailure)
+		return
+	}
+	u.th.Success(throttle_service.KindLogin, username)
+	u.createSession(c, user, true)
+}
+
+// @Summary Refresh Token
+// @Description Exchange a refresh token for a new access token and refresh token. Every refresh token can only be used once.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body schema.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} schema.ResponseData "Successfully refreshed."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /auth/refresh [post]
+func (u *AuthController) Refresh(c *gin.Context) {
+	var req schema.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	resp, err := u.jwt.Refresh(req.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Logout
+// @Description Revoke the session of the current access token together with its refresh token.
+// @Tags User
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully logged out."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /auth/logout [post]
+func (u *AuthController) Logout(c *gin.Context) {
+	sid := c.GetString("session_id")
+	if sid == "" {
+		c.Error(exception.ErrUserUnauthorizedAccess)
+		return
+	}
+	if err := u.jwt.RevokeSession(sid, c.GetString("username")); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary List My Sessions
+// @Description List the active login sessions of the current user.
+// @Tags User
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved sessions."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /user/sessions [get]
+func (u *AuthController) ListSessions(c *gin.Context) {
+	username := c.GetString("username")
+	if username == "" {
+		c.Error(exception.ErrUserUnauthorizedAccess)
+		return
+	}
+	resp, err := u.jwt.ListSessions(username, c.GetString("session_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Revoke My Session
+// @Description Sign out one of the sessions of the current user.
+// @Tags User
+// @Produce json
+// @Security ApiKeyAuth
+// @Param sid path string true "Session id"
+// @Success 200 {object} schema.ResponseData "Successfully revoked session."
+// @Failure 400 {object} schema.ResponseData "The session does not exist."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /user/sessions/{sid} [delete]
+func (u *AuthController) RevokeSession(c *gin.Context) {
+	username := c.GetString("username")
+	if username == "" {
+		c.Error(exception.ErrUserUnauthorizedAccess)
+		return
+	}
+	if err := u.jwt.RevokeSession(c.Param("sid"), username); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary Change Password
+// @Description Change the password of the current user, the old password is required. All sessions of the user are signed out and a new one is returned.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body user_schema.ChangePasswordRequest true "Old and new password"
+// @Success 200 {object} schema.ResponseData "Successfully changed password."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters or wrong password."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /user/password [put]
+func (u *AuthController) ChangePassword(c *gin.Context) {
+	username := c.GetString("username")
+	if username == "" {
+		c.Error(exception.ErrUserUnauthorizedAccess)
+		return
+	}
+	var req user_schema.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	if err := u.s.ChangePassword(username, req.OldPassword, req.NewPassword); err != nil {
+		c.Error(err)
+		return
+	}
+	user, err := u.s.GetActiveUser(username)
+	if err != nil {
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, schema.TokenResponse{Token: token}))
+	u.createSession(c, user, c.GetBool("two_factor"))
 }
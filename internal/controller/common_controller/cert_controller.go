@@ -0,0 +1,63 @@
+package common_controller
+
+import (
+	"encoding/pem"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/controller"
+	"fadacontrol/internal/service/cert_service"
+	"fadacontrol/internal/service/http_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type CertController struct {
+	hs *http_service.HttpService
+	cs *cert_service.CertService
+}
+
+func NewCertController(hs *http_service.HttpService, cs *cert_service.CertService) *CertController {
+	return &CertController{hs: hs, cs: cs}
+}
+
+// @Summary Download CA Certificate
+// @Description Download the certificate of the local authority, install it on phones and other clients to trust the HTTPS API.
+// @Tags Certificate
+// @Produce application/x-x509-ca-cert
+// @Param format query string false "Encoding of the certificate, defaults to der" Enums(der, pem)
+// @Success 200 {string} string "The CA certificate."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /certs/ca [get]
+func (cc *CertController) GetCaCert(c *gin.Context) {
+	ca, err := cc.cs.CACertificate()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	switch c.DefaultQuery("format", "der") {
+	case "der":
+		c.Header("Content-Disposition", "attachment; filename=fadacontrol-ca.crt")
+		c.Data(http.StatusOK, "application/x-x509-ca-cert", ca.Raw)
+	case "pem":
+		c.Header("Content-Disposition", "attachment; filename=fadacontrol-ca.pem")
+		c.Data(http.StatusOK, "application/x-pem-file", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}))
+	default:
+		c.Error(exception.ErrUserParameterError)
+	}
+}
+
+// @Summary Get Certificates
+// @Description Get the subjects, validity and SHA-256 fingerprints of the local authority and of the server certificate, for certificate pinning.
+// @Tags Certificate
+// @Produce json
+// @Success 200 {object} schema.ResponseData "Successfully retrieved certificates."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /certs [get]
+func (cc *CertController) GetCertificates(c *gin.Context) {
+	resp, err := cc.hs.Certificates()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
@@ -6,8 +6,10 @@ import (
 	"fadacontrol/internal/base/constants"
 	"fadacontrol/internal/base/exception"
 	"fadacontrol/internal/controller"
+	"fadacontrol/internal/schema"
+	"fadacontrol/internal/schema/audit_schema"
+	"fadacontrol/internal/service/audit_service"
 	"fadacontrol/internal/service/control_pc"
-	"fadacontrol/pkg/goroutine"
 	"fadacontrol/pkg/sys"
 	"fadacontrol/pkg/utils"
 	"strconv"
@@ -19,11 +21,12 @@ import (
 
 type ControlPCController struct {
 	p   *control_pc.ControlPCService
+	as  *audit_service.AuditService
 	ctx context.Context
 }
 
-func NewControlPCController(ctx context.Context, p *control_pc.ControlPCService) *ControlPCController {
-	return &ControlPCController{ctx: ctx, p: p}
+func NewControlPCController(as *audit_service.AuditService, ctx context.Context, p *control_pc.ControlPCService) *ControlPCController {
+	return &ControlPCController{as: as, ctx: ctx, p: p}
 }
 
 // ControlPC Control computer interface
@@ -52,24 +55,28 @@ func (o *ControlPCController) ControlPC(c *gin.Context) {
 	}
 	var ret error
 	switch action {
-	case "shutdown":
-
+	case "shutdown", "standby":
 		tpe := c.DefaultQuery("shutdown_type", strconv.Itoa(int(sys.S_E_FORCE_SHUTDOWN)))
 		shutdownType, err := strconv.Atoi(tpe)
 		if err != nil {
 			c.Error(exception.ErrUserParameterError)
 			return
 		}
-		goroutine.RecoverGO(func() {
-			time.Sleep(time.Duration(delaySec) * time.Second)
-			ret = o.p.Shutdown(sys.ShutdownType(shutdownType))
-		})
-
-	case "standby":
-		goroutine.RecoverGO(func() {
-			time.Sleep(time.Duration(delaySec) * time.Second)
-			ret = o.p.Standby()
-		})
+		pending, ex := o.p.SchedulePowerAction(action, sys.ShutdownType(shutdownType), time.Duration(delaySec)*time.Second, c.GetString("username"), schema.PendingSourceHttp)
+		params := map[string]interface{}{"delay": delaySec}
+		if action == schema.PendingActionShutdown {
+			params["shutdown_type"] = shutdownType
+		}
+		if pending != nil {
+			params["pending_id"] = pending.Id
+		}
+		o.as.RecordHttp(c, action, params, ex)
+		if ex != nil {
+			c.Error(ex)
+			return
+		}
+		c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, pending))
+		return
 
 	case "lock":
 		_conf := utils.GetValueFromContext(o.ctx, constants.ConfKey, conf.NewDefaultConf())
@@ -78,6 +85,7 @@ func (o *ControlPCController) ControlPC(c *gin.Context) {
 		} else {
 			ret = o.p.LockWindows(false)
 		}
+		o.as.RecordHttp(c, audit_schema.ActionLock, nil, ret)
 
 	default:
 		c.Error(exception.ErrUserParameterError)
@@ -92,6 +100,79 @@ func (o *ControlPCController) ControlPC(c *gin.Context) {
 	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
 }
 
+// @Summary List Pending Power Actions
+// @Description List the delayed shutdown and standby actions that have not started yet
+// @Tags Control
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "success"
+// @Router /control-pc/pending [get]
+func (o *ControlPCController) ListPendingActions(c *gin.Context) {
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, o.p.ListPendingActions()))
+}
+
+// @Summary Get Pending Power Action
+// @Description Get a pending action, or the outcome of a recently finished or cancelled one
+// @Tags Control
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Pending action id"
+// @Success 200 {object} schema.ResponseData "success"
+// @Failure 400 {object} schema.ResponseData "The pending action does not exist"
+// @Router /control-pc/pending/{id} [get]
+func (o *ControlPCController) GetPendingAction(c *gin.Context) {
+	pending, ex := o.p.GetPendingAction(c.Param("id"))
+	if ex != nil {
+		c.Error(ex)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, pending))
+}
+
+// @Summary Cancel Pending Power Action
+// @Description Cancel a delayed shutdown or standby before it starts
+// @Tags Control
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Pending action id"
+// @Success 200 {object} schema.ResponseData "success"
+// @Failure 400 {object} schema.ResponseData "The pending action does not exist or has already started"
+// @Router /control-pc/pending/{id} [delete]
+func (o *ControlPCController) CancelPendingAction(c *gin.Context) {
+	pending, ex := o.p.CancelPendingAction(c.Param("id"))
+	o.as.RecordHttp(c, audit_schema.ActionCancelPending, map[string]interface{}{"pending_id": c.Param("id")}, ex)
+	if ex != nil {
+		c.Error(ex)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, pending))
+}
+
+// @Summary Postpone Pending Power Action
+// @Description Move a delayed shutdown or standby later
+// @Tags Control
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Pending action id"
+// @Param seconds query int true "Seconds to postpone by"
+// @Success 200 {object} schema.ResponseData "success"
+// @Failure 400 {object} schema.ResponseData "The pending action does not exist or has already started"
+// @Router /control-pc/pending/{id}/postpone [post]
+func (o *ControlPCController) PostponePendingAction(c *gin.Context) {
+	seconds, err := strconv.Atoi(c.Query("seconds"))
+	if err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	pending, ex := o.p.PostponePendingAction(c.Param("id"), time.Duration(seconds)*time.Second)
+	o.as.RecordHttp(c, audit_schema.ActionPostponePending, map[string]interface{}{"pending_id": c.Param("id"), "seconds": seconds}, ex)
+	if ex != nil {
+		c.Error(ex)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, pending))
+}
+
 // GetInterface Returns a valid network interface
 // @Summary Returns a valid network interface
 // @Description Based on the specified IP version type, a list of valid network interfaces is returned
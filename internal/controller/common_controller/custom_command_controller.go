@@ -2,9 +2,13 @@ package common_controller
 
 import (
 	"context"
-	"errors"
+	"fadacontrol/internal/base/exception"
 	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/controller"
+	"fadacontrol/internal/schema/audit_schema"
 	"fadacontrol/internal/schema/custom_command_schema"
+	"fadacontrol/internal/service/audit_service"
+	"fadacontrol/internal/service/auth_service"
 	"fadacontrol/internal/service/custom_command_service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -16,47 +20,68 @@ type chanGroup struct {
 	stderr *custom_command_schema.CustomWriter
 }
 type CustomCommandController struct {
+	as       *audit_service.AuditService
 	ctx      context.Context
 	service  *custom_command_service.CustomCommandService
+	auth     *auth_service.AuthService
 	cmdCache map[string]*chanGroup
 }
 
-func NewCustomCommandController(ctx context.Context, service *custom_command_service.CustomCommandService) *CustomCommandController {
-	return &CustomCommandController{ctx: ctx, service: service, cmdCache: make(map[string]*chanGroup)}
+func NewCustomCommandController(as *audit_service.AuditService, ctx context.Context, service *custom_command_service.CustomCommandService, auth *auth_service.AuthService) *CustomCommandController {
+	return &CustomCommandController{as: as, ctx: ctx, service: service, auth: auth, cmdCache: make(map[string]*chanGroup)}
 }
 
 func (d *CustomCommandController) Execute(c *gin.Context) {
 	var dto custom_command_schema.CustomCommandReq
 	err := c.ShouldBind(&dto)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	commandID, err := d.execute(c.GetString("username"), dto)
+	params := map[string]interface{}{"path": dto.Path, "name": dto.Name}
+	if commandID != "" {
+		params["command_id"] = commandID
+	}
+	d.as.RecordHttp(c, audit_schema.ActionCommand, params, err)
+	if err != nil {
+		c.Error(err)
+		return
 	}
+
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, custom_command_schema.CustomCommandResp{Id: commandID}))
+
+}
+
+// execute starts the command for username and returns the id to read its output with.
+func (d *CustomCommandController) execute(username string, dto custom_command_schema.CustomCommandReq) (string, error) {
 	cmds, err := d.service.ReadConfig(dto.Path)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", exception.ErrUserParameterError.SetMsg(err.Error())
 	}
 	cmd, ok := cmds[dto.Name]
 	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"error": errors.New("command not found")})
+		return "", exception.ErrUserCommandNotFound
+	}
+	if !d.auth.CheckCommandPermission(username, cmd, custom_command_schema.LanSource) {
+		return "", exception.ErrUserUnauthorizedAccess
 	}
 	_uuid, err := uuid.NewRandom()
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", exception.ErrSystemUnknownException
 	}
 
 	commandID := _uuid.String()
 
-	resp := custom_command_schema.CustomCommandResp{Id: commandID}
 	stdout := custom_command_schema.NewCustomWriter()
 	stderr := custom_command_schema.NewCustomWriter()
 	g := &chanGroup{stdout: stdout, stderr: stderr}
-	d.cmdCache[commandID] = g
 	err = d.service.ExecuteCommand(cmd, stdout, stderr)
-
-	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-
-	c.JSON(http.StatusOK, resp)
-
+	if err != nil {
+		return "", err
+	}
+	d.cmdCache[commandID] = g
+	return commandID, nil
 }
 func (d *CustomCommandController) ExecResult(c *gin.Context) {
 	commandID := c.Param("id")
@@ -0,0 +1,62 @@
+package common_controller
+
+import (
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/metrics"
+	"fadacontrol/internal/controller"
+	"fadacontrol/internal/schema/health_schema"
+	"fadacontrol/internal/service/health_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type HealthController struct {
+	hs      *health_service.HealthService
+	metrics http.Handler
+}
+
+func NewHealthController(hs *health_service.HealthService) *HealthController {
+	return &HealthController{hs: hs, metrics: metrics.Handler()}
+}
+
+func (h *HealthController) writeReport(c *gin.Context, report *health_schema.HealthResponse, ex *exception.Exception) {
+	if report.Status != health_schema.StatusUp {
+		c.JSON(http.StatusServiceUnavailable, controller.GetGinErrorWithData(c, ex, report))
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, report))
+}
+
+// @Summary Liveness Probe
+// @Description Get the state of the database, the HTTP servers, discovery, the remote connection, the credential provider pipe and the rpc server of the slave programs, responds with 503 when one of them is down.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} schema.ResponseData "Every component is up, disabled, starting or stopped."
+// @Failure 503 {object} schema.ResponseData "A component is down."
+// @Router /healthz [get]
+func (h *HealthController) Healthz(c *gin.Context) {
+	h.writeReport(c, h.hs.Liveness(), exception.ErrSystemServiceUnhealthy)
+}
+
+// @Summary Readiness Probe
+// @Description Get the state of the components like the liveness probe, responds with 503 until every enabled component is up.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} schema.ResponseData "Every enabled component is up."
+// @Failure 503 {object} schema.ResponseData "A component is starting, stopped or down."
+// @Router /readyz [get]
+func (h *HealthController) Readyz(c *gin.Context) {
+	h.writeReport(c, h.hs.Readiness(), exception.ErrSystemServiceNotFullyStarted)
+}
+
+// @Summary Prometheus Metrics
+// @Description Export request counts and latency by route, control actions by type and result, unlock failures, discovery packets, remote messages, connected slave programs and Go runtime statistics in the Prometheus text format.
+// @Tags Health
+// @Security ApiKeyAuth
+// @Produce plain
+// @Success 200 {string} string "The metrics."
+// @Failure 401 {object} schema.ResponseData "Unauthorized"
+// @Router /metrics [get]
+func (h *HealthController) Metrics(c *gin.Context) {
+	h.metrics.ServeHTTP(c.Writer, c.Request)
+}
@@ -9,7 +9,9 @@ import (
 	"fadacontrol/internal/base/version"
 	"fadacontrol/internal/controller"
 	"fadacontrol/internal/schema"
+	"fadacontrol/internal/schema/log_schema"
 	"fadacontrol/internal/service/control_pc"
+	"fadacontrol/internal/service/log_service"
 	"fadacontrol/internal/service/update_service"
 	"fadacontrol/pkg/secure"
 	"fadacontrol/pkg/syncer"
@@ -24,10 +26,11 @@ type SystemController struct {
 	ctx context.Context
 	_co *control_pc.ControlPCService
 	_up *update_service.UpdateService
+	_ls *log_service.LogService
 }
 
-func NewSystemController(_co *control_pc.ControlPCService, ctx context.Context, _up *update_service.UpdateService) *SystemController {
-	return &SystemController{_co: _co, ctx: ctx, _up: _up}
+func NewSystemController(_ls *log_service.LogService, _co *control_pc.ControlPCService, ctx context.Context, _up *update_service.UpdateService) *SystemController {
+	return &SystemController{_ls: _ls, _co: _co, ctx: ctx, _up: _up}
 }
 
 // @Summary Get Software Info
@@ -161,6 +164,45 @@ func (s *SystemController) GetLog(c *gin.Context) {
 	c.JSON(http.StatusOK, controller.GetGinError(c, exception.ErrUnknownException))
 }
 
+// @Summary Search System Logs
+// @Description Search the log file and the rotated backups, including the compressed ones, newest first.
+// @Tags System
+// @Produce json
+// @Security ApiKeyAuth
+// @Param module path string true "Specify the module to retrieve logs from (must be 'service')"
+// @Param from query string false "Only entries at or after this RFC 3339 time"
+// @Param to query string false "Only entries before this RFC 3339 time"
+// @Param level query string false "Minimum level" Enums(debug, info, warn, error, fatal)
+// @Param module query string false "Package of the caller, such as unlock or http_service"
+// @Param request_id query string false "Request ID, returned in the X-Request-Id header of every response"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(100)
+// @Success 200 {object} schema.ResponseData "Successfully retrieved log entries."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /logs/{module}/search [get]
+func (s *SystemController) SearchLog(c *gin.Context) {
+	if c.Param("module") != "service" {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	q := log_schema.LogQuery{Page: 1, PageSize: 100}
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.Error(exception.ErrUserParameterError.SetMsg(err.Error()))
+		return
+	}
+	if q.Page < 1 || q.PageSize < 1 || q.PageSize > 1000 {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	resp, err := s._ls.Search(&q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
 // @Summary Check for System Updates
 // @Description Check if there are any updates available for the system. This endpoint returns the latest available update details, including the version, update URL, and release notes.
 // @Tags System
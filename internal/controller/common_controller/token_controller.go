@@ -0,0 +1,165 @@
+package common_controller
+
+import (
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/controller"
+	"fadacontrol/internal/schema/token_schema"
+	"fadacontrol/internal/service/token_service"
+	"fadacontrol/internal/service/two_factor_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type TokenController struct {
+	ts *token_service.TokenService
+	tf *two_factor_service.TwoFactorService
+}
+
+func NewTokenController(ts *token_service.TokenService, tf *two_factor_service.TwoFactorService) *TokenController {
+	return &TokenController{ts: ts, tf: tf}
+}
+
+func parseTokenId(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// @Summary List Token Scopes
+// @Description List the scopes an api token can be created with.
+// @Tags Token
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved scopes."
+// @Router /user/tokens/scopes [get]
+func (t *TokenController) ListScopes(c *gin.Context) {
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, token_service.ListScopes()))
+}
+
+// @Summary List My Tokens
+// @Description List the api tokens of the current user, the token secrets are never returned.
+// @Tags Token
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved tokens."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /user/tokens [get]
+func (t *TokenController) ListOwnTokens(c *gin.Context) {
+	username := c.GetString("username")
+	if username == "" {
+		c.Error(exception.ErrUserUnauthorizedAccess)
+		return
+	}
+	resp, err := t.ts.ListTokens(username)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Create Token
+// @Description Create an api token for the current user. The token is only shown in this response, send it as "Authorization: Bearer <token>".
+// @Tags Token
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param token body token_schema.CreateTokenRequest true "Token"
+// @Success 200 {object} schema.ResponseData "Successfully created token."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /user/tokens [post]
+func (t *TokenController) CreateToken(c *gin.Context) {
+	username := c.GetString("username")
+	if username == "" {
+		c.Error(exception.ErrUserUnauthorizedAccess)
+		return
+	}
+	// a token would otherwise let a password-only session bypass the two-factor requirement
+	if !c.GetBool("two_factor") && t.tf.Applies(username) {
+		c.Error(exception.ErrUserTwoFactorRequired)
+		return
+	}
+	var req token_schema.CreateTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	resp, err := t.ts.CreateToken(username, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Revoke My Token
+// @Description Revoke an api token of the current user.
+// @Tags Token
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Token id"
+// @Success 200 {object} schema.ResponseData "Successfully revoked token."
+// @Failure 400 {object} schema.ResponseData "The token does not exist."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /user/tokens/{id} [delete]
+func (t *TokenController) RevokeOwnToken(c *gin.Context) {
+	username := c.GetString("username")
+	if username == "" {
+		c.Error(exception.ErrUserUnauthorizedAccess)
+		return
+	}
+	id, ok := parseTokenId(c)
+	if !ok {
+		return
+	}
+	if err := t.ts.RevokeToken(id, username); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary List Tokens
+// @Description List the api tokens of every user, or of one user.
+// @Tags Token
+// @Produce json
+// @Security ApiKeyAuth
+// @Param username query string false "Only list the tokens of this user"
+// @Success 200 {object} schema.ResponseData "Successfully retrieved tokens."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /tokens [get]
+func (t *TokenController) ListTokens(c *gin.Context) {
+	resp, err := t.ts.ListTokens(c.Query("username"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Revoke Token
+// @Description Revoke the api token of any user.
+// @Tags Token
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Token id"
+// @Success 200 {object} schema.ResponseData "Successfully revoked token."
+// @Failure 400 {object} schema.ResponseData "The token does not exist."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /tokens/{id} [delete]
+func (t *TokenController) RevokeToken(c *gin.Context) {
+	id, ok := parseTokenId(c)
+	if !ok {
+		return
+	}
+	if err := t.ts.RevokeToken(id, ""); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
@@ -0,0 +1,231 @@
+package common_controller
+
+import (
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/controller"
+	"fadacontrol/internal/schema/two_factor_schema"
+	"fadacontrol/internal/service/jwt_service"
+	"fadacontrol/internal/service/two_factor_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type TwoFactorController struct {
+	tf *two_factor_service.TwoFactorService
+	jw *jwt_service.JwtService
+}
+
+func NewTwoFactorController(tf *two_factor_service.TwoFactorService, jw *jwt_service.JwtService) *TwoFactorController {
+	return &TwoFactorController{tf: tf, jw: jw}
+}
+
+func currentUser(c *gin.Context) (string, bool) {
+	username := c.GetString("username")
+	if username == "" {
+		c.Error(exception.ErrUserUnauthorizedAccess)
+		return "", false
+	}
+	return username, true
+}
+
+func bindCode(c *gin.Context) (string, bool) {
+	var req two_factor_schema.CodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return "", false
+	}
+	return req.Code, true
+}
+
+// @Summary Two-Factor Status
+// @Description Show whether two-factor authentication is enabled or required for the current user.
+// @Tags TwoFactor
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved status."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /user/2fa [get]
+func (t *TwoFactorController) Status(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	resp, err := t.tf.Status(username)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Begin Two-Factor Enrollment
+// @Description Generate a new TOTP secret for the current user. It takes effect once it is confirmed with a code.
+// @Tags TwoFactor
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully generated secret."
+// @Failure 400 {object} schema.ResponseData "Two-factor authentication is already enabled."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /user/2fa/enroll [post]
+func (t *TwoFactorController) BeginEnrollment(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	resp, err := t.tf.BeginEnrollment(username)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, resp))
+}
+
+// @Summary Enrollment QR Code
+// @Description Get the provisioning URI of the pending enrollment as a QR code.
+// @Tags TwoFactor
+// @Produce png
+// @Security ApiKeyAuth
+// @Success 200 {file} binary "QR code"
+// @Failure 404 {object} schema.ResponseData "No pending enrollment."
+// @Router /user/2fa/qr [get]
+func (t *TwoFactorController) QrCode(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	png, err := t.tf.QrCode(username)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// @Summary Confirm Two-Factor Enrollment
+// @Description Enable two-factor authentication with a code of the new secret. Returns the recovery codes, which are only shown once, and a new two-factor session replacing the current one.
+// @Tags TwoFactor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body two_factor_schema.CodeRequest true "TOTP code"
+// @Success 200 {object} schema.ResponseData "Successfully enabled."
+// @Failure 400 {object} schema.ResponseData "Invalid code."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /user/2fa/confirm [post]
+func (t *TwoFactorController) ConfirmEnrollment(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	code, ok := bindCode(c)
+	if !ok {
+		return
+	}
+	codes, err := t.tf.ConfirmEnrollment(username, code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if sid := c.GetString("session_id"); sid != "" {
+		if err := t.jw.RevokeSession(sid, username); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+	session, err := t.jw.CreateSession(username, c.ClientIP(), c.Request.UserAgent(), true)
+	if err != nil {
+		c.Error(exception.ErrSystemGenTokenErr)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, two_factor_schema.ConfirmResponse{RecoveryCodes: codes, Session: session}))
+}
+
+// @Summary Disable Two-Factor Authentication
+// @Description Disable two-factor authentication of the current user, a TOTP or recovery code is required.
+// @Tags TwoFactor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body two_factor_schema.CodeRequest true "TOTP or recovery code"
+// @Success 200 {object} schema.ResponseData "Successfully disabled."
+// @Failure 400 {object} schema.ResponseData "Invalid code."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /user/2fa/disable [post]
+func (t *TwoFactorController) Disable(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	code, ok := bindCode(c)
+	if !ok {
+		return
+	}
+	if err := t.tf.Disable(username, code); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
+
+// @Summary Regenerate Recovery Codes
+// @Description Replace the recovery codes of the current user, a TOTP or recovery code is required.
+// @Tags TwoFactor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body two_factor_schema.CodeRequest true "TOTP or recovery code"
+// @Success 200 {object} schema.ResponseData "Successfully regenerated."
+// @Failure 400 {object} schema.ResponseData "Invalid code."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /user/2fa/recovery-codes [post]
+func (t *TwoFactorController) RegenerateRecoveryCodes(c *gin.Context) {
+	username, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	code, ok := bindCode(c)
+	if !ok {
+		return
+	}
+	codes, err := t.tf.RegenerateRecoveryCodes(username, code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, two_factor_schema.RecoveryCodesResponse{RecoveryCodes: codes}))
+}
+
+// @Summary Get Two-Factor Policy
+// @Description Show whether accounts that can run power actions or custom commands must use two-factor authentication.
+// @Tags TwoFactor
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.ResponseData "Successfully retrieved policy."
+// @Router /auth/2fa/policy [get]
+func (t *TwoFactorController) GetPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, controller.GetGinSuccessWithData(c, two_factor_schema.PolicyRequest{RequireTwoFactor: t.tf.Required()}))
+}
+
+// @Summary Set Two-Factor Policy
+// @Description Require two-factor authentication for accounts that can run power actions or custom commands. Affected accounts without it are limited to the self-service endpoints until they enroll.
+// @Tags TwoFactor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body two_factor_schema.PolicyRequest true "Policy"
+// @Success 200 {object} schema.ResponseData "Successfully updated policy."
+// @Failure 400 {object} schema.ResponseData "Invalid request parameters."
+// @Failure 500 {object} schema.ResponseData "Internal Server Error"
+// @Router /auth/2fa/policy [put]
+func (t *TwoFactorController) SetPolicy(c *gin.Context) {
+	var req two_factor_schema.PolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(exception.ErrUserParameterError)
+		return
+	}
+	if err := t.tf.SetRequired(req.RequireTwoFactor); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, controller.GetGinSuccess(c))
+}
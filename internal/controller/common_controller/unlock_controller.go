@@ -5,6 +5,8 @@ import (
 	"fadacontrol/internal/base/logger"
 	"fadacontrol/internal/controller"
 	"fadacontrol/internal/schema"
+	"fadacontrol/internal/schema/audit_schema"
+	"fadacontrol/internal/service/audit_service"
 	"fadacontrol/internal/service/unlock"
 	"fmt"
 	"github.com/getsentry/sentry-go"
@@ -13,11 +15,12 @@ import (
 )
 
 type UnlockController struct {
-	u *unlock.UnLockService
+	u  *unlock.UnLockService
+	as *audit_service.AuditService
 }
 
-func NewUnlockController(u *unlock.UnLockService) *UnlockController {
-	return &UnlockController{u}
+func NewUnlockController(as *audit_service.AuditService, u *unlock.UnLockService) *UnlockController {
+	return &UnlockController{u: u, as: as}
 }
 
 // @Summary	Unlock your computer
@@ -25,6 +28,7 @@ func NewUnlockController(u *unlock.UnLockService) *UnlockController {
 // @Param UserInfo body schema.PcUserInfo true "User Information"
 // @Success	200		{object}	schema.ResponseData		"success"
 // @Failure	400		{object}	schema.ResponseData			"The request is incorrect"
+// @Failure	429		{object}	schema.ResponseData			"Too many failed attempts"
 // @Failure	500		{object}	schema.ResponseData			"Internal errors"
 // @Router		/unlock [post]
 func (o *UnlockController) Unlock(c *gin.Context) {
@@ -63,7 +67,8 @@ func (o *UnlockController) Unlock(c *gin.Context) {
 	}
 
 	logger.Info("Username and password information have been received")
-	e := o.u.UnlockPc(reqData.UserName, reqData.Password)
+	e := o.u.UnlockPc(c.RemoteIP(), reqData.UserName, reqData.Password)
+	o.as.RecordHttp(c, audit_schema.ActionUnlock, map[string]interface{}{"username": reqData.UserName}, e)
 	if exception.ErrSuccess.NotEqual(e) {
 		logger.Info(e.Msg)
 		c.Error(e)
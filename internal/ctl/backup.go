@@ -0,0 +1,148 @@
+package ctl
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fadacontrol/internal/schema/backup_schema"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func changesTable(changes []backup_schema.Change) *table {
+	t := &table{headers: []string{"OP", "SECTION", "KEY", "FIELDS"}}
+	for _, c := range changes {
+		key := c.Key
+		if key == "" {
+			key = "-"
+		}
+		fields := strings.Join(c.Fields, ",")
+		if fields == "" {
+			fields = "-"
+		}
+		t.add(c.Op, c.Section, key, fields)
+	}
+	return t
+}
+
+// passphraseFlags reads the passphrase of an archive from a flag or the standard input.
+type passphraseFlags struct {
+	passphrase string
+	stdin      bool
+}
+
+func (p *passphraseFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&p.passphrase, "passphrase", "", "passphrase the secrets of the archive are encrypted with")
+	cmd.Flags().BoolVar(&p.stdin, "passphrase-stdin", false, "read the passphrase from the standard input")
+	cmd.MarkFlagsMutuallyExclusive("passphrase", "passphrase-stdin")
+}
+
+func (p *passphraseFlags) read(cmd *cobra.Command) (string, error) {
+	if p.stdin {
+		line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		if err != nil && line == "" {
+			return "", errors.New("no passphrase on standard input")
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+	if p.passphrase == "" {
+		return "", errors.New("pass the passphrase with --passphrase or --passphrase-stdin")
+	}
+	return p.passphrase, nil
+}
+
+func (o *options) backupCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Export and restore the configuration",
+	}
+	cmd.AddCommand(o.backupExportCommand(), o.backupImportCommand())
+	return cmd
+}
+
+func (o *options) backupExportCommand() *cobra.Command {
+	var p passphraseFlags
+	cmd := &cobra.Command{
+		Use:   "export <file>",
+		Short: "Export the configuration, users and policies to an archive",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			passphrase, err := p.read(cmd)
+			if err != nil {
+				return err
+			}
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context(cmd)
+			defer cancel()
+			archive, err := c.ExportBackup(ctx, passphrase)
+			if err != nil {
+				return err
+			}
+			data, err := json.MarshalIndent(archive, "", "  ")
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(args[0], data, 0600); err != nil {
+				return err
+			}
+			return o.printMessage(cmd.OutOrStdout(), "exported to "+args[0])
+		},
+	}
+	p.register(cmd)
+	return cmd
+}
+
+func (o *options) backupImportCommand() *cobra.Command {
+	var p passphraseFlags
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Restore an archive, the entries that are not in the archive are kept",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			passphrase, err := p.read(cmd)
+			if err != nil {
+				return err
+			}
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context(cmd)
+			defer cancel()
+			resp, err := c.ImportBackup(ctx, &backup_schema.ImportRequest{Archive: data, Passphrase: passphrase, DryRun: dryRun})
+			if err != nil {
+				return err
+			}
+			if o.output == outputJson {
+				return o.print(cmd.OutOrStdout(), resp, nil)
+			}
+			w := cmd.OutOrStdout()
+			if len(resp.Changes) == 0 {
+				return o.printMessage(w, "the configuration already matches the archive")
+			}
+			if err := changesTable(resp.Changes).write(w); err != nil {
+				return err
+			}
+			switch {
+			case resp.DryRun:
+				return o.printMessage(w, "dry run, nothing was changed")
+			case resp.RestartRequired:
+				return o.printMessage(w, "restored, restart the service to apply the http and remote settings")
+			}
+			return o.printMessage(w, "restored")
+		},
+	}
+	p.register(cmd)
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only show the changes")
+	return cmd
+}
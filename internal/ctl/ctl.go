@@ -0,0 +1,102 @@
+package ctl
+
+import (
+	"context"
+	"errors"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/pkg/client"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// TokenEnv overrides the local token file.
+const TokenEnv = "FADACONTROL_TOKEN"
+
+type options struct {
+	// workDir is the working directory flag of the root command, the token file of the service is below it.
+	workDir   *string
+	url       string
+	tokenFile string
+	output    string
+	timeout   time.Duration
+}
+
+// NewCommand returns the ctl command that drives the running service through the local administration API,
+// workDir points to the working directory flag of the root command.
+func NewCommand(workDir *string) *cobra.Command {
+	o := &options{workDir: workDir}
+	cmd := &cobra.Command{
+		Use:          "ctl",
+		Short:        "Control the running service",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if o.output != outputTable && o.output != outputJson {
+				return fmt.Errorf("unknown output format %q, use table or json", o.output)
+			}
+			return nil
+		},
+	}
+	cmd.PersistentFlags().StringVar(&o.url, "url", client.DefaultAdminBaseURL, "address of the administration API")
+	cmd.PersistentFlags().StringVar(&o.tokenFile, "token-file", "", "file with the API token, defaults to the token written by the service")
+	cmd.PersistentFlags().StringVarP(&o.output, "output", "o", outputTable, "output format (table or json)")
+	cmd.PersistentFlags().DurationVar(&o.timeout, "timeout", 30*time.Second, "timeout of every request")
+
+	cmd.AddCommand(o.lockCommand(), o.shutdownCommand(), o.standbyCommand(), o.pendingCommand(), o.cancelCommand(),
+		o.postponeCommand(), o.statusCommand(), o.logsCommand(), o.remoteCommand(), o.userCommand(), o.backupCommand(),
+		o.reloadCommand())
+	return cmd
+}
+
+// tokenFiles returns the candidate paths of the token file, the working directory of the service is usually
+// the directory of the executable.
+func (o *options) tokenFiles() []string {
+	if o.tokenFile != "" {
+		return []string{o.tokenFile}
+	}
+	if o.workDir != nil && *o.workDir != "" {
+		return []string{conf.LocalTokenPath(*o.workDir)}
+	}
+	var files []string
+	if exe, err := os.Executable(); err == nil {
+		files = append(files, conf.LocalTokenPath(filepath.Dir(exe)))
+	}
+	return append(files, conf.LocalTokenPath("."))
+}
+
+func (o *options) token() (string, error) {
+	if token := strings.TrimSpace(os.Getenv(TokenEnv)); token != "" {
+		return token, nil
+	}
+	for _, file := range o.tokenFiles() {
+		data, err := os.ReadFile(file)
+		if err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+		if os.IsPermission(err) {
+			return "", fmt.Errorf("the token file %s is only readable by the service account and the administrators", file)
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return "", errors.New("no local token found, is the service running? Pass --token-file or set " + TokenEnv)
+}
+
+func (o *options) client() (*client.Client, error) {
+	token, err := o.token()
+	if err != nil {
+		return nil, err
+	}
+	return client.NewClientBuilder(o.url).SetTimeout(o.timeout).SetToken(token).Build()
+}
+
+// context returns a context that is cancelled on interrupt.
+func (o *options) context(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(cmd.Context(), os.Interrupt)
+}
@@ -0,0 +1,225 @@
+package ctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/schema"
+	"fadacontrol/internal/schema/backup_schema"
+	"fadacontrol/internal/schema/user_schema"
+	"fadacontrol/pkg/sys"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseShutdownType(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    sys.ShutdownType
+		wantErr bool
+	}{
+		{"force-reboot", sys.S_E_FORCE_REBOOT, false},
+		{"Force-Shutdown", sys.S_E_FORCE_SHUTDOWN, false},
+		{"logoff", sys.S_E_LOGOFF, false},
+		{"4", sys.ShutdownType(4), false},
+		{"0", sys.Unknown, true},
+		{"99", sys.Unknown, true},
+		{"halt", sys.Unknown, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseShutdownType(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseShutdownType(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseShutdownType(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"table", outputTable, "NAME  VALUE\nfoo   1\n"},
+		{"json", outputJson, "{\n  \"name\": \"foo\"\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &options{output: tt.output}
+			tab := &table{headers: []string{"NAME", "VALUE"}}
+			tab.add("foo", "1")
+			var buf bytes.Buffer
+			if err := o.print(&buf, map[string]string{"name": "foo"}, tab); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("print() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func writeEnvelope(w http.ResponseWriter, status int, ex *exception.Exception, data interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(schema.ResponseData{RequestId: "req-1", Code: ex.Code, Msg: ex.Msg, Data: data})
+}
+
+// newTestServer fakes the administration API and accepts only the token written to the returned token file.
+func newTestServer(t *testing.T) (url string, tokenFile string) {
+	const token = "fct_test"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/api/v1/control-pc/shutdown", func(w http.ResponseWriter, r *http.Request) {
+		writeEnvelope(w, http.StatusOK, exception.ErrSuccess, schema.PendingAction{
+			Id:        "a1",
+			Action:    "shutdown",
+			Status:    "pending",
+			Source:    "http",
+			Requester: "root",
+			// echo the query so that the test can check the parsed flags
+			Msg:       r.URL.Query().Get("shutdown_type") + "/" + r.URL.Query().Get("delay"),
+			ExecuteAt: time.Now().Add(30 * time.Second),
+			Remaining: 30,
+		})
+	})
+	mux.HandleFunc("/admin/api/v1/control-pc/lock", func(w http.ResponseWriter, r *http.Request) {
+		writeEnvelope(w, http.StatusOK, exception.ErrSuccess, nil)
+	})
+	mux.HandleFunc("/admin/api/v1/users", func(w http.ResponseWriter, r *http.Request) {
+		var req user_schema.CreateUserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Password != "secret" {
+			writeEnvelope(w, http.StatusBadRequest, exception.ErrUserParameterError, nil)
+			return
+		}
+		writeEnvelope(w, http.StatusOK, exception.ErrSuccess, user_schema.UserResponse{Username: req.Username, Roles: req.Roles})
+	})
+	mux.HandleFunc("/admin/api/v1/backup/import", func(w http.ResponseWriter, r *http.Request) {
+		var req backup_schema.ImportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Passphrase != "passphrase" {
+			writeEnvelope(w, http.StatusBadRequest, exception.ErrUserParameterError, nil)
+			return
+		}
+		writeEnvelope(w, http.StatusOK, exception.ErrSuccess, backup_schema.ImportResponse{DryRun: req.DryRun, Version: 1, Changes: []backup_schema.Change{
+			{Section: backup_schema.SectionUsers, Key: "bob", Op: backup_schema.OpAdd},
+			{Section: backup_schema.SectionHttpConfigs, Key: "HTTP_SERVICE_API", Op: backup_schema.OpUpdate, Fields: []string{"host", "port"}},
+		}})
+	})
+	mux.HandleFunc("/admin/api/v1/sys/reload", func(w http.ResponseWriter, r *http.Request) {
+		writeEnvelope(w, http.StatusOK, exception.ErrSuccess, schema.ReloadResponse{Success: false, Components: []schema.ReloadComponent{
+			{Name: "log", Status: schema.ReloadApplied},
+			{Name: "remote", Status: schema.ReloadFailed, Error: "connection refused"},
+		}})
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			writeEnvelope(w, http.StatusUnauthorized, exception.ErrUserUnauthorizedAccess, nil)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	tokenFile = filepath.Join(t.TempDir(), "ctl.token")
+	if err := os.WriteFile(tokenFile, []byte(token+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return server.URL + "/admin/api/v1", tokenFile
+}
+
+func TestCommands(t *testing.T) {
+	t.Setenv(TokenEnv, "")
+	url, tokenFile := newTestServer(t)
+	wrongToken := filepath.Join(t.TempDir(), "wrong.token")
+	if err := os.WriteFile(wrongToken, []byte("fct_wrong"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "backup.json")
+	if err := os.WriteFile(archive, []byte(`{"format":"fadacontrol-backup"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		stdin   string
+		want    []string
+		wantErr string
+	}{
+		{"lock", []string{"lock"}, "", []string{"locked"}, ""},
+		{"lock json", []string{"lock", "-o", "json"}, "", []string{`"msg": "locked"`}, ""},
+		{"shutdown", []string{"shutdown", "--type", "force-reboot", "--delay", "30"}, "", []string{"ID", "a1", "shutdown", "pending"}, ""},
+		{"shutdown json", []string{"shutdown", "--type", "force-reboot", "--delay", "30", "-o", "json"}, "", []string{`"msg": "3/30"`}, ""},
+		{"unknown shutdown type", []string{"shutdown", "--type", "halt"}, "", nil, "unknown shutdown type"},
+		{"user add", []string{"user", "add", "alice", "--role", "operator", "--password-stdin"}, "secret\n", []string{"alice", "operator"}, ""},
+		{"user add without password", []string{"user", "add", "alice"}, "", nil, "--password"},
+		{"api error", []string{"user", "add", "alice", "--password", "wrong"}, "", nil, exception.ErrUserParameterError.Msg},
+		{"backup import dry run", []string{"backup", "import", archive, "--dry-run", "--passphrase-stdin"}, "passphrase\n", []string{"add     users", "host,port", "dry run"}, ""},
+		{"backup import without passphrase", []string{"backup", "import", archive}, "", nil, "--passphrase"},
+		{"reload", []string{"reload"}, "", nil, "failed to reload"},
+		{"unknown output", []string{"lock", "-o", "yaml"}, "", nil, "unknown output format"},
+		{"wrong token", []string{"lock", "--token-file", wrongToken}, "", nil, exception.ErrUserUnauthorizedAccess.Msg},
+		{"missing token", []string{"lock", "--token-file", filepath.Join(t.TempDir(), "missing")}, "", nil, "no local token found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewCommand(nil)
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetIn(strings.NewReader(tt.stdin))
+			args := append([]string{"--url", url, "--token-file", tokenFile}, tt.args...)
+			cmd.SetArgs(args)
+			err := cmd.Execute()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Execute() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output %q does not contain %q", out.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestCopyUntilIdle(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	w.WriteString("line 1\nline 2\n")
+	var out bytes.Buffer
+	done := make(chan error, 1)
+	go func() { done <- copyUntilIdle(context.Background(), &out, r, 50*time.Millisecond) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("copyUntilIdle did not return on an idle stream")
+	}
+	if out.String() != "line 1\nline 2\n" {
+		t.Errorf("copied %q", out.String())
+	}
+}
@@ -0,0 +1,204 @@
+package ctl
+
+import (
+	"fadacontrol/internal/schema"
+	"fadacontrol/pkg/sys"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// shutdownTypes are the names of the shutdown types accepted by --type.
+var shutdownTypes = map[string]sys.ShutdownType{
+	"logoff":                             sys.S_E_LOGOFF,
+	"force-shutdown":                     sys.S_E_FORCE_SHUTDOWN,
+	"force-reboot":                       sys.S_E_FORCE_REBOOT,
+	"shutdown":                           sys.S_EWX_SHUTDOWN,
+	"reboot":                             sys.S_EWX_REBOOT,
+	"poweroff":                           sys.S_EWX_POWEROFF,
+	"force-poweroff":                     sys.S_EWX_FORCE_POWEROFF,
+	"hybrid-shutdown":                    sys.S_EWX_HYBRID_SHUTDOWN,
+	"hybrid-shutdown-force":              sys.S_EWX_HYBRID_SHUTDOWN_FORCE,
+	"reboot-restart-apps":                sys.S_EWX_REBOOT_RESTARTAPPS,
+	"force-reboot-restart-apps":          sys.S_EWX_FORCE_REBOOT_RESTARTAPPS,
+	"shutdown-restart-apps":              sys.S_EWX_SHUTDOWN_RESTARTAPPS,
+	"hybrid-shutdown-restart-apps":       sys.S_EWX_HYBRID_SHUTDOWN_RESTARTAPPS,
+	"hybrid-shutdown-force-restart-apps": sys.S_EWX_HYBRID_SHUTDOWN_FORCE_RESTARTAPPS,
+}
+
+func shutdownTypeNames() string {
+	names := make([]string, 0, len(shutdownTypes))
+	for name := range shutdownTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// parseShutdownType accepts the name or the number of a shutdown type.
+func parseShutdownType(s string) (sys.ShutdownType, error) {
+	if t, ok := shutdownTypes[strings.ToLower(s)]; ok {
+		return t, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n > int(sys.Unknown) && n <= int(sys.S_EWX_HYBRID_SHUTDOWN_FORCE_RESTARTAPPS) {
+		return sys.ShutdownType(n), nil
+	}
+	return sys.Unknown, fmt.Errorf("unknown shutdown type %q, use one of %s", s, shutdownTypeNames())
+}
+
+func pendingTable(actions ...schema.PendingAction) *table {
+	t := &table{headers: []string{"ID", "ACTION", "STATUS", "EXECUTE AT", "REMAINING", "REQUESTER", "SOURCE"}}
+	for _, a := range actions {
+		t.add(a.Id, a.Action, a.Status, formatTime(&a.ExecuteAt), (time.Duration(a.Remaining) * time.Second).String(), a.Requester, a.Source)
+	}
+	return t
+}
+
+func (o *options) printPending(w io.Writer, action *schema.PendingAction) error {
+	return o.print(w, action, pendingTable(*action))
+}
+
+func (o *options) lockCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "lock",
+		Short: "Lock the computer",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context(cmd)
+			defer cancel()
+			if err := c.Lock(ctx); err != nil {
+				return err
+			}
+			return o.printMessage(cmd.OutOrStdout(), "locked")
+		},
+	}
+}
+
+func (o *options) shutdownCommand() *cobra.Command {
+	var tpe string
+	var delay int
+	cmd := &cobra.Command{
+		Use:   "shutdown",
+		Short: "Shut down or reboot the computer after a delay",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shutdownType, err := parseShutdownType(tpe)
+			if err != nil {
+				return err
+			}
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context(cmd)
+			defer cancel()
+			pending, err := c.Shutdown(ctx, time.Duration(delay)*time.Second, int(shutdownType))
+			if err != nil {
+				return err
+			}
+			return o.printPending(cmd.OutOrStdout(), pending)
+		},
+	}
+	cmd.Flags().StringVar(&tpe, "type", "force-shutdown", "shutdown type, one of "+shutdownTypeNames())
+	cmd.Flags().IntVar(&delay, "delay", 5, "delay in seconds")
+	return cmd
+}
+
+func (o *options) standbyCommand() *cobra.Command {
+	var delay int
+	cmd := &cobra.Command{
+		Use:   "standby",
+		Short: "Put the computer to sleep after a delay",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context(cmd)
+			defer cancel()
+			pending, err := c.Standby(ctx, time.Duration(delay)*time.Second)
+			if err != nil {
+				return err
+			}
+			return o.printPending(cmd.OutOrStdout(), pending)
+		},
+	}
+	cmd.Flags().IntVar(&delay, "delay", 5, "delay in seconds")
+	return cmd
+}
+
+func (o *options) pendingCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pending",
+		Short: "List the delayed shutdown and standby actions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context(cmd)
+			defer cancel()
+			actions, err := c.ListPendingActions(ctx)
+			if err != nil {
+				return err
+			}
+			return o.print(cmd.OutOrStdout(), actions, pendingTable(actions...))
+		},
+	}
+}
+
+func (o *options) cancelCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel <id>",
+		Short: "Cancel a delayed shutdown or standby",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context(cmd)
+			defer cancel()
+			pending, err := c.CancelPendingAction(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			return o.printPending(cmd.OutOrStdout(), pending)
+		},
+	}
+}
+
+func (o *options) postponeCommand() *cobra.Command {
+	var seconds int
+	cmd := &cobra.Command{
+		Use:   "postpone <id>",
+		Short: "Postpone a delayed shutdown or standby",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context(cmd)
+			defer cancel()
+			pending, err := c.PostponePendingAction(ctx, args[0], time.Duration(seconds)*time.Second)
+			if err != nil {
+				return err
+			}
+			return o.printPending(cmd.OutOrStdout(), pending)
+		},
+	}
+	cmd.Flags().IntVar(&seconds, "seconds", 300, "seconds to postpone by")
+	return cmd
+}
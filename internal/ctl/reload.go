@@ -0,0 +1,42 @@
+package ctl
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+func (o *options) reloadCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reload",
+		Short: "Apply config.yml without restarting the service",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context(cmd)
+			defer cancel()
+			resp, err := c.Reload(ctx)
+			if err != nil {
+				return err
+			}
+			t := &table{headers: []string{"COMPONENT", "STATUS", "ERROR"}}
+			for _, component := range resp.Components {
+				msg := component.Error
+				if msg == "" {
+					msg = "-"
+				}
+				t.add(component.Name, component.Status, msg)
+			}
+			if err := o.print(cmd.OutOrStdout(), resp, t); err != nil {
+				return err
+			}
+			if !resp.Success {
+				return errors.New("some components failed to reload")
+			}
+			return nil
+		},
+	}
+}
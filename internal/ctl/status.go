@@ -0,0 +1,235 @@
+package ctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// logIdleTimeout ends the logs command without --follow once the buffered lines are written.
+const logIdleTimeout = 500 * time.Millisecond
+
+type endpointStatus struct {
+	Enable         bool   `json:"enable"`
+	Address        string `json:"address"`
+	ClientAuthMode string `json:"client_auth_mode,omitempty"`
+	AutoCert       *bool  `json:"auto_cert,omitempty"`
+}
+
+type statusResponse struct {
+	Version         string          `json:"version"`
+	AppVersion      string          `json:"app_version"`
+	Edition         string          `json:"edition"`
+	Language        string          `json:"language"`
+	WorkDir         string          `json:"work_dir"`
+	LogPath         string          `json:"log_path"`
+	PowerSavingMode bool            `json:"power_saving_mode"`
+	Https           *endpointStatus `json:"https"`
+	Discovery       bool            `json:"discovery"`
+	Remote          bool            `json:"remote"`
+	PendingActions  int             `json:"pending_actions"`
+}
+
+func address(host string, port int) string {
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return host + ":" + strconv.Itoa(port)
+}
+
+func (o *options) statusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the version and the state of the services",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context(cmd)
+			defer cancel()
+			info, err := c.SoftwareInfo(ctx)
+			if err != nil {
+				return err
+			}
+			status := statusResponse{
+				Version:    info.Version,
+				AppVersion: info.AppVersion,
+				Edition:    info.Edition,
+				Language:   info.Language,
+				WorkDir:    info.WorkDir,
+				LogPath:    info.LogPath,
+			}
+			if status.PowerSavingMode, err = c.PowerSavingModeStatus(ctx); err != nil {
+				return err
+			}
+			https, err := c.HttpsConfig(ctx)
+			if err != nil {
+				return err
+			}
+			status.Https = &endpointStatus{Enable: https.Enable, Address: address(https.Host, https.Port), ClientAuthMode: https.ClientAuthMode, AutoCert: https.AutoCert}
+			discovery, err := c.DiscoveryConfig(ctx)
+			if err != nil {
+				return err
+			}
+			status.Discovery = discovery.Enabled
+			remote, err := c.RemoteConfig(ctx)
+			if err != nil {
+				return err
+			}
+			status.Remote = remote.Enable
+			pending, err := c.ListPendingActions(ctx)
+			if err != nil {
+				return err
+			}
+			status.PendingActions = len(pending)
+
+			t := &table{}
+			t.add("Version:", status.Version)
+			t.add("App version:", status.AppVersion)
+			t.add("Edition:", status.Edition)
+			t.add("Language:", status.Language)
+			t.add("Work dir:", status.WorkDir)
+			t.add("Log:", status.LogPath)
+			t.add("Power saving:", formatBool(status.PowerSavingMode))
+			t.add("HTTPS API:", fmt.Sprintf("%s (enabled: %s, client certificates: %s)", status.Https.Address, formatBool(status.Https.Enable), status.Https.ClientAuthMode))
+			t.add("Discovery:", formatBool(status.Discovery))
+			t.add("Remote:", formatBool(status.Remote))
+			t.add("Pending actions:", strconv.Itoa(status.PendingActions))
+			return o.print(cmd.OutOrStdout(), status, t)
+		},
+	}
+}
+
+// copyUntilIdle copies r to w until no data arrived for idle.
+func copyUntilIdle(ctx context.Context, w io.Writer, r io.Reader, idle time.Duration) error {
+	chunks := make(chan []byte)
+	errs := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := r.Read(buf)
+			if n > 0 {
+				chunk := make([]byte, n)
+				copy(chunk, buf[:n])
+				select {
+				case chunks <- chunk:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+	timer := time.NewTimer(idle)
+	defer timer.Stop()
+	for {
+		select {
+		case chunk := <-chunks:
+			if _, err := w.Write(chunk); err != nil {
+				return err
+			}
+			timer.Reset(idle)
+		case err := <-errs:
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (o *options) logsCommand() *cobra.Command {
+	var follow bool
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Print the recent log of the service",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context(cmd)
+			defer cancel()
+			body, err := c.StreamServiceLogs(ctx)
+			if err != nil {
+				return err
+			}
+			defer body.Close()
+			if follow {
+				_, err = io.Copy(cmd.OutOrStdout(), body)
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+			return copyUntilIdle(ctx, cmd.OutOrStdout(), body, logIdleTimeout)
+		},
+	}
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "keep printing new lines until interrupted")
+	return cmd
+}
+
+func (o *options) remoteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remote",
+		Short: "Inspect and restart the remote connection",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "Show the configuration of the remote connection",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context(cmd)
+			defer cancel()
+			config, err := c.RemoteConfig(ctx)
+			if err != nil {
+				return err
+			}
+			// the security key stays on the computer
+			config.SecurityKey = ""
+			t := &table{}
+			t.add("Enabled:", formatBool(config.Enable))
+			t.add("Client id:", config.ClientId)
+			t.add("API server:", config.ApiServerUrl)
+			t.add("Message servers:", strings.Join(config.MsgServerUrls, ", "))
+			t.add("Timestamp check:", formatBool(config.TimeStampCheck))
+			return o.print(cmd.OutOrStdout(), config, t)
+		},
+	}, &cobra.Command{
+		Use:   "restart",
+		Short: "Reconnect to the remote servers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context(cmd)
+			defer cancel()
+			if err := c.RestartRemoteService(ctx); err != nil {
+				return err
+			}
+			return o.printMessage(cmd.OutOrStdout(), "remote connection restarted")
+		},
+	})
+	return cmd
+}
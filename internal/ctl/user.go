@@ -0,0 +1,114 @@
+package ctl
+
+import (
+	"bufio"
+	"errors"
+	"fadacontrol/internal/schema/user_schema"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func usersTable(users ...user_schema.UserResponse) *table {
+	t := &table{headers: []string{"USERNAME", "ROLES", "DISABLED", "MUST CHANGE PASSWORD", "CREATED AT"}}
+	for _, u := range users {
+		t.add(u.Username, strings.Join(u.Roles, ","), formatBool(u.Disabled), formatBool(u.MustChangePassword), formatTime(&u.CreatedAt))
+	}
+	return t
+}
+
+func (o *options) userCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "Manage the users of the service",
+	}
+	cmd.AddCommand(o.userListCommand(), o.userAddCommand(), o.userDeleteCommand())
+	return cmd
+}
+
+func (o *options) userListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the users",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context(cmd)
+			defer cancel()
+			users, err := c.ListUsers(ctx)
+			if err != nil {
+				return err
+			}
+			return o.print(cmd.OutOrStdout(), users, usersTable(users...))
+		},
+	}
+}
+
+func (o *options) userAddCommand() *cobra.Command {
+	var roles []string
+	var password string
+	var passwordStdin bool
+	var mustChange bool
+	cmd := &cobra.Command{
+		Use:   "add <username>",
+		Short: "Create a user",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if passwordStdin {
+				line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+				if err != nil && line == "" {
+					return errors.New("no password on standard input")
+				}
+				password = strings.TrimRight(line, "\r\n")
+			}
+			if password == "" {
+				return errors.New("pass the password with --password or --password-stdin")
+			}
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context(cmd)
+			defer cancel()
+			user, err := c.CreateUser(ctx, &user_schema.CreateUserRequest{
+				Username:           args[0],
+				Password:           password,
+				Roles:              roles,
+				MustChangePassword: mustChange,
+			})
+			if err != nil {
+				return err
+			}
+			return o.print(cmd.OutOrStdout(), user, usersTable(*user))
+		},
+	}
+	cmd.Flags().StringSliceVar(&roles, "role", nil, "role of the user, can be repeated")
+	cmd.Flags().StringVar(&password, "password", "", "password of the user")
+	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "read the password from the standard input")
+	cmd.Flags().BoolVar(&mustChange, "must-change-password", false, "require a new password at the first login")
+	cmd.MarkFlagsMutuallyExclusive("password", "password-stdin")
+	return cmd
+}
+
+func (o *options) userDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <username>",
+		Short: "Delete a user with its tokens and sessions",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := o.context(cmd)
+			defer cancel()
+			if err := c.DeleteUser(ctx, args[0]); err != nil {
+				return err
+			}
+			return o.printMessage(cmd.OutOrStdout(), "deleted "+args[0])
+		},
+	}
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// ApiToken is a long-lived personal token, only the SHA-256 hash of the secret is stored.
+type ApiToken struct {
+	gorm.Model
+	Username string `gorm:"not null;index"`
+	Name     string `gorm:"not null"`
+	// Prefix is the beginning of the token, kept so that users can recognise their tokens.
+	Prefix string `gorm:"not null"`
+	Hash   string `gorm:"not null;uniqueIndex"`
+	// Scopes and AllowedIPs are comma separated lists, an empty AllowedIPs allows every address.
+	Scopes     string `gorm:"not null"`
+	AllowedIPs string `gorm:"not null;default:''"`
+	ExpiresAt  *time.Time
+	RevokedAt  *time.Time
+	LastUsedAt *time.Time
+	LastUsedIP string `gorm:"not null;default:''"`
+}
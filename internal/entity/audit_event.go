@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// AuditEvent records a control action, who requested it, where the request came from and its result.
+type AuditEvent struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"not null;index"`
+	ActorType string    `gorm:"not null;index"`
+	Actor     string    `gorm:"not null;default:'';index"`
+	// ActorId is the id of the api token or client certificate that authenticated the request.
+	ActorId  string `gorm:"not null;default:''"`
+	Source   string `gorm:"not null;index"`
+	SourceIP string `gorm:"not null;default:''"`
+	Action   string `gorm:"not null;index"`
+	// Params is a JSON object of the parameters of the action, secrets are redacted before it is saved.
+	Params string `gorm:"not null;default:'{}'"`
+	Code   int    `gorm:"not null"`
+	Msg    string `gorm:"not null;default:''"`
+}
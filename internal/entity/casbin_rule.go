@@ -10,3 +10,8 @@ type CasbinRule struct {
 	V4    string `gorm:"size:128;uniqueIndex:unique_index"`
 	V5    string `gorm:"size:128;uniqueIndex:unique_index"`
 }
+
+// TableName is the default table of the casbin gorm adapter.
+func (CasbinRule) TableName() string {
+	return "casbin_rule"
+}
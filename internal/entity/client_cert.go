@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// CertAuthority is the local certificate authority, Name tells the authorities apart.
+type CertAuthority struct {
+	gorm.Model
+	Name string `gorm:"not null;uniqueIndex"`
+	// Cert and Key are PEM encoded.
+	Cert string `gorm:"not null"`
+	Key  string `gorm:"not null"`
+	// CrlNumber is increased every time a revocation list is signed.
+	CrlNumber int64 `gorm:"not null;default:0"`
+}
+
+// ClientCert is a client certificate issued by the local authority, the private key is never stored.
+type ClientCert struct {
+	gorm.Model
+	// Serial is the hex encoded serial number of the certificate.
+	Serial      string    `gorm:"not null;uniqueIndex"`
+	Username    string    `gorm:"not null;index"`
+	Name        string    `gorm:"not null"`
+	Fingerprint string    `gorm:"not null"`
+	NotAfter    time.Time `gorm:"not null"`
+	RevokedAt   *time.Time
+}
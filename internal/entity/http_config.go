@@ -11,4 +11,9 @@ type HttpConfig struct {
 	Cer         string `gorm:"not null;default:''"`
 	Key         string `gorm:"not null;default:''"`
 	EnableHttp3 bool   `gorm:"not null;default:false"`
+	// ClientAuthMode is none, optional or required, it decides whether TLS clients present a certificate.
+	ClientAuthMode string `gorm:"not null;default:'none'"`
+	// AutoCert means the certificate is issued and renewed by the local authority, it is nil for configurations
+	// created before the certificates were managed.
+	AutoCert *bool
 }
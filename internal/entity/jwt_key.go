@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// JwtKey is a key used to sign access tokens, Kid is written to the token header.
+type JwtKey struct {
+	gorm.Model
+	Kid       string `gorm:"not null;uniqueIndex"`
+	Algorithm string `gorm:"not null"`
+	// Secret is the base64 encoded HMAC secret or Ed25519 private key.
+	Secret string `gorm:"not null"`
+	// PublicKey is the base64 encoded Ed25519 public key, empty for HMAC keys.
+	PublicKey string `gorm:"not null;default:''"`
+	// RetiredAt is set when the key is replaced, it still verifies tokens until they expire.
+	RetiredAt *time.Time
+}
+
+// UserSession is created on login, the access tokens carry its Sid and the refresh token is bound to it.
+type UserSession struct {
+	gorm.Model
+	Sid         string    `gorm:"not null;uniqueIndex"`
+	Username    string    `gorm:"not null;index"`
+	RefreshHash string    `gorm:"not null;default:''"`
+	ExpiresAt   time.Time `gorm:"not null"`
+	RevokedAt   *time.Time
+	RefreshedAt *time.Time
+	// TwoFactor is set when the login was completed with a second factor.
+	TwoFactor bool   `gorm:"not null;default:false"`
+	ClientIP  string `gorm:"not null;default:''"`
+	UserAgent string `gorm:"not null;default:''"`
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type Macro struct {
+	gorm.Model
+	Name        string `gorm:"not null;uniqueIndex"`
+	Description string `gorm:"not null;default:''"`
+	CreatedBy   string `gorm:"not null;default:''"`
+}
+
+type MacroStep struct {
+	gorm.Model
+	MacroId      uint   `gorm:"not null;index"`
+	StepIndex    int    `gorm:"not null"`
+	Type         string `gorm:"not null"`
+	Condition    string `gorm:"not null;default:''"`
+	ShutdownType int    `gorm:"not null;default:0"`
+	CommandPath  string `gorm:"not null;default:''"`
+	CommandName  string `gorm:"not null;default:''"`
+	Seconds      int    `gorm:"not null;default:0"`
+	Mac          string `gorm:"not null;default:''"`
+	Broadcast    string `gorm:"not null;default:''"`
+}
+
+type MacroRun struct {
+	gorm.Model
+	MacroId    uint   `gorm:"not null;index"`
+	MacroName  string `gorm:"not null"`
+	Source     string `gorm:"not null;default:''"`
+	Requester  string `gorm:"not null;default:''"`
+	StartedAt  time.Time
+	FinishedAt *time.Time
+	Status     string `gorm:"not null"`
+	Code       int    `gorm:"not null;default:0"`
+	Msg        string `gorm:"not null;default:''"`
+}
+
+type MacroStepRun struct {
+	gorm.Model
+	RunId      uint   `gorm:"not null;index"`
+	StepIndex  int    `gorm:"not null"`
+	Type       string `gorm:"not null"`
+	Status     string `gorm:"not null"`
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+	Code       int    `gorm:"not null;default:0"`
+	Msg        string `gorm:"not null;default:''"`
+}
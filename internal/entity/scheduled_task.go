@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type ScheduledTask struct {
+	gorm.Model
+	Name            string     `gorm:"not null"`
+	CronExpr        string     `gorm:"not null;default:''"` // empty for one-shot tasks
+	RunAt           *time.Time // one-shot time, only used when CronExpr is empty
+	Timezone        string     `gorm:"not null;default:''"`
+	Action          string     `gorm:"not null"`
+	ShutdownType    int        `gorm:"not null;default:0"`
+	CommandPath     string     `gorm:"not null;default:''"`
+	CommandName     string     `gorm:"not null;default:''"`
+	MacroName       string     `gorm:"not null;default:''"`
+	Enabled         bool       `gorm:"not null"`
+	MissedRunPolicy string     `gorm:"not null;default:'skip'"`
+	CreatedBy       string     `gorm:"not null;default:''"`
+	LastRunAt       *time.Time
+	NextRunAt       *time.Time `gorm:"index"`
+}
+
+type ScheduledTaskRun struct {
+	gorm.Model
+	TaskId      uint      `gorm:"not null;index"`
+	ScheduledAt time.Time `gorm:"not null"`
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+	Missed      bool   `gorm:"not null;default:false"`
+	Code        int    `gorm:"not null;default:0"`
+	Msg         string `gorm:"not null;default:''"`
+}
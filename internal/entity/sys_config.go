@@ -7,4 +7,9 @@ type SysConfig struct {
 	PowerSavingMode bool   `gorm:"default:false"`
 	Region          int    `gorm:"not null;default:0"`
 	Language        string `gorm:"not null;default:'en'"`
+	// RequireTwoFactor forces accounts that can run power actions or custom commands to log in with a second factor.
+	RequireTwoFactor bool `gorm:"not null;default:false"`
+	// AuditRetentionDays and AuditMaxEvents limit the audit log, zero keeps the events forever.
+	AuditRetentionDays int `gorm:"not null;default:90"`
+	AuditMaxEvents     int `gorm:"not null;default:100000"`
 }
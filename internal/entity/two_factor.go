@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// TwoFactor holds the TOTP secret of a user, it is only used for login after Enabled is set by the confirmation step.
+type TwoFactor struct {
+	gorm.Model
+	Username string `gorm:"not null;uniqueIndex"`
+	Secret   string `gorm:"not null"`
+	Enabled  bool   `gorm:"not null;default:false"`
+	// LastCounter is the last accepted time step, codes can not be used twice.
+	LastCounter int64 `gorm:"not null;default:0"`
+}
+
+type RecoveryCode struct {
+	gorm.Model
+	Username string `gorm:"not null;index"`
+	Hash     string `gorm:"not null"`
+	UsedAt   *time.Time
+}
@@ -7,4 +7,7 @@ type User struct {
 	Username string `gorm:"unique,not null,column:username"`
 	Password string `gorm:"not null,column:password"`
 	Salt     string `gorm:"not null,column:salt"`
+	Disabled bool   `gorm:"not null;default:false"`
+	// MustChangePassword restricts the user to the self-service endpoints until the password is changed.
+	MustChangePassword bool `gorm:"not null;default:false"`
 }
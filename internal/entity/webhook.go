@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Webhook is a subscription that receives the events in Events, a comma separated list where * matches every event.
+type Webhook struct {
+	gorm.Model
+	Name    string `gorm:"not null;uniqueIndex"`
+	Url     string `gorm:"not null"`
+	Events  string `gorm:"not null"`
+	Secret  string `gorm:"not null"`
+	Enabled bool   `gorm:"not null"`
+}
+
+// WebhookDelivery is an attempt to deliver an event to a webhook, the attempts of a delivery share DeliveryId.
+type WebhookDelivery struct {
+	ID         uint      `gorm:"primarykey"`
+	CreatedAt  time.Time `gorm:"not null"`
+	WebhookId  uint      `gorm:"not null;index"`
+	DeliveryId string    `gorm:"not null;index"`
+	Event      string    `gorm:"not null"`
+	Attempt    int       `gorm:"not null"`
+	StatusCode int       `gorm:"not null;default:0"`
+	Success    bool      `gorm:"not null"`
+	Error      string    `gorm:"not null;default:''"`
+	Response   string    `gorm:"not null;default:''"`
+	DurationMs int64     `gorm:"not null;default:0"`
+}
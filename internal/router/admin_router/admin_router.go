@@ -23,10 +23,26 @@ type AdminRouter struct {
 	_sys        *common_controller.SystemController
 	_http       *admin_controller.HttpController
 	_de         *common_controller.DebugController
+	term        *admin_controller.TerminalController
+	sch         *admin_controller.ScheduleController
+	mac         *admin_controller.MacroController
+	user        *admin_controller.UserController
+	policy      *admin_controller.PolicyController
+	tok         *common_controller.TokenController
+	key         *admin_controller.JwtKeyController
+	tf          *common_controller.TwoFactorController
+	cert        *admin_controller.ClientCertController
+	certs       *common_controller.CertController
+	hc          *common_controller.HealthController
+	audit       *admin_controller.AuditController
+	wh          *admin_controller.WebhookController
+	ev          *admin_controller.EventController
+	backup      *admin_controller.BackupController
+	reload      *admin_controller.ReloadController
 }
 
-func NewAdminRouter(_de *common_controller.DebugController, _http *admin_controller.HttpController, sys *common_controller.SystemController, jwt *middleware.JwtMiddleware, rc *admin_controller.RemoteController, u *common_controller.UnlockController, o *common_controller.ControlPCController, di *admin_controller.DiscoverController, auth *common_controller.AuthController) *AdminRouter {
-	return &AdminRouter{router: gin.Default(), u: u, o: o, rc: rc, di: di, auth: auth, jwt: jwt, _sys: sys, _http: _http, _de: _de}
+func NewAdminRouter(reload *admin_controller.ReloadController, backup *admin_controller.BackupController, ev *admin_controller.EventController, wh *admin_controller.WebhookController, audit *admin_controller.AuditController, hc *common_controller.HealthController, certs *common_controller.CertController, cert *admin_controller.ClientCertController, tf *common_controller.TwoFactorController, key *admin_controller.JwtKeyController, tok *common_controller.TokenController, policy *admin_controller.PolicyController, user *admin_controller.UserController, mac *admin_controller.MacroController, sch *admin_controller.ScheduleController, term *admin_controller.TerminalController, _de *common_controller.DebugController, _http *admin_controller.HttpController, sys *common_controller.SystemController, jwt *middleware.JwtMiddleware, rc *admin_controller.RemoteController, u *common_controller.UnlockController, o *common_controller.ControlPCController, di *admin_controller.DiscoverController, auth *common_controller.AuthController) *AdminRouter {
+	return &AdminRouter{router: gin.Default(), u: u, o: o, rc: rc, di: di, auth: auth, jwt: jwt, _sys: sys, _http: _http, _de: _de, term: term, sch: sch, mac: mac, user: user, policy: policy, tok: tok, key: key, tf: tf, cert: cert, certs: certs, hc: hc, audit: audit, wh: wh, ev: ev, backup: backup, reload: reload}
 }
 
 var swagHandler gin.HandlerFunc
@@ -34,6 +50,8 @@ var swagHandler gin.HandlerFunc
 func (d *AdminRouter) Register() {
 
 	r := gin.Default()
+	r.Use(middleware.RequestLog())
+	r.Use(middleware.Metrics("admin"))
 	r.Use(middleware.Recovery())
 	r.Use(middleware.Cors())
 	r.Use(middleware.ErrorHandler())
@@ -47,7 +65,14 @@ func (d *AdminRouter) Register() {
 	apiv1 := r.Group("/admin/api/v1")
 	{
 		apiv1.GET("/ping", d._de.Ping)
+		apiv1.GET("/healthz", d.hc.Healthz)
+		apiv1.GET("/readyz", d.hc.Readyz)
+		apiv1.GET("/metrics", d.hc.Metrics)
 		apiv1.POST("/control-pc/:action", d.o.ControlPC)
+		apiv1.GET("/control-pc/pending", d.o.ListPendingActions)
+		apiv1.GET("/control-pc/pending/:id", d.o.GetPendingAction)
+		apiv1.DELETE("/control-pc/pending/:id", d.o.CancelPendingAction)
+		apiv1.POST("/control-pc/pending/:id/postpone", d.o.PostponePendingAction)
 		apiv1.POST("/unlock", d.u.Unlock)
 		apiv1.GET("/interface/:ip", d.o.GetInterfaceByIP)
 		apiv1.GET("/interface/:ip/all", d.o.GetInterfaceByIPAll)
@@ -58,6 +83,7 @@ func (d *AdminRouter) Register() {
 		apiv1.PATCH("/info/language", d._sys.SetLanguage)
 		apiv1.GET("/logs", d._sys.GetLog)
 		apiv1.GET("/logs/:module", d._sys.GetLog)
+		apiv1.GET("/logs/:module/search", d._sys.SearchLog)
 		apiv1.POST("/power-saving", d._sys.SetPowerSavingMode)
 		apiv1.GET("/power-saving/status", d._sys.GetPowerSavingModeStatus)
 		//	apiv1.GET("/internal-cmd/", d.internal.GetInternalCommandEvents)
@@ -75,10 +101,102 @@ func (d *AdminRouter) Register() {
 		apiv1.GET("/http/config", d._http.GetHttpConfig)
 		apiv1.PATCH("/http/config", d._http.PatchHttpConfig)
 		apiv1.PUT("/http/config", d._http.UpdateHttpConfig)
+		apiv1.GET("/http/bindings", d._http.GetBindings)
+		apiv1.POST("/http/cert/renew", d._http.RenewServerCert)
 
 		apiv1.POST("/sys/stop", d._http.StopService)
+		apiv1.POST("/sys/reload", d.reload.Reload)
+
+		apiv1.GET("/terminal", d.term.OpenTerminal)
+
+		apiv1.GET("/schedule/tasks", d.sch.ListTasks)
+		apiv1.POST("/schedule/tasks", d.sch.CreateTask)
+		apiv1.GET("/schedule/tasks/:id", d.sch.GetTask)
+		apiv1.PUT("/schedule/tasks/:id", d.sch.UpdateTask)
+		apiv1.DELETE("/schedule/tasks/:id", d.sch.DeleteTask)
+		apiv1.POST("/schedule/tasks/:id/run", d.sch.RunTask)
+		apiv1.GET("/schedule/tasks/:id/next-runs", d.sch.TaskNextRuns)
+		apiv1.GET("/schedule/tasks/:id/history", d.sch.ListRuns)
+		apiv1.POST("/schedule/preview", d.sch.PreviewNextRuns)
+
+		apiv1.GET("/macros", d.mac.ListMacros)
+		apiv1.POST("/macros", d.mac.CreateMacro)
+		apiv1.GET("/macros/:id", d.mac.GetMacro)
+		apiv1.PUT("/macros/:id", d.mac.UpdateMacro)
+		apiv1.DELETE("/macros/:id", d.mac.DeleteMacro)
+		apiv1.POST("/macros/:id/run", d.mac.RunMacro)
+		apiv1.GET("/macros/:id/runs", d.mac.ListRuns)
+		apiv1.GET("/macro-runs/:id", d.mac.GetRun)
+
+		apiv1.GET("/users", d.user.ListUsers)
+		apiv1.POST("/users", d.user.CreateUser)
+		apiv1.GET("/users/:username", d.user.GetUser)
+		apiv1.DELETE("/users/:username", d.user.DeleteUser)
+		apiv1.POST("/users/:username/disable", d.user.DisableUser)
+		apiv1.POST("/users/:username/enable", d.user.EnableUser)
+		apiv1.POST("/users/:username/reset-password", d.user.ResetPassword)
+		apiv1.PUT("/users/:username/roles", d.user.SetRoles)
+		apiv1.DELETE("/users/:username/sessions", d.user.RevokeSessions)
+		apiv1.DELETE("/users/:username/2fa", d.user.ResetTwoFactor)
+		apiv1.PUT("/user/password", d.auth.ChangePassword)
+		apiv1.GET("/user/tokens", d.tok.ListOwnTokens)
+		apiv1.POST("/user/tokens", d.tok.CreateToken)
+		apiv1.GET("/user/tokens/scopes", d.tok.ListScopes)
+		apiv1.DELETE("/user/tokens/:id", d.tok.RevokeOwnToken)
+		apiv1.GET("/user/2fa", d.tf.Status)
+		apiv1.POST("/user/2fa/enroll", d.tf.BeginEnrollment)
+		apiv1.GET("/user/2fa/qr", d.tf.QrCode)
+		apiv1.POST("/user/2fa/confirm", d.tf.ConfirmEnrollment)
+		apiv1.POST("/user/2fa/disable", d.tf.Disable)
+		apiv1.POST("/user/2fa/recovery-codes", d.tf.RegenerateRecoveryCodes)
+		apiv1.GET("/tokens", d.tok.ListTokens)
+		apiv1.DELETE("/tokens/:id", d.tok.RevokeToken)
+
+		apiv1.GET("/policies", d.policy.ListPolicies)
+		apiv1.POST("/policies", d.policy.AddPolicy)
+		apiv1.DELETE("/policies", d.policy.RemovePolicy)
+		apiv1.GET("/roles", d.policy.ListRoles)
+		apiv1.GET("/roles/assignments", d.policy.ListRoleAssignments)
+		apiv1.POST("/roles/assignments", d.policy.AddRoleAssignment)
+		apiv1.DELETE("/roles/assignments", d.policy.RemoveRoleAssignment)
 
 		apiv1.POST("/login", d.auth.Login)
+		apiv1.POST("/login/2fa", d.auth.CompleteTwoFactor)
+		apiv1.POST("/auth/refresh", d.auth.Refresh)
+		apiv1.POST("/auth/logout", d.auth.Logout)
+		apiv1.GET("/user/sessions", d.auth.ListSessions)
+		apiv1.DELETE("/user/sessions/:sid", d.auth.RevokeSession)
+		apiv1.GET("/auth/keys", d.key.ListKeys)
+		apiv1.POST("/auth/keys/rotate", d.key.RotateKey)
+		apiv1.GET("/auth/2fa/policy", d.tf.GetPolicy)
+		apiv1.PUT("/auth/2fa/policy", d.tf.SetPolicy)
+
+		apiv1.GET("/certs/clients", d.cert.ListClientCerts)
+		apiv1.POST("/certs/clients", d.cert.IssueClientCert)
+		apiv1.DELETE("/certs/clients/:id", d.cert.RevokeClientCert)
+		apiv1.GET("/certs/crl", d.cert.GetCRL)
+		apiv1.GET("/certs", d.certs.GetCertificates)
+		apiv1.GET("/certs/ca", d.certs.GetCaCert)
+
+		apiv1.GET("/audit", d.audit.ListEvents)
+		apiv1.GET("/audit/export", d.audit.ExportEvents)
+		apiv1.GET("/audit/retention", d.audit.GetRetention)
+		apiv1.PUT("/audit/retention", d.audit.SetRetention)
+
+		apiv1.GET("/webhooks", d.wh.ListWebhooks)
+		apiv1.POST("/webhooks", d.wh.CreateWebhook)
+		apiv1.GET("/webhooks/events", d.wh.ListEvents)
+		apiv1.GET("/webhooks/:id", d.wh.GetWebhook)
+		apiv1.PUT("/webhooks/:id", d.wh.UpdateWebhook)
+		apiv1.DELETE("/webhooks/:id", d.wh.DeleteWebhook)
+		apiv1.POST("/webhooks/:id/test", d.wh.TestWebhook)
+		apiv1.GET("/webhooks/:id/deliveries", d.wh.ListDeliveries)
+
+		apiv1.GET("/events", d.ev.Stream)
+		apiv1.GET("/events/topics", d.ev.ListTopics)
+
+		apiv1.POST("/backup/export", d.backup.Export)
+		apiv1.POST("/backup/import", d.backup.Import)
 	}
 
 	d.router = r
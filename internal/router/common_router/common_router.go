@@ -21,10 +21,14 @@ type CommonRouter struct {
 	jwt  *middleware.JwtMiddleware
 	sys  *common_controller.SystemController
 	_de  *common_controller.DebugController
+	tok  *common_controller.TokenController
+	tf   *common_controller.TwoFactorController
+	cert *common_controller.CertController
+	hc   *common_controller.HealthController
 }
 
-func NewCommonRouter(_de *common_controller.DebugController, sys *common_controller.SystemController, jwt *middleware.JwtMiddleware, auth *common_controller.AuthController, cu *common_controller.CustomCommandController, u *common_controller.UnlockController, o *common_controller.ControlPCController) *CommonRouter {
-	return &CommonRouter{router: gin.Default(), u: u, o: o, cu: cu, auth: auth, jwt: jwt, sys: sys, _de: _de}
+func NewCommonRouter(hc *common_controller.HealthController, cert *common_controller.CertController, tf *common_controller.TwoFactorController, tok *common_controller.TokenController, _de *common_controller.DebugController, sys *common_controller.SystemController, jwt *middleware.JwtMiddleware, auth *common_controller.AuthController, cu *common_controller.CustomCommandController, u *common_controller.UnlockController, o *common_controller.ControlPCController) *CommonRouter {
+	return &CommonRouter{router: gin.Default(), u: u, o: o, cu: cu, auth: auth, jwt: jwt, sys: sys, _de: _de, tok: tok, tf: tf, cert: cert, hc: hc}
 }
 
 var swagHandler gin.HandlerFunc
@@ -32,7 +36,9 @@ var swagHandler gin.HandlerFunc
 func (d *CommonRouter) Register() {
 
 	r := gin.Default()
+	r.Use(middleware.RequestLog())
 	r.Use(middleware.UserHttp3())
+	r.Use(middleware.Metrics("api"))
 	r.Use(middleware.Recovery())
 	r.Use(middleware.Cors())
 	r.Use(middleware.ErrorHandler())
@@ -48,13 +54,38 @@ func (d *CommonRouter) Register() {
 	{
 
 		apiv1.GET("/ping", d._de.Ping)
+		apiv1.GET("/healthz", d.hc.Healthz)
+		apiv1.GET("/readyz", d.hc.Readyz)
+		apiv1.GET("/metrics", d.hc.Metrics)
 		apiv1.POST("/control-pc/:action", d.o.ControlPC)
+		apiv1.GET("/control-pc/pending", d.o.ListPendingActions)
+		apiv1.GET("/control-pc/pending/:id", d.o.GetPendingAction)
+		apiv1.DELETE("/control-pc/pending/:id", d.o.CancelPendingAction)
+		apiv1.POST("/control-pc/pending/:id/postpone", d.o.PostponePendingAction)
 		apiv1.POST("/unlock", d.u.Unlock)
 		apiv1.GET("/interface/:ip", d.o.GetInterfaceByIP)
 		apiv1.GET("/interface/:ip/all", d.o.GetInterfaceByIPAll)
 		apiv1.GET("/interface/", d.o.GetInterface)
 		apiv1.POST("/login", d.auth.Login)
+		apiv1.POST("/login/2fa", d.auth.CompleteTwoFactor)
+		apiv1.POST("/auth/refresh", d.auth.Refresh)
+		apiv1.POST("/auth/logout", d.auth.Logout)
+		apiv1.GET("/user/sessions", d.auth.ListSessions)
+		apiv1.DELETE("/user/sessions/:sid", d.auth.RevokeSession)
+		apiv1.PUT("/user/password", d.auth.ChangePassword)
+		apiv1.GET("/user/tokens", d.tok.ListOwnTokens)
+		apiv1.POST("/user/tokens", d.tok.CreateToken)
+		apiv1.GET("/user/tokens/scopes", d.tok.ListScopes)
+		apiv1.DELETE("/user/tokens/:id", d.tok.RevokeOwnToken)
+		apiv1.GET("/user/2fa", d.tf.Status)
+		apiv1.POST("/user/2fa/enroll", d.tf.BeginEnrollment)
+		apiv1.GET("/user/2fa/qr", d.tf.QrCode)
+		apiv1.POST("/user/2fa/confirm", d.tf.ConfirmEnrollment)
+		apiv1.POST("/user/2fa/disable", d.tf.Disable)
+		apiv1.POST("/user/2fa/recovery-codes", d.tf.RegenerateRecoveryCodes)
 		apiv1.GET("/info", d.sys.GetSoftwareInfo)
+		apiv1.GET("/certs", d.cert.GetCertificates)
+		apiv1.GET("/certs/ca", d.cert.GetCaCert)
 		//apiv1.POST("/execute", d.cu.Execute)
 		//apiv1.GET("/execute/:id", d.cu.ExecResult)
 
@@ -0,0 +1,124 @@
+package audit_schema
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	ActorUser  = "user"
+	ActorToken = "token"
+	// ActorDevice is a paired device that authenticated with a client certificate.
+	ActorDevice    = "device"
+	ActorRemote    = "remote"
+	ActorScheduler = "scheduler"
+	ActorAnonymous = "anonymous"
+)
+
+const (
+	SourceLanHttp   = "lan_http"
+	SourceAdmin     = "admin"
+	SourceRelay     = "relay"
+	SourceScheduler = "scheduler"
+)
+
+const (
+	ActionShutdown        = "shutdown"
+	ActionStandby         = "standby"
+	ActionLock            = "lock"
+	ActionUnlock          = "unlock"
+	ActionCancelPending   = "pending_cancel"
+	ActionPostponePending = "pending_postpone"
+	ActionCommand         = "command"
+	ActionMacro           = "macro"
+	ActionTerminal        = "terminal"
+)
+
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+const (
+	ExportCsv  = "csv"
+	ExportJson = "json"
+)
+
+// Redacted replaces the value of a secret parameter.
+const Redacted = "[REDACTED]"
+
+// secretKeys are the parts of a parameter name that mark its value as a secret.
+var secretKeys = []string{"password", "passwd", "secret", "token", "key", "otp", "code"}
+
+// Actor is who requested an action, Id is the id of the api token or client certificate.
+type Actor struct {
+	Type string
+	Name string
+	Id   string
+}
+
+type AuditEventResponse struct {
+	Id        uint                   `json:"id"`
+	Time      time.Time              `json:"time"`
+	ActorType string                 `json:"actor_type"`
+	Actor     string                 `json:"actor"`
+	ActorId   string                 `json:"actor_id"`
+	Source    string                 `json:"source"`
+	SourceIP  string                 `json:"source_ip"`
+	Action    string                 `json:"action"`
+	Params    map[string]interface{} `json:"params"`
+	Code      int                    `json:"code"`
+	Msg       string                 `json:"msg"`
+}
+
+type AuditEventListResponse struct {
+	Total  int64                 `json:"total"`
+	Events []*AuditEventResponse `json:"events"`
+}
+
+// AuditQuery filters the audit events, empty fields match every event.
+type AuditQuery struct {
+	From      *time.Time `form:"from"`
+	To        *time.Time `form:"to"`
+	ActorType string     `form:"actor_type"`
+	Actor     string     `form:"actor"`
+	Source    string     `form:"source"`
+	Action    string     `form:"action"`
+	// Result is success or failure.
+	Result   string `form:"result"`
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+}
+
+type AuditRetention struct {
+	// Days is how long events are kept, MaxEvents is how many are kept, zero means no limit.
+	Days      int `json:"days" binding:"gte=0"`
+	MaxEvents int `json:"max_events" binding:"gte=0"`
+}
+
+// RedactParams returns a copy of params where the values of secret parameters are replaced, nested objects are
+// redacted too.
+func RedactParams(params map[string]interface{}) map[string]interface{} {
+	ret := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		if isSecretKey(k) {
+			ret[k] = Redacted
+			continue
+		}
+		if nested, ok := v.(map[string]interface{}); ok {
+			v = RedactParams(nested)
+		}
+		ret[k] = v
+	}
+	return ret
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range secretKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
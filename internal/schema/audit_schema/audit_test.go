@@ -0,0 +1,36 @@
+package audit_schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedactParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   map[string]interface{}
+	}{
+		{"nil", nil, map[string]interface{}{}},
+		{"no secrets", map[string]interface{}{"username": "bob", "delay": 5}, map[string]interface{}{"username": "bob", "delay": 5}},
+		{"secrets", map[string]interface{}{"username": "bob", "Password": "pw", "api_token": "t", "SecurityKey": "k", "otp_code": "123456"},
+			map[string]interface{}{"username": "bob", "Password": Redacted, "api_token": Redacted, "SecurityKey": Redacted, "otp_code": Redacted}},
+		{"nested", map[string]interface{}{"user": map[string]interface{}{"name": "bob", "passwd": "pw"}},
+			map[string]interface{}{"user": map[string]interface{}{"name": "bob", "passwd": Redacted}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactParams(tt.params); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RedactParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactParamsCopies(t *testing.T) {
+	params := map[string]interface{}{"password": "pw"}
+	RedactParams(params)
+	if params["password"] != "pw" {
+		t.Errorf("RedactParams() changed its argument")
+	}
+}
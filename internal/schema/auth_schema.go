@@ -1,9 +1,45 @@
 package schema
 
+import "time"
+
 type TokenResponse struct {
 	Token string `json:"token"`
+	// ExpiresIn is the lifetime of Token in seconds.
+	ExpiresIn          int64  `json:"expires_in"`
+	RefreshToken       string `json:"refresh_token,omitempty"`
+	MustChangePassword bool   `json:"must_change_password,omitempty"`
+	// TwoFactorRequired means the password was accepted and Challenge has to be completed with a code.
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	Challenge         string `json:"challenge,omitempty"`
+	// TwoFactorEnrollmentRequired restricts the session to the self-service endpoints until two-factor authentication is enrolled.
+	TwoFactorEnrollmentRequired bool `json:"two_factor_enrollment_required,omitempty"`
 }
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	// Code is the optional TOTP or recovery code, it saves the challenge round trip.
+	Code string `json:"code"`
+}
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type SessionResponse struct {
+	Sid         string     `json:"sid"`
+	Username    string     `json:"username"`
+	ClientIP    string     `json:"client_ip"`
+	UserAgent   string     `json:"user_agent"`
+	Current     bool       `json:"current"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RefreshedAt *time.Time `json:"refreshed_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+}
+
+type JwtKeyResponse struct {
+	Kid       string     `json:"kid"`
+	Algorithm string     `json:"algorithm"`
+	PublicKey string     `json:"public_key,omitempty"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+	RetiredAt *time.Time `json:"retired_at"`
 }
@@ -0,0 +1,180 @@
+package backup_schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fadacontrol/pkg/secure"
+	"fmt"
+	"time"
+)
+
+// Format identifies a backup archive.
+const Format = "fadacontrol-backup"
+
+// Version is the schema version of the archives written by this build, older archives are migrated on import.
+const Version = 1
+
+const (
+	KdfArgon2id            = "argon2id"
+	CipherChaCha20Poly1305 = "chacha20-poly1305"
+)
+
+const (
+	kdfTime   = 3
+	kdfKeyLen = 32
+	saltSize  = 16
+)
+
+var (
+	ErrNotAnArchive     = errors.New("the file is not a fadacontrol backup")
+	ErrNewerArchive     = errors.New("the archive was created by a newer version of the program")
+	ErrWrongPassphrase  = errors.New("the passphrase is wrong or the archive is damaged")
+	ErrUnsupportedCrypt = errors.New("the archive is encrypted with an unsupported algorithm")
+)
+
+type Kdf struct {
+	Algorithm string `json:"algorithm"`
+	Salt      []byte `json:"salt"`
+	Time      uint32 `json:"time"`
+}
+
+// Archive is the file a backup is exported to, the configuration is readable and the secrets are encrypted with a
+// key derived from the passphrase. The encryption also authenticates the other fields.
+type Archive struct {
+	Format     string          `json:"format"`
+	Version    int             `json:"version"`
+	CreatedAt  time.Time       `json:"created_at"`
+	AppVersion string          `json:"app_version"`
+	Kdf        Kdf             `json:"kdf"`
+	Cipher     string          `json:"cipher"`
+	Config     json.RawMessage `json:"config" swaggertype:"object"`
+	Secrets    []byte          `json:"secrets"`
+}
+
+// Seal builds the archive of a snapshot.
+func Seal(s *Snapshot, passphrase, appVersion string) (*Archive, error) {
+	config, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := json.Marshal(s.secrets())
+	if err != nil {
+		return nil, err
+	}
+	salt, err := secure.GenerateSalt(saltSize)
+	if err != nil {
+		return nil, err
+	}
+	kdf := Kdf{Algorithm: KdfArgon2id, Salt: salt, Time: kdfTime}
+	key, err := kdf.key(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	a := &Archive{
+		Format:     Format,
+		Version:    Version,
+		CreatedAt:  time.Now().UTC(),
+		AppVersion: appVersion,
+		Kdf:        kdf,
+		Cipher:     CipherChaCha20Poly1305,
+		Config:     config,
+	}
+	ad, err := a.associatedData()
+	if err != nil {
+		return nil, err
+	}
+	a.Secrets, err = secure.EncryptChaCha20Poly1305WithAD(key, plain, ad)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// associatedData returns the readable part of the archive, it is authenticated with the secrets so the header and
+// the configuration can not be changed without the passphrase.
+func (a Archive) associatedData() ([]byte, error) {
+	a.Secrets = nil
+	// the configuration is compacted when marshalled, the indentation of the file does not matter
+	return json.Marshal(a)
+}
+
+func (k Kdf) key(passphrase string) ([]byte, error) {
+	if k.Algorithm != KdfArgon2id {
+		return nil, ErrUnsupportedCrypt
+	}
+	return secure.GenerateArgon2IDKeyOneTime64MB4Threads(passphrase, k.Salt, k.Time, kdfKeyLen)
+}
+
+// Open parses an archive, migrates it to the current version and decrypts its secrets. It returns the snapshot
+// and the version of the archive before the migration.
+func Open(data []byte, passphrase string) (*Snapshot, int, error) {
+	var a Archive
+	if err := json.Unmarshal(data, &a); err != nil || a.Format != Format {
+		return nil, 0, ErrNotAnArchive
+	}
+	version := a.Version
+	if version > Version {
+		return nil, version, ErrNewerArchive
+	}
+	if a.Cipher != CipherChaCha20Poly1305 {
+		return nil, version, ErrUnsupportedCrypt
+	}
+	// the archive is authenticated as it was written, before it is migrated
+	ad, err := a.associatedData()
+	if err != nil {
+		return nil, version, err
+	}
+	key, err := a.Kdf.key(passphrase)
+	if err != nil {
+		return nil, version, err
+	}
+	plain, err := secure.DecryptChaCha20Poly1305WithAD(key, a.Secrets, ad)
+	if err != nil {
+		return nil, version, ErrWrongPassphrase
+	}
+	if err := migrate(&a, migrations); err != nil {
+		return nil, version, err
+	}
+	var sec secrets
+	if err := json.Unmarshal(plain, &sec); err != nil {
+		return nil, version, ErrWrongPassphrase
+	}
+	var s Snapshot
+	if err := json.Unmarshal(a.Config, &s); err != nil {
+		return nil, version, fmt.Errorf("invalid configuration in the archive: %v", err)
+	}
+	s.setSecrets(&sec)
+	return &s, version, nil
+}
+
+// migration upgrades the configuration of an archive from its index + 1 to the next version, the secrets are
+// only readable with the passphrase and keep their layout.
+type migration func(config map[string]interface{}) error
+
+// migrations holds the upgrade of every version but the current one, append the upgrade to the previous version
+// when the snapshot changes and increase Version.
+var migrations []migration
+
+func migrate(a *Archive, migrations []migration) error {
+	if a.Version < 1 || a.Version > len(migrations)+1 {
+		return fmt.Errorf("unsupported archive version %d", a.Version)
+	}
+	if a.Version == len(migrations)+1 {
+		return nil
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal(a.Config, &config); err != nil {
+		return fmt.Errorf("invalid configuration in the archive: %v", err)
+	}
+	for ; a.Version <= len(migrations); a.Version++ {
+		if err := migrations[a.Version-1](config); err != nil {
+			return fmt.Errorf("failed to migrate the archive from version %d: %v", a.Version, err)
+		}
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	a.Config = data
+	return nil
+}
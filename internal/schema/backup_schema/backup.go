@@ -0,0 +1,272 @@
+package backup_schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MinPassphraseLength is the shortest passphrase the secrets of an archive are encrypted with.
+const MinPassphraseLength = 8
+
+// Sections of a snapshot, used in the changes of an import.
+const (
+	SectionSysConfig      = "sys_config"
+	SectionHttpConfigs    = "http_configs"
+	SectionRemoteConnect  = "remote_connect"
+	SectionDiscover       = "discover"
+	SectionUsers          = "users"
+	SectionCasbinRules    = "casbin_rules"
+	SectionCustomCommands = "custom_commands"
+)
+
+const (
+	OpAdd    = "add"
+	OpUpdate = "update"
+)
+
+type SysConfig struct {
+	PowerSavingMode    bool   `json:"power_saving_mode"`
+	Region             int    `json:"region"`
+	Language           string `json:"language"`
+	RequireTwoFactor   bool   `json:"require_two_factor"`
+	AuditRetentionDays int    `json:"audit_retention_days"`
+	AuditMaxEvents     int    `json:"audit_max_events"`
+}
+
+type HttpConfig struct {
+	ServiceName string `json:"service_name"`
+	Enable      bool   `json:"enable"`
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	Cer         string `json:"cer"`
+	// Key is the private key of the certificate, it is kept in the encrypted secrets.
+	Key            string `json:"-" backup:"key"`
+	EnableHttp3    bool   `json:"enable_http3"`
+	ClientAuthMode string `json:"client_auth_mode"`
+	AutoCert       *bool  `json:"auto_cert"`
+}
+
+type RemoteConnectConfig struct {
+	Enable         bool     `json:"enable"`
+	ClientId       string   `json:"client_id"`
+	SecurityKey    string   `json:"-" backup:"security_key"`
+	Token          string   `json:"-" backup:"token"`
+	TimeStampCheck bool     `json:"timestamp_check"`
+	ApiServerUrl   string   `json:"api_server_url"`
+	MsgServerUrls  []string `json:"msg_server_urls"`
+}
+
+type DiscoverConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+type User struct {
+	Username string `json:"username"`
+	// Password and Salt are the hash of the password, they are kept in the encrypted secrets.
+	Password           string `json:"-" backup:"password"`
+	Salt               string `json:"-" backup:"salt"`
+	Disabled           bool   `json:"disabled"`
+	MustChangePassword bool   `json:"must_change_password"`
+}
+
+// CasbinRule is a policy or role assignment, trailing empty values are omitted.
+type CasbinRule struct {
+	Ptype  string   `json:"ptype"`
+	Values []string `json:"values"`
+}
+
+func (r CasbinRule) key() string {
+	return r.Ptype + ", " + strings.Join(r.Values, ", ")
+}
+
+// CommandFile is a custom command config file referenced by a macro or a scheduled task.
+type CommandFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Snapshot is the configuration stored in an archive.
+type Snapshot struct {
+	SysConfig      *SysConfig           `json:"sys_config,omitempty"`
+	HttpConfigs    []HttpConfig         `json:"http_configs"`
+	RemoteConnect  *RemoteConnectConfig `json:"remote_connect,omitempty"`
+	Discover       *DiscoverConfig      `json:"discover,omitempty"`
+	Users          []User               `json:"users"`
+	CasbinRules    []CasbinRule         `json:"casbin_rules"`
+	CustomCommands []CommandFile        `json:"custom_commands"`
+}
+
+// secrets are the values of a snapshot that are encrypted under the passphrase.
+type secrets struct {
+	HttpKeys          map[string]string     `json:"http_keys"`
+	RemoteSecurityKey string                `json:"remote_security_key"`
+	RemoteToken       string                `json:"remote_token"`
+	Users             map[string]userSecret `json:"users"`
+}
+
+type userSecret struct {
+	Password string `json:"password"`
+	Salt     string `json:"salt"`
+}
+
+func (s *Snapshot) secrets() *secrets {
+	ret := &secrets{HttpKeys: make(map[string]string), Users: make(map[string]userSecret)}
+	for _, h := range s.HttpConfigs {
+		ret.HttpKeys[h.ServiceName] = h.Key
+	}
+	if s.RemoteConnect != nil {
+		ret.RemoteSecurityKey = s.RemoteConnect.SecurityKey
+		ret.RemoteToken = s.RemoteConnect.Token
+	}
+	for _, u := range s.Users {
+		ret.Users[u.Username] = userSecret{Password: u.Password, Salt: u.Salt}
+	}
+	return ret
+}
+
+func (s *Snapshot) setSecrets(sec *secrets) {
+	for i := range s.HttpConfigs {
+		s.HttpConfigs[i].Key = sec.HttpKeys[s.HttpConfigs[i].ServiceName]
+	}
+	if s.RemoteConnect != nil {
+		s.RemoteConnect.SecurityKey = sec.RemoteSecurityKey
+		s.RemoteConnect.Token = sec.RemoteToken
+	}
+	for i := range s.Users {
+		secret := sec.Users[s.Users[i].Username]
+		s.Users[i].Password = secret.Password
+		s.Users[i].Salt = secret.Salt
+	}
+}
+
+type ExportRequest struct {
+	Passphrase string `json:"passphrase" binding:"required"`
+}
+
+type ImportRequest struct {
+	Archive    json.RawMessage `json:"archive" binding:"required" swaggertype:"object"`
+	Passphrase string          `json:"passphrase" binding:"required"`
+	// DryRun only reports the changes the import would make.
+	DryRun bool `json:"dry_run"`
+}
+
+// Change is an entry of the archive that differs from the current configuration, Fields are the names of the
+// changed fields of an update.
+type Change struct {
+	Section string   `json:"section"`
+	Key     string   `json:"key,omitempty"`
+	Op      string   `json:"op"`
+	Fields  []string `json:"fields,omitempty"`
+}
+
+func (c Change) String() string {
+	s := c.Op + " " + c.Section
+	if c.Key != "" {
+		s += " " + c.Key
+	}
+	if len(c.Fields) > 0 {
+		s += " (" + strings.Join(c.Fields, ", ") + ")"
+	}
+	return s
+}
+
+type ImportResponse struct {
+	DryRun bool `json:"dry_run"`
+	// Version is the schema version of the archive before it was migrated.
+	Version int      `json:"version"`
+	Changes []Change `json:"changes"`
+	// RestartRequired is set when the http or remote servers have to be restarted to use the configuration.
+	RestartRequired bool `json:"restart_required"`
+}
+
+// Diff returns the changes importing the incoming snapshot makes to the current one. Entries that are only in
+// the current snapshot are kept by an import and are not reported.
+func Diff(current, incoming *Snapshot) []Change {
+	changes := make([]Change, 0)
+	single := func(section string, cur, in interface{}) {
+		if reflect.ValueOf(in).IsNil() {
+			return
+		}
+		if reflect.ValueOf(cur).IsNil() {
+			changes = append(changes, Change{Section: section, Op: OpAdd})
+			return
+		}
+		if fields := changedFields(cur, in); len(fields) > 0 {
+			changes = append(changes, Change{Section: section, Op: OpUpdate, Fields: fields})
+		}
+	}
+	single(SectionSysConfig, current.SysConfig, incoming.SysConfig)
+
+	https := make(map[string]*HttpConfig)
+	for i := range current.HttpConfigs {
+		https[current.HttpConfigs[i].ServiceName] = &current.HttpConfigs[i]
+	}
+	for i := range incoming.HttpConfigs {
+		in := &incoming.HttpConfigs[i]
+		changes = appendKeyed(changes, SectionHttpConfigs, in.ServiceName, https[in.ServiceName], in)
+	}
+
+	single(SectionRemoteConnect, current.RemoteConnect, incoming.RemoteConnect)
+	single(SectionDiscover, current.Discover, incoming.Discover)
+
+	users := make(map[string]*User)
+	for i := range current.Users {
+		users[current.Users[i].Username] = &current.Users[i]
+	}
+	for i := range incoming.Users {
+		in := &incoming.Users[i]
+		changes = appendKeyed(changes, SectionUsers, in.Username, users[in.Username], in)
+	}
+
+	rules := make(map[string]bool)
+	for _, r := range current.CasbinRules {
+		rules[r.key()] = true
+	}
+	for _, r := range incoming.CasbinRules {
+		if !rules[r.key()] {
+			changes = append(changes, Change{Section: SectionCasbinRules, Key: r.key(), Op: OpAdd})
+		}
+	}
+
+	files := make(map[string]*CommandFile)
+	for i := range current.CustomCommands {
+		files[current.CustomCommands[i].Path] = &current.CustomCommands[i]
+	}
+	for i := range incoming.CustomCommands {
+		in := &incoming.CustomCommands[i]
+		changes = appendKeyed(changes, SectionCustomCommands, in.Path, files[in.Path], in)
+	}
+	return changes
+}
+
+func appendKeyed[T any](changes []Change, section, key string, cur, in *T) []Change {
+	if cur == nil {
+		return append(changes, Change{Section: section, Key: key, Op: OpAdd})
+	}
+	if fields := changedFields(cur, in); len(fields) > 0 {
+		return append(changes, Change{Section: section, Key: key, Op: OpUpdate, Fields: fields})
+	}
+	return changes
+}
+
+// changedFields compares two pointers to the same struct type and returns the names of the fields that differ,
+// the secret fields are named by their backup tag.
+func changedFields(a, b interface{}) []string {
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	var fields []string
+	for i := 0; i < va.NumField(); i++ {
+		if reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			continue
+		}
+		f := va.Type().Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			name = f.Tag.Get("backup")
+		}
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
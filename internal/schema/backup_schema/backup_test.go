@@ -0,0 +1,196 @@
+package backup_schema
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSnapshot() *Snapshot {
+	auto := true
+	return &Snapshot{
+		SysConfig: &SysConfig{Language: "en", AuditRetentionDays: 90, AuditMaxEvents: 100000},
+		HttpConfigs: []HttpConfig{
+			{ServiceName: "http_api", Enable: true, Host: "0.0.0.0", Port: 2091, ClientAuthMode: "none"},
+			{ServiceName: "https_api", Enable: true, Host: "0.0.0.0", Port: 2092, Cer: "CERT", Key: "PRIVATE KEY", ClientAuthMode: "optional", AutoCert: &auto},
+		},
+		RemoteConnect: &RemoteConnectConfig{Enable: true, ClientId: "client", SecurityKey: "remote-key", Token: "remote-token", ApiServerUrl: "https://api", MsgServerUrls: []string{"tcp://msg"}},
+		Discover:      &DiscoverConfig{Enabled: true},
+		Users: []User{
+			{Username: "root", Password: "hash", Salt: "salt"},
+		},
+		CasbinRules: []CasbinRule{
+			{Ptype: "g", Values: []string{"root", "admin"}},
+			{Ptype: "p", Values: []string{"admin", "*", "*"}},
+		},
+		CustomCommands: []CommandFile{{Path: "C:\\commands.yaml", Content: "commands: []\n"}},
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	s := testSnapshot()
+	a, err := Seal(s, "correct horse", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"PRIVATE KEY", "remote-key", "remote-token", "hash"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("the archive contains the secret %q in clear text", secret)
+		}
+	}
+	if !strings.Contains(string(data), "CERT") {
+		t.Errorf("expected the certificate to be readable")
+	}
+
+	got, version, err := Open(data, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != Version {
+		t.Errorf("version = %d, want %d", version, Version)
+	}
+	if !reflect.DeepEqual(got, s) {
+		t.Errorf("got %+v, want %+v", got, s)
+	}
+
+	if _, _, err := Open(data, "wrong passphrase"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase, got %v", err)
+	}
+}
+
+func TestOpenTampered(t *testing.T) {
+	a, err := Seal(testSnapshot(), "correct horse", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		tamper func(a *Archive)
+	}{
+		{"config", func(a *Archive) {
+			a.Config = json.RawMessage(strings.Replace(string(a.Config), `"port":2091`, `"port":2093`, 1))
+		}},
+		{"certificate", func(a *Archive) {
+			a.Config = json.RawMessage(strings.Replace(string(a.Config), "CERT", "OTHER", 1))
+		}},
+		{"app version", func(a *Archive) { a.AppVersion = "0.9.0" }},
+		{"created at", func(a *Archive) { a.CreatedAt = a.CreatedAt.Add(-time.Hour) }},
+		{"kdf time", func(a *Archive) { a.Kdf.Time = 1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := *a
+			tt.tamper(&tampered)
+			if string(tampered.Config) == string(a.Config) && tampered.AppVersion == a.AppVersion &&
+				tampered.CreatedAt.Equal(a.CreatedAt) && tampered.Kdf.Time == a.Kdf.Time {
+				t.Fatal("the archive was not changed")
+			}
+			data, err := json.Marshal(&tampered)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := Open(data, "correct horse"); !errors.Is(err, ErrWrongPassphrase) {
+				t.Errorf("expected ErrWrongPassphrase, got %v", err)
+			}
+		})
+	}
+
+	// the layout of the file is not authenticated
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Open(data, "correct horse"); err != nil {
+		t.Errorf("failed to open the indented archive: %v", err)
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want error
+	}{
+		{"not json", "garbage", ErrNotAnArchive},
+		{"other format", `{"format":"other","version":1}`, ErrNotAnArchive},
+		{"newer", `{"format":"fadacontrol-backup","version":99}`, ErrNewerArchive},
+		{"cipher", `{"format":"fadacontrol-backup","version":1,"cipher":"rot13","config":{}}`, ErrUnsupportedCrypt},
+		{"kdf", `{"format":"fadacontrol-backup","version":1,"cipher":"chacha20-poly1305","kdf":{"algorithm":"md5"},"config":{}}`, ErrUnsupportedCrypt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Open([]byte(tt.data), "passphrase"); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	if len(migrations)+1 != Version {
+		t.Fatalf("%d migrations for version %d", len(migrations), Version)
+	}
+	a := &Archive{Version: 1, Config: json.RawMessage(`{"discover":{"enable":true}}`)}
+	steps := []migration{
+		func(config map[string]interface{}) error {
+			discover := config["discover"].(map[string]interface{})
+			discover["enabled"] = discover["enable"]
+			delete(discover, "enable")
+			return nil
+		},
+		func(config map[string]interface{}) error {
+			config["users"] = []interface{}{}
+			return nil
+		},
+	}
+	if err := migrate(a, steps); err != nil {
+		t.Fatal(err)
+	}
+	if a.Version != 3 {
+		t.Errorf("version = %d, want 3", a.Version)
+	}
+	if string(a.Config) != `{"discover":{"enabled":true},"users":[]}` {
+		t.Errorf("config = %s", a.Config)
+	}
+	if err := migrate(&Archive{Version: 4}, steps); err == nil {
+		t.Errorf("expected an error for a version without migration")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	current := testSnapshot()
+	incoming := testSnapshot()
+	if changes := Diff(current, incoming); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", changes)
+	}
+
+	incoming.SysConfig.Language = "zh"
+	incoming.HttpConfigs[1].Port = 443
+	incoming.HttpConfigs[1].Key = "NEW KEY"
+	incoming.Users[0].Password = "new hash"
+	incoming.Users = append(incoming.Users, User{Username: "bob"})
+	incoming.CasbinRules = append(incoming.CasbinRules, CasbinRule{Ptype: "g", Values: []string{"bob", "user"}})
+	incoming.CustomCommands[0].Content = "commands: [a]\n"
+	current.Discover = nil
+	incoming.RemoteConnect = nil
+
+	want := []Change{
+		{Section: SectionSysConfig, Op: OpUpdate, Fields: []string{"language"}},
+		{Section: SectionHttpConfigs, Key: "https_api", Op: OpUpdate, Fields: []string{"key", "port"}},
+		{Section: SectionDiscover, Op: OpAdd},
+		{Section: SectionUsers, Key: "root", Op: OpUpdate, Fields: []string{"password"}},
+		{Section: SectionUsers, Key: "bob", Op: OpAdd},
+		{Section: SectionCasbinRules, Key: "g, bob, user", Op: OpAdd},
+		{Section: SectionCustomCommands, Key: "C:\\commands.yaml", Op: OpUpdate, Fields: []string{"content"}},
+	}
+	if got := Diff(current, incoming); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+package cert_schema
+
+import "time"
+
+const (
+	MaxCertNameLength   = 64
+	DefaultCertValidity = 365
+	MaxCertValidity     = 3650
+)
+
+type IssueClientCertRequest struct {
+	// Username is the FadaControl user the certificate authenticates as.
+	Username string `json:"username" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	// ValidDays defaults to 365 days.
+	ValidDays int `json:"valid_days"`
+}
+
+type ClientCertResponse struct {
+	Id          uint       `json:"id"`
+	Serial      string     `json:"serial"`
+	Username    string     `json:"username"`
+	Name        string     `json:"name"`
+	Fingerprint string     `json:"fingerprint"`
+	NotAfter    time.Time  `json:"not_after"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// IssueClientCertResponse carries the private key of the certificate, it is only returned once.
+type IssueClientCertResponse struct {
+	ClientCertResponse
+	Certificate   string `json:"certificate"`
+	PrivateKey    string `json:"private_key"`
+	CaCertificate string `json:"ca_certificate"`
+}
+
+type CertificateInfo struct {
+	Subject string `json:"subject"`
+	Issuer  string `json:"issuer"`
+	// Fingerprint is the hex encoded SHA-256 hash of the certificate, SpkiPin the base64 encoded SHA-256 hash
+	// of its public key as used by HTTP public key pinning.
+	Fingerprint string    `json:"fingerprint_sha256"`
+	SpkiPin     string    `json:"spki_sha256"`
+	NotBefore   time.Time `json:"not_before"`
+	NotAfter    time.Time `json:"not_after"`
+	DNSNames    []string  `json:"dns_names,omitempty"`
+	IPAddresses []string  `json:"ip_addresses,omitempty"`
+}
+
+// CertificatesResponse describes the certificates of the HTTPS service, the server certificate is reissued
+// regularly so long-lived pins should use the authority.
+type CertificatesResponse struct {
+	CA *CertificateInfo `json:"ca"`
+	// Server is the certificate presented by the HTTPS service, it is empty when the service is not running.
+	Server   *CertificateInfo `json:"server"`
+	AutoCert bool             `json:"auto_cert"`
+}
@@ -1,14 +1,53 @@
 package custom_command_schema
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 type Command struct {
-	Name    string            `yaml:"name"`
-	Cmd     string            `yaml:"cmd"`
-	Args    []string          `yaml:"args"`
-	Env     map[string]string `yaml:"env"`
-	WorkDir string            `yaml:"workdir"`
+	Name          string            `yaml:"name"`
+	Cmd           string            `yaml:"cmd"`
+	Args          []string          `yaml:"args"`
+	Env           map[string]string `yaml:"env"`
+	WorkDir       string            `yaml:"workdir"`
+	Auth          CommandAuth       `yaml:"auth"`
+	MaxConcurrent int               `yaml:"max_concurrent"` // 0 means unlimited
+	Cooldown      time.Duration     `yaml:"cooldown"`       // minimum interval between two starts, e.g. "30s"
+	Limits        ResourceLimits    `yaml:"limits"`
+	// Path is the absolute path of the config file the command was loaded from, the auth rules of the command are
+	// checked against it.
+	Path string `yaml:"-"`
 }
+
+// CommandAuth describes who may run a command and from where.
+// Users and Roles are casbin subjects, root is always allowed by the default policy.
+type CommandAuth struct {
+	Users       []string `yaml:"users"`
+	Roles       []string `yaml:"roles"`
+	AllowRemote bool     `yaml:"allow_remote"` // allow execution through the remote relay, LAN only when false
+}
+
+// ResourceLimits are applied to the child process, only supported on linux.
+type ResourceLimits struct {
+	CpuTime         uint64 `yaml:"cpu_time"` // seconds
+	Memory          uint64 `yaml:"memory"`   // MiB of address space
+	NoNewPrivileges bool   `yaml:"no_new_privileges"`
+}
+
+func (l ResourceLimits) IsEmpty() bool {
+	return l.CpuTime == 0 && l.Memory == 0 && !l.NoNewPrivileges
+}
+
+type CommandSource uint8
+
+const (
+	UnknownSource CommandSource = iota
+	LanSource
+	RemoteSource
+	SchedulerSource
+)
+
 type CustomWriter struct {
 	Ch   chan []byte
 	done chan struct{}
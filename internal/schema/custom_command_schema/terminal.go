@@ -0,0 +1,19 @@
+package custom_command_schema
+
+type TerminalMessageType string
+
+const (
+	TerminalInput  TerminalMessageType = "input"
+	TerminalResize TerminalMessageType = "resize"
+	TerminalExit   TerminalMessageType = "exit"
+)
+
+// TerminalMessage is exchanged as a websocket text frame, terminal output is sent as binary frames.
+type TerminalMessage struct {
+	Type TerminalMessageType `json:"type"`
+	Data string              `json:"data,omitempty"`
+	Rows uint16              `json:"rows,omitempty"`
+	Cols uint16              `json:"cols,omitempty"`
+	Code int                 `json:"code,omitempty"`
+	Msg  string              `json:"msg,omitempty"`
+}
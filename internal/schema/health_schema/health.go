@@ -0,0 +1,26 @@
+package health_schema
+
+import "time"
+
+// States of a component, a component that is down fails the liveness check and only components that are up or
+// disabled pass the readiness check.
+const (
+	StatusUp       = "up"
+	StatusStarting = "starting"
+	StatusStopped  = "stopped"
+	StatusDisabled = "disabled"
+	StatusDown     = "down"
+)
+
+type ComponentHealth struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Error is the reason the component is down.
+	Error string    `json:"error,omitempty"`
+	Since time.Time `json:"since"`
+}
+
+type HealthResponse struct {
+	Status     string            `json:"status"`
+	Components []ComponentHealth `json:"components"`
+}
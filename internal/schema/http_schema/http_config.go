@@ -1,5 +1,19 @@
 package http_schema
 
+import "time"
+
+// Client certificate modes of the HTTPS service.
+const (
+	ClientAuthNone     = "none"
+	ClientAuthOptional = "optional"
+	ClientAuthRequired = "required"
+)
+
+// IsValidClientAuthMode reports whether mode is one of the client certificate modes.
+func IsValidClientAuthMode(mode string) bool {
+	return mode == ClientAuthNone || mode == ClientAuthOptional || mode == ClientAuthRequired
+}
+
 type HttpConfigRequest struct {
 	Enable bool   `json:"enable"`
 	Host   string `json:"host"`
@@ -12,6 +26,10 @@ type HttpsConfigRequest struct {
 	Cer         string `json:"cer"`
 	Key         string `json:"key"`
 	EnableHttp3 bool   `json:"enable_http3"`
+	// ClientAuthMode is none, optional or required.
+	ClientAuthMode string `json:"client_auth_mode"`
+	// AutoCert lets the local authority issue and renew the certificate, it is turned off when Cer or Key change.
+	AutoCert *bool `json:"auto_cert"`
 }
 type HttpConfigResponse struct {
 	Enable bool   `json:"enable"`
@@ -19,10 +37,22 @@ type HttpConfigResponse struct {
 	Port   int    `json:"port"`
 }
 type HttpsConfigResponse struct {
-	Enable      bool   `json:"enable"`
-	Host        string `json:"host"`
-	Port        int    `json:"port"`
-	Cer         string `json:"cer"`
-	Key         string `json:"key"`
-	EnableHttp3 bool   `json:"enable_http3"`
+	Enable         bool   `json:"enable"`
+	Host           string `json:"host"`
+	Port           int    `json:"port"`
+	Cer            string `json:"cer"`
+	Key            string `json:"key"`
+	EnableHttp3    bool   `json:"enable_http3"`
+	ClientAuthMode string `json:"client_auth_mode"`
+	AutoCert       *bool  `json:"auto_cert"`
+}
+
+// HttpBinding is an address a server of the service is listening on.
+type HttpBinding struct {
+	Service        string    `json:"service"`
+	Address        string    `json:"address"`
+	Tls            bool      `json:"tls"`
+	Http3          bool      `json:"http3"`
+	ClientAuthMode string    `json:"client_auth_mode,omitempty"`
+	Since          time.Time `json:"since"`
 }
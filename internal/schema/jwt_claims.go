@@ -4,5 +4,9 @@ import "github.com/golang-jwt/jwt/v5"
 
 type JwtClaims struct {
 	Username string `json:"user_name,omitempty"`
+	// SessionId ties the token to a UserSession so that it can be revoked.
+	SessionId string `json:"sid,omitempty"`
+	// TwoFactor is true when the session was authenticated with a second factor.
+	TwoFactor bool `json:"mfa,omitempty"`
 	jwt.RegisteredClaims
 }
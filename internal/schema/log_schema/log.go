@@ -0,0 +1,33 @@
+package log_schema
+
+import "time"
+
+// LogQuery filters the entries of the log files, empty fields match every entry.
+type LogQuery struct {
+	From *time.Time `form:"from"`
+	To   *time.Time `form:"to"`
+	// Level is the minimum level, debug, info, warn, error or fatal.
+	Level     string `form:"level"`
+	Module    string `form:"module"`
+	RequestId string `form:"request_id"`
+	Page      int    `form:"page"`
+	PageSize  int    `form:"page_size"`
+}
+
+type LogEntry struct {
+	Time      time.Time              `json:"time"`
+	Level     string                 `json:"level"`
+	Module    string                 `json:"module"`
+	Caller    string                 `json:"caller"`
+	Message   string                 `json:"message"`
+	RequestId string                 `json:"request_id,omitempty"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Stack     string                 `json:"stack,omitempty"`
+	// File is the log file the entry was read from.
+	File string `json:"file"`
+}
+
+type LogSearchResponse struct {
+	Total   int         `json:"total"`
+	Entries []*LogEntry `json:"entries"`
+}
@@ -0,0 +1,90 @@
+package macro_schema
+
+import "time"
+
+const (
+	StepCommand  = "command"
+	StepLock     = "lock"
+	StepShutdown = "shutdown"
+	StepStandby  = "standby"
+	StepWait     = "wait"
+	StepWol      = "wol"
+)
+
+// A step runs depending on the result of the previous executed step, skipped steps do not change it.
+const (
+	ConditionAlways  = "always"
+	ConditionSuccess = "success" // default
+	ConditionFailure = "failure"
+)
+
+const (
+	RunStatusRunning = "running"
+	RunStatusSuccess = "success"
+	RunStatusFailed  = "failed"
+	RunStatusSkipped = "skipped"
+)
+
+const (
+	SourceHttp      = "http"
+	SourceRemote    = "remote"
+	SourceScheduler = "scheduler"
+)
+
+const MaxMacroSteps = 64
+
+type MacroStep struct {
+	Type         string `json:"type" binding:"required"`
+	Condition    string `json:"condition"`
+	ShutdownType int    `json:"shutdown_type"`
+	CommandPath  string `json:"command_path"`
+	CommandName  string `json:"command_name"`
+	Seconds      int    `json:"seconds"`   // wait duration
+	Mac          string `json:"mac"`       // wake-on-lan target
+	Broadcast    string `json:"broadcast"` // wake-on-lan broadcast address, host:port
+}
+
+type MacroRequest struct {
+	Name        string      `json:"name" binding:"required"`
+	Description string      `json:"description"`
+	Steps       []MacroStep `json:"steps" binding:"required"`
+}
+
+type MacroResponse struct {
+	Id          uint        `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Steps       []MacroStep `json:"steps"`
+	CreatedBy   string      `json:"created_by"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+type MacroStepRunResponse struct {
+	Index      int        `json:"index"`
+	Type       string     `json:"type"`
+	Status     string     `json:"status"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	Code       int        `json:"code"`
+	Msg        string     `json:"msg"`
+}
+
+type MacroRunResponse struct {
+	Id         uint                    `json:"id"`
+	MacroId    uint                    `json:"macro_id"`
+	MacroName  string                  `json:"macro_name"`
+	Source     string                  `json:"source"`
+	Requester  string                  `json:"requester"`
+	StartedAt  time.Time               `json:"started_at"`
+	FinishedAt *time.Time              `json:"finished_at"`
+	Status     string                  `json:"status"`
+	Code       int                     `json:"code"`
+	Msg        string                  `json:"msg"`
+	Steps      []*MacroStepRunResponse `json:"steps,omitempty"`
+}
+
+type MacroRunListResponse struct {
+	Total int64               `json:"total"`
+	Runs  []*MacroRunResponse `json:"runs"`
+}
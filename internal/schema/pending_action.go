@@ -0,0 +1,36 @@
+package schema
+
+import "time"
+
+const (
+	PendingActionShutdown = "shutdown"
+	PendingActionStandby  = "standby"
+)
+
+const (
+	PendingStatusPending   = "pending"
+	PendingStatusRunning   = "running"
+	PendingStatusCancelled = "cancelled"
+	PendingStatusExecuted  = "executed"
+	PendingStatusFailed    = "failed"
+)
+
+const (
+	PendingSourceHttp   = "http"
+	PendingSourceRemote = "remote"
+)
+
+// PendingAction is a delayed power action, it can be cancelled or postponed until it starts.
+type PendingAction struct {
+	Id           string    `json:"id"`
+	Action       string    `json:"action"`
+	ShutdownType int       `json:"shutdown_type"`
+	Requester    string    `json:"requester"`
+	Source       string    `json:"source"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExecuteAt    time.Time `json:"execute_at"`
+	Remaining    int64     `json:"remaining"` // seconds
+	Status       string    `json:"status"`
+	Code         int       `json:"code"`
+	Msg          string    `json:"msg"`
+}
@@ -0,0 +1,19 @@
+package policy_schema
+
+type Policy struct {
+	Subject string `json:"subject" binding:"required"`
+	Object  string `json:"object" binding:"required"`
+	Action  string `json:"action" binding:"required"`
+}
+
+type RoleAssignment struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
+
+type RoleResponse struct {
+	Name     string    `json:"name"`
+	Builtin  bool      `json:"builtin"`
+	Policies []*Policy `json:"policies"`
+	Members  []string  `json:"members"`
+}
@@ -0,0 +1,20 @@
+package schema
+
+// Results of a component of a reload.
+const (
+	ReloadApplied   = "applied"
+	ReloadUnchanged = "unchanged"
+	ReloadFailed    = "failed"
+)
+
+type ReloadComponent struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ReloadResponse struct {
+	// Success is false when a component failed to apply the configuration.
+	Success    bool              `json:"success"`
+	Components []ReloadComponent `json:"components"`
+}
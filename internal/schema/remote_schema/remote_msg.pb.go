@@ -118,6 +118,8 @@ const (
 	MsgType_Shutdown       MsgType = 4
 	MsgType_Standby        MsgType = 5
 	MsgType_CustomCommand  MsgType = 6
+	MsgType_PendingAction  MsgType = 7
+	MsgType_RunMacro       MsgType = 8
 )
 
 // Enum value maps for MsgType.
@@ -130,6 +132,8 @@ var (
 		4: "Shutdown",
 		5: "Standby",
 		6: "CustomCommand",
+		7: "PendingAction",
+		8: "RunMacro",
 	}
 	MsgType_value = map[string]int32{
 		"Unknown":        0,
@@ -139,6 +143,8 @@ var (
 		"Shutdown":       4,
 		"Standby":        5,
 		"CustomCommand":  6,
+		"PendingAction":  7,
+		"RunMacro":       8,
 	}
 )
 
@@ -169,6 +175,55 @@ func (MsgType) EnumDescriptor() ([]byte, []int) {
 	return file_remote_msg_proto_rawDescGZIP(), []int{1}
 }
 
+type PendingActionOp int32
+
+const (
+	PendingActionOp_List     PendingActionOp = 0
+	PendingActionOp_Cancel   PendingActionOp = 1
+	PendingActionOp_Postpone PendingActionOp = 2
+)
+
+// Enum value maps for PendingActionOp.
+var (
+	PendingActionOp_name = map[int32]string{
+		0: "List",
+		1: "Cancel",
+		2: "Postpone",
+	}
+	PendingActionOp_value = map[string]int32{
+		"List":     0,
+		"Cancel":   1,
+		"Postpone": 2,
+	}
+)
+
+func (x PendingActionOp) Enum() *PendingActionOp {
+	p := new(PendingActionOp)
+	*p = x
+	return p
+}
+
+func (x PendingActionOp) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PendingActionOp) Descriptor() protoreflect.EnumDescriptor {
+	return file_remote_msg_proto_enumTypes[2].Descriptor()
+}
+
+func (PendingActionOp) Type() protoreflect.EnumType {
+	return &file_remote_msg_proto_enumTypes[2]
+}
+
+func (x PendingActionOp) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PendingActionOp.Descriptor instead.
+func (PendingActionOp) EnumDescriptor() ([]byte, []int) {
+	return file_remote_msg_proto_rawDescGZIP(), []int{2}
+}
+
 type UnlockMsg struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -229,7 +284,8 @@ type ShutdownMsg struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Type ShutdownType `protobuf:"varint,1,opt,name=type,proto3,enum=remote_schema.ShutdownType" json:"type,omitempty"`
+	Type  ShutdownType `protobuf:"varint,1,opt,name=type,proto3,enum=remote_schema.ShutdownType" json:"type,omitempty"`
+	Delay int32        `protobuf:"varint,2,opt,name=delay,proto3" json:"delay,omitempty"` // 延迟执行的秒数，大于0时作为待执行操作登记，可以被取消或推迟
 }
 
 func (x *ShutdownMsg) Reset() {
@@ -271,6 +327,391 @@ func (x *ShutdownMsg) GetType() ShutdownType {
 	return ShutdownType_E_UNKNOWN
 }
 
+func (x *ShutdownMsg) GetDelay() int32 {
+	if x != nil {
+		return x.Delay
+	}
+	return 0
+}
+
+type StandbyMsg struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Delay int32 `protobuf:"varint,1,opt,name=delay,proto3" json:"delay,omitempty"`
+}
+
+func (x *StandbyMsg) Reset() {
+	*x = StandbyMsg{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_msg_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StandbyMsg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StandbyMsg) ProtoMessage() {}
+
+func (x *StandbyMsg) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_msg_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StandbyMsg.ProtoReflect.Descriptor instead.
+func (*StandbyMsg) Descriptor() ([]byte, []int) {
+	return file_remote_msg_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StandbyMsg) GetDelay() int32 {
+	if x != nil {
+		return x.Delay
+	}
+	return 0
+}
+
+type PendingActionMsg struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Op      PendingActionOp `protobuf:"varint,1,opt,name=op,proto3,enum=remote_schema.PendingActionOp" json:"op,omitempty"`
+	Id      string          `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Seconds int32           `protobuf:"varint,3,opt,name=seconds,proto3" json:"seconds,omitempty"` // 推迟的秒数，仅在 Postpone 时有效
+}
+
+func (x *PendingActionMsg) Reset() {
+	*x = PendingActionMsg{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_msg_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PendingActionMsg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PendingActionMsg) ProtoMessage() {}
+
+func (x *PendingActionMsg) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_msg_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PendingActionMsg.ProtoReflect.Descriptor instead.
+func (*PendingActionMsg) Descriptor() ([]byte, []int) {
+	return file_remote_msg_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PendingActionMsg) GetOp() PendingActionOp {
+	if x != nil {
+		return x.Op
+	}
+	return PendingActionOp_List
+}
+
+func (x *PendingActionMsg) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PendingActionMsg) GetSeconds() int32 {
+	if x != nil {
+		return x.Seconds
+	}
+	return 0
+}
+
+type PendingActionInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id           string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Action       string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	ShutdownType ShutdownType           `protobuf:"varint,3,opt,name=shutdown_type,json=shutdownType,proto3,enum=remote_schema.ShutdownType" json:"shutdown_type,omitempty"`
+	Requester    string                 `protobuf:"bytes,4,opt,name=requester,proto3" json:"requester,omitempty"`
+	Source       string                 `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	ExecuteAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=execute_at,json=executeAt,proto3" json:"execute_at,omitempty"`
+	Remaining    int64                  `protobuf:"varint,7,opt,name=remaining,proto3" json:"remaining,omitempty"` // 剩余秒数
+	Status       string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`
+	Code         int32                  `protobuf:"varint,9,opt,name=code,proto3" json:"code,omitempty"`
+	Msg          string                 `protobuf:"bytes,10,opt,name=msg,proto3" json:"msg,omitempty"`
+}
+
+func (x *PendingActionInfo) Reset() {
+	*x = PendingActionInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_msg_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PendingActionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PendingActionInfo) ProtoMessage() {}
+
+func (x *PendingActionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_msg_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PendingActionInfo.ProtoReflect.Descriptor instead.
+func (*PendingActionInfo) Descriptor() ([]byte, []int) {
+	return file_remote_msg_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PendingActionInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PendingActionInfo) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *PendingActionInfo) GetShutdownType() ShutdownType {
+	if x != nil {
+		return x.ShutdownType
+	}
+	return ShutdownType_E_UNKNOWN
+}
+
+func (x *PendingActionInfo) GetRequester() string {
+	if x != nil {
+		return x.Requester
+	}
+	return ""
+}
+
+func (x *PendingActionInfo) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *PendingActionInfo) GetExecuteAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExecuteAt
+	}
+	return nil
+}
+
+func (x *PendingActionInfo) GetRemaining() int64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *PendingActionInfo) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *PendingActionInfo) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *PendingActionInfo) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+type MacroMsg struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *MacroMsg) Reset() {
+	*x = MacroMsg{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_msg_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MacroMsg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MacroMsg) ProtoMessage() {}
+
+func (x *MacroMsg) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_msg_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MacroMsg.ProtoReflect.Descriptor instead.
+func (*MacroMsg) Descriptor() ([]byte, []int) {
+	return file_remote_msg_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MacroMsg) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type MacroRunMsg struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RunId  uint32 `protobuf:"varint,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	Status string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+}
+
+func (x *MacroRunMsg) Reset() {
+	*x = MacroRunMsg{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_msg_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MacroRunMsg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MacroRunMsg) ProtoMessage() {}
+
+func (x *MacroRunMsg) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_msg_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MacroRunMsg.ProtoReflect.Descriptor instead.
+func (*MacroRunMsg) Descriptor() ([]byte, []int) {
+	return file_remote_msg_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MacroRunMsg) GetRunId() uint32 {
+	if x != nil {
+		return x.RunId
+	}
+	return 0
+}
+
+func (x *MacroRunMsg) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type PendingActionListMsg struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Actions []*PendingActionInfo `protobuf:"bytes,1,rep,name=actions,proto3" json:"actions,omitempty"`
+}
+
+func (x *PendingActionListMsg) Reset() {
+	*x = PendingActionListMsg{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_msg_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PendingActionListMsg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PendingActionListMsg) ProtoMessage() {}
+
+func (x *PendingActionListMsg) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_msg_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PendingActionListMsg.ProtoReflect.Descriptor instead.
+func (*PendingActionListMsg) Descriptor() ([]byte, []int) {
+	return file_remote_msg_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PendingActionListMsg) GetActions() []*PendingActionInfo {
+	if x != nil {
+		return x.Actions
+	}
+	return nil
+}
+
 type CommonResponseMsg struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -283,7 +724,7 @@ type CommonResponseMsg struct {
 func (x *CommonResponseMsg) Reset() {
 	*x = CommonResponseMsg{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_remote_msg_proto_msgTypes[2]
+		mi := &file_remote_msg_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -296,7 +737,7 @@ func (x *CommonResponseMsg) String() string {
 func (*CommonResponseMsg) ProtoMessage() {}
 
 func (x *CommonResponseMsg) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_msg_proto_msgTypes[2]
+	mi := &file_remote_msg_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -309,7 +750,7 @@ func (x *CommonResponseMsg) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CommonResponseMsg.ProtoReflect.Descriptor instead.
 func (*CommonResponseMsg) Descriptor() ([]byte, []int) {
-	return file_remote_msg_proto_rawDescGZIP(), []int{2}
+	return file_remote_msg_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *CommonResponseMsg) GetCode() int32 {
@@ -334,17 +775,21 @@ type RemoteMsg struct {
 	Type      MsgType                `protobuf:"varint,1,opt,name=type,proto3,enum=remote_schema.MsgType" json:"type,omitempty"`
 	Timestamp *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	// Types that are assignable to MsgBody:
-	//
 	//	*RemoteMsg_UnlockMsg
 	//	*RemoteMsg_ShutdownMsg
 	//	*RemoteMsg_ResponseMsg
+	//	*RemoteMsg_StandbyMsg
+	//	*RemoteMsg_PendingActionMsg
+	//	*RemoteMsg_PendingActionListMsg
+	//	*RemoteMsg_MacroMsg
+	//	*RemoteMsg_MacroRunMsg
 	MsgBody isRemoteMsg_MsgBody `protobuf_oneof:"msg_body"`
 }
 
 func (x *RemoteMsg) Reset() {
 	*x = RemoteMsg{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_remote_msg_proto_msgTypes[3]
+		mi := &file_remote_msg_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -357,7 +802,7 @@ func (x *RemoteMsg) String() string {
 func (*RemoteMsg) ProtoMessage() {}
 
 func (x *RemoteMsg) ProtoReflect() protoreflect.Message {
-	mi := &file_remote_msg_proto_msgTypes[3]
+	mi := &file_remote_msg_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -370,7 +815,7 @@ func (x *RemoteMsg) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RemoteMsg.ProtoReflect.Descriptor instead.
 func (*RemoteMsg) Descriptor() ([]byte, []int) {
-	return file_remote_msg_proto_rawDescGZIP(), []int{3}
+	return file_remote_msg_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *RemoteMsg) GetType() MsgType {
@@ -415,6 +860,41 @@ func (x *RemoteMsg) GetResponseMsg() *CommonResponseMsg {
 	return nil
 }
 
+func (x *RemoteMsg) GetStandbyMsg() *StandbyMsg {
+	if x, ok := x.GetMsgBody().(*RemoteMsg_StandbyMsg); ok {
+		return x.StandbyMsg
+	}
+	return nil
+}
+
+func (x *RemoteMsg) GetPendingActionMsg() *PendingActionMsg {
+	if x, ok := x.GetMsgBody().(*RemoteMsg_PendingActionMsg); ok {
+		return x.PendingActionMsg
+	}
+	return nil
+}
+
+func (x *RemoteMsg) GetPendingActionListMsg() *PendingActionListMsg {
+	if x, ok := x.GetMsgBody().(*RemoteMsg_PendingActionListMsg); ok {
+		return x.PendingActionListMsg
+	}
+	return nil
+}
+
+func (x *RemoteMsg) GetMacroMsg() *MacroMsg {
+	if x, ok := x.GetMsgBody().(*RemoteMsg_MacroMsg); ok {
+		return x.MacroMsg
+	}
+	return nil
+}
+
+func (x *RemoteMsg) GetMacroRunMsg() *MacroRunMsg {
+	if x, ok := x.GetMsgBody().(*RemoteMsg_MacroRunMsg); ok {
+		return x.MacroRunMsg
+	}
+	return nil
+}
+
 type isRemoteMsg_MsgBody interface {
 	isRemoteMsg_MsgBody()
 }
@@ -431,12 +911,42 @@ type RemoteMsg_ResponseMsg struct {
 	ResponseMsg *CommonResponseMsg `protobuf:"bytes,4,opt,name=responseMsg,proto3,oneof"`
 }
 
+type RemoteMsg_StandbyMsg struct {
+	StandbyMsg *StandbyMsg `protobuf:"bytes,6,opt,name=standbyMsg,proto3,oneof"`
+}
+
+type RemoteMsg_PendingActionMsg struct {
+	PendingActionMsg *PendingActionMsg `protobuf:"bytes,7,opt,name=pendingActionMsg,proto3,oneof"`
+}
+
+type RemoteMsg_PendingActionListMsg struct {
+	PendingActionListMsg *PendingActionListMsg `protobuf:"bytes,8,opt,name=pendingActionListMsg,proto3,oneof"`
+}
+
+type RemoteMsg_MacroMsg struct {
+	MacroMsg *MacroMsg `protobuf:"bytes,9,opt,name=macroMsg,proto3,oneof"`
+}
+
+type RemoteMsg_MacroRunMsg struct {
+	MacroRunMsg *MacroRunMsg `protobuf:"bytes,10,opt,name=macroRunMsg,proto3,oneof"`
+}
+
 func (*RemoteMsg_UnlockMsg) isRemoteMsg_MsgBody() {}
 
 func (*RemoteMsg_ShutdownMsg) isRemoteMsg_MsgBody() {}
 
 func (*RemoteMsg_ResponseMsg) isRemoteMsg_MsgBody() {}
 
+func (*RemoteMsg_StandbyMsg) isRemoteMsg_MsgBody() {}
+
+func (*RemoteMsg_PendingActionMsg) isRemoteMsg_MsgBody() {}
+
+func (*RemoteMsg_PendingActionListMsg) isRemoteMsg_MsgBody() {}
+
+func (*RemoteMsg_MacroMsg) isRemoteMsg_MsgBody() {}
+
+func (*RemoteMsg_MacroRunMsg) isRemoteMsg_MsgBody() {}
+
 var File_remote_msg_proto protoreflect.FileDescriptor
 
 var file_remote_msg_proto_rawDesc = []byte{
@@ -448,70 +958,140 @@ var file_remote_msg_proto_rawDesc = []byte{
 	0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
 	0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70,
 	0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70,
-	0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x22, 0x3e, 0x0a, 0x0b, 0x53, 0x68, 0x75, 0x74, 0x64,
+	0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x22, 0x54, 0x0a, 0x0b, 0x53, 0x68, 0x75, 0x74, 0x64,
 	0x6f, 0x77, 0x6e, 0x4d, 0x73, 0x67, 0x12, 0x2f, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01,
 	0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x73, 0x63,
 	0x68, 0x65, 0x6d, 0x61, 0x2e, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x54, 0x79, 0x70,
-	0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x39, 0x0a, 0x11, 0x43, 0x6f, 0x6d, 0x6d, 0x6f,
-	0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4d, 0x73, 0x67, 0x12, 0x12, 0x0a, 0x04,
-	0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65,
-	0x12, 0x10, 0x0a, 0x03, 0x6d, 0x73, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6d,
-	0x73, 0x67, 0x22, 0xbd, 0x02, 0x0a, 0x09, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x4d, 0x73, 0x67,
-	0x12, 0x2a, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16,
-	0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x4d,
-	0x73, 0x67, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x38, 0x0a, 0x09,
-	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d,
-	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x38, 0x0a, 0x09, 0x75, 0x6e, 0x6c, 0x6f, 0x63, 0x6b,
-	0x4d, 0x73, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x72, 0x65, 0x6d, 0x6f,
-	0x74, 0x65, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x55, 0x6e, 0x6c, 0x6f, 0x63, 0x6b,
-	0x4d, 0x73, 0x67, 0x48, 0x00, 0x52, 0x09, 0x75, 0x6e, 0x6c, 0x6f, 0x63, 0x6b, 0x4d, 0x73, 0x67,
-	0x12, 0x3e, 0x0a, 0x0b, 0x73, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x4d, 0x73, 0x67, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x73,
-	0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x4d, 0x73,
-	0x67, 0x48, 0x00, 0x52, 0x0b, 0x73, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x4d, 0x73, 0x67,
-	0x12, 0x44, 0x0a, 0x0b, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4d, 0x73, 0x67, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x73,
-	0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x4d, 0x73, 0x67, 0x48, 0x00, 0x52, 0x0b, 0x72, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x4d, 0x73, 0x67, 0x42, 0x0a, 0x0a, 0x08, 0x6d, 0x73, 0x67, 0x5f, 0x62, 0x6f,
-	0x64, 0x79, 0x2a, 0x85, 0x03, 0x0a, 0x0c, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x54,
-	0x79, 0x70, 0x65, 0x12, 0x0d, 0x0a, 0x09, 0x45, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e,
-	0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x45, 0x5f, 0x4c, 0x4f, 0x47, 0x4f, 0x46, 0x46, 0x10, 0x01,
-	0x12, 0x14, 0x0a, 0x10, 0x45, 0x5f, 0x46, 0x4f, 0x52, 0x43, 0x45, 0x5f, 0x53, 0x48, 0x55, 0x54,
-	0x44, 0x4f, 0x57, 0x4e, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x45, 0x5f, 0x46, 0x4f, 0x52, 0x43,
-	0x45, 0x5f, 0x52, 0x45, 0x42, 0x4f, 0x4f, 0x54, 0x10, 0x03, 0x12, 0x10, 0x0a, 0x0c, 0x45, 0x57,
-	0x58, 0x5f, 0x53, 0x48, 0x55, 0x54, 0x44, 0x4f, 0x57, 0x4e, 0x10, 0x04, 0x12, 0x0e, 0x0a, 0x0a,
-	0x45, 0x57, 0x58, 0x5f, 0x52, 0x45, 0x42, 0x4f, 0x4f, 0x54, 0x10, 0x05, 0x12, 0x10, 0x0a, 0x0c,
-	0x45, 0x57, 0x58, 0x5f, 0x50, 0x4f, 0x57, 0x45, 0x52, 0x4f, 0x46, 0x46, 0x10, 0x06, 0x12, 0x17,
-	0x0a, 0x13, 0x45, 0x57, 0x58, 0x5f, 0x48, 0x59, 0x42, 0x52, 0x49, 0x44, 0x5f, 0x53, 0x48, 0x55,
-	0x54, 0x44, 0x4f, 0x57, 0x4e, 0x10, 0x07, 0x12, 0x16, 0x0a, 0x12, 0x45, 0x57, 0x58, 0x5f, 0x46,
-	0x4f, 0x52, 0x43, 0x45, 0x5f, 0x50, 0x4f, 0x57, 0x45, 0x52, 0x4f, 0x46, 0x46, 0x10, 0x08, 0x12,
-	0x1a, 0x0a, 0x16, 0x45, 0x57, 0x58, 0x5f, 0x52, 0x45, 0x42, 0x4f, 0x4f, 0x54, 0x5f, 0x52, 0x45,
-	0x53, 0x54, 0x41, 0x52, 0x54, 0x41, 0x50, 0x50, 0x53, 0x10, 0x09, 0x12, 0x20, 0x0a, 0x1c, 0x45,
-	0x57, 0x58, 0x5f, 0x46, 0x4f, 0x52, 0x43, 0x45, 0x5f, 0x52, 0x45, 0x42, 0x4f, 0x4f, 0x54, 0x5f,
-	0x52, 0x45, 0x53, 0x54, 0x41, 0x52, 0x54, 0x41, 0x50, 0x50, 0x53, 0x10, 0x0a, 0x12, 0x1c, 0x0a,
-	0x18, 0x45, 0x57, 0x58, 0x5f, 0x53, 0x48, 0x55, 0x54, 0x44, 0x4f, 0x57, 0x4e, 0x5f, 0x52, 0x45,
-	0x53, 0x54, 0x41, 0x52, 0x54, 0x41, 0x50, 0x50, 0x53, 0x10, 0x0b, 0x12, 0x1d, 0x0a, 0x19, 0x45,
-	0x57, 0x58, 0x5f, 0x48, 0x59, 0x42, 0x52, 0x49, 0x44, 0x5f, 0x53, 0x48, 0x55, 0x54, 0x44, 0x4f,
-	0x57, 0x4e, 0x5f, 0x46, 0x4f, 0x52, 0x43, 0x45, 0x10, 0x0c, 0x12, 0x23, 0x0a, 0x1f, 0x45, 0x57,
-	0x58, 0x5f, 0x48, 0x59, 0x42, 0x52, 0x49, 0x44, 0x5f, 0x53, 0x48, 0x55, 0x54, 0x44, 0x4f, 0x57,
-	0x4e, 0x5f, 0x52, 0x45, 0x53, 0x54, 0x41, 0x52, 0x54, 0x41, 0x50, 0x50, 0x53, 0x10, 0x0d, 0x12,
-	0x29, 0x0a, 0x25, 0x45, 0x57, 0x58, 0x5f, 0x48, 0x59, 0x42, 0x52, 0x49, 0x44, 0x5f, 0x53, 0x48,
-	0x55, 0x54, 0x44, 0x4f, 0x57, 0x4e, 0x5f, 0x46, 0x4f, 0x52, 0x43, 0x45, 0x5f, 0x52, 0x45, 0x53,
-	0x54, 0x41, 0x52, 0x54, 0x41, 0x50, 0x50, 0x53, 0x10, 0x0e, 0x2a, 0x74, 0x0a, 0x07, 0x4d, 0x73,
-	0x67, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e,
-	0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x43, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x55, 0x6e, 0x6c, 0x6f, 0x63, 0x6b,
-	0x10, 0x02, 0x12, 0x0e, 0x0a, 0x0a, 0x4c, 0x6f, 0x63, 0x6b, 0x53, 0x63, 0x72, 0x65, 0x65, 0x6e,
-	0x10, 0x03, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x10, 0x04,
-	0x12, 0x0b, 0x0a, 0x07, 0x53, 0x74, 0x61, 0x6e, 0x64, 0x62, 0x79, 0x10, 0x05, 0x12, 0x11, 0x0a,
-	0x0d, 0x43, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x10, 0x06,
-	0x42, 0x2b, 0x5a, 0x29, 0x66, 0x61, 0x64, 0x61, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x2f,
-	0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x3b,
-	0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x64, 0x65, 0x6c, 0x61, 0x79,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x64, 0x65, 0x6c, 0x61, 0x79, 0x22, 0x22, 0x0a,
+	0x0a, 0x53, 0x74, 0x61, 0x6e, 0x64, 0x62, 0x79, 0x4d, 0x73, 0x67, 0x12, 0x14, 0x0a, 0x05, 0x64,
+	0x65, 0x6c, 0x61, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x64, 0x65, 0x6c, 0x61,
+	0x79, 0x22, 0x6c, 0x0a, 0x10, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x4d, 0x73, 0x67, 0x12, 0x2e, 0x0a, 0x02, 0x6f, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x1e, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d,
+	0x61, 0x2e, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4f,
+	0x70, 0x52, 0x02, 0x6f, 0x70, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22,
+	0xca, 0x02, 0x0a, 0x11, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x40, 0x0a,
+	0x0d, 0x73, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x73, 0x63,
+	0x68, 0x65, 0x6d, 0x61, 0x2e, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x54, 0x79, 0x70,
+	0x65, 0x52, 0x0c, 0x73, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x1c, 0x0a, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x72, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65,
+	0x5f, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x41, 0x74,
+	0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x73,
+	0x67, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6d, 0x73, 0x67, 0x22, 0x1e, 0x0a, 0x08,
+	0x4d, 0x61, 0x63, 0x72, 0x6f, 0x4d, 0x73, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x3c, 0x0a, 0x0b,
+	0x4d, 0x61, 0x63, 0x72, 0x6f, 0x52, 0x75, 0x6e, 0x4d, 0x73, 0x67, 0x12, 0x15, 0x0a, 0x06, 0x72,
+	0x75, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x72, 0x75, 0x6e,
+	0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x52, 0x0a, 0x14, 0x50, 0x65,
+	0x6e, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x69, 0x73, 0x74, 0x4d,
+	0x73, 0x67, 0x12, 0x3a, 0x0a, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x73, 0x63, 0x68,
+	0x65, 0x6d, 0x61, 0x2e, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x39,
+	0x0a, 0x11, 0x43, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x4d, 0x73, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x73, 0x67, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6d, 0x73, 0x67, 0x22, 0x9b, 0x05, 0x0a, 0x09, 0x52, 0x65,
+	0x6d, 0x6f, 0x74, 0x65, 0x4d, 0x73, 0x67, 0x12, 0x2a, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x73,
+	0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x4d, 0x73, 0x67, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x38, 0x0a,
+	0x09, 0x75, 0x6e, 0x6c, 0x6f, 0x63, 0x6b, 0x4d, 0x73, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x18, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61,
+	0x2e, 0x55, 0x6e, 0x6c, 0x6f, 0x63, 0x6b, 0x4d, 0x73, 0x67, 0x48, 0x00, 0x52, 0x09, 0x75, 0x6e,
+	0x6c, 0x6f, 0x63, 0x6b, 0x4d, 0x73, 0x67, 0x12, 0x3e, 0x0a, 0x0b, 0x73, 0x68, 0x75, 0x74, 0x64,
+	0x6f, 0x77, 0x6e, 0x4d, 0x73, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x53, 0x68, 0x75,
+	0x74, 0x64, 0x6f, 0x77, 0x6e, 0x4d, 0x73, 0x67, 0x48, 0x00, 0x52, 0x0b, 0x73, 0x68, 0x75, 0x74,
+	0x64, 0x6f, 0x77, 0x6e, 0x4d, 0x73, 0x67, 0x12, 0x44, 0x0a, 0x0b, 0x72, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x4d, 0x73, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x43, 0x6f, 0x6d,
+	0x6d, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4d, 0x73, 0x67, 0x48, 0x00,
+	0x52, 0x0b, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4d, 0x73, 0x67, 0x12, 0x3b, 0x0a,
+	0x0a, 0x73, 0x74, 0x61, 0x6e, 0x64, 0x62, 0x79, 0x4d, 0x73, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d,
+	0x61, 0x2e, 0x53, 0x74, 0x61, 0x6e, 0x64, 0x62, 0x79, 0x4d, 0x73, 0x67, 0x48, 0x00, 0x52, 0x0a,
+	0x73, 0x74, 0x61, 0x6e, 0x64, 0x62, 0x79, 0x4d, 0x73, 0x67, 0x12, 0x4d, 0x0a, 0x10, 0x70, 0x65,
+	0x6e, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x67, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x73, 0x63,
+	0x68, 0x65, 0x6d, 0x61, 0x2e, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x4d, 0x73, 0x67, 0x48, 0x00, 0x52, 0x10, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67,
+	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x67, 0x12, 0x59, 0x0a, 0x14, 0x70, 0x65, 0x6e,
+	0x64, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x73,
+	0x67, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65,
+	0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x41,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x73, 0x67, 0x48, 0x00, 0x52, 0x14,
+	0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x69, 0x73,
+	0x74, 0x4d, 0x73, 0x67, 0x12, 0x35, 0x0a, 0x08, 0x6d, 0x61, 0x63, 0x72, 0x6f, 0x4d, 0x73, 0x67,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f,
+	0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x4d, 0x61, 0x63, 0x72, 0x6f, 0x4d, 0x73, 0x67, 0x48,
+	0x00, 0x52, 0x08, 0x6d, 0x61, 0x63, 0x72, 0x6f, 0x4d, 0x73, 0x67, 0x12, 0x3e, 0x0a, 0x0b, 0x6d,
+	0x61, 0x63, 0x72, 0x6f, 0x52, 0x75, 0x6e, 0x4d, 0x73, 0x67, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61,
+	0x2e, 0x4d, 0x61, 0x63, 0x72, 0x6f, 0x52, 0x75, 0x6e, 0x4d, 0x73, 0x67, 0x48, 0x00, 0x52, 0x0b,
+	0x6d, 0x61, 0x63, 0x72, 0x6f, 0x52, 0x75, 0x6e, 0x4d, 0x73, 0x67, 0x42, 0x0a, 0x0a, 0x08, 0x6d,
+	0x73, 0x67, 0x5f, 0x62, 0x6f, 0x64, 0x79, 0x2a, 0x85, 0x03, 0x0a, 0x0c, 0x53, 0x68, 0x75, 0x74,
+	0x64, 0x6f, 0x77, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0d, 0x0a, 0x09, 0x45, 0x5f, 0x55, 0x4e,
+	0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x45, 0x5f, 0x4c, 0x4f, 0x47,
+	0x4f, 0x46, 0x46, 0x10, 0x01, 0x12, 0x14, 0x0a, 0x10, 0x45, 0x5f, 0x46, 0x4f, 0x52, 0x43, 0x45,
+	0x5f, 0x53, 0x48, 0x55, 0x54, 0x44, 0x4f, 0x57, 0x4e, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x45,
+	0x5f, 0x46, 0x4f, 0x52, 0x43, 0x45, 0x5f, 0x52, 0x45, 0x42, 0x4f, 0x4f, 0x54, 0x10, 0x03, 0x12,
+	0x10, 0x0a, 0x0c, 0x45, 0x57, 0x58, 0x5f, 0x53, 0x48, 0x55, 0x54, 0x44, 0x4f, 0x57, 0x4e, 0x10,
+	0x04, 0x12, 0x0e, 0x0a, 0x0a, 0x45, 0x57, 0x58, 0x5f, 0x52, 0x45, 0x42, 0x4f, 0x4f, 0x54, 0x10,
+	0x05, 0x12, 0x10, 0x0a, 0x0c, 0x45, 0x57, 0x58, 0x5f, 0x50, 0x4f, 0x57, 0x45, 0x52, 0x4f, 0x46,
+	0x46, 0x10, 0x06, 0x12, 0x17, 0x0a, 0x13, 0x45, 0x57, 0x58, 0x5f, 0x48, 0x59, 0x42, 0x52, 0x49,
+	0x44, 0x5f, 0x53, 0x48, 0x55, 0x54, 0x44, 0x4f, 0x57, 0x4e, 0x10, 0x07, 0x12, 0x16, 0x0a, 0x12,
+	0x45, 0x57, 0x58, 0x5f, 0x46, 0x4f, 0x52, 0x43, 0x45, 0x5f, 0x50, 0x4f, 0x57, 0x45, 0x52, 0x4f,
+	0x46, 0x46, 0x10, 0x08, 0x12, 0x1a, 0x0a, 0x16, 0x45, 0x57, 0x58, 0x5f, 0x52, 0x45, 0x42, 0x4f,
+	0x4f, 0x54, 0x5f, 0x52, 0x45, 0x53, 0x54, 0x41, 0x52, 0x54, 0x41, 0x50, 0x50, 0x53, 0x10, 0x09,
+	0x12, 0x20, 0x0a, 0x1c, 0x45, 0x57, 0x58, 0x5f, 0x46, 0x4f, 0x52, 0x43, 0x45, 0x5f, 0x52, 0x45,
+	0x42, 0x4f, 0x4f, 0x54, 0x5f, 0x52, 0x45, 0x53, 0x54, 0x41, 0x52, 0x54, 0x41, 0x50, 0x50, 0x53,
+	0x10, 0x0a, 0x12, 0x1c, 0x0a, 0x18, 0x45, 0x57, 0x58, 0x5f, 0x53, 0x48, 0x55, 0x54, 0x44, 0x4f,
+	0x57, 0x4e, 0x5f, 0x52, 0x45, 0x53, 0x54, 0x41, 0x52, 0x54, 0x41, 0x50, 0x50, 0x53, 0x10, 0x0b,
+	0x12, 0x1d, 0x0a, 0x19, 0x45, 0x57, 0x58, 0x5f, 0x48, 0x59, 0x42, 0x52, 0x49, 0x44, 0x5f, 0x53,
+	0x48, 0x55, 0x54, 0x44, 0x4f, 0x57, 0x4e, 0x5f, 0x46, 0x4f, 0x52, 0x43, 0x45, 0x10, 0x0c, 0x12,
+	0x23, 0x0a, 0x1f, 0x45, 0x57, 0x58, 0x5f, 0x48, 0x59, 0x42, 0x52, 0x49, 0x44, 0x5f, 0x53, 0x48,
+	0x55, 0x54, 0x44, 0x4f, 0x57, 0x4e, 0x5f, 0x52, 0x45, 0x53, 0x54, 0x41, 0x52, 0x54, 0x41, 0x50,
+	0x50, 0x53, 0x10, 0x0d, 0x12, 0x29, 0x0a, 0x25, 0x45, 0x57, 0x58, 0x5f, 0x48, 0x59, 0x42, 0x52,
+	0x49, 0x44, 0x5f, 0x53, 0x48, 0x55, 0x54, 0x44, 0x4f, 0x57, 0x4e, 0x5f, 0x46, 0x4f, 0x52, 0x43,
+	0x45, 0x5f, 0x52, 0x45, 0x53, 0x54, 0x41, 0x52, 0x54, 0x41, 0x50, 0x50, 0x53, 0x10, 0x0e, 0x2a,
+	0x95, 0x01, 0x0a, 0x07, 0x4d, 0x73, 0x67, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x55,
+	0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x43, 0x6f, 0x6d, 0x6d,
+	0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06,
+	0x55, 0x6e, 0x6c, 0x6f, 0x63, 0x6b, 0x10, 0x02, 0x12, 0x0e, 0x0a, 0x0a, 0x4c, 0x6f, 0x63, 0x6b,
+	0x53, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x10, 0x03, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x68, 0x75, 0x74,
+	0x64, 0x6f, 0x77, 0x6e, 0x10, 0x04, 0x12, 0x0b, 0x0a, 0x07, 0x53, 0x74, 0x61, 0x6e, 0x64, 0x62,
+	0x79, 0x10, 0x05, 0x12, 0x11, 0x0a, 0x0d, 0x43, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x43, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x10, 0x06, 0x12, 0x11, 0x0a, 0x0d, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e,
+	0x67, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x10, 0x07, 0x12, 0x0c, 0x0a, 0x08, 0x52, 0x75, 0x6e,
+	0x4d, 0x61, 0x63, 0x72, 0x6f, 0x10, 0x08, 0x2a, 0x35, 0x0a, 0x0f, 0x50, 0x65, 0x6e, 0x64, 0x69,
+	0x6e, 0x67, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x4f, 0x70, 0x12, 0x08, 0x0a, 0x04, 0x4c, 0x69,
+	0x73, 0x74, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x10, 0x01,
+	0x12, 0x0c, 0x0a, 0x08, 0x50, 0x6f, 0x73, 0x74, 0x70, 0x6f, 0x6e, 0x65, 0x10, 0x02, 0x42, 0x2b,
+	0x5a, 0x29, 0x66, 0x61, 0x64, 0x61, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x2f, 0x69, 0x6e,
+	0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x3b, 0x72, 0x65,
+	0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
 }
 
 var (
@@ -526,29 +1106,45 @@ func file_remote_msg_proto_rawDescGZIP() []byte {
 	return file_remote_msg_proto_rawDescData
 }
 
-var file_remote_msg_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_remote_msg_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_remote_msg_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_remote_msg_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_remote_msg_proto_goTypes = []any{
 	(ShutdownType)(0),             // 0: remote_schema.ShutdownType
 	(MsgType)(0),                  // 1: remote_schema.MsgType
-	(*UnlockMsg)(nil),             // 2: remote_schema.UnlockMsg
-	(*ShutdownMsg)(nil),           // 3: remote_schema.ShutdownMsg
-	(*CommonResponseMsg)(nil),     // 4: remote_schema.CommonResponseMsg
-	(*RemoteMsg)(nil),             // 5: remote_schema.RemoteMsg
-	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
+	(PendingActionOp)(0),          // 2: remote_schema.PendingActionOp
+	(*UnlockMsg)(nil),             // 3: remote_schema.UnlockMsg
+	(*ShutdownMsg)(nil),           // 4: remote_schema.ShutdownMsg
+	(*StandbyMsg)(nil),            // 5: remote_schema.StandbyMsg
+	(*PendingActionMsg)(nil),      // 6: remote_schema.PendingActionMsg
+	(*PendingActionInfo)(nil),     // 7: remote_schema.PendingActionInfo
+	(*MacroMsg)(nil),              // 8: remote_schema.MacroMsg
+	(*MacroRunMsg)(nil),           // 9: remote_schema.MacroRunMsg
+	(*PendingActionListMsg)(nil),  // 10: remote_schema.PendingActionListMsg
+	(*CommonResponseMsg)(nil),     // 11: remote_schema.CommonResponseMsg
+	(*RemoteMsg)(nil),             // 12: remote_schema.RemoteMsg
+	(*timestamppb.Timestamp)(nil), // 13: google.protobuf.Timestamp
 }
 var file_remote_msg_proto_depIdxs = []int32{
-	0, // 0: remote_schema.ShutdownMsg.type:type_name -> remote_schema.ShutdownType
-	1, // 1: remote_schema.RemoteMsg.type:type_name -> remote_schema.MsgType
-	6, // 2: remote_schema.RemoteMsg.timestamp:type_name -> google.protobuf.Timestamp
-	2, // 3: remote_schema.RemoteMsg.unlockMsg:type_name -> remote_schema.UnlockMsg
-	3, // 4: remote_schema.RemoteMsg.shutdownMsg:type_name -> remote_schema.ShutdownMsg
-	4, // 5: remote_schema.RemoteMsg.responseMsg:type_name -> remote_schema.CommonResponseMsg
-	6, // [6:6] is the sub-list for method output_type
-	6, // [6:6] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	0,  // 0: remote_schema.ShutdownMsg.type:type_name -> remote_schema.ShutdownType
+	2,  // 1: remote_schema.PendingActionMsg.op:type_name -> remote_schema.PendingActionOp
+	0,  // 2: remote_schema.PendingActionInfo.shutdown_type:type_name -> remote_schema.ShutdownType
+	13, // 3: remote_schema.PendingActionInfo.execute_at:type_name -> google.protobuf.Timestamp
+	7,  // 4: remote_schema.PendingActionListMsg.actions:type_name -> remote_schema.PendingActionInfo
+	1,  // 5: remote_schema.RemoteMsg.type:type_name -> remote_schema.MsgType
+	13, // 6: remote_schema.RemoteMsg.timestamp:type_name -> google.protobuf.Timestamp
+	3,  // 7: remote_schema.RemoteMsg.unlockMsg:type_name -> remote_schema.UnlockMsg
+	4,  // 8: remote_schema.RemoteMsg.shutdownMsg:type_name -> remote_schema.ShutdownMsg
+	11, // 9: remote_schema.RemoteMsg.responseMsg:type_name -> remote_schema.CommonResponseMsg
+	5,  // 10: remote_schema.RemoteMsg.standbyMsg:type_name -> remote_schema.StandbyMsg
+	6,  // 11: remote_schema.RemoteMsg.pendingActionMsg:type_name -> remote_schema.PendingActionMsg
+	10, // 12: remote_schema.RemoteMsg.pendingActionListMsg:type_name -> remote_schema.PendingActionListMsg
+	8,  // 13: remote_schema.RemoteMsg.macroMsg:type_name -> remote_schema.MacroMsg
+	9,  // 14: remote_schema.RemoteMsg.macroRunMsg:type_name -> remote_schema.MacroRunMsg
+	15, // [15:15] is the sub-list for method output_type
+	15, // [15:15] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_remote_msg_proto_init() }
@@ -582,7 +1178,7 @@ func file_remote_msg_proto_init() {
 			}
 		}
 		file_remote_msg_proto_msgTypes[2].Exporter = func(v any, i int) any {
-			switch v := v.(*CommonResponseMsg); i {
+			switch v := v.(*StandbyMsg); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -594,6 +1190,78 @@ func file_remote_msg_proto_init() {
 			}
 		}
 		file_remote_msg_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*PendingActionMsg); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_msg_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*PendingActionInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_msg_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*MacroMsg); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_msg_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*MacroRunMsg); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_msg_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*PendingActionListMsg); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_msg_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*CommonResponseMsg); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_msg_proto_msgTypes[9].Exporter = func(v any, i int) any {
 			switch v := v.(*RemoteMsg); i {
 			case 0:
 				return &v.state
@@ -606,18 +1274,23 @@ func file_remote_msg_proto_init() {
 			}
 		}
 	}
-	file_remote_msg_proto_msgTypes[3].OneofWrappers = []any{
+	file_remote_msg_proto_msgTypes[9].OneofWrappers = []any{
 		(*RemoteMsg_UnlockMsg)(nil),
 		(*RemoteMsg_ShutdownMsg)(nil),
 		(*RemoteMsg_ResponseMsg)(nil),
+		(*RemoteMsg_StandbyMsg)(nil),
+		(*RemoteMsg_PendingActionMsg)(nil),
+		(*RemoteMsg_PendingActionListMsg)(nil),
+		(*RemoteMsg_MacroMsg)(nil),
+		(*RemoteMsg_MacroRunMsg)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_remote_msg_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   4,
+			NumEnums:      3,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,76 @@
+package schedule_schema
+
+import "time"
+
+const (
+	ActionShutdown = "shutdown"
+	ActionStandby  = "standby"
+	ActionLock     = "lock"
+	ActionCommand  = "command"
+	ActionMacro    = "macro"
+)
+
+const (
+	// MissedRunSkip records the missed run and waits for the next activation.
+	MissedRunSkip = "skip"
+	// MissedRunOnce runs the task once as soon as the service notices the missed activation.
+	MissedRunOnce = "run_once"
+)
+
+type ScheduledTaskRequest struct {
+	Name            string     `json:"name" binding:"required"`
+	CronExpr        string     `json:"cron_expr"`
+	RunAt           *time.Time `json:"run_at"`
+	Timezone        string     `json:"timezone"`
+	Action          string     `json:"action" binding:"required"`
+	ShutdownType    int        `json:"shutdown_type"`
+	CommandPath     string     `json:"command_path"`
+	CommandName     string     `json:"command_name"`
+	MacroName       string     `json:"macro_name"`
+	Enabled         bool       `json:"enabled"`
+	MissedRunPolicy string     `json:"missed_run_policy"`
+}
+
+type ScheduledTaskResponse struct {
+	Id              uint       `json:"id"`
+	Name            string     `json:"name"`
+	CronExpr        string     `json:"cron_expr"`
+	RunAt           *time.Time `json:"run_at"`
+	Timezone        string     `json:"timezone"`
+	Action          string     `json:"action"`
+	ShutdownType    int        `json:"shutdown_type"`
+	CommandPath     string     `json:"command_path"`
+	CommandName     string     `json:"command_name"`
+	MacroName       string     `json:"macro_name"`
+	Enabled         bool       `json:"enabled"`
+	MissedRunPolicy string     `json:"missed_run_policy"`
+	CreatedBy       string     `json:"created_by"`
+	LastRunAt       *time.Time `json:"last_run_at"`
+	NextRunAt       *time.Time `json:"next_run_at"`
+}
+
+type ScheduledTaskRunResponse struct {
+	Id          uint       `json:"id"`
+	TaskId      uint       `json:"task_id"`
+	ScheduledAt time.Time  `json:"scheduled_at"`
+	StartedAt   *time.Time `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	Missed      bool       `json:"missed"`
+	Code        int        `json:"code"`
+	Msg         string     `json:"msg"`
+}
+
+type NextRunsRequest struct {
+	CronExpr string     `json:"cron_expr"`
+	RunAt    *time.Time `json:"run_at"`
+	Timezone string     `json:"timezone"`
+	Count    int        `json:"count"`
+}
+type NextRunsResponse struct {
+	NextRuns []time.Time `json:"next_runs"`
+}
+
+type ScheduledTaskRunListResponse struct {
+	Total int64                       `json:"total"`
+	Runs  []*ScheduledTaskRunResponse `json:"runs"`
+}
@@ -0,0 +1,42 @@
+package token_schema
+
+import "time"
+
+const (
+	MaxTokenNameLength = 64
+	MaxTokensPerUser   = 32
+)
+
+type CreateTokenRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+	// ExpiresAt is optional, the token never expires when it is omitted.
+	ExpiresAt *time.Time `json:"expires_at"`
+	// AllowedIPs restricts the token to these addresses or CIDR ranges.
+	AllowedIPs []string `json:"allowed_ips"`
+}
+
+type TokenResponse struct {
+	Id         uint       `json:"id"`
+	Username   string     `json:"username"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	AllowedIPs []string   `json:"allowed_ips"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateTokenResponse carries the token secret, it is only returned once.
+type CreateTokenResponse struct {
+	TokenResponse
+	Token string `json:"token"`
+}
+
+type ScopeResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
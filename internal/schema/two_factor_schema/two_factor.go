@@ -0,0 +1,43 @@
+package two_factor_schema
+
+import "fadacontrol/internal/schema"
+
+const RecoveryCodeCount = 10
+
+type EnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type CodeRequest struct {
+	// Code is a TOTP code, or a recovery code where noted.
+	Code string `json:"code" binding:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type StatusResponse struct {
+	Enabled bool `json:"enabled"`
+	// Pending is true when an enrollment was started but not confirmed.
+	Pending           bool `json:"pending"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+	// Required is true when the policy requires this account to use two-factor authentication.
+	Required bool `json:"required"`
+}
+
+type PolicyRequest struct {
+	RequireTwoFactor bool `json:"require_two_factor"`
+}
+
+type LoginChallengeRequest struct {
+	Challenge string `json:"challenge" binding:"required"`
+	Code      string `json:"code" binding:"required"`
+}
+
+type ConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	// Session replaces the session the enrollment was confirmed with.
+	Session *schema.TokenResponse `json:"session"`
+}
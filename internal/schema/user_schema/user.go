@@ -0,0 +1,36 @@
+package user_schema
+
+import "time"
+
+const MinPasswordLength = 8
+const MaxPasswordLength = 128
+
+type CreateUserRequest struct {
+	Username string   `json:"username" binding:"required"`
+	Password string   `json:"password" binding:"required"`
+	Roles    []string `json:"roles"`
+	// MustChangePassword forces the user to choose a new password on first login.
+	MustChangePassword bool `json:"must_change_password"`
+}
+
+type UserResponse struct {
+	Username           string    `json:"username"`
+	Roles              []string  `json:"roles"`
+	Disabled           bool      `json:"disabled"`
+	MustChangePassword bool      `json:"must_change_password"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type SetRolesRequest struct {
+	Roles []string `json:"roles"`
+}
@@ -0,0 +1,117 @@
+package webhook_schema
+
+import "time"
+
+const (
+	EventSessionLock       = "session.lock"
+	EventSessionUnlock     = "session.unlock"
+	EventPowerShutdown     = "power.shutdown"
+	EventPowerStandby      = "power.standby"
+	EventSlaveConnected    = "slave.connected"
+	EventSlaveDisconnected = "slave.disconnected"
+	EventUnlockFailed      = "unlock.failed"
+	// EventTest is only sent by the test delivery endpoint.
+	EventTest = "webhook.test"
+	// EventAll subscribes to every event.
+	EventAll = "*"
+)
+
+// PowerShutdown and PowerStandby are the actions of the power events.
+const (
+	PowerShutdown = "shutdown"
+	PowerStandby  = "standby"
+)
+
+// Events are the events a webhook can subscribe to.
+var Events = []string{EventSessionLock, EventSessionUnlock, EventPowerShutdown, EventPowerStandby, EventSlaveConnected,
+	EventSlaveDisconnected, EventUnlockFailed}
+
+func IsValidEvent(event string) bool {
+	if event == EventAll {
+		return true
+	}
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether a webhook subscribed to events receives event, every webhook receives the test event.
+func Matches(events []string, event string) bool {
+	if event == EventTest {
+		return true
+	}
+	for _, e := range events {
+		if e == EventAll || e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Url    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required,min=1"`
+	// Secret signs the deliveries, a random secret is generated when it is empty on creation and the current one
+	// is kept on update.
+	Secret  string `json:"secret"`
+	Enabled *bool  `json:"enabled"`
+}
+
+type WebhookResponse struct {
+	Id        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Url       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	// Secret is only returned when the webhook is created.
+	Secret string `json:"secret,omitempty"`
+}
+
+type WebhookDeliveryResponse struct {
+	Id         uint      `json:"id"`
+	WebhookId  uint      `json:"webhook_id"`
+	DeliveryId string    `json:"delivery_id"`
+	Event      string    `json:"event"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error"`
+	Response   string    `json:"response"`
+	DurationMs int64     `json:"duration_ms"`
+	Time       time.Time `json:"time"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Total      int64                      `json:"total"`
+	Deliveries []*WebhookDeliveryResponse `json:"deliveries"`
+}
+
+// Payload is the body of a delivery.
+type Payload struct {
+	Id    string      `json:"id"`
+	Event string      `json:"event"`
+	Time  time.Time   `json:"time"`
+	Data  interface{} `json:"data"`
+}
+
+type PowerEventData struct {
+	Action       string `json:"action"`
+	ShutdownType int    `json:"shutdown_type,omitempty"`
+}
+
+type SlaveEventData struct {
+	ClientId string `json:"client_id"`
+	Username string `json:"username"`
+}
+
+type UnlockFailedEventData struct {
+	Source   string `json:"source"`
+	Username string `json:"username"`
+	Code     int    `json:"code"`
+	Msg      string `json:"msg"`
+}
@@ -0,0 +1,333 @@
+package audit_service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fadacontrol/internal/base/events"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/audit_schema"
+	"fadacontrol/pkg/goroutine"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// purgeInterval is how often the events outside the retention policy are deleted.
+const purgeInterval = time.Hour
+
+// exportBatchSize is the number of events loaded at a time while exporting.
+const exportBatchSize = 500
+
+type AuditService struct {
+	ctx       context.Context
+	db        *gorm.DB
+	cancel    context.CancelFunc
+	StartLock sync.Mutex
+}
+
+func NewAuditService(ctx context.Context, db *gorm.DB) *AuditService {
+	return &AuditService{ctx: ctx, db: db}
+}
+
+func (a *AuditService) StartService() error {
+	if !a.StartLock.TryLock() {
+		return exception.ErrSystemServiceAlreadyRunning
+	}
+	defer a.StartLock.Unlock()
+	if a.cancel != nil {
+		return exception.ErrSystemServiceAlreadyRunning
+	}
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.cancel = cancel
+	goroutine.RecoverGO(func() {
+		ticker := time.NewTicker(purgeInterval)
+		defer ticker.Stop()
+		for {
+			if err := a.Purge(); err != nil {
+				logger.Errorf("failed to purge the audit log: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+	return nil
+}
+
+func (a *AuditService) StopService() error {
+	a.StartLock.Lock()
+	defer a.StartLock.Unlock()
+	if a.cancel != nil {
+		a.cancel()
+		a.cancel = nil
+	}
+	return nil
+}
+
+// Record saves an action and its result, the secrets in params are redacted. Failing to save the event is logged
+// and does not fail the action.
+func (a *AuditService) Record(actor audit_schema.Actor, source, sourceIP, action string, params map[string]interface{}, err error) {
+	ex := toException(err)
+	data, jsonErr := json.Marshal(audit_schema.RedactParams(params))
+	if jsonErr != nil {
+		data = []byte("{}")
+	}
+	event := entity.AuditEvent{
+		ActorType: actor.Type,
+		Actor:     actor.Name,
+		ActorId:   actor.Id,
+		Source:    source,
+		SourceIP:  sourceIP,
+		Action:    action,
+		Params:    string(data),
+		Code:      ex.Code,
+		Msg:       ex.Msg,
+	}
+	if err := a.db.Create(&event).Error; err != nil {
+		logger.Errorf("failed to record audit event %s by %s: %v", action, actor.Name, err)
+	}
+}
+
+// RecordHttp saves an action requested over HTTP, the actor is the authenticated user, token or device.
+func (a *AuditService) RecordHttp(c *gin.Context, action string, params map[string]interface{}, err error) {
+	source := audit_schema.SourceLanHttp
+	if strings.HasPrefix(c.Request.URL.Path, "/admin/") {
+		source = audit_schema.SourceAdmin
+	}
+	a.Record(HttpActor(c), source, c.RemoteIP(), action, params, err)
+}
+
+// HttpActor returns who sent the request, as set by the jwt middleware.
+func HttpActor(c *gin.Context) audit_schema.Actor {
+	actor := audit_schema.Actor{Type: audit_schema.ActorAnonymous, Name: c.GetString("username")}
+	if id, ok := c.Get("api_token_id"); ok {
+		actor.Type = audit_schema.ActorToken
+		actor.Id = fmt.Sprint(id)
+	} else if id, ok := c.Get("client_cert_id"); ok {
+		actor.Type = audit_schema.ActorDevice
+		actor.Id = fmt.Sprint(id)
+	} else if actor.Name != "" {
+		actor.Type = audit_schema.ActorUser
+	}
+	return actor
+}
+
+// toException returns the result of an action, nil and a nil exception mean success.
+func toException(err error) *exception.Exception {
+	if err == nil {
+		return exception.ErrSuccess
+	}
+	var ex *exception.Exception
+	if errors.As(err, &ex) {
+		if ex == nil {
+			return exception.ErrSuccess
+		}
+		return ex
+	}
+	return exception.ErrSystemUnknownException.SetMsg(err.Error())
+}
+
+func (a *AuditService) filter(q *audit_schema.AuditQuery) *gorm.DB {
+	query := a.db.Model(&entity.AuditEvent{})
+	if q.From != nil {
+		query = query.Where("created_at >= ?", *q.From)
+	}
+	if q.To != nil {
+		query = query.Where("created_at < ?", *q.To)
+	}
+	if q.ActorType != "" {
+		query = query.Where("actor_type = ?", q.ActorType)
+	}
+	if q.Actor != "" {
+		query = query.Where("actor = ?", q.Actor)
+	}
+	if q.Source != "" {
+		query = query.Where("source = ?", q.Source)
+	}
+	if q.Action != "" {
+		query = query.Where("action = ?", q.Action)
+	}
+	switch q.Result {
+	case audit_schema.ResultSuccess:
+		query = query.Where("code = ?", exception.ErrSuccess.Code)
+	case audit_schema.ResultFailure:
+		query = query.Where("code <> ?", exception.ErrSuccess.Code)
+	}
+	return query
+}
+
+func validateQuery(q *audit_schema.AuditQuery) error {
+	if q.Result != "" && q.Result != audit_schema.ResultSuccess && q.Result != audit_schema.ResultFailure {
+		return exception.ErrUserParameterError.SetMsg("result must be success or failure")
+	}
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return exception.ErrUserParameterError.SetMsg("from must be before to")
+	}
+	return nil
+}
+
+func toEventResponse(event *entity.AuditEvent) *audit_schema.AuditEventResponse {
+	params := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(event.Params), &params); err != nil {
+		logger.Warnf("invalid parameters of audit event %d: %v", event.ID, err)
+	}
+	return &audit_schema.AuditEventResponse{
+		Id:        event.ID,
+		Time:      event.CreatedAt,
+		ActorType: event.ActorType,
+		Actor:     event.Actor,
+		ActorId:   event.ActorId,
+		Source:    event.Source,
+		SourceIP:  event.SourceIP,
+		Action:    event.Action,
+		Params:    params,
+		Code:      event.Code,
+		Msg:       event.Msg,
+	}
+}
+
+// ListEvents returns a page of the events matching q, newest first.
+func (a *AuditService) ListEvents(q *audit_schema.AuditQuery) ([]*audit_schema.AuditEventResponse, int64, error) {
+	if err := validateQuery(q); err != nil {
+		return nil, 0, err
+	}
+	var total int64
+	if err := a.filter(q).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var events []entity.AuditEvent
+	if err := a.filter(q).Order("id desc").Offset((q.Page - 1) * q.PageSize).Limit(q.PageSize).Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+	ret := make([]*audit_schema.AuditEventResponse, 0, len(events))
+	for i := range events {
+		ret = append(ret, toEventResponse(&events[i]))
+	}
+	return ret, total, nil
+}
+
+var csvHeader = []string{"id", "time", "actor_type", "actor", "actor_id", "source", "source_ip", "action", "params", "code", "msg"}
+
+// Export writes every event matching q to w as CSV or as a JSON array, oldest first.
+func (a *AuditService) Export(q *audit_schema.AuditQuery, format string, w io.Writer) error {
+	if err := validateQuery(q); err != nil {
+		return err
+	}
+	var write func(event *entity.AuditEvent) error
+	var finish func() error
+	switch format {
+	case audit_schema.ExportCsv:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		write = func(event *entity.AuditEvent) error {
+			return cw.Write([]string{strconv.FormatUint(uint64(event.ID), 10), event.CreatedAt.Format(time.RFC3339), event.ActorType,
+				event.Actor, event.ActorId, event.Source, event.SourceIP, event.Action, event.Params, strconv.Itoa(event.Code), event.Msg})
+		}
+		finish = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	case audit_schema.ExportJson:
+		if _, err := io.WriteString(w, "["); err != nil {
+			return err
+		}
+		first := true
+		enc := json.NewEncoder(w)
+		write = func(event *entity.AuditEvent) error {
+			if !first {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			first = false
+			return enc.Encode(toEventResponse(event))
+		}
+		finish = func() error {
+			_, err := io.WriteString(w, "]\n")
+			return err
+		}
+	default:
+		return exception.ErrUserParameterError.SetMsg("format must be csv or json")
+	}
+
+	var events []entity.AuditEvent
+	var writeErr error
+	err := a.filter(q).Order("id").FindInBatches(&events, exportBatchSize, func(tx *gorm.DB, batch int) error {
+		for i := range events {
+			if writeErr = write(&events[i]); writeErr != nil {
+				return writeErr
+			}
+		}
+		return nil
+	}).Error
+	if writeErr != nil {
+		return writeErr
+	}
+	if err != nil {
+		return err
+	}
+	return finish()
+}
+
+func (a *AuditService) GetRetention() (*audit_schema.AuditRetention, error) {
+	var config entity.SysConfig
+	if err := a.db.First(&config).Error; err != nil {
+		return nil, err
+	}
+	return &audit_schema.AuditRetention{Days: config.AuditRetentionDays, MaxEvents: config.AuditMaxEvents}, nil
+}
+
+// SetRetention saves the retention policy and deletes the events it no longer keeps.
+func (a *AuditService) SetRetention(req *audit_schema.AuditRetention) error {
+	var config entity.SysConfig
+	if err := a.db.First(&config).Error; err != nil {
+		return err
+	}
+	err := a.db.Model(&config).Select("AuditRetentionDays", "AuditMaxEvents").
+		Updates(entity.SysConfig{AuditRetentionDays: req.Days, AuditMaxEvents: req.MaxEvents}).Error
+	if err != nil {
+		return err
+	}
+	events.PublishConfigChanged(events.SectionAudit, "retention", nil)
+	return a.Purge()
+}
+
+// Purge deletes the events that are older than the retention period or beyond the maximum number of events.
+func (a *AuditService) Purge() error {
+	retention, err := a.GetRetention()
+	if err != nil {
+		return err
+	}
+	if retention.Days > 0 {
+		before := time.Now().AddDate(0, 0, -retention.Days)
+		if err := a.db.Where("created_at < ?", before).Delete(&entity.AuditEvent{}).Error; err != nil {
+			return err
+		}
+	}
+	if retention.MaxEvents > 0 {
+		var ids []uint
+		err := a.db.Model(&entity.AuditEvent{}).Order("id desc").Offset(retention.MaxEvents).Limit(1).Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		if len(ids) > 0 {
+			if err := a.db.Where("id <= ?", ids[0]).Delete(&entity.AuditEvent{}).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
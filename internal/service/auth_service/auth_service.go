@@ -1,6 +1,12 @@
 package auth_service
 
-import "github.com/casbin/casbin/v2"
+import (
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/schema/custom_command_schema"
+	"github.com/casbin/casbin/v2"
+	"path/filepath"
+	"strings"
+)
 
 type AuthService struct {
 	enforcer *casbin.Enforcer
@@ -8,16 +14,92 @@ type AuthService struct {
 type Action string
 
 const (
-	Read  Action = "r"
-	Write Action = "w"
+	Read    Action = "r"
+	Write   Action = "w"
+	Execute Action = "x"
 )
 
 const HttpPrefix = "api:"
+const CommandPrefix = "cmd:"
 
 func NewAuthService(enforcer *casbin.Enforcer) *AuthService {
 	return &AuthService{enforcer: enforcer}
 }
 
+// commandObject returns the policy object of the command name loaded from the config file at path. The path is
+// written with slashes after "cmd:/", so keyMatch2 never reads the drive letter of a windows path as a parameter.
+func commandObject(path, name string) string {
+	return CommandPrefix + "/" + strings.TrimPrefix(filepath.ToSlash(path), "/") + "/" + name
+}
+
+// SetCommandRules replaces the policies of the commands loaded from the config file at path. Each user and role of
+// the auth rule of a command may execute the object "cmd:<path>/<name>", the policies of the other files and the
+// ones on the command names are left alone.
+func (a *AuthService) SetCommandRules(path string, cmds map[string]custom_command_schema.Command) error {
+	prefix := commandObject(path, "")
+	policies, err := a.enforcer.GetPolicy()
+	if err != nil {
+		return err
+	}
+	var stale [][]string
+	for _, p := range policies {
+		if len(p) > 1 && strings.HasPrefix(p[1], prefix) {
+			stale = append(stale, p)
+		}
+	}
+	if len(stale) > 0 {
+		if _, err := a.enforcer.RemovePolicies(stale); err != nil {
+			return err
+		}
+	}
+	seen := make(map[[2]string]bool)
+	var rules [][]string
+	for name, cmd := range cmds {
+		for _, sub := range append(append([]string{}, cmd.Auth.Users...), cmd.Auth.Roles...) {
+			key := [2]string{sub, name}
+			if sub == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			rules = append(rules, []string{sub, prefix + name, string(Execute)})
+		}
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	_, err = a.enforcer.AddPolicies(rules)
+	return err
+}
+
+// CheckCommandPermission reports whether username may run cmd when the request arrives from source. The policies of
+// the config file of cmd and the ones on its name are both checked.
+func (a *AuthService) CheckCommandPermission(username string, cmd custom_command_schema.Command, source custom_command_schema.CommandSource) bool {
+	if source == custom_command_schema.RemoteSource && !cmd.Auth.AllowRemote {
+		return false
+	}
+	if source == custom_command_schema.UnknownSource {
+		return false
+	}
+	if username == "" {
+		return false
+	}
+	objects := []string{CommandPrefix + cmd.Name}
+	if cmd.Path != "" {
+		objects = append(objects, commandObject(cmd.Path, cmd.Name))
+	}
+	for _, obj := range objects {
+		allowed, err := a.enforcer.Enforce(username, obj, string(Execute))
+		if err != nil {
+			logger.Warnf("check command permission failed: %v", err)
+			return false
+		}
+		if allowed {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *AuthService) CheckHttpPermission(username string, path string, act Action) bool {
 	if username == "" {
 		username = "*"
@@ -29,3 +111,124 @@ func (a *AuthService) CheckHttpPermission(username string, path string, act Acti
 	}
 	return allowed
 }
+
+// GetRoles returns the roles directly assigned to username.
+func (a *AuthService) GetRoles(username string) ([]string, error) {
+	return a.enforcer.GetRolesForUser(username)
+}
+
+// SetRoles replaces the roles of username.
+func (a *AuthService) SetRoles(username string, roles []string) error {
+	if _, err := a.enforcer.DeleteRolesForUser(username); err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if _, err := a.enforcer.AddRoleForUser(username, role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteSubject removes the role assignments and the policies of username.
+func (a *AuthService) DeleteSubject(username string) error {
+	_, err := a.enforcer.DeleteUser(username)
+	return err
+}
+
+// IsAdmin reports whether username has full access, that is whether a "*, *" policy applies to it. The policy may
+// belong to the user, to one of its roles or to every subject, so this is not limited to the members of the admin
+// role. Full access is required to administer policies.
+func (a *AuthService) IsAdmin(username string) bool {
+	allowed, err := a.enforcer.Enforce(username, "*", "*")
+	if err != nil {
+		return false
+	}
+	return allowed
+}
+
+func (a *AuthService) GetPolicies() ([][]string, error) {
+	return a.enforcer.GetPolicy()
+}
+func (a *AuthService) AddPolicy(sub, obj, act string) (bool, error) {
+	return a.enforcer.AddPolicy(sub, obj, act)
+}
+func (a *AuthService) RemovePolicy(sub, obj, act string) (bool, error) {
+	return a.enforcer.RemovePolicy(sub, obj, act)
+}
+
+// AddRule adds a policy or role assignment of any type, it reports whether the rule is new.
+func (a *AuthService) AddRule(ptype string, values []string) (bool, error) {
+	if strings.HasPrefix(ptype, "g") {
+		return a.enforcer.AddNamedGroupingPolicy(ptype, values)
+	}
+	return a.enforcer.AddNamedPolicy(ptype, values)
+}
+
+// LoadPolicy reloads the rules from the database, after they were changed without the enforcer.
+func (a *AuthService) LoadPolicy() error {
+	return a.enforcer.LoadPolicy()
+}
+
+// IsRole reports whether name is a built-in role, or a role that has policies or members.
+func (a *AuthService) IsRole(name string) bool {
+	if IsBuiltinRole(name) {
+		return true
+	}
+	policies, err := a.enforcer.GetFilteredPolicy(0, name)
+	if err != nil {
+		logger.Warnf("get policies of %s failed: %v", name, err)
+		return false
+	}
+	if len(policies) > 0 {
+		return true
+	}
+	members, err := a.enforcer.GetUsersForRole(name)
+	if err != nil {
+		logger.Warnf("get members of %s failed: %v", name, err)
+		return false
+	}
+	return len(members) > 0
+}
+func (a *AuthService) GetRoleAssignments() ([][]string, error) {
+	return a.enforcer.GetGroupingPolicy()
+}
+func (a *AuthService) AddRole(username, role string) (bool, error) {
+	return a.enforcer.AddRoleForUser(username, role)
+}
+func (a *AuthService) RemoveRole(username, role string) (bool, error) {
+	return a.enforcer.DeleteRoleForUser(username, role)
+}
+
+// powerPaths are the endpoints that trigger power actions directly or through a macro or scheduled task.
+var powerPaths = []string{"/control-pc/shutdown", "/control-pc/standby", "/control-pc/lock", "/macros/0/run", "/schedule/tasks/0/run"}
+
+// IsPrivileged reports whether username may run power actions or custom commands.
+func (a *AuthService) IsPrivileged(username string) bool {
+	for _, prefix := range apiPrefixes {
+		for _, path := range powerPaths {
+			if a.CheckHttpPermission(username, prefix+path, Write) {
+				return true
+			}
+		}
+	}
+	perms, err := a.enforcer.GetImplicitPermissionsForUser(username)
+	if err != nil {
+		logger.Warnf("get permissions of %s failed: %v", username, err)
+		return true
+	}
+	everyone, err := a.enforcer.GetFilteredPolicy(0, "*")
+	if err != nil {
+		logger.Warnf("get policies failed: %v", err)
+		return true
+	}
+	for _, p := range append(perms, everyone...) {
+		if len(p) < 3 {
+			continue
+		}
+		if (p[1] == "*" || strings.HasPrefix(p[1], CommandPrefix)) && (p[2] == string(Execute) || p[2] == "*") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package auth_service
+
+import (
+	"fadacontrol/internal/base/data"
+	"fadacontrol/internal/schema/custom_command_schema"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+)
+
+func newTestAuthService(t *testing.T) *AuthService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "auth.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adapter, err := data.NewAdapterByDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enforcer, err := data.NewEnforcer(adapter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewAuthService(enforcer)
+}
+
+func TestCheckCommandPermission(t *testing.T) {
+	a := newTestAuthService(t)
+	if _, err := a.AddRole("alice", "ops"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.AddRole("ops", "oncall"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.AddPolicy("carol", CommandPrefix+"backup", string(Execute)); err != nil {
+		t.Fatal(err)
+	}
+	backup := custom_command_schema.Command{Name: "backup", Path: "/etc/commands.yml", Auth: custom_command_schema.CommandAuth{Users: []string{"bob"}, Roles: []string{"oncall"}}}
+	remote := custom_command_schema.Command{Name: "wake", Path: "/etc/commands.yml", Auth: custom_command_schema.CommandAuth{Users: []string{"*"}, AllowRemote: true}}
+	if err := a.SetCommandRules("/etc/commands.yml", map[string]custom_command_schema.Command{"backup": backup, "wake": remote}); err != nil {
+		t.Fatal(err)
+	}
+	// the same command of another file has other rules
+	other := backup
+	other.Path = "/etc/other.yml"
+
+	tests := []struct {
+		username string
+		cmd      custom_command_schema.Command
+		source   custom_command_schema.CommandSource
+		allowed  bool
+	}{
+		{"bob", backup, custom_command_schema.LanSource, true},
+		{"alice", backup, custom_command_schema.SchedulerSource, true},
+		{"carol", backup, custom_command_schema.LanSource, true},
+		{"dave", backup, custom_command_schema.LanSource, false},
+		{"bob", backup, custom_command_schema.RemoteSource, false},
+		{"bob", backup, custom_command_schema.UnknownSource, false},
+		{"", backup, custom_command_schema.LanSource, false},
+		{"dave", remote, custom_command_schema.RemoteSource, true},
+		{"bob", other, custom_command_schema.LanSource, false},
+		{"carol", other, custom_command_schema.LanSource, true},
+	}
+	for _, tt := range tests {
+		if got := a.CheckCommandPermission(tt.username, tt.cmd, tt.source); got != tt.allowed {
+			t.Errorf("%s running %s from %d: got %v, want %v", tt.username, tt.cmd.Name, tt.source, got, tt.allowed)
+		}
+	}
+
+	// the rules of the commands are policies on the command of the file
+	allowed, err := a.enforcer.Enforce("bob", CommandPrefix+"/etc/commands.yml/backup", string(Execute))
+	if err != nil || !allowed {
+		t.Errorf("bob may not execute cmd:/etc/commands.yml/backup, err = %v", err)
+	}
+}
+
+func TestCommandObject(t *testing.T) {
+	if got := commandObject("/etc/commands.yml", "backup"); got != "cmd:/etc/commands.yml/backup" {
+		t.Errorf("got %s", got)
+	}
+	// the drive letter is not read as a parameter of keyMatch2
+	a := newTestAuthService(t)
+	cmd := custom_command_schema.Command{Name: "backup", Auth: custom_command_schema.CommandAuth{Users: []string{"bob"}}}
+	if err := a.SetCommandRules("C:/commands.yml", map[string]custom_command_schema.Command{"backup": cmd}); err != nil {
+		t.Fatal(err)
+	}
+	cmd.Path = "D:/commands.yml"
+	if a.CheckCommandPermission("bob", cmd, custom_command_schema.LanSource) {
+		t.Error("the rules of C:/commands.yml apply to D:/commands.yml")
+	}
+	cmd.Path = "C:/commands.yml"
+	if !a.CheckCommandPermission("bob", cmd, custom_command_schema.LanSource) {
+		t.Error("the rules of C:/commands.yml do not apply")
+	}
+}
+
+func TestSetCommandRules(t *testing.T) {
+	a := newTestAuthService(t)
+	if a.IsPrivileged("bob") {
+		t.Fatal("bob is privileged without any rule")
+	}
+	if _, err := a.AddPolicy("carol", CommandPrefix+"backup", string(Execute)); err != nil {
+		t.Fatal(err)
+	}
+	cmd := custom_command_schema.Command{Name: "backup", Auth: custom_command_schema.CommandAuth{Users: []string{"bob"}, Roles: []string{"bob"}}}
+	if err := a.SetCommandRules("/etc/commands.yml", map[string]custom_command_schema.Command{"backup": cmd}); err != nil {
+		t.Fatal(err)
+	}
+	if !a.IsPrivileged("bob") {
+		t.Error("bob may run a command but is not privileged")
+	}
+	if err := a.SetCommandRules("/etc/other.yml", map[string]custom_command_schema.Command{}); err != nil {
+		t.Fatal(err)
+	}
+	if !a.IsPrivileged("bob") {
+		t.Error("loading another file replaced the rules of the first one")
+	}
+	if err := a.SetCommandRules("/etc/commands.yml", map[string]custom_command_schema.Command{}); err != nil {
+		t.Fatal(err)
+	}
+	if a.IsPrivileged("bob") {
+		t.Error("the rules of the reloaded file were kept")
+	}
+	// the policies added by the administrators are never replaced
+	policies, err := a.GetPolicies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policies) != 1 || policies[0][0] != "carol" {
+		t.Errorf("unexpected policies %v", policies)
+	}
+}
@@ -0,0 +1,41 @@
+package auth_service
+
+const (
+	RoleAdmin    = "admin"
+	RoleOperator = "operator"
+	RoleViewer   = "viewer"
+)
+
+// apiPrefixes are the path prefixes of the common and the admin api, built-in role policies apply to both.
+var apiPrefixes = []string{"/api/v1", "/admin/api/v1"}
+
+// BuiltinRolePolicies returns the policies of the built-in roles as (sub, obj, act) triples.
+func BuiltinRolePolicies() [][]string {
+	policies := [][]string{
+		{RoleAdmin, "*", "*"},
+	}
+	for _, prefix := range apiPrefixes {
+		// operators may run power actions and manage pending ones
+		policies = append(policies,
+			[]string{RoleOperator, HttpPrefix + prefix + "/control-pc/*", "*"},
+			[]string{RoleOperator, HttpPrefix + prefix + "/unlock", "*"},
+		)
+		for _, path := range []string{"/info", "/info/*", "/interface/*", "/control-pc/*", "/power-saving/status", "/schedule/tasks", "/schedule/tasks/*", "/macros", "/macros/*", "/macro-runs/*", "/metrics"} {
+			policies = append(policies, []string{RoleViewer, HttpPrefix + prefix + path, string(Read)})
+		}
+	}
+	return policies
+}
+
+// BuiltinRoles are the roles seeded with BuiltinRolePolicies, they always exist and can't be used as usernames.
+var BuiltinRoles = []string{RoleAdmin, RoleOperator, RoleViewer}
+
+// IsBuiltinRole reports whether role is one of BuiltinRoles.
+func IsBuiltinRole(role string) bool {
+	for _, r := range BuiltinRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,587 @@
+package backup_service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/events"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/base/version"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/backup_schema"
+	"fadacontrol/internal/service/auth_service"
+	"fadacontrol/internal/service/policy_service"
+	"fadacontrol/pkg/utils"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type BackupService struct {
+	ps   *policy_service.PolicyService
+	ctx  context.Context
+	db   *gorm.DB
+	auth *auth_service.AuthService
+}
+
+func NewBackupService(ps *policy_service.PolicyService, ctx context.Context, db *gorm.DB, auth *auth_service.AuthService) *BackupService {
+	return &BackupService{ps: ps, ctx: ctx, db: db, auth: auth}
+}
+
+func checkPassphrase(passphrase string) error {
+	if len(passphrase) < backup_schema.MinPassphraseLength {
+		return exception.ErrUserParameterError.SetMsg("the passphrase is too short")
+	}
+	return nil
+}
+
+// Export returns the archive of the current configuration.
+func (b *BackupService) Export(passphrase string) (*backup_schema.Archive, error) {
+	if err := checkPassphrase(passphrase); err != nil {
+		return nil, err
+	}
+	s, err := b.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return backup_schema.Seal(s, passphrase, version.GetVersion())
+}
+
+// first loads the only row of a configuration table, found is false when it has not been created yet.
+func first(db *gorm.DB, dest interface{}) (found bool, err error) {
+	err = db.First(dest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Snapshot reads the configuration that is backed up.
+func (b *BackupService) Snapshot() (*backup_schema.Snapshot, error) {
+	s := &backup_schema.Snapshot{
+		HttpConfigs:    []backup_schema.HttpConfig{},
+		Users:          []backup_schema.User{},
+		CasbinRules:    []backup_schema.CasbinRule{},
+		CustomCommands: []backup_schema.CommandFile{},
+	}
+
+	var sys entity.SysConfig
+	if found, err := first(b.db, &sys); err != nil {
+		return nil, err
+	} else if found {
+		s.SysConfig = &backup_schema.SysConfig{
+			PowerSavingMode:    sys.PowerSavingMode,
+			Region:             sys.Region,
+			Language:           sys.Language,
+			RequireTwoFactor:   sys.RequireTwoFactor,
+			AuditRetentionDays: sys.AuditRetentionDays,
+			AuditMaxEvents:     sys.AuditMaxEvents,
+		}
+	}
+
+	var https []entity.HttpConfig
+	if err := b.db.Order("service_name").Find(&https).Error; err != nil {
+		return nil, err
+	}
+	for _, h := range https {
+		s.HttpConfigs = append(s.HttpConfigs, backup_schema.HttpConfig{
+			ServiceName:    h.ServiceName,
+			Enable:         h.Enable,
+			Host:           h.Host,
+			Port:           h.Port,
+			Cer:            h.Cer,
+			Key:            h.Key,
+			EnableHttp3:    h.EnableHttp3,
+			ClientAuthMode: h.ClientAuthMode,
+			AutoCert:       h.AutoCert,
+		})
+	}
+
+	var remote entity.RemoteConnectConfig
+	if found, err := first(b.db, &remote); err != nil {
+		return nil, err
+	} else if found {
+		var servers []entity.RemoteMsgServer
+		if err := b.db.Where(&entity.RemoteMsgServer{RemoteConnectConfigId: remote.ID}).Order("id").Find(&servers).Error; err != nil {
+			return nil, err
+		}
+		urls := make([]string, 0, len(servers))
+		for _, server := range servers {
+			urls = append(urls, server.MsgServerUrl)
+		}
+		s.RemoteConnect = &backup_schema.RemoteConnectConfig{
+			Enable:         remote.Enable,
+			ClientId:       remote.ClientId,
+			SecurityKey:    remote.SecurityKey,
+			Token:          remote.Token,
+			TimeStampCheck: remote.TimeStampCheck,
+			ApiServerUrl:   remote.ApiServerUrl,
+			MsgServerUrls:  urls,
+		}
+	}
+
+	var discover entity.DiscoverConfig
+	if found, err := first(b.db, &discover); err != nil {
+		return nil, err
+	} else if found {
+		s.Discover = &backup_schema.DiscoverConfig{Enabled: discover.Enabled}
+	}
+
+	var users []entity.User
+	if err := b.db.Order("username").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		s.Users = append(s.Users, backup_schema.User{
+			Username:           u.Username,
+			Password:           u.Password,
+			Salt:               u.Salt,
+			Disabled:           u.Disabled,
+			MustChangePassword: u.MustChangePassword,
+		})
+	}
+
+	var rules []entity.CasbinRule
+	if err := b.db.Order("id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		values := []string{r.V0, r.V1, r.V2, r.V3, r.V4, r.V5}
+		for len(values) > 0 && values[len(values)-1] == "" {
+			values = values[:len(values)-1]
+		}
+		s.CasbinRules = append(s.CasbinRules, backup_schema.CasbinRule{Ptype: r.Ptype, Values: values})
+	}
+
+	paths, err := b.commandPaths()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		if _, err := b.commandFilePath(path); err != nil {
+			logger.Warnf("skipping the command file %s in the backup: %v", path, err)
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			logger.Warnf("skipping the command file %s in the backup: %v", path, err)
+			continue
+		}
+		s.CustomCommands = append(s.CustomCommands, backup_schema.CommandFile{Path: path, Content: string(content)})
+	}
+	return s, nil
+}
+
+// commandPaths returns the command config files the macros and scheduled tasks refer to.
+func (b *BackupService) commandPaths() ([]string, error) {
+	var steps, tasks []string
+	if err := b.db.Model(&entity.MacroStep{}).Where("command_path <> ''").Distinct().Pluck("command_path", &steps).Error; err != nil {
+		return nil, err
+	}
+	if err := b.db.Model(&entity.ScheduledTask{}).Where("command_path <> ''").Distinct().Pluck("command_path", &tasks).Error; err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var paths []string
+	for _, path := range append(steps, tasks...) {
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Import compares the archive with the current configuration and applies it unless it is a dry run. Entries of
+// the current configuration that are not in the archive are kept. The fields managed by config.yml can't be changed,
+// and the import is reverted when it leaves no enabled administrator.
+func (b *BackupService) Import(req *backup_schema.ImportRequest) (*backup_schema.ImportResponse, error) {
+	incoming, archiveVersion, err := backup_schema.Open(req.Archive, req.Passphrase)
+	if err != nil {
+		if errors.Is(err, backup_schema.ErrNewerArchive) || errors.Is(err, backup_schema.ErrNotAnArchive) ||
+			errors.Is(err, backup_schema.ErrWrongPassphrase) || errors.Is(err, backup_schema.ErrUnsupportedCrypt) {
+			return nil, exception.ErrUserParameterError.SetMsg(err.Error())
+		}
+		return nil, err
+	}
+	current, err := b.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	changes := backup_schema.Diff(current, incoming)
+	resp := &backup_schema.ImportResponse{DryRun: req.DryRun, Version: archiveVersion, Changes: changes}
+	for _, c := range changes {
+		if c.Section == backup_schema.SectionHttpConfigs || c.Section == backup_schema.SectionRemoteConnect {
+			resp.RestartRequired = true
+		}
+	}
+	if req.DryRun || len(changes) == 0 {
+		return resp, nil
+	}
+
+	for _, f := range incoming.CustomCommands {
+		if _, err := b.commandFilePath(f.Path); err != nil {
+			return nil, exception.ErrUserParameterError.SetMsg(err.Error())
+		}
+	}
+	_conf := utils.GetValueFromContext(b.ctx, constants.ConfKey, conf.NewDefaultConf())
+	if err := checkManaged(_conf, changes, incoming); err != nil {
+		return nil, err
+	}
+
+	// the command files are written last, a failure rolls back the database and the files written before
+	var restore func()
+	err = b.ps.Guard("", func() error {
+		err := b.db.Transaction(func(tx *gorm.DB) error {
+			if err := applySnapshot(tx, incoming); err != nil {
+				return err
+			}
+			var err error
+			restore, err = writeCommandFiles(incoming.CustomCommands)
+			return err
+		})
+		if err != nil {
+			if restore != nil {
+				restore()
+			}
+			return err
+		}
+		return b.auth.LoadPolicy()
+	}, func() error {
+		if restore != nil {
+			restore()
+		}
+		err := b.db.Transaction(func(tx *gorm.DB) error {
+			return revertSnapshot(tx, current, incoming)
+		})
+		return errors.Join(err, b.auth.LoadPolicy())
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("configuration restored from a backup of version %d with %d changes", archiveVersion, len(changes))
+	events.PublishConfigChanged(events.SectionBackup, "", nil)
+	return resp, nil
+}
+
+// checkManaged returns ErrUserConfigManaged when the changes of the import set a field managed by config.yml. The
+// import never removes rules, so the policies of the file are always kept.
+func checkManaged(c *conf.Conf, changes []backup_schema.Change, incoming *backup_schema.Snapshot) error {
+	for _, change := range changes {
+		var managed []string
+		switch change.Section {
+		case backup_schema.SectionHttpConfigs:
+			managed = c.ManagedHttpFields(change.Key)
+		case backup_schema.SectionRemoteConnect:
+			managed = c.ManagedRemoteFields()
+		case backup_schema.SectionDiscover:
+			managed = c.ManagedDiscoveryFields()
+		default:
+			continue
+		}
+		if len(managed) > 0 && change.Op == backup_schema.OpAdd {
+			return exception.ErrUserConfigManaged.SetMsg(change.Section + " is managed by config.yml")
+		}
+		changed := make(map[string]interface{}, len(change.Fields))
+		for _, field := range change.Fields {
+			// the field is named after the request of the remote configuration in config.yml
+			if field == "timestamp_check" {
+				field = "time_stamp_check"
+			}
+			changed[field] = true
+		}
+		if err := conf.CheckManaged(managed, changed); err != nil {
+			return err
+		}
+	}
+	for _, u := range incoming.Users {
+		if m := c.ManagedUser(u.Username); m != nil && m.Disabled != nil && *m.Disabled != u.Disabled {
+			return exception.ErrUserConfigManaged.SetMsg("the user " + u.Username + " is managed by config.yml")
+		}
+	}
+	for _, r := range incoming.CasbinRules {
+		if !strings.HasPrefix(r.Ptype, "g") || len(r.Values) < 2 {
+			continue
+		}
+		m := c.ManagedUser(r.Values[0])
+		if m == nil || m.Roles == nil {
+			continue
+		}
+		managed := false
+		for _, role := range m.Roles {
+			managed = managed || role == r.Values[1]
+		}
+		if !managed {
+			return exception.ErrUserConfigManaged.SetMsg("the roles of " + r.Values[0] + " are managed by config.yml")
+		}
+	}
+	return nil
+}
+
+func applySnapshot(tx *gorm.DB, s *backup_schema.Snapshot) error {
+	if s.SysConfig != nil {
+		sys := entity.SysConfig{
+			PowerSavingMode:    s.SysConfig.PowerSavingMode,
+			Region:             s.SysConfig.Region,
+			Language:           s.SysConfig.Language,
+			RequireTwoFactor:   s.SysConfig.RequireTwoFactor,
+			AuditRetentionDays: s.SysConfig.AuditRetentionDays,
+			AuditMaxEvents:     s.SysConfig.AuditMaxEvents,
+		}
+		if err := upsert(tx, &entity.SysConfig{}, nil, &sys); err != nil {
+			return err
+		}
+	}
+
+	for _, h := range s.HttpConfigs {
+		config := entity.HttpConfig{
+			ServiceName:    h.ServiceName,
+			Enable:         h.Enable,
+			Host:           h.Host,
+			Port:           h.Port,
+			Cer:            h.Cer,
+			Key:            h.Key,
+			EnableHttp3:    h.EnableHttp3,
+			ClientAuthMode: h.ClientAuthMode,
+			AutoCert:       h.AutoCert,
+		}
+		if err := upsert(tx, &entity.HttpConfig{}, &entity.HttpConfig{ServiceName: h.ServiceName}, &config); err != nil {
+			return err
+		}
+	}
+
+	if s.RemoteConnect != nil {
+		config := entity.RemoteConnectConfig{
+			Enable:         s.RemoteConnect.Enable,
+			ClientId:       s.RemoteConnect.ClientId,
+			SecurityKey:    s.RemoteConnect.SecurityKey,
+			Token:          s.RemoteConnect.Token,
+			TimeStampCheck: s.RemoteConnect.TimeStampCheck,
+			ApiServerUrl:   s.RemoteConnect.ApiServerUrl,
+		}
+		var existing entity.RemoteConnectConfig
+		if err := upsert(tx, &existing, nil, &config); err != nil {
+			return err
+		}
+		if err := tx.First(&existing).Error; err != nil {
+			return err
+		}
+		for _, url := range s.RemoteConnect.MsgServerUrls {
+			var count int64
+			if err := tx.Model(&entity.RemoteMsgServer{}).Where(&entity.RemoteMsgServer{MsgServerUrl: url}).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+			server := entity.RemoteMsgServer{MsgServerUrl: url, RemoteConnectConfigId: existing.ID}
+			if err := tx.Create(&server).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	if s.Discover != nil {
+		config := entity.DiscoverConfig{Enabled: s.Discover.Enabled}
+		if err := upsert(tx, &entity.DiscoverConfig{}, nil, &config); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range s.CasbinRules {
+		rule := toCasbinRule(r)
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rule).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, u := range s.Users {
+		user := entity.User{
+			Username:           u.Username,
+			Password:           u.Password,
+			Salt:               u.Salt,
+			Disabled:           u.Disabled,
+			MustChangePassword: u.MustChangePassword,
+		}
+		if err := upsert(tx, &entity.User{}, &entity.User{Username: u.Username}, &user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revertSnapshot restores current after incoming was applied over it, the rows of current are written back and the
+// ones incoming added are deleted.
+func revertSnapshot(tx *gorm.DB, current, incoming *backup_schema.Snapshot) error {
+	if err := applySnapshot(tx, current); err != nil {
+		return err
+	}
+	if current.SysConfig == nil && incoming.SysConfig != nil {
+		if err := tx.Unscoped().Where("1 = 1").Delete(&entity.SysConfig{}).Error; err != nil {
+			return err
+		}
+	}
+
+	https := make(map[string]bool)
+	for _, h := range current.HttpConfigs {
+		https[h.ServiceName] = true
+	}
+	for _, h := range incoming.HttpConfigs {
+		if https[h.ServiceName] {
+			continue
+		}
+		if err := tx.Unscoped().Where(&entity.HttpConfig{ServiceName: h.ServiceName}).Delete(&entity.HttpConfig{}).Error; err != nil {
+			return err
+		}
+	}
+
+	if current.RemoteConnect == nil && incoming.RemoteConnect != nil {
+		if err := tx.Unscoped().Where("1 = 1").Delete(&entity.RemoteMsgServer{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("1 = 1").Delete(&entity.RemoteConnectConfig{}).Error; err != nil {
+			return err
+		}
+	} else if incoming.RemoteConnect != nil {
+		urls := make(map[string]bool)
+		for _, url := range current.RemoteConnect.MsgServerUrls {
+			urls[url] = true
+		}
+		for _, url := range incoming.RemoteConnect.MsgServerUrls {
+			if urls[url] {
+				continue
+			}
+			if err := tx.Unscoped().Where(&entity.RemoteMsgServer{MsgServerUrl: url}).Delete(&entity.RemoteMsgServer{}).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	if current.Discover == nil && incoming.Discover != nil {
+		if err := tx.Unscoped().Where("1 = 1").Delete(&entity.DiscoverConfig{}).Error; err != nil {
+			return err
+		}
+	}
+
+	rules := make(map[entity.CasbinRule]bool)
+	for _, r := range current.CasbinRules {
+		rules[toCasbinRule(r)] = true
+	}
+	for _, r := range incoming.CasbinRules {
+		rule := toCasbinRule(r)
+		if rules[rule] {
+			continue
+		}
+		where := map[string]interface{}{"ptype": rule.Ptype, "v0": rule.V0, "v1": rule.V1, "v2": rule.V2, "v3": rule.V3, "v4": rule.V4, "v5": rule.V5}
+		if err := tx.Where(where).Delete(&entity.CasbinRule{}).Error; err != nil {
+			return err
+		}
+	}
+
+	users := make(map[string]bool)
+	for _, u := range current.Users {
+		users[u.Username] = true
+	}
+	for _, u := range incoming.Users {
+		if users[u.Username] {
+			continue
+		}
+		if err := tx.Unscoped().Where(&entity.User{Username: u.Username}).Delete(&entity.User{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toCasbinRule returns the row of a rule of the archive.
+func toCasbinRule(r backup_schema.CasbinRule) entity.CasbinRule {
+	rule := entity.CasbinRule{Ptype: r.Ptype}
+	for i, v := range []*string{&rule.V0, &rule.V1, &rule.V2, &rule.V3, &rule.V4, &rule.V5} {
+		if i < len(r.Values) {
+			*v = r.Values[i]
+		}
+	}
+	return rule
+}
+
+// upsert updates every field of the first row matching where, or creates the row when there is none.
+func upsert(tx *gorm.DB, existing, where, values interface{}) error {
+	query := tx
+	if where != nil {
+		query = tx.Where(where)
+	}
+	found, err := first(query, existing)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return tx.Create(values).Error
+	}
+	return tx.Model(existing).Select("*").Omit("ID", "CreatedAt", "DeletedAt").Updates(values).Error
+}
+
+// commandFilePath returns the absolute path of a command config file, it must be in the command directory.
+func (b *BackupService) commandFilePath(path string) (string, error) {
+	_conf := utils.GetValueFromContext(b.ctx, constants.ConfKey, conf.NewDefaultConf())
+	dir, err := filepath.Abs(_conf.GetCommandDir())
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("the command file %s is not in the command directory %s", path, dir)
+	}
+	return abs, nil
+}
+
+// writeCommandFiles writes the files that changed. When a write fails the files written before are restored, else
+// the returned func restores them.
+func writeCommandFiles(files []backup_schema.CommandFile) (restore func(), err error) {
+	var undo []func()
+	restore = func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}
+	for _, f := range files {
+		current, err := os.ReadFile(f.Path)
+		if err == nil && bytes.Equal(current, []byte(f.Content)) {
+			continue
+		}
+		existed := err == nil
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+			restore()
+			return nil, err
+		}
+		if err := os.WriteFile(f.Path, []byte(f.Content), 0644); err != nil {
+			restore()
+			return nil, err
+		}
+		path := f.Path
+		undo = append(undo, func() {
+			var err error
+			if existed {
+				err = os.WriteFile(path, current, 0644)
+			} else {
+				err = os.Remove(path)
+			}
+			if err != nil {
+				logger.Errorf("failed to restore the command file %s: %v", path, err)
+			}
+		})
+		logger.Infof("restored the command file %s", f.Path)
+	}
+	return restore, nil
+}
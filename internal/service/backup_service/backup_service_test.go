@@ -0,0 +1,241 @@
+package backup_service
+
+import (
+	"context"
+	"encoding/json"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/data"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/backup_schema"
+	"fadacontrol/internal/service/auth_service"
+	"fadacontrol/internal/service/policy_service"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestBackupService returns a service with the configuration c, root is the only administrator.
+func newTestBackupService(t *testing.T, c *conf.Conf) (*BackupService, *auth_service.AuthService, string) {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "backup.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.SysConfig{}, &entity.HttpConfig{}, &entity.RemoteConnectConfig{}, &entity.RemoteMsgServer{},
+		&entity.DiscoverConfig{}, &entity.User{}, &entity.MacroStep{}, &entity.ScheduledTask{}); err != nil {
+		t.Fatal(err)
+	}
+	adapter, err := data.NewAdapterByDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enforcer, err := data.NewEnforcer(adapter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetWorkdir(dir)
+	ctx := context.WithValue(context.Background(), constants.ConfKey, c)
+	auth := auth_service.NewAuthService(enforcer)
+	if err := db.Create(&entity.User{Username: "root", Password: "hash", Salt: "salt"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.AddPolicy(auth_service.RoleAdmin, "*", "*"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.AddRole("root", auth_service.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	return NewBackupService(policy_service.NewPolicyService(ctx, db, auth), ctx, db, auth), auth, c.GetCommandDir()
+}
+
+func importRequest(t *testing.T, s *backup_schema.Snapshot) *backup_schema.ImportRequest {
+	t.Helper()
+	a, err := backup_schema.Seal(s, "correct horse", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &backup_schema.ImportRequest{Archive: archive, Passphrase: "correct horse"}
+}
+
+func TestImport(t *testing.T) {
+	b, auth, commandDir := newTestBackupService(t, conf.NewDefaultConf())
+	path := filepath.Join(commandDir, "commands.yaml")
+	s := &backup_schema.Snapshot{
+		Users:          []backup_schema.User{{Username: "alice", Password: "hash", Salt: "salt"}},
+		CasbinRules:    []backup_schema.CasbinRule{{Ptype: "g", Values: []string{"alice", "admin"}}},
+		CustomCommands: []backup_schema.CommandFile{{Path: path, Content: "commands: []\n"}},
+	}
+	if _, err := b.Import(importRequest(t, s)); err != nil {
+		t.Fatal(err)
+	}
+	if roles, _ := auth.GetRoles("alice"); len(roles) != 1 || roles[0] != "admin" {
+		t.Errorf("roles of alice = %v, want [admin]", roles)
+	}
+	if content, err := os.ReadFile(path); err != nil || string(content) != "commands: []\n" {
+		t.Errorf("command file = %q, %v", content, err)
+	}
+
+	// a second import of the same rules adds nothing
+	if _, err := b.Import(importRequest(t, s)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportOutsideCommandDir(t *testing.T) {
+	b, auth, commandDir := newTestBackupService(t, conf.NewDefaultConf())
+	for _, path := range []string{
+		filepath.Join(filepath.Dir(commandDir), "outside.yaml"),
+		filepath.Join(commandDir, "..", "outside.yaml"),
+		commandDir,
+	} {
+		s := &backup_schema.Snapshot{
+			Users:          []backup_schema.User{{Username: "alice", Password: "hash", Salt: "salt"}},
+			CasbinRules:    []backup_schema.CasbinRule{{Ptype: "g", Values: []string{"alice", "admin"}}},
+			CustomCommands: []backup_schema.CommandFile{{Path: path, Content: "commands: []\n"}},
+		}
+		_, err := b.Import(importRequest(t, s))
+		if ex, ok := err.(*exception.Exception); !ok || ex.Code != exception.ErrUserParameterError.Code {
+			t.Fatalf("import of %s: expected a parameter error, got %v", path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(commandDir), "outside.yaml")); !os.IsNotExist(err) {
+		t.Errorf("expected the file outside the command directory not to be written, got %v", err)
+	}
+	if roles, _ := auth.GetRoles("alice"); len(roles) != 0 {
+		t.Errorf("expected no roles to be imported, got %v", roles)
+	}
+	var count int64
+	b.db.Model(&entity.User{}).Count(&count)
+	if count != 1 {
+		t.Errorf("expected no users to be imported, got %d users", count)
+	}
+}
+
+func TestImportLastAdmin(t *testing.T) {
+	b, auth, _ := newTestBackupService(t, conf.NewDefaultConf())
+	discover := &entity.DiscoverConfig{Enabled: true}
+	if err := b.db.Create(discover).Error; err != nil {
+		t.Fatal(err)
+	}
+	s := &backup_schema.Snapshot{
+		Discover: &backup_schema.DiscoverConfig{Enabled: false},
+		Users: []backup_schema.User{
+			{Username: "root", Password: "hash", Salt: "salt", Disabled: true},
+			{Username: "alice", Password: "hash", Salt: "salt"},
+		},
+		CasbinRules: []backup_schema.CasbinRule{{Ptype: "g", Values: []string{"alice", "operator"}}},
+	}
+	_, err := b.Import(importRequest(t, s))
+	if ex, ok := err.(*exception.Exception); !ok || ex.Code != exception.ErrUserLastAdmin.Code {
+		t.Fatalf("expected the last admin error, got %v", err)
+	}
+	// the whole import is reverted
+	var root entity.User
+	if err := b.db.Where(&entity.User{Username: "root"}).First(&root).Error; err != nil || root.Disabled {
+		t.Errorf("root = %+v, %v, want it enabled", root, err)
+	}
+	var count int64
+	b.db.Unscoped().Model(&entity.User{}).Where(&entity.User{Username: "alice"}).Count(&count)
+	if count != 0 {
+		t.Error("the imported user was kept")
+	}
+	if roles, _ := auth.GetRoles("alice"); len(roles) != 0 {
+		t.Errorf("roles of alice = %v, want none", roles)
+	}
+	if err := b.db.First(discover, discover.ID).Error; err != nil || !discover.Enabled {
+		t.Errorf("discover = %+v, %v, want it enabled", discover, err)
+	}
+}
+
+func TestImportManaged(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name string
+		conf func(c *conf.Conf)
+		s    *backup_schema.Snapshot
+	}{
+		{"discovery", func(c *conf.Conf) { c.Discovery = &conf.DiscoveryConf{Enabled: &enabled} },
+			&backup_schema.Snapshot{Discover: &backup_schema.DiscoverConfig{Enabled: false}}},
+		{"http", func(c *conf.Conf) { port := 2092; c.Http = map[string]*conf.HttpServiceConf{"api": {Port: &port}} },
+			&backup_schema.Snapshot{HttpConfigs: []backup_schema.HttpConfig{{ServiceName: conf.HttpServiceNames["api"], Port: 2093}}}},
+		{"disabled user", func(c *conf.Conf) { c.Users = []conf.UserConf{{Username: "bob", Disabled: &disabled}} },
+			&backup_schema.Snapshot{Users: []backup_schema.User{{Username: "bob", Disabled: true}}}},
+		{"roles", func(c *conf.Conf) { c.Users = []conf.UserConf{{Username: "bob", Roles: []string{"viewer"}}} },
+			&backup_schema.Snapshot{CasbinRules: []backup_schema.CasbinRule{{Ptype: "g", Values: []string{"bob", "admin"}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.NewDefaultConf()
+			tt.conf(c)
+			b, auth, _ := newTestBackupService(t, c)
+			_, err := b.Import(importRequest(t, tt.s))
+			if ex, ok := err.(*exception.Exception); !ok || ex.Code != exception.ErrUserConfigManaged.Code {
+				t.Fatalf("expected a managed error, got %v", err)
+			}
+			if roles, _ := auth.GetRoles("bob"); len(roles) != 0 {
+				t.Errorf("roles of bob = %v, want none", roles)
+			}
+		})
+	}
+
+	// the values of the file may be imported
+	c := conf.NewDefaultConf()
+	c.Users = []conf.UserConf{{Username: "bob", Roles: []string{"viewer"}, Disabled: &disabled}}
+	b, auth, _ := newTestBackupService(t, c)
+	s := &backup_schema.Snapshot{
+		Users:       []backup_schema.User{{Username: "bob", Password: "hash", Salt: "salt"}},
+		CasbinRules: []backup_schema.CasbinRule{{Ptype: "g", Values: []string{"bob", "viewer"}}},
+	}
+	if _, err := b.Import(importRequest(t, s)); err != nil {
+		t.Fatal(err)
+	}
+	if roles, _ := auth.GetRoles("bob"); len(roles) != 1 || roles[0] != "viewer" {
+		t.Errorf("roles of bob = %v, want [viewer]", roles)
+	}
+}
+
+func TestWriteCommandFilesRestore(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.yaml")
+	if err := os.WriteFile(existing, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	created := filepath.Join(dir, "sub", "created.yaml")
+	// the parent of the last file is a file, the write fails and the others are restored
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := writeCommandFiles([]backup_schema.CommandFile{
+		{Path: existing, Content: "new"},
+		{Path: created, Content: "new"},
+		{Path: filepath.Join(blocker, "failed.yaml"), Content: "new"},
+	})
+	if err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	if content, _ := os.ReadFile(existing); string(content) != "old" {
+		t.Errorf("existing file = %q, want old", content)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("expected the created file to be removed, got %v", err)
+	}
+
+	restore, err := writeCommandFiles([]backup_schema.CommandFile{{Path: existing, Content: "new"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	restore()
+	if content, _ := os.ReadFile(existing); string(content) != "old" {
+		t.Errorf("existing file = %q after restore, want old", content)
+	}
+}
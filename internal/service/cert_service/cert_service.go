@@ -0,0 +1,267 @@
+package cert_service
+
+import (
+	"crypto/x509"
+	"errors"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/cert_schema"
+	"fadacontrol/pkg/secure"
+	"gorm.io/gorm"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// LocalCAName is the name of the authority that issues the certificates of this installation.
+	LocalCAName = "local"
+
+	caCommonName = "FadaControl Local CA"
+	// crlValidity is the lifetime of a revocation list, a new one is signed after half of it.
+	crlValidity = 7 * 24 * time.Hour
+)
+
+type CertService struct {
+	db *gorm.DB
+
+	mu         sync.Mutex
+	ca         *secure.CertificateAuthority
+	crl        []byte
+	crlUpdated time.Time
+}
+
+func NewCertService(db *gorm.DB) *CertService {
+	return &CertService{db: db}
+}
+
+// authority returns the local authority and creates it on first use, s.mu must be held.
+func (s *CertService) authority() (*secure.CertificateAuthority, error) {
+	if s.ca != nil {
+		return s.ca, nil
+	}
+	var record entity.CertAuthority
+	err := s.db.Where(&entity.CertAuthority{Name: LocalCAName}).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		hostname, _, _ := serverNames()
+		certPEM, keyPEM, err := secure.GenerateCA(caCommonName + " (" + hostname + ")")
+		if err != nil {
+			return nil, err
+		}
+		record = entity.CertAuthority{Name: LocalCAName, Cert: string(certPEM), Key: string(keyPEM)}
+		if err := s.db.Create(&record).Error; err != nil {
+			return nil, err
+		}
+		logger.Info("local certificate authority created")
+	} else if err != nil {
+		return nil, err
+	}
+	ca, err := secure.LoadCA([]byte(record.Cert), []byte(record.Key))
+	if err != nil {
+		return nil, err
+	}
+	s.ca = ca
+	return ca, nil
+}
+
+// CertPool returns the pool of authorities trusted for client certificates.
+func (s *CertService) CertPool() (*x509.CertPool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ca, err := s.authority()
+	if err != nil {
+		return nil, err
+	}
+	return ca.CertPool(), nil
+}
+
+func serialOf(cert *x509.Certificate) string {
+	return cert.SerialNumber.Text(16)
+}
+
+func toClientCertResponse(cert *entity.ClientCert) *cert_schema.ClientCertResponse {
+	return &cert_schema.ClientCertResponse{
+		Id:          cert.ID,
+		Serial:      cert.Serial,
+		Username:    cert.Username,
+		Name:        cert.Name,
+		Fingerprint: cert.Fingerprint,
+		NotAfter:    cert.NotAfter,
+		RevokedAt:   cert.RevokedAt,
+		CreatedAt:   cert.CreatedAt,
+	}
+}
+
+// IssueClientCert issues a certificate that authenticates as req.Username, the subject common name of the
+// certificate is the username.
+func (s *CertService) IssueClientCert(req *cert_schema.IssueClientCertRequest) (*cert_schema.IssueClientCertResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > cert_schema.MaxCertNameLength {
+		return nil, exception.ErrUserParameterError.SetMsg("the certificate name must be 1 to 64 characters long")
+	}
+	days := req.ValidDays
+	if days == 0 {
+		days = cert_schema.DefaultCertValidity
+	}
+	if days < 0 || days > cert_schema.MaxCertValidity {
+		return nil, exception.ErrUserParameterError.SetMsg("the validity must be 1 to 3650 days")
+	}
+	var user entity.User
+	if err := s.db.Where(&entity.User{Username: req.Username}).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, exception.ErrUserResourceNotFound
+		}
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ca, err := s.authority()
+	if err != nil {
+		return nil, err
+	}
+	cert, certPEM, keyPEM, err := ca.IssueClientCert(req.Username, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	record := &entity.ClientCert{
+		Serial:      serialOf(cert),
+		Username:    req.Username,
+		Name:        name,
+		Fingerprint: secure.Fingerprint(cert),
+		NotAfter:    cert.NotAfter,
+	}
+	if err := s.db.Create(record).Error; err != nil {
+		return nil, err
+	}
+	logger.Infof("client certificate %s (%s) issued for user %s", record.Serial, record.Name, record.Username)
+	return &cert_schema.IssueClientCertResponse{
+		ClientCertResponse: *toClientCertResponse(record),
+		Certificate:        string(certPEM),
+		PrivateKey:         string(keyPEM),
+		CaCertificate:      string(ca.CertPEM()),
+	}, nil
+}
+
+// ListClientCerts returns the certificates of username, or of every user when username is empty.
+func (s *CertService) ListClientCerts(username string) ([]*cert_schema.ClientCertResponse, error) {
+	var certs []entity.ClientCert
+	tx := s.db.Order("id")
+	if username != "" {
+		tx = tx.Where("username = ?", username)
+	}
+	if err := tx.Find(&certs).Error; err != nil {
+		return nil, err
+	}
+	ret := make([]*cert_schema.ClientCertResponse, 0, len(certs))
+	for i := range certs {
+		ret = append(ret, toClientCertResponse(&certs[i]))
+	}
+	return ret, nil
+}
+
+// RevokeClientCert revokes the certificate with id and puts it on the revocation list.
+func (s *CertService) RevokeClientCert(id uint) error {
+	var cert entity.ClientCert
+	if err := s.db.First(&cert, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return exception.ErrUserResourceNotFound
+		}
+		return err
+	}
+	if cert.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	if err := s.db.Model(&cert).Update("revoked_at", &now).Error; err != nil {
+		return err
+	}
+	s.invalidateCRL()
+	logger.Infof("client certificate %s of user %s revoked", cert.Serial, cert.Username)
+	return nil
+}
+
+// RevokeUserCerts revokes every certificate of username, the records are kept for the revocation list.
+func (s *CertService) RevokeUserCerts(username string) error {
+	now := time.Now()
+	tx := s.db.Model(&entity.ClientCert{}).Where("username = ? AND revoked_at IS NULL", username).Update("revoked_at", &now)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected > 0 {
+		s.invalidateCRL()
+	}
+	return nil
+}
+
+func (s *CertService) invalidateCRL() {
+	s.mu.Lock()
+	s.crl = nil
+	s.mu.Unlock()
+}
+
+// CRL returns the PEM encoded revocation list of the certificates that are revoked but not yet expired.
+func (s *CertService) CRL() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.crl != nil && time.Since(s.crlUpdated) < crlValidity/2 {
+		return s.crl, nil
+	}
+	ca, err := s.authority()
+	if err != nil {
+		return nil, err
+	}
+	var certs []entity.ClientCert
+	if err := s.db.Where("revoked_at IS NOT NULL AND not_after > ?", time.Now()).Order("id").Find(&certs).Error; err != nil {
+		return nil, err
+	}
+	revoked := make([]x509.RevocationListEntry, 0, len(certs))
+	for _, cert := range certs {
+		serial, ok := new(big.Int).SetString(cert.Serial, 16)
+		if !ok {
+			logger.Warnf("invalid serial %s of client certificate %d", cert.Serial, cert.ID)
+			continue
+		}
+		revoked = append(revoked, x509.RevocationListEntry{SerialNumber: serial, RevocationTime: *cert.RevokedAt})
+	}
+	var record entity.CertAuthority
+	if err := s.db.Where(&entity.CertAuthority{Name: LocalCAName}).First(&record).Error; err != nil {
+		return nil, err
+	}
+	number := record.CrlNumber + 1
+	crl, err := ca.CreateCRL(revoked, big.NewInt(number), crlValidity)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(&record).Update("crl_number", number).Error; err != nil {
+		return nil, err
+	}
+	s.crl = crl
+	s.crlUpdated = time.Now()
+	return crl, nil
+}
+
+// AuthenticateClientCert returns the certificate record of a verified peer certificate, it fails when the
+// certificate is unknown, revoked or its subject does not match the user it was issued for.
+func (s *CertService) AuthenticateClientCert(peer *x509.Certificate) (*entity.ClientCert, error) {
+	s.mu.Lock()
+	ca, err := s.authority()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if err := ca.VerifyClientCert(peer); err != nil {
+		return nil, exception.ErrUserUnauthorizedAccess
+	}
+	var cert entity.ClientCert
+	if err := s.db.Where("serial = ?", serialOf(peer)).First(&cert).Error; err != nil {
+		return nil, exception.ErrUserUnauthorizedAccess
+	}
+	if cert.RevokedAt != nil || cert.Fingerprint != secure.Fingerprint(peer) || cert.Username != peer.Subject.CommonName {
+		logger.Warnf("rejected client certificate %s of user %s", cert.Serial, cert.Username)
+		return nil, exception.ErrUserUnauthorizedAccess
+	}
+	return &cert, nil
+}
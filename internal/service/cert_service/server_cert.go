@@ -0,0 +1,197 @@
+package cert_service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/cert_schema"
+	"fadacontrol/pkg/secure"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// serverCertValidity stays below the 398 days accepted by browsers for certificates of private authorities.
+	serverCertValidity = 397 * 24 * time.Hour
+	// serverCertRenewBefore is the remaining validity at which the server certificate is reissued.
+	serverCertRenewBefore = 30 * 24 * time.Hour
+)
+
+// serverNames returns the host name and the names and addresses the server certificate has to cover.
+func serverNames() (string, []string, []net.IP) {
+	dnsNames := []string{"localhost"}
+	hostname, err := os.Hostname()
+	hostname = strings.ToLower(hostname)
+	if err != nil || hostname == "" {
+		hostname = "localhost"
+	} else {
+		dnsNames = append(dnsNames, hostname)
+		if !strings.Contains(hostname, ".") {
+			dnsNames = append(dnsNames, hostname+".local")
+		}
+	}
+	ips := []net.IP{net.IPv4(127, 0, 0, 1).To4(), net.IPv6loopback}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		logger.Warnf("failed to list the interface addresses: %v", err)
+		return hostname, dnsNames, ips
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() {
+			continue
+		}
+		// IPv6 link-local addresses need a zone and can not be used in a certificate
+		if ipNet.IP.To4() == nil && ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		ip := ipNet.IP
+		if v4 := ip.To4(); v4 != nil {
+			ip = v4
+		}
+		ips = append(ips, ip)
+	}
+	return hostname, dnsNames, ips
+}
+
+// coversNames reports whether cert is valid for every name and address.
+func coversNames(cert *x509.Certificate, dnsNames []string, ips []net.IP) bool {
+	for _, name := range dnsNames {
+		found := false
+		for _, n := range cert.DNSNames {
+			if strings.EqualFold(n, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, ip := range ips {
+		found := false
+		for _, i := range cert.IPAddresses {
+			if i.Equal(ip) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ServerCertificate returns the certificate of the HTTPS service described by config. When AutoCert is set the
+// certificate is issued by the local authority and reissued when it expires soon or does not cover the current
+// names and addresses, the new certificate is saved to config.
+func (s *CertService) ServerCertificate(config *entity.HttpConfig) (tls.Certificate, error) {
+	current, loadErr := secure.LoadBaseX509KeyPair(config.Cer, config.Key)
+	if config.AutoCert != nil && !*config.AutoCert {
+		return current, loadErr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ca, err := s.authority()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	hostname, dnsNames, ips := serverNames()
+	reason := "no certificate"
+	if loadErr == nil {
+		leaf := current.Leaf
+		issued := leaf.CheckSignatureFrom(ca.Cert) == nil
+		if config.AutoCert == nil {
+			// configurations of older versions keep certificates uploaded by the user
+			auto := issued || secure.IsGeneratedX509Cert(leaf)
+			if err := s.db.Model(config).Update("auto_cert", auto).Error; err != nil {
+				return tls.Certificate{}, err
+			}
+			config.AutoCert = &auto
+			if !auto {
+				logger.Infof("the server certificate of %s was uploaded by the user, automatic management disabled", config.ServiceName)
+				return current, nil
+			}
+		}
+		switch {
+		case !issued:
+			reason = "not issued by the local authority"
+		case time.Until(leaf.NotAfter) < serverCertRenewBefore:
+			reason = "certificate expires soon"
+		case !coversNames(leaf, dnsNames, ips):
+			reason = "names or addresses changed"
+		default:
+			return current, nil
+		}
+	}
+	return s.issueServerCert(ca, config, hostname, dnsNames, ips, reason)
+}
+
+// RenewServerCertificate reissues the certificate of the HTTPS service described by config and enables the
+// automatic management.
+func (s *CertService) RenewServerCertificate(config *entity.HttpConfig) (tls.Certificate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ca, err := s.authority()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	hostname, dnsNames, ips := serverNames()
+	return s.issueServerCert(ca, config, hostname, dnsNames, ips, "renewal requested")
+}
+
+// issueServerCert issues and saves a new server certificate, s.mu must be held.
+func (s *CertService) issueServerCert(ca *secure.CertificateAuthority, config *entity.HttpConfig, hostname string, dnsNames []string, ips []net.IP, reason string) (tls.Certificate, error) {
+	_, certPEM, keyPEM, err := ca.IssueServerCert(hostname, dnsNames, ips, serverCertValidity)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	cert, err := secure.LoadX509KeyPairFromMemory(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	auto := true
+	config.AutoCert = &auto
+	config.Cer = base64.StdEncoding.EncodeToString(certPEM)
+	config.Key = base64.StdEncoding.EncodeToString(keyPEM)
+	err = s.db.Model(config).Updates(map[string]interface{}{"cer": config.Cer, "key": config.Key, "auto_cert": auto}).Error
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	logger.Infof("server certificate of %s issued (%s), fingerprint %s", config.ServiceName, reason, secure.Fingerprint(cert.Leaf))
+	return cert, nil
+}
+
+// CACertificate returns the certificate of the local authority.
+func (s *CertService) CACertificate() (*x509.Certificate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ca, err := s.authority()
+	if err != nil {
+		return nil, err
+	}
+	return ca.Cert, nil
+}
+
+// CertificateInfo describes cert for clients that pin it.
+func CertificateInfo(cert *x509.Certificate) *cert_schema.CertificateInfo {
+	info := &cert_schema.CertificateInfo{
+		Subject:     cert.Subject.String(),
+		Issuer:      cert.Issuer.String(),
+		Fingerprint: secure.Fingerprint(cert),
+		SpkiPin:     secure.SpkiPin(cert),
+		NotBefore:   cert.NotBefore,
+		NotAfter:    cert.NotAfter,
+		DNSNames:    cert.DNSNames,
+	}
+	for _, ip := range cert.IPAddresses {
+		info.IPAddresses = append(info.IPAddresses, ip.String())
+	}
+	return info
+}
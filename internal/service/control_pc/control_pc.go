@@ -1,15 +1,24 @@
 package control_pc
 
 import (
+	"fadacontrol/internal/base/events"
 	"fadacontrol/internal/base/exception"
 	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/base/metrics"
 	"fadacontrol/internal/schema"
 	"fadacontrol/internal/service/internal_master_service"
 	"fadacontrol/pkg/sys"
+	"sync"
 )
 
 type ControlPCService struct {
-	_im *internal_master_service.InternalMasterService
+	_im       *internal_master_service.InternalMasterService
+	pendingMu sync.Mutex
+	pending   map[string]*pendingAction
+	finished  []schema.PendingAction
+
+	powerCallbacks   []func(action string, tpe sys.ShutdownType)
+	powerCallbacksMu sync.RWMutex
 }
 
 func NewControlPCService(_im *internal_master_service.InternalMasterService) *ControlPCService {
@@ -17,15 +26,56 @@ func NewControlPCService(_im *internal_master_service.InternalMasterService) *Co
 
 }
 
+func requestAction(action string) {
+	events.Publish(events.ActionRequested, events.ActionData{Action: action})
+}
+
+// countAction counts a control action by its result, publishes the completion and passes the result through.
+func countAction(action string, ex *exception.Exception) *exception.Exception {
+	result := metrics.ResultSuccess
+	data := events.ActionData{Action: action, Success: true}
+	if ex != nil && exception.ErrSuccess.NotEqual(ex) {
+		result = metrics.ResultFailure
+		data = events.ActionData{Action: action, Code: ex.Code, Msg: ex.Msg}
+	}
+	metrics.ControlAction(action, result)
+	events.Publish(events.ActionCompleted, data)
+	return ex
+}
+
+// AddPowerActionCallback registers a callback that runs before every shutdown and standby, the action waits for it.
+func (control *ControlPCService) AddPowerActionCallback(callback func(action string, tpe sys.ShutdownType)) {
+	control.powerCallbacksMu.Lock()
+	defer control.powerCallbacksMu.Unlock()
+	control.powerCallbacks = append(control.powerCallbacks, callback)
+}
+
+func (control *ControlPCService) beforePowerAction(action string, tpe sys.ShutdownType) {
+	control.powerCallbacksMu.RLock()
+	defer control.powerCallbacksMu.RUnlock()
+	for _, callback := range control.powerCallbacks {
+		callback(action, tpe)
+	}
+}
+
 func (control *ControlPCService) Standby() *exception.Exception {
-	return sys.Standby()
+	requestAction("standby")
+	control.beforePowerAction(schema.PendingActionStandby, sys.Unknown)
+	return countAction("standby", sys.Standby())
 
 }
 func (control *ControlPCService) Shutdown(tpe sys.ShutdownType) *exception.Exception {
-	return sys.Shutdown(tpe)
+	requestAction("shutdown")
+	control.beforePowerAction(schema.PendingActionShutdown, tpe)
+	return countAction("shutdown", sys.Shutdown(tpe))
 }
 
 func (control *ControlPCService) LockWindows(useAgent bool) *exception.Exception {
+	requestAction("lock")
+	return countAction("lock", control.lockWindows(useAgent))
+}
+
+func (control *ControlPCService) lockWindows(useAgent bool) *exception.Exception {
 
 	logger.Debug("lock windows")
 	if !useAgent {
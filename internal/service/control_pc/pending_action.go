@@ -0,0 +1,193 @@
+package control_pc
+
+import (
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/schema"
+	"fadacontrol/pkg/goroutine"
+	"fadacontrol/pkg/sys"
+	"github.com/google/uuid"
+	"sort"
+	"time"
+)
+
+// maxFinishedActions is the number of finished or cancelled actions kept so callers can query the outcome.
+const maxFinishedActions = 32
+
+type pendingAction struct {
+	info  schema.PendingAction
+	timer *time.Timer
+}
+
+func (p *pendingAction) snapshot(now time.Time) schema.PendingAction {
+	info := p.info
+	if info.Status == schema.PendingStatusPending {
+		info.Remaining = int64(info.ExecuteAt.Sub(now).Round(time.Second) / time.Second)
+		if info.Remaining < 0 {
+			info.Remaining = 0
+		}
+	}
+	return info
+}
+
+// SchedulePowerAction registers a shutdown or standby that runs after delay.
+// The returned action can be cancelled or postponed until its timer fires.
+func (control *ControlPCService) SchedulePowerAction(action string, tpe sys.ShutdownType, delay time.Duration, requester, source string) (*schema.PendingAction, *exception.Exception) {
+	switch action {
+	case schema.PendingActionShutdown:
+	case schema.PendingActionStandby:
+		tpe = sys.Unknown
+	default:
+		return nil, exception.ErrUserParameterError
+	}
+	if delay < 0 {
+		return nil, exception.ErrUserParameterError
+	}
+	now := time.Now()
+	p := &pendingAction{info: schema.PendingAction{
+		Id:           uuid.New().String(),
+		Action:       action,
+		ShutdownType: int(tpe),
+		Requester:    requester,
+		Source:       source,
+		CreatedAt:    now,
+		ExecuteAt:    now.Add(delay),
+		Status:       schema.PendingStatusPending,
+	}}
+
+	control.pendingMu.Lock()
+	defer control.pendingMu.Unlock()
+	if control.pending == nil {
+		control.pending = make(map[string]*pendingAction)
+	}
+	control.pending[p.info.Id] = p
+	p.timer = time.AfterFunc(delay, func() {
+		goroutine.RecoverGO(func() {
+			control.runPendingAction(p.info.Id)
+		})
+	})
+	logger.Infof("%s scheduled by %s from %s, id %s, delay %v", action, requester, source, p.info.Id, delay)
+	info := p.snapshot(now)
+	return &info, nil
+}
+
+func (control *ControlPCService) runPendingAction(id string) {
+	control.pendingMu.Lock()
+	p, ok := control.pending[id]
+	if !ok || p.info.Status != schema.PendingStatusPending || time.Now().Before(p.info.ExecuteAt) {
+		// cancelled, or postponed after the timer had already fired
+		control.pendingMu.Unlock()
+		return
+	}
+	p.info.Status = schema.PendingStatusRunning
+	control.pendingMu.Unlock()
+
+	logger.Infof("running pending %s %s", p.info.Action, id)
+	var ex *exception.Exception
+	switch p.info.Action {
+	case schema.PendingActionShutdown:
+		ex = control.Shutdown(sys.ShutdownType(p.info.ShutdownType))
+	case schema.PendingActionStandby:
+		ex = control.Standby()
+	}
+	if ex == nil {
+		ex = exception.ErrSuccess
+	}
+
+	control.pendingMu.Lock()
+	defer control.pendingMu.Unlock()
+	p.info.Code = ex.Code
+	p.info.Msg = ex.Msg
+	p.info.Status = schema.PendingStatusExecuted
+	if ex.Code != exception.ErrSuccess.Code {
+		p.info.Status = schema.PendingStatusFailed
+		logger.Warnf("pending %s %s failed: %s", p.info.Action, id, ex.ToString())
+	}
+	control.finishLocked(p)
+}
+
+func (control *ControlPCService) finishLocked(p *pendingAction) {
+	p.info.Remaining = 0
+	delete(control.pending, p.info.Id)
+	control.finished = append(control.finished, p.info)
+	if len(control.finished) > maxFinishedActions {
+		control.finished = control.finished[len(control.finished)-maxFinishedActions:]
+	}
+}
+
+// ListPendingActions returns the actions that have not started yet, ordered by execution time.
+func (control *ControlPCService) ListPendingActions() []schema.PendingAction {
+	control.pendingMu.Lock()
+	defer control.pendingMu.Unlock()
+	now := time.Now()
+	ret := make([]schema.PendingAction, 0, len(control.pending))
+	for _, p := range control.pending {
+		ret = append(ret, p.snapshot(now))
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ExecuteAt.Before(ret[j].ExecuteAt)
+	})
+	return ret
+}
+
+// GetPendingAction looks up a pending action, or a recently finished one to report its outcome.
+func (control *ControlPCService) GetPendingAction(id string) (*schema.PendingAction, *exception.Exception) {
+	control.pendingMu.Lock()
+	defer control.pendingMu.Unlock()
+	if p, ok := control.pending[id]; ok {
+		info := p.snapshot(time.Now())
+		return &info, nil
+	}
+	for i := len(control.finished) - 1; i >= 0; i-- {
+		if control.finished[i].Id == id {
+			info := control.finished[i]
+			return &info, nil
+		}
+	}
+	return nil, exception.ErrUserResourceNotFound
+}
+
+func (control *ControlPCService) CancelPendingAction(id string) (*schema.PendingAction, *exception.Exception) {
+	control.pendingMu.Lock()
+	defer control.pendingMu.Unlock()
+	p, ok := control.pending[id]
+	if !ok {
+		return nil, exception.ErrUserResourceNotFound
+	}
+	if p.info.Status != schema.PendingStatusPending {
+		return nil, exception.ErrUserPendingActionAlreadyStarted
+	}
+	p.timer.Stop()
+	p.info.Status = schema.PendingStatusCancelled
+	p.info.Code = exception.ErrSuccess.Code
+	p.info.Msg = "cancelled"
+	control.finishLocked(p)
+	logger.Infof("pending %s %s cancelled", p.info.Action, id)
+	info := p.info
+	return &info, nil
+}
+
+// PostponePendingAction moves the execution time of a pending action later by delay.
+func (control *ControlPCService) PostponePendingAction(id string, delay time.Duration) (*schema.PendingAction, *exception.Exception) {
+	if delay <= 0 {
+		return nil, exception.ErrUserParameterError
+	}
+	control.pendingMu.Lock()
+	defer control.pendingMu.Unlock()
+	p, ok := control.pending[id]
+	if !ok {
+		return nil, exception.ErrUserResourceNotFound
+	}
+	if p.info.Status != schema.PendingStatusPending {
+		return nil, exception.ErrUserPendingActionAlreadyStarted
+	}
+	now := time.Now()
+	if p.info.ExecuteAt.Before(now) {
+		p.info.ExecuteAt = now
+	}
+	p.info.ExecuteAt = p.info.ExecuteAt.Add(delay)
+	p.timer.Reset(p.info.ExecuteAt.Sub(now))
+	logger.Infof("pending %s %s postponed to %v", p.info.Action, id, p.info.ExecuteAt)
+	info := p.snapshot(now)
+	return &info, nil
+}
@@ -0,0 +1,124 @@
+package control_pc
+
+import (
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/schema"
+	"fadacontrol/pkg/sys"
+	"testing"
+	"time"
+)
+
+// the actions are scheduled far enough in the future that their timers never fire during the tests
+const testDelay = time.Hour
+
+func TestCancelPendingAction(t *testing.T) {
+	control := NewControlPCService(nil)
+	info, ex := control.SchedulePowerAction(schema.PendingActionShutdown, sys.S_E_FORCE_SHUTDOWN, testDelay, "alice", schema.PendingSourceHttp)
+	if ex != nil {
+		t.Fatal(ex.ToString())
+	}
+	if info.Status != schema.PendingStatusPending || info.Remaining != int64(testDelay/time.Second) {
+		t.Fatalf("unexpected action %+v", info)
+	}
+	if list := control.ListPendingActions(); len(list) != 1 || list[0].Id != info.Id {
+		t.Fatalf("unexpected pending actions %+v", list)
+	}
+
+	cancelled, ex := control.CancelPendingAction(info.Id)
+	if ex != nil {
+		t.Fatal(ex.ToString())
+	}
+	if cancelled.Status != schema.PendingStatusCancelled {
+		t.Errorf("got status %s, want cancelled", cancelled.Status)
+	}
+	if list := control.ListPendingActions(); len(list) != 0 {
+		t.Errorf("the cancelled action is still pending: %+v", list)
+	}
+	got, ex := control.GetPendingAction(info.Id)
+	if ex != nil || got.Status != schema.PendingStatusCancelled {
+		t.Errorf("the outcome of the cancelled action is not kept: %+v %v", got, ex)
+	}
+	if _, ex := control.CancelPendingAction(info.Id); ex != exception.ErrUserResourceNotFound {
+		t.Errorf("cancelling twice: got %v", ex)
+	}
+	if _, ex := control.PostponePendingAction(info.Id, time.Minute); ex != exception.ErrUserResourceNotFound {
+		t.Errorf("postponing a cancelled action: got %v", ex)
+	}
+
+	// a timer that already fired does not run the cancelled action
+	control.runPendingAction(info.Id)
+	if got, _ := control.GetPendingAction(info.Id); got.Status != schema.PendingStatusCancelled {
+		t.Errorf("got status %s after the timer fired", got.Status)
+	}
+}
+
+func TestPostponePendingAction(t *testing.T) {
+	control := NewControlPCService(nil)
+	info, ex := control.SchedulePowerAction(schema.PendingActionStandby, sys.S_E_FORCE_SHUTDOWN, testDelay, "alice", schema.PendingSourceRemote)
+	if ex != nil {
+		t.Fatal(ex.ToString())
+	}
+	if info.ShutdownType != int(sys.Unknown) {
+		t.Errorf("the shutdown type of a standby is %d", info.ShutdownType)
+	}
+	defer control.CancelPendingAction(info.Id)
+
+	postponed, ex := control.PostponePendingAction(info.Id, 10*time.Minute)
+	if ex != nil {
+		t.Fatal(ex.ToString())
+	}
+	if !postponed.ExecuteAt.Equal(info.ExecuteAt.Add(10 * time.Minute)) {
+		t.Errorf("got execution time %v, want %v", postponed.ExecuteAt, info.ExecuteAt.Add(10*time.Minute))
+	}
+	if postponed.Status != schema.PendingStatusPending {
+		t.Errorf("got status %s, want pending", postponed.Status)
+	}
+
+	// a timer that fires before the postponed time does not run the action
+	control.runPendingAction(info.Id)
+	if got, _ := control.GetPendingAction(info.Id); got.Status != schema.PendingStatusPending {
+		t.Errorf("got status %s before the postponed time", got.Status)
+	}
+
+	for _, delay := range []time.Duration{0, -time.Minute} {
+		if _, ex := control.PostponePendingAction(info.Id, delay); ex != exception.ErrUserParameterError {
+			t.Errorf("postponing by %v: got %v", delay, ex)
+		}
+	}
+	if _, ex := control.PostponePendingAction("unknown", time.Minute); ex != exception.ErrUserResourceNotFound {
+		t.Errorf("postponing an unknown action: got %v", ex)
+	}
+}
+
+func TestPendingActionStarted(t *testing.T) {
+	control := NewControlPCService(nil)
+	info, ex := control.SchedulePowerAction(schema.PendingActionShutdown, sys.S_E_FORCE_SHUTDOWN, testDelay, "alice", schema.PendingSourceHttp)
+	if ex != nil {
+		t.Fatal(ex.ToString())
+	}
+	control.pendingMu.Lock()
+	p := control.pending[info.Id]
+	p.timer.Stop()
+	p.info.Status = schema.PendingStatusRunning
+	control.pendingMu.Unlock()
+
+	if _, ex := control.CancelPendingAction(info.Id); ex != exception.ErrUserPendingActionAlreadyStarted {
+		t.Errorf("cancelling a running action: got %v", ex)
+	}
+	if _, ex := control.PostponePendingAction(info.Id, time.Minute); ex != exception.ErrUserPendingActionAlreadyStarted {
+		t.Errorf("postponing a running action: got %v", ex)
+	}
+}
+
+func TestSchedulePowerActionInvalid(t *testing.T) {
+	control := NewControlPCService(nil)
+	if _, ex := control.SchedulePowerAction("reboot", sys.S_E_FORCE_SHUTDOWN, testDelay, "alice", schema.PendingSourceHttp); ex != exception.ErrUserParameterError {
+		t.Errorf("unknown action: got %v", ex)
+	}
+	if _, ex := control.SchedulePowerAction(schema.PendingActionShutdown, sys.S_E_FORCE_SHUTDOWN, -time.Second, "alice", schema.PendingSourceHttp); ex != exception.ErrUserParameterError {
+		t.Errorf("negative delay: got %v", ex)
+	}
+	if list := control.ListPendingActions(); len(list) != 0 {
+		t.Errorf("invalid actions were scheduled: %+v", list)
+	}
+}
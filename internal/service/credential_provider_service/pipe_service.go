@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/health"
 	"fadacontrol/internal/base/logger"
 	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/health_schema"
 	"fadacontrol/pkg/goroutine"
 	"fadacontrol/pkg/sys"
 	"fmt"
@@ -31,6 +33,7 @@ type CredentialProviderService struct {
 	respMap  map[uint32]uint32
 
 	reqCond *sync.Cond
+	state   health.State
 }
 
 func NewCredentialProviderService(db *gorm.DB) *CredentialProviderService {
@@ -114,13 +117,26 @@ func (p *CredentialProviderService) getResp(reqId uint32, timeout time.Duration)
 func (p *CredentialProviderService) Start() {
 
 	goroutine.RecoverGO(func() {
-		err := sys.ListenNamedPipeWithHandler(FCPipeName, p.PipeHandler, pipeCacheSize, pipeCacheSize)
+		listener, err := sys.NewNamedPipeListener(FCPipeName, pipeCacheSize, pipeCacheSize)
 		if err != nil {
 			logger.Error(err.Error())
+			p.state.Set(health_schema.StatusDown, err)
+			return
+		}
+		p.state.Set(health_schema.StatusUp, nil)
+		err = sys.ServeNamedPipe(listener, p.PipeHandler)
+		if err != nil {
+			logger.Error(err.Error())
+			p.state.Set(health_schema.StatusDown, err)
 		}
 	})
 
 }
+
+// Health returns the state of the pipe the credential provider connects to.
+func (p *CredentialProviderService) Health() health_schema.ComponentHealth {
+	return p.state.Component("credential_pipe")
+}
 func (p *CredentialProviderService) GenReqId() uint32 {
 	return atomic.AddUint32(&p.reqId, 1)
 
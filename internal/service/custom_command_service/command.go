@@ -2,30 +2,81 @@ package custom_command_service
 
 import (
 	"context"
+	"errors"
 	"fadacontrol/internal/base/conf"
 	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/exception"
 	"fadacontrol/internal/base/logger"
 	"fadacontrol/internal/schema/custom_command_schema"
+	"fadacontrol/internal/service/auth_service"
 	"fadacontrol/pkg/goroutine"
 	"fadacontrol/pkg/utils"
 	"fmt"
 	"gopkg.in/yaml.v3"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
 )
 
 type cmdConfig struct {
 	Commands []custom_command_schema.Command `yaml:"commands"`
 }
+type commandState struct {
+	running   int
+	lastStart time.Time
+}
+
+// loadedConfig is a parsed command config file and the size and modification time it had when it was read.
+type loadedConfig struct {
+	modTime time.Time
+	size    int64
+	cmds    map[string]custom_command_schema.Command
+}
 type CustomCommandService struct {
-	ctx context.Context
+	auth    *auth_service.AuthService
+	ctx     context.Context
+	mu      sync.Mutex
+	states  map[string]*commandState
+	configs map[string]*loadedConfig
 }
 
-func NewCustomCommandService(ctx context.Context) *CustomCommandService {
-	return &CustomCommandService{ctx: ctx}
+func NewCustomCommandService(auth *auth_service.AuthService, ctx context.Context) *CustomCommandService {
+	return &CustomCommandService{auth: auth, ctx: ctx, states: make(map[string]*commandState), configs: make(map[string]*loadedConfig)}
 }
+
+// ReadConfig returns the commands of the config file at filePath. The file is parsed again only when it changed, and
+// the policies of its commands then replace the ones loaded from it before.
 func (u *CustomCommandService) ReadConfig(filePath string) (map[string]custom_command_schema.Command, error) {
-	data, err := os.ReadFile(filePath)
+	path, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if loaded, ok := u.configs[path]; ok && loaded.modTime.Equal(info.ModTime()) && loaded.size == info.Size() {
+		return loaded.cmds, nil
+	}
+	cmds, err := parseConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if u.auth != nil {
+		if err := u.auth.SetCommandRules(path, cmds); err != nil {
+			return nil, err
+		}
+	}
+	u.configs[path] = &loadedConfig{modTime: info.ModTime(), size: info.Size(), cmds: cmds}
+	return cmds, nil
+}
+func parseConfig(path string) (map[string]custom_command_schema.Command, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -38,6 +89,7 @@ func (u *CustomCommandService) ReadConfig(filePath string) (map[string]custom_co
 
 	ret := make(map[string]custom_command_schema.Command, len(config.Commands))
 	for _, command := range config.Commands {
+		command.Path = path
 		ret[command.Name] = command
 	}
 
@@ -51,25 +103,114 @@ func (u *CustomCommandService) ExecuteCommand(cmd custom_command_schema.Command,
 
 	return nil
 }
-func (u *CustomCommandService) executeCommand(cmd custom_command_schema.Command, stdout, stderr *custom_command_schema.CustomWriter) error {
+
+// acquire reserves an execution slot for cmd, honouring MaxConcurrent and Cooldown.
+func (u *CustomCommandService) acquire(cmd custom_command_schema.Command) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	state, ok := u.states[cmd.Name]
+	if !ok {
+		state = &commandState{}
+		u.states[cmd.Name] = state
+	}
+	if cmd.MaxConcurrent > 0 && state.running >= cmd.MaxConcurrent {
+		return exception.ErrUserCommandConcurrencyLimit
+	}
+	if cmd.Cooldown > 0 && !state.lastStart.IsZero() && time.Since(state.lastStart) < cmd.Cooldown {
+		return exception.ErrUserCommandCoolingDown
+	}
+	state.running++
+	state.lastStart = time.Now()
+	return nil
+}
+func (u *CustomCommandService) release(cmd custom_command_schema.Command) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if state, ok := u.states[cmd.Name]; ok && state.running > 0 {
+		state.running--
+	}
+}
+
+// Running returns the number of running instances of the named command.
+func (u *CustomCommandService) Running(name string) int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if state, ok := u.states[name]; ok {
+		return state.running
+	}
+	return 0
+}
+
+// RunCommand runs cmd to completion and returns its exit code. Output is discarded when output is nil.
+func (u *CustomCommandService) RunCommand(ctx context.Context, cmd custom_command_schema.Command, output io.Writer) (int, error) {
+	_conf := utils.GetValueFromContext(u.ctx, constants.ConfKey, conf.NewDefaultConf())
+	if _conf.StartMode != conf.CommonMode && _conf.StartMode != conf.SlaveMode {
+		return -1, exception.ErrSystemCommandUnavailableInServiceMode
+	}
+	if err := u.acquire(cmd); err != nil {
+		logger.Warnf("Command %s rejected: %v", cmd.Name, err)
+		return -1, err
+	}
+	defer u.release(cmd)
+
+	command := buildCommand(cmd)
+	command.Stdout = output
+	command.Stderr = output
+	logger.Debugf("Running command: %s", cmd.Name)
+	if err := startWithLimits(command, cmd.Limits); err != nil {
+		logger.Warnf("Command %s failed with error: %v", cmd.Name, err)
+		return -1, err
+	}
+	done := make(chan error, 1)
+	goroutine.RecoverGO(func() {
+		done <- command.Wait()
+	})
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		_ = command.Process.Kill()
+		<-done
+		return -1, ctx.Err()
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		return -1, err
+	}
+	return 0, nil
+}
+func buildCommand(cmd custom_command_schema.Command) *exec.Cmd {
 	command := exec.Command(cmd.Cmd, cmd.Args...)
 
 	for key, value := range cmd.Env {
 		command.Env = append(command.Env, fmt.Sprintf("%s=%s", key, value))
 	}
 	command.Dir = cmd.WorkDir
+	return command
+}
+func (u *CustomCommandService) executeCommand(cmd custom_command_schema.Command, stdout, stderr *custom_command_schema.CustomWriter) error {
+	if err := u.acquire(cmd); err != nil {
+		logger.Warnf("Command %s rejected: %v", cmd.Name, err)
+		return err
+	}
+	command := buildCommand(cmd)
 	command.Stdout = stdout
 	command.Stderr = stderr
 
 	logger.Debugf("Executing command: %s", cmd.Name)
 
-	if err := command.Start(); err != nil {
+	if err := startWithLimits(command, cmd.Limits); err != nil {
+		u.release(cmd)
 		logger.Warnf("Command %s failed with error: %v", cmd.Name, err)
 		return err
 	} else {
 		logger.Warnf("Command %s executed successfully.", cmd.Name)
 	}
 	goroutine.RecoverGO(func() {
+		defer u.release(cmd)
 		if err := command.Wait(); err != nil {
 
 			logger.Warnf("Command %s failed with error: %v", cmd.Name, err)
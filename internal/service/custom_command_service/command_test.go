@@ -0,0 +1,76 @@
+package custom_command_service
+
+import (
+	"context"
+	"errors"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/schema/custom_command_schema"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	u := NewCustomCommandService(nil, context.Background())
+	cmd := custom_command_schema.Command{Name: "backup", MaxConcurrent: 2}
+	for i := 0; i < 2; i++ {
+		if err := u.acquire(cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := u.acquire(cmd); !errors.Is(err, exception.ErrUserCommandConcurrencyLimit) {
+		t.Fatalf("got %v, want the concurrency limit", err)
+	}
+	u.release(cmd)
+	if u.Running("backup") != 1 {
+		t.Fatalf("got %d running", u.Running("backup"))
+	}
+	if err := u.acquire(cmd); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquireCooldown(t *testing.T) {
+	u := NewCustomCommandService(nil, context.Background())
+	cmd := custom_command_schema.Command{Name: "backup", Cooldown: 50 * time.Millisecond}
+	if err := u.acquire(cmd); err != nil {
+		t.Fatal(err)
+	}
+	u.release(cmd)
+	if err := u.acquire(cmd); !errors.Is(err, exception.ErrUserCommandCoolingDown) {
+		t.Fatalf("got %v, want cooling down", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if err := u.acquire(cmd); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadConfig(t *testing.T) {
+	u := NewCustomCommandService(nil, context.Background())
+	path := filepath.Join(t.TempDir(), "commands.yml")
+	if err := os.WriteFile(path, []byte("commands:\n  - name: backup\n    cmd: tar\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmds, err := u.ReadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmds["backup"].Cmd != "tar" {
+		t.Fatalf("unexpected commands %v", cmds)
+	}
+	if err := os.WriteFile(path, []byte("commands:\n  - name: backup\n    cmd: rsync\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, time.Now(), time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	cmds, err = u.ReadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmds["backup"].Cmd != "rsync" {
+		t.Errorf("the changed file was not loaded again, got %v", cmds)
+	}
+}
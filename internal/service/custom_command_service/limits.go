@@ -0,0 +1,16 @@
+package custom_command_service
+
+import (
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/schema/custom_command_schema"
+	"fadacontrol/pkg/limits"
+	"os/exec"
+)
+
+// startWithLimits starts command with its resource limits, they are only supported on linux.
+func startWithLimits(command *exec.Cmd, l custom_command_schema.ResourceLimits) error {
+	if !limits.Supported && !l.IsEmpty() {
+		logger.Warn("resource limits are only supported on linux, ignored")
+	}
+	return limits.Start(command, l)
+}
@@ -0,0 +1,55 @@
+package custom_command_service
+
+import (
+	"errors"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/schema/custom_command_schema"
+	"fadacontrol/pkg/goroutine"
+	"fadacontrol/pkg/pty"
+	"os"
+)
+
+const ShellCommandName = "shell"
+
+// DefaultShellCommand returns the login shell of the service account.
+func DefaultShellCommand() custom_command_schema.Command {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return custom_command_schema.Command{
+		Name:          ShellCommandName,
+		Cmd:           shell,
+		MaxConcurrent: conf.MaxTerminalSessions,
+	}
+}
+
+// StartTerminal starts cmd in a pseudo-terminal of the given size.
+func (u *CustomCommandService) StartTerminal(cmd custom_command_schema.Command, rows, cols uint16) (*pty.Terminal, error) {
+	if err := u.acquire(cmd); err != nil {
+		logger.Warnf("Terminal %s rejected: %v", cmd.Name, err)
+		return nil, err
+	}
+	command := buildCommand(cmd)
+	if command.Env == nil {
+		command.Env = os.Environ()
+	}
+	command.Env = append(command.Env, "TERM=xterm-256color")
+
+	t, err := pty.Start(command, cmd.Limits, rows, cols)
+	if err != nil {
+		u.release(cmd)
+		logger.Warnf("Terminal %s failed with error: %v", cmd.Name, err)
+		if errors.Is(err, errors.ErrUnsupported) {
+			return nil, exception.ErrUserOperationNotSupportedOnOS
+		}
+		return nil, err
+	}
+	goroutine.RecoverGO(func() {
+		defer u.release(cmd)
+		logger.Debugf("Terminal %s exited with code %d", cmd.Name, t.ExitCode())
+	})
+	return t, nil
+}
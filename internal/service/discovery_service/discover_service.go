@@ -2,9 +2,16 @@ package discovery_service
 
 import (
 	"context"
+	"errors"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/events"
+	"fadacontrol/internal/base/health"
 	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/base/metrics"
 	"fadacontrol/internal/entity"
 	"fadacontrol/internal/schema"
+	"fadacontrol/internal/schema/health_schema"
 	"fadacontrol/pkg/goroutine"
 	"fadacontrol/pkg/utils"
 	"fadacontrol/pkg/utils/cache"
@@ -31,6 +38,7 @@ type DiscoverService struct {
 	StartLock             sync.Mutex
 	StopLock              sync.Mutex
 	RestartLock           sync.Mutex
+	state                 health.State
 }
 
 const udpSendInterval = 2 * time.Second
@@ -52,6 +60,11 @@ func NewDiscoverService(db *gorm.DB, ctx context.Context) *DiscoverService {
 	return &d
 }
 
+// Health returns the state of the UDP listener.
+func (d *DiscoverService) Health() health_schema.ComponentHealth {
+	return d.state.Component("discovery")
+}
+
 func (d *DiscoverService) GetDiscoverConfig() (*schema.DiscoverSchema, error) {
 	var config entity.DiscoverConfig
 	err := d._db.First(&config).Error
@@ -62,6 +75,10 @@ func (d *DiscoverService) GetDiscoverConfig() (*schema.DiscoverSchema, error) {
 }
 
 func (d *DiscoverService) PatchDiscoverServiceConfig(content map[string]interface{}) error {
+	_conf := utils.GetValueFromContext(d.ctx, constants.ConfKey, conf.NewDefaultConf())
+	if err := conf.CheckManaged(_conf.ManagedDiscoveryFields(), content); err != nil {
+		return err
+	}
 
 	var config entity.DiscoverConfig
 	if err := d._db.First(&config).Error; err != nil {
@@ -70,7 +87,7 @@ func (d *DiscoverService) PatchDiscoverServiceConfig(content map[string]interfac
 	if err := d._db.Model(&config).Updates(content).Error; err != nil {
 		return err
 	}
-	return nil
+	return events.PublishConfigChanged(events.SectionDiscovery, "", nil)
 
 }
 
@@ -86,9 +103,11 @@ func (d *DiscoverService) listenAndSend(port int) {
 		conn, err := net.ListenUDP("udp", &addr)
 		if err != nil {
 			logger.Warn("Error listening:", err.Error())
+			d.state.Set(health_schema.StatusDown, err)
 			return
 		}
 		d.ListenConn = conn
+		d.state.Set(health_schema.StatusUp, nil)
 
 		logger.Info("Listening on port: ", port)
 		buffer := make([]byte, 1024)
@@ -97,18 +116,23 @@ func (d *DiscoverService) listenAndSend(port int) {
 			if err != nil {
 				if isClosedConnError(err) {
 					logger.Warn("Udp Connection closed:")
+					if d.discoverServiceCtx.Err() == nil {
+						d.state.Set(health_schema.StatusDown, errors.New("the UDP connection was closed"))
+					}
 					return
 				}
 				logger.Warn("Error reading from UDP:", err)
-				conn.Close()
 				continue
 			}
 
 			logger.Debugf("Received message from %s: %s", remoteAddr, string(buffer[:n]))
+			metrics.DiscoveryPacket(metrics.DirectionReceived)
 
 			err = conn.SetWriteDeadline(time.Now().Add(connTimeout))
 			if err != nil {
 				fmt.Println("SetWriteDeadline failed:", err)
+				// listen again on a new connection
+				conn.Close()
 				break
 			}
 			_, err = conn.WriteToUDP([]byte(d.hostname), remoteAddr)
@@ -117,8 +141,8 @@ func (d *DiscoverService) listenAndSend(port int) {
 				logger.Warn("Error sending response:", err)
 			} else {
 				logger.Warnf("Sent udp data to clinet: %s", remoteAddr)
+				metrics.DiscoveryPacket(metrics.DirectionSent)
 			}
-			conn.Close()
 		}
 
 	}
@@ -133,6 +157,7 @@ func (d *DiscoverService) StopService() error {
 	if d.ListenConn != nil {
 		d.ListenConn.Close()
 	}
+	d.state.Set(health_schema.StatusStopped, nil)
 	logger.Info("The UDP service service is stopped")
 	return nil
 }
@@ -275,6 +300,7 @@ func (d *DiscoverService) sendUdp(ip net.IP) {
 		logger.Debug(err)
 		return
 	}
+	metrics.DiscoveryPacket(metrics.DirectionSent)
 
 }
 func (d *DiscoverService) readConfig() {
@@ -291,8 +317,10 @@ func (d *DiscoverService) StartService() {
 	d.discoverServiceCtx, d.discoverServiceCancel = context.WithCancel(d.ctx)
 	d.readConfig()
 	if d.config.Enabled == false {
+		d.state.Set(health_schema.StatusDisabled, nil)
 		return
 	}
+	d.state.Set(health_schema.StatusStarting, nil)
 
 	logger.Info("starting discovery service")
 	d.StartBroadcast()
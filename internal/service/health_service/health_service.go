@@ -0,0 +1,75 @@
+package health_service
+
+import (
+	"context"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/health"
+	"fadacontrol/internal/schema/health_schema"
+	"fadacontrol/internal/service/credential_provider_service"
+	"fadacontrol/internal/service/discovery_service"
+	"fadacontrol/internal/service/http_service"
+	"fadacontrol/internal/service/internal_master_service"
+	"fadacontrol/internal/service/remote_service"
+	"fadacontrol/pkg/utils"
+	"gorm.io/gorm"
+	"time"
+)
+
+// dbPingTimeout bounds the database check so that a locked database does not hang the probes.
+const dbPingTimeout = 2 * time.Second
+
+type HealthService struct {
+	ctx     context.Context
+	db      *gorm.DB
+	_http   *http_service.HttpService
+	dis     *discovery_service.DiscoverService
+	re      *remote_service.RemoteService
+	cp      *credential_provider_service.CredentialProviderService
+	master  *internal_master_service.InternalMasterService
+	dbState health.State
+}
+
+func NewHealthService(ctx context.Context, db *gorm.DB, _http *http_service.HttpService, dis *discovery_service.DiscoverService, re *remote_service.RemoteService, cp *credential_provider_service.CredentialProviderService, master *internal_master_service.InternalMasterService) *HealthService {
+	return &HealthService{ctx: ctx, db: db, _http: _http, dis: dis, re: re, cp: cp, master: master}
+}
+
+func (h *HealthService) checkDatabase() health_schema.ComponentHealth {
+	sqlDB, err := h.db.DB()
+	if err == nil {
+		ctx, cancel := context.WithTimeout(h.ctx, dbPingTimeout)
+		defer cancel()
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		h.dbState.Set(health_schema.StatusDown, err)
+	} else {
+		h.dbState.Set(health_schema.StatusUp, nil)
+	}
+	return h.dbState.Component("database")
+}
+
+// Components returns the state of the database and of the services started by the bootstraps, the rpc server
+// of the slave programs only runs in service mode.
+func (h *HealthService) Components() []health_schema.ComponentHealth {
+	components := []health_schema.ComponentHealth{h.checkDatabase()}
+	components = append(components, h._http.Health()...)
+	components = append(components, h.dis.Health(), h.re.Health(), h.cp.Health())
+	_conf := utils.GetValueFromContext(h.ctx, constants.ConfKey, conf.NewDefaultConf())
+	if _conf.StartMode == conf.ServiceMode {
+		components = append(components, h.master.Health())
+	}
+	return components
+}
+
+// Liveness reports whether the service works, it fails when a component is down.
+func (h *HealthService) Liveness() *health_schema.HealthResponse {
+	components := h.Components()
+	return &health_schema.HealthResponse{Status: health.Liveness(components), Components: components}
+}
+
+// Readiness reports whether the service accepts requests, it fails until every enabled component is up.
+func (h *HealthService) Readiness() *health_schema.HealthResponse {
+	components := h.Components()
+	return &health_schema.HealthResponse{Status: health.Readiness(components), Components: components}
+}
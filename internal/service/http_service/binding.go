@@ -0,0 +1,309 @@
+package http_service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/router"
+	"fadacontrol/internal/schema/health_schema"
+	"fadacontrol/internal/schema/http_schema"
+	"fadacontrol/pkg/goroutine"
+	"fadacontrol/pkg/secure"
+	"fmt"
+	"golang.org/x/net/context"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type runningServer struct {
+	config entity.HttpConfig
+	// addr is the address of the listener.
+	addr   string
+	tls    bool
+	http3  bool
+	since  time.Time
+	cancel context.CancelFunc
+	// done is closed when the server released its address.
+	done <-chan struct{}
+	// drained is closed when the requests in flight were completed or the connections were closed.
+	drained <-chan struct{}
+}
+
+// serverDrainTimeout is how long a stopped server completes the requests in flight before the connections are closed.
+const serverDrainTimeout = 10 * time.Second
+
+// serverStopTimeout is how long a restart waits for the old server to release its address, the UDP socket of
+// HTTP/3 is released after the connections drained.
+const serverStopTimeout = serverDrainTimeout + time.Second
+
+// listenAddress is the address a server binds, the HTTPS service listens on all the interfaces.
+func listenAddress(host string, port int, secure bool) string {
+	if secure {
+		return fmt.Sprintf(":%d", port)
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// sameBinding reports whether a server started with a uses the configuration b.
+func sameBinding(a, b *entity.HttpConfig) bool {
+	autoA := a.AutoCert != nil && *a.AutoCert
+	autoB := b.AutoCert != nil && *b.AutoCert
+	return sameListener(a, b) && a.Cer == b.Cer && a.Key == b.Key && autoA == autoB
+}
+
+// sameListener reports whether a server started with a can keep its listener for the configuration b, the
+// certificate is replaced without binding the address again.
+func sameListener(a, b *entity.HttpConfig) bool {
+	return a.Enable == b.Enable && a.Host == b.Host && a.Port == b.Port && a.EnableHttp3 == b.EnableHttp3 &&
+		a.ClientAuthMode == b.ClientAuthMode
+}
+
+func (s *HttpService) routerOf(serviceName string) router.FadaControlRouter {
+	s.serversLock.Lock()
+	defer s.serversLock.Unlock()
+	switch serviceName {
+	case HttpServiceAdmin:
+		return s.adminRouter
+	case HttpServiceApi, HttpsServiceApi:
+		return s.commonRouter
+	}
+	return nil
+}
+
+// loadServerCert returns the certificate of the HTTPS service, a temporary self-signed one is used when it can't
+// be loaded.
+func (s *HttpService) loadServerCert(config *entity.HttpConfig) (tls.Certificate, error) {
+	cert, err := s.cs.ServerCertificate(config)
+	if err == nil {
+		return cert, nil
+	}
+	logger.Errorf("failed to load the server certificate, using a temporary self-signed one: %v", err)
+	_cert, _key, err := secure.GenerateX509Cert()
+	if err != nil {
+		logger.Error("gen key err", err)
+		return tls.Certificate{}, err
+	}
+	cert, err = secure.LoadX509KeyPairFromMemory(_cert, _key)
+	if err != nil {
+		logger.Error(err)
+		return tls.Certificate{}, err
+	}
+	return cert, nil
+}
+
+// bind listens on the address of config and serves the routes of r until the cancel of the returned server is
+// called.
+func (s *HttpService) bind(serviceName string, r router.FadaControlRouter, config entity.HttpConfig) (*runningServer, error) {
+	state := s.serverState(serviceName)
+	var getCert func(*tls.ClientHelloInfo) (*tls.Certificate, error)
+	clientAuth := tls.NoClientCert
+	var clientCAs *x509.CertPool
+	enableQuic := false
+	var prevCert *tls.Certificate
+	if serviceName == HttpsServiceApi {
+		cert, err := s.loadServerCert(&config)
+		if err != nil {
+			state.Set(health_schema.StatusDown, err)
+			return nil, err
+		}
+		clientAuth, clientCAs, err = s.clientAuthConfig(config.ClientAuthMode)
+		if err != nil {
+			logger.Errorf("failed to load the client certificate authority: %v", err)
+			state.Set(health_schema.StatusDown, err)
+			return nil, err
+		}
+		prevCert = s.serverCert.Swap(&cert)
+		getCert = s.getServerCert
+		s.certWatchOnce.Do(func() {
+			goroutine.RecoverGO(s.watchServerCert)
+		})
+		enableQuic = config.EnableHttp3
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	srv, err := startHttpServer(config.Host, config.Port, getCert, clientAuth, clientCAs, r.GetRouter(), enableQuic, ctx, state)
+	if err != nil {
+		cancel()
+		if serviceName == HttpsServiceApi {
+			s.serverCert.Store(prevCert)
+		}
+		return nil, err
+	}
+	srv.config = config
+	srv.cancel = cancel
+	srv.since = time.Now()
+	if serviceName == HttpsServiceApi {
+		conf.Http3Enabled = srv.http3
+		conf.Http3Port = config.Port
+	}
+	return srv, nil
+}
+
+func (s *HttpService) setRunning(serviceName string, srv *runningServer) {
+	s.serversLock.Lock()
+	defer s.serversLock.Unlock()
+	s.running[serviceName] = srv
+}
+
+// stop closes the listener of the server, the requests in flight are completed in the background.
+func (s *HttpService) stop(serviceName string, srv *runningServer) error {
+	srv.cancel()
+	s.serversLock.Lock()
+	if s.running[serviceName] == srv {
+		delete(s.running, serviceName)
+	}
+	s.serversLock.Unlock()
+	select {
+	case <-srv.done:
+		return nil
+	case <-time.After(serverStopTimeout):
+		return fmt.Errorf("timed out stopping the %s server", serviceName)
+	}
+}
+
+// restoreConfig saves the configuration of the running server back to the database after the new one could not
+// be applied.
+func (s *HttpService) restoreConfig(srv *runningServer, cause error) error {
+	config := srv.config
+	err := s._db.Model(&config).
+		Select("enable", "host", "port", "cer", "key", "enable_http3", "client_auth_mode", "auto_cert").
+		Updates(&config).Error
+	if err != nil {
+		logger.Errorf("failed to restore the configuration of the %s server: %v", config.ServiceName, err)
+		return fmt.Errorf("failed to apply the configuration of the %s server: %v", config.ServiceName, cause)
+	}
+	logger.Warnf("restored the configuration of the %s server: %v", config.ServiceName, cause)
+	return fmt.Errorf("failed to apply the configuration of the %s server, the previous one was restored: %v", config.ServiceName, cause)
+}
+
+// RestartServer applies the configuration of the service in the database to its server, applied is false when the
+// running server already uses it. A new address is bound before the old server stops and the requests in flight are
+// completed by the old server. When the new configuration can't be bound the previous one is restored.
+func (s *HttpService) RestartServer(serviceName string) (applied bool, err error) {
+	s.restartLock.Lock()
+	defer s.restartLock.Unlock()
+	r := s.routerOf(serviceName)
+	if r == nil {
+		// the configuration is used when the server starts
+		return false, nil
+	}
+	var config entity.HttpConfig
+	if err := s._db.Where(&entity.HttpConfig{ServiceName: serviceName}).First(&config).Error; err != nil {
+		return false, err
+	}
+	s.serversLock.Lock()
+	old := s.running[serviceName]
+	s.serversLock.Unlock()
+	state := s.serverState(serviceName)
+
+	switch {
+	case old == nil && !config.Enable, old != nil && sameBinding(&old.config, &config):
+		return false, nil
+	case old == nil:
+		return true, s.start(r, serviceName)
+	case !config.Enable:
+		logger.Infof("stopping the %s server", serviceName)
+		if err := s.stop(serviceName, old); err != nil {
+			return false, err
+		}
+		if serviceName == HttpsServiceApi {
+			conf.Http3Enabled = false
+		}
+		state.Set(health_schema.StatusDisabled, nil)
+		return true, nil
+	case sameListener(&old.config, &config):
+		if serviceName == HttpsServiceApi {
+			cert, err := s.loadServerCert(&config)
+			if err != nil {
+				return false, s.restoreConfig(old, err)
+			}
+			s.serverCert.Store(&cert)
+		}
+		s.serversLock.Lock()
+		old.config = config
+		s.serversLock.Unlock()
+		logger.Infof("replaced the certificate of the %s server", serviceName)
+		return true, nil
+	}
+
+	secure := serviceName == HttpsServiceApi
+	logger.Infof("rebinding the %s server from %s to %s", serviceName, old.addr, listenAddress(config.Host, config.Port, secure))
+	if listenAddress(old.config.Host, old.config.Port, secure) != listenAddress(config.Host, config.Port, secure) {
+		srv, err := s.bind(serviceName, r, config)
+		if err != nil {
+			state.Set(health_schema.StatusUp, nil)
+			return false, s.restoreConfig(old, err)
+		}
+		s.setRunning(serviceName, srv)
+		old.cancel()
+		return true, nil
+	}
+
+	// the address is kept, the old listener is closed before binding it again
+	if err := s.stop(serviceName, old); err != nil {
+		return false, err
+	}
+	srv, err := s.bind(serviceName, r, config)
+	if err != nil {
+		prev, rebindErr := s.bind(serviceName, r, old.config)
+		if rebindErr != nil {
+			logger.Errorf("failed to bind the previous configuration of the %s server: %v", serviceName, rebindErr)
+		} else {
+			s.setRunning(serviceName, prev)
+		}
+		return false, s.restoreConfig(old, err)
+	}
+	s.setRunning(serviceName, srv)
+	return true, nil
+}
+
+// StopServers stops all the servers and waits for the requests in flight to complete.
+func (s *HttpService) StopServers() error {
+	s.restartLock.Lock()
+	defer s.restartLock.Unlock()
+	s.serversLock.Lock()
+	running := s.running
+	s.running = make(map[string]*runningServer)
+	s.serversLock.Unlock()
+
+	for _, srv := range running {
+		srv.cancel()
+	}
+	var errs []error
+	for name, srv := range running {
+		select {
+		case <-srv.drained:
+		case <-time.After(serverStopTimeout):
+			errs = append(errs, fmt.Errorf("timed out stopping the %s server", name))
+		}
+		s.serverState(name).Set(health_schema.StatusStopped, nil)
+	}
+	return errors.Join(errs...)
+}
+
+// Bindings returns the addresses the servers are listening on.
+func (s *HttpService) Bindings() []http_schema.HttpBinding {
+	s.serversLock.Lock()
+	defer s.serversLock.Unlock()
+	ret := make([]http_schema.HttpBinding, 0, len(s.running))
+	for name, srv := range s.running {
+		binding := http_schema.HttpBinding{
+			Service: name,
+			Address: srv.addr,
+			Tls:     srv.tls,
+			Http3:   srv.http3,
+			Since:   srv.since,
+		}
+		if srv.tls {
+			binding.ClientAuthMode = srv.config.ClientAuthMode
+		}
+		ret = append(ret, binding)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Service < ret[j].Service })
+	return ret
+}
@@ -0,0 +1,244 @@
+package http_service
+
+import (
+	"context"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/health_schema"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testRouter struct {
+	engine *gin.Engine
+}
+
+func (r *testRouter) Register() {
+	r.engine.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+}
+func (r *testRouter) GetRouter() *gin.Engine {
+	return r.engine
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func ping(port int) error {
+	client := http.Client{Timeout: time.Second}
+	resp, err := client.Get(fmt.Sprintf("http://127.0.0.1:%d/ping", port))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// newTestAdminServer starts the admin server on a free port of the loopback interface.
+func newTestAdminServer(t *testing.T) (*HttpService, *entity.HttpConfig) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "http.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.HttpConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	config := &entity.HttpConfig{ServiceName: HttpServiceAdmin, Enable: true, Host: "127.0.0.1", Port: freePort(t), ClientAuthMode: "none"}
+	if err := db.Create(config).Error; err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), constants.ConfKey, conf.NewDefaultConf())
+	s := NewHttpService(db, ctx, nil)
+	if err := s.StartServer(&testRouter{engine: gin.New()}, HttpServiceAdmin); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = s.StopServers()
+	})
+	if err := ping(config.Port); err != nil {
+		t.Fatal(err)
+	}
+	return s, config
+}
+
+func updateConfig(t *testing.T, s *HttpService, config *entity.HttpConfig, values map[string]interface{}) {
+	t.Helper()
+	if err := s._db.Model(config).Updates(values).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSameBinding(t *testing.T) {
+	auto := true
+	base := entity.HttpConfig{Enable: true, Host: "0.0.0.0", Port: 2092, Cer: "cert", Key: "key", ClientAuthMode: "none"}
+	tests := []struct {
+		name     string
+		change   func(c *entity.HttpConfig)
+		listener bool
+		binding  bool
+	}{
+		{"same", func(c *entity.HttpConfig) {}, true, true},
+		{"nil auto cert is false", func(c *entity.HttpConfig) { f := false; c.AutoCert = &f }, true, true},
+		{"certificate", func(c *entity.HttpConfig) { c.Cer = "other" }, true, false},
+		{"key", func(c *entity.HttpConfig) { c.Key = "other" }, true, false},
+		{"auto cert", func(c *entity.HttpConfig) { c.AutoCert = &auto }, true, false},
+		{"port", func(c *entity.HttpConfig) { c.Port = 2093 }, false, false},
+		{"host", func(c *entity.HttpConfig) { c.Host = "127.0.0.1" }, false, false},
+		{"http3", func(c *entity.HttpConfig) { c.EnableHttp3 = true }, false, false},
+		{"client auth", func(c *entity.HttpConfig) { c.ClientAuthMode = "required" }, false, false},
+		{"disabled", func(c *entity.HttpConfig) { c.Enable = false }, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := base
+			tt.change(&changed)
+			if got := sameListener(&base, &changed); got != tt.listener {
+				t.Errorf("sameListener = %v, want %v", got, tt.listener)
+			}
+			if got := sameBinding(&base, &changed); got != tt.binding {
+				t.Errorf("sameBinding = %v, want %v", got, tt.binding)
+			}
+		})
+	}
+}
+
+func TestListenAddress(t *testing.T) {
+	if got := listenAddress("127.0.0.1", 2091, false); got != "127.0.0.1:2091" {
+		t.Errorf("got %s", got)
+	}
+	if got := listenAddress("::1", 2091, false); got != "[::1]:2091" {
+		t.Errorf("got %s", got)
+	}
+	// the HTTPS service listens on all the interfaces
+	if got := listenAddress("127.0.0.1", 2092, true); got != ":2092" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestRestartServerUnchanged(t *testing.T) {
+	s, _ := newTestAdminServer(t)
+	applied, err := s.RestartServer(HttpServiceAdmin)
+	if err != nil || applied {
+		t.Errorf("applied = %v, err = %v, want an unchanged server", applied, err)
+	}
+}
+
+func TestRestartServerRebind(t *testing.T) {
+	s, config := newTestAdminServer(t)
+	oldPort := config.Port
+	newPort := freePort(t)
+	updateConfig(t, s, config, map[string]interface{}{"port": newPort})
+
+	applied, err := s.RestartServer(HttpServiceAdmin)
+	if err != nil || !applied {
+		t.Fatalf("applied = %v, err = %v", applied, err)
+	}
+	if err := ping(newPort); err != nil {
+		t.Errorf("the server does not answer on the new port: %v", err)
+	}
+	bindings := s.Bindings()
+	if len(bindings) != 1 || bindings[0].Address != fmt.Sprintf("127.0.0.1:%d", newPort) {
+		t.Errorf("bindings = %+v", bindings)
+	}
+	// the old listener is closed once the new one is bound
+	deadline := time.Now().Add(serverStopTimeout)
+	for ping(oldPort) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("the old port is still served")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestRestartServerSameAddress(t *testing.T) {
+	s, config := newTestAdminServer(t)
+	updateConfig(t, s, config, map[string]interface{}{"client_auth_mode": "optional"})
+
+	applied, err := s.RestartServer(HttpServiceAdmin)
+	if err != nil || !applied {
+		t.Fatalf("applied = %v, err = %v", applied, err)
+	}
+	if err := ping(config.Port); err != nil {
+		t.Errorf("the server does not answer after the rebind: %v", err)
+	}
+	s.serversLock.Lock()
+	mode := s.running[HttpServiceAdmin].config.ClientAuthMode
+	s.serversLock.Unlock()
+	if mode != "optional" {
+		t.Errorf("the running server uses the client auth mode %q", mode)
+	}
+}
+
+func TestRestartServerRollback(t *testing.T) {
+	s, config := newTestAdminServer(t)
+	oldPort := config.Port
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+	updateConfig(t, s, config, map[string]interface{}{"port": busy.Addr().(*net.TCPAddr).Port})
+
+	applied, err := s.RestartServer(HttpServiceAdmin)
+	if err == nil || applied {
+		t.Fatalf("applied = %v, err = %v, want the bind to fail", applied, err)
+	}
+	if err := ping(oldPort); err != nil {
+		t.Errorf("the previous server stopped: %v", err)
+	}
+	var restored entity.HttpConfig
+	if err := s._db.First(&restored, config.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if restored.Port != oldPort {
+		t.Errorf("port = %d, want the previous port %d to be restored", restored.Port, oldPort)
+	}
+	if h := s.Health(); len(h) != 1 || h[0].Status != health_schema.StatusUp {
+		t.Errorf("health = %+v", h)
+	}
+
+	// the restored configuration is the running one
+	applied, err = s.RestartServer(HttpServiceAdmin)
+	if err != nil || applied {
+		t.Errorf("applied = %v, err = %v, want an unchanged server", applied, err)
+	}
+}
+
+func TestRestartServerDisable(t *testing.T) {
+	s, config := newTestAdminServer(t)
+	updateConfig(t, s, config, map[string]interface{}{"enable": false})
+
+	applied, err := s.RestartServer(HttpServiceAdmin)
+	if err != nil || !applied {
+		t.Fatalf("applied = %v, err = %v", applied, err)
+	}
+	if err := ping(config.Port); err == nil {
+		t.Error("the disabled server still answers")
+	}
+	if len(s.Bindings()) != 0 {
+		t.Errorf("bindings = %+v", s.Bindings())
+	}
+	if h := s.Health(); len(h) != 1 || h[0].Status != health_schema.StatusDisabled {
+		t.Errorf("health = %+v", h)
+	}
+}
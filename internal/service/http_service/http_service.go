@@ -2,18 +2,29 @@ package http_service
 
 import (
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fadacontrol/internal/base/conf"
 	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/events"
 	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/health"
 	"fadacontrol/internal/base/logger"
 	"fadacontrol/pkg/goroutine"
 	"fadacontrol/pkg/utils"
 	"golang.org/x/net/context"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"fadacontrol/internal/entity"
 	"fadacontrol/internal/router"
+	"fadacontrol/internal/schema/cert_schema"
+	"fadacontrol/internal/schema/health_schema"
 	"fadacontrol/internal/schema/http_schema"
+	"fadacontrol/internal/service/cert_service"
 	"fadacontrol/pkg/secure"
 	"fmt"
 	"github.com/gin-gonic/gin"
@@ -26,19 +37,69 @@ import (
 type HttpService struct {
 	_db                  *gorm.DB
 	ctx                  context.Context
+	cs                   *cert_service.CertService
 	adminRouter          router.FadaControlRouter
 	commonRouter         router.FadaControlRouter
 	restartAllServerFunc func() error
+	// serverCert is the certificate presented by the HTTPS service, it is replaced when the certificate is renewed.
+	serverCert atomic.Pointer[tls.Certificate]
+	// servers are the states of the started servers by service name.
+	servers map[string]*health.State
+	// running are the servers that are listening by service name.
+	running     map[string]*runningServer
+	serversLock sync.Mutex
+	// registered are the routers whose routes were registered, the routes are kept when a server restarts.
+	registered    map[router.FadaControlRouter]bool
+	registerLock  sync.Mutex
+	certWatchOnce sync.Once
+	// restartLock serializes the changes of the running servers.
+	restartLock sync.Mutex
 }
 
-func NewHttpService(_db *gorm.DB, ctx context.Context) *HttpService {
-	return &HttpService{_db: _db, ctx: ctx}
+// serverCertCheckInterval is how often the server certificate is checked for expiry and address changes.
+const serverCertCheckInterval = 10 * time.Minute
+
+func NewHttpService(_db *gorm.DB, ctx context.Context, cs *cert_service.CertService) *HttpService {
+	return &HttpService{_db: _db, ctx: ctx, cs: cs, running: make(map[string]*runningServer), registered: make(map[router.FadaControlRouter]bool)}
 }
 
 const HttpServiceApi = "HTTP_SERVICE_API"
 const HttpsServiceApi = "HTTPS_SERVICE_API"
 const HttpServiceAdmin = "HTTP_SERVICE_ADMIN"
 
+// componentNames are the names of the servers in the health report.
+var componentNames = map[string]string{
+	HttpServiceApi:   "http_api",
+	HttpsServiceApi:  "https_api",
+	HttpServiceAdmin: "http_admin",
+}
+
+func (s *HttpService) serverState(serviceName string) *health.State {
+	s.serversLock.Lock()
+	defer s.serversLock.Unlock()
+	if s.servers == nil {
+		s.servers = make(map[string]*health.State)
+	}
+	state, ok := s.servers[serviceName]
+	if !ok {
+		state = &health.State{}
+		s.servers[serviceName] = state
+	}
+	return state
+}
+
+// Health returns the states of the servers that were started.
+func (s *HttpService) Health() []health_schema.ComponentHealth {
+	s.serversLock.Lock()
+	defer s.serversLock.Unlock()
+	ret := make([]health_schema.ComponentHealth, 0, len(s.servers))
+	for name, state := range s.servers {
+		ret = append(ret, state.Component(componentNames[name]))
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
+
 func (s *HttpService) GetHttpConfig(serviceName string) (interface{}, error) {
 
 	if serviceName == HttpServiceApi {
@@ -66,14 +127,19 @@ func (s *HttpService) GetHttpConfig(serviceName string) (interface{}, error) {
 			Port:   httpsConfig.Port,
 			Cer:    httpsConfig.Cer,
 			Key:    httpsConfig.Key,
+
+			ClientAuthMode: httpsConfig.ClientAuthMode,
+			AutoCert:       httpsConfig.AutoCert,
 		}, nil
 	}
 
 	return nil, exception.ErrUserParameterError
 }
 
+// UpdateHttpConfig saves the configuration of the service and rebinds its server, h is a *HttpConfigRequest or a
+// *HttpsConfigRequest.
 func (s *HttpService) UpdateHttpConfig(h interface{}, serviceName string) error {
-	if request, ok := h.(http_schema.HttpConfigRequest); ok {
+	if request, ok := h.(*http_schema.HttpConfigRequest); ok {
 		if serviceName != HttpServiceApi {
 			return exception.ErrUserParameterError
 		}
@@ -82,6 +148,19 @@ func (s *HttpService) UpdateHttpConfig(h interface{}, serviceName string) error
 			logger.Errorf("failed to find database: %v", err)
 			return fmt.Errorf("failed to find database: %v", err)
 		}
+		changed := make(map[string]interface{})
+		for field, differs := range map[string]bool{
+			"enable": request.Enable != httpConfig.Enable,
+			"host":   request.Host != httpConfig.Host,
+			"port":   request.Port != httpConfig.Port,
+		} {
+			if differs {
+				changed[field] = true
+			}
+		}
+		if err := s.checkManaged(serviceName, changed); err != nil {
+			return err
+		}
 		httpConfig.Enable = request.Enable
 		httpConfig.Host = request.Host
 		httpConfig.Port = request.Port
@@ -89,15 +168,14 @@ func (s *HttpService) UpdateHttpConfig(h interface{}, serviceName string) error
 		if err != nil {
 			return fmt.Errorf("failed to save http config: %v", err)
 		}
+		return s.applyConfig(serviceName)
 	}
-	if request, ok := h.(http_schema.HttpsConfigRequest); ok {
-		if serviceName != HttpServiceApi {
+	if request, ok := h.(*http_schema.HttpsConfigRequest); ok {
+		if serviceName != HttpsServiceApi {
 			return exception.ErrUserParameterError
 		}
-		_, err := secure.LoadBaseX509KeyPair(request.Cer, request.Key)
-		if err != nil {
-			logger.Error(err)
-			return fmt.Errorf("failed to load https config: %v", err)
+		if !http_schema.IsValidClientAuthMode(request.ClientAuthMode) {
+			return exception.ErrUserParameterError.SetMsg("the client auth mode must be none, optional or required")
 		}
 
 		var httpsConfig entity.HttpConfig
@@ -106,24 +184,78 @@ func (s *HttpService) UpdateHttpConfig(h interface{}, serviceName string) error
 			return fmt.Errorf("failed to find database: %v", err)
 		}
 
+		auto := httpsConfig.AutoCert != nil && *httpsConfig.AutoCert
+		changed := make(map[string]interface{})
+		for field, differs := range map[string]bool{
+			"enable":           request.Enable != httpsConfig.Enable,
+			"host":             request.Host != httpsConfig.Host,
+			"port":             request.Port != httpsConfig.Port,
+			"cer":              request.Cer != httpsConfig.Cer,
+			"key":              request.Key != httpsConfig.Key,
+			"auto_cert":        request.AutoCert != nil && *request.AutoCert != auto,
+			"enable_http3":     request.EnableHttp3 != httpsConfig.EnableHttp3,
+			"client_auth_mode": request.ClientAuthMode != httpsConfig.ClientAuthMode,
+		} {
+			if differs {
+				changed[field] = true
+			}
+		}
+		if err := s.checkManaged(serviceName, changed); err != nil {
+			return err
+		}
+		if request.Cer != httpsConfig.Cer || request.Key != httpsConfig.Key {
+			if _, err := secure.LoadBaseX509KeyPair(request.Cer, request.Key); err != nil {
+				logger.Error(err)
+				return fmt.Errorf("failed to load https config: %v", err)
+			}
+		}
+
+		// a certificate uploaded by the user is no longer managed unless requested, auto_cert is kept when it is
+		// left out
+		if request.AutoCert != nil {
+			auto := *request.AutoCert
+			httpsConfig.AutoCert = &auto
+		} else if request.Cer != httpsConfig.Cer || request.Key != httpsConfig.Key {
+			auto := false
+			httpsConfig.AutoCert = &auto
+		}
 		httpsConfig.Enable = request.Enable
 		httpsConfig.Host = request.Host
 		httpsConfig.Port = request.Port
 		httpsConfig.Key = request.Key
 		httpsConfig.Cer = request.Cer
 		httpsConfig.EnableHttp3 = request.EnableHttp3
+		httpsConfig.ClientAuthMode = request.ClientAuthMode
 
-		err = s._db.Save(&httpsConfig).Error
-		if err != nil {
+		if err := s._db.Save(&httpsConfig).Error; err != nil {
 			return fmt.Errorf("failed to save http config: %v", err)
 		}
+		return s.applyConfig(serviceName)
 	}
 
 	return exception.ErrUserParameterError
 
 }
 
+// applyConfig rebinds the server of the service to the saved configuration, the previous configuration is restored
+// when the server can't be bound.
+func (s *HttpService) applyConfig(serviceName string) error {
+	if _, err := s.RestartServer(serviceName); err != nil {
+		return exception.ErrUserParameterError.SetMsg(err.Error())
+	}
+	return events.PublishConfigChanged(events.SectionHttp, serviceName, nil)
+}
+
+// checkManaged rejects changes to the fields of the service that are set in config.yml.
+func (s *HttpService) checkManaged(serviceName string, changed map[string]interface{}) error {
+	_conf := utils.GetValueFromContext(s.ctx, constants.ConfKey, conf.NewDefaultConf())
+	return conf.CheckManaged(_conf.ManagedHttpFields(serviceName), changed)
+}
+
 func (s *HttpService) PatchHttpConfig(data map[string]interface{}, serviceName string) error {
+	if err := s.checkManaged(serviceName, data); err != nil {
+		return err
+	}
 	if serviceName == HttpServiceApi {
 
 		var config entity.HttpConfig
@@ -136,10 +268,20 @@ func (s *HttpService) PatchHttpConfig(data map[string]interface{}, serviceName s
 			logger.Errorf("failed to patch http config: %v", err)
 			return fmt.Errorf("failed to patch http config: ")
 		}
-		return nil
+		return s.applyConfig(serviceName)
 
 	}
 	if serviceName == HttpsServiceApi {
+		if mode, ok := data["client_auth_mode"]; ok {
+			if str, ok := mode.(string); !ok || !http_schema.IsValidClientAuthMode(str) {
+				return exception.ErrUserParameterError.SetMsg("the client auth mode must be none, optional or required")
+			}
+		}
+		_, cer := data["cer"]
+		_, key := data["key"]
+		if _, ok := data["auto_cert"]; !ok && (cer || key) {
+			data["auto_cert"] = false
+		}
 		var config entity.HttpConfig
 		if err := s._db.Where(&entity.HttpConfig{ServiceName: HttpsServiceApi}).First(&config).Error; err != nil {
 			logger.Errorf("failed to find database: %v", err)
@@ -148,15 +290,28 @@ func (s *HttpService) PatchHttpConfig(data map[string]interface{}, serviceName s
 		if err := s._db.Model(&config).Updates(data).Error; err != nil {
 			return err
 		}
-		return nil
+		return s.applyConfig(serviceName)
 	}
 
 	return exception.ErrUserParameterError
 }
 
+func (s *HttpService) register(r router.FadaControlRouter) {
+	s.registerLock.Lock()
+	defer s.registerLock.Unlock()
+	if !s.registered[r] {
+		r.Register()
+		s.registered[r] = true
+	}
+}
+
 func (s *HttpService) StartServer(r router.FadaControlRouter, serviceName string) error {
-	enableQuic := false
+	s.restartLock.Lock()
+	defer s.restartLock.Unlock()
+	return s.start(r, serviceName)
+}
 
+func (s *HttpService) start(r router.FadaControlRouter, serviceName string) error {
 	_conf := utils.GetValueFromContext(s.ctx, constants.ConfKey, conf.NewDefaultConf())
 	if _conf.Debug {
 		gin.SetMode(gin.DebugMode)
@@ -164,6 +319,7 @@ func (s *HttpService) StartServer(r router.FadaControlRouter, serviceName string
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	s.serversLock.Lock()
 	if serviceName == HttpServiceAdmin {
 		s.adminRouter = r
 	}
@@ -171,127 +327,241 @@ func (s *HttpService) StartServer(r router.FadaControlRouter, serviceName string
 	if serviceName == HttpServiceApi || serviceName == HttpsServiceApi {
 		s.commonRouter = r
 	}
+	s.serversLock.Unlock()
 
 	if serviceName == HttpServiceApi || serviceName == HttpServiceAdmin || serviceName == HttpsServiceApi {
+		state := s.serverState(serviceName)
+		state.Set(health_schema.StatusStarting, nil)
 		var config entity.HttpConfig
 		if err := s._db.Where(&entity.HttpConfig{ServiceName: serviceName}).First(&config).Error; err != nil {
 			logger.Errorf("failed to find database: %v", err)
+			state.Set(health_schema.StatusDown, err)
 			return fmt.Errorf("failed to find database: %v", err)
 		}
 		if config.Enable == false {
+			state.Set(health_schema.StatusDisabled, nil)
 			return nil
 		}
 		logger.Infof("Starting HTTP server on %s:%d ", config.Host, config.Port)
-		r.Register()
-		cert := tls.Certificate{}
-		if serviceName == HttpsServiceApi {
-			var err error
-			cert, err = secure.LoadBaseX509KeyPair(config.Cer, config.Key)
-			if err != nil {
-				logger.Error(err)
-				_cert, _key, err := secure.GenerateX509Cert()
-				if err != nil {
-					logger.Error("gen key err", err)
-					return err
-				}
-				cert, err = secure.LoadX509KeyPairFromMemory(_cert, _key)
-				if err != nil {
-					logger.Error(err)
-					return err
-				}
-				return err
-			}
-			enableQuic = config.EnableHttp3
+		s.register(r)
+		srv, err := s.bind(serviceName, r, config)
+		if err != nil {
+			return err
 		}
+		s.setRunning(serviceName, srv)
+		return nil
+	}
 
-		_router := r.GetRouter()
+	return nil
+}
 
-		goroutine.RecoverGO(func() {
-			startHttpServer(config.Host, config.Port, cert, _router, enableQuic, s.ctx)
-		})
-		return nil
+// clientAuthConfig maps the client certificate mode of the HTTPS service to the TLS settings, the certificates
+// are verified against the local authority and the revocation is checked when the request is authenticated.
+func (s *HttpService) clientAuthConfig(mode string) (tls.ClientAuthType, *x509.CertPool, error) {
+	var clientAuth tls.ClientAuthType
+	switch mode {
+	case http_schema.ClientAuthOptional:
+		clientAuth = tls.VerifyClientCertIfGiven
+	case http_schema.ClientAuthRequired:
+		clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return tls.NoClientCert, nil, nil
+	}
+	pool, err := s.cs.CertPool()
+	if err != nil {
+		return tls.NoClientCert, nil, err
+	}
+	logger.Infof("client certificate authentication is %s", mode)
+	return clientAuth, pool, nil
+}
+
+func (s *HttpService) getServerCert(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return s.serverCert.Load(), nil
+}
+
+// watchServerCert reissues the server certificate when it expires soon or the addresses of the computer change,
+// it also picks up certificates uploaded by the user.
+func (s *HttpService) watchServerCert() {
+	ticker := time.NewTicker(serverCertCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.refreshServerCert(false); err != nil {
+				logger.Warnf("failed to check the server certificate: %v", err)
+			}
+		}
 	}
+}
 
+func (s *HttpService) refreshServerCert(renew bool) error {
+	var config entity.HttpConfig
+	if err := s._db.Where(&entity.HttpConfig{ServiceName: HttpsServiceApi}).First(&config).Error; err != nil {
+		return err
+	}
+	var cert tls.Certificate
+	var err error
+	if renew {
+		cert, err = s.cs.RenewServerCertificate(&config)
+	} else {
+		cert, err = s.cs.ServerCertificate(&config)
+	}
+	if err != nil {
+		return err
+	}
+	if s.serverCert.Load() != nil {
+		s.serverCert.Store(&cert)
+	}
 	return nil
 }
 
-func startHttpServer(host string, port int, cert tls.Certificate, router *gin.Engine, enableEnableHttp3 bool, ctx context.Context) {
+// RenewServerCert reissues the server certificate with the local authority, the running HTTPS service presents
+// it to new connections right away.
+func (s *HttpService) RenewServerCert() (*cert_schema.CertificatesResponse, error) {
+	if err := s.refreshServerCert(true); err != nil {
+		return nil, err
+	}
+	return s.Certificates()
+}
+
+// Certificates describes the local authority and the certificate presented by the HTTPS service, clients use
+// the fingerprints to pin them.
+func (s *HttpService) Certificates() (*cert_schema.CertificatesResponse, error) {
+	ca, err := s.cs.CACertificate()
+	if err != nil {
+		return nil, err
+	}
+	var config entity.HttpConfig
+	if err := s._db.Where(&entity.HttpConfig{ServiceName: HttpsServiceApi}).First(&config).Error; err != nil {
+		return nil, err
+	}
+	resp := &cert_schema.CertificatesResponse{
+		CA:       cert_service.CertificateInfo(ca),
+		AutoCert: config.AutoCert == nil || *config.AutoCert,
+	}
+	if cert := s.serverCert.Load(); cert != nil && cert.Leaf != nil {
+		resp.Server = cert_service.CertificateInfo(cert.Leaf)
+	}
+	return resp, nil
+}
+
+// startHttpServer binds the listener and serves in the background until ctx is done, the requests in flight are then
+// given serverDrainTimeout to complete before the connections are closed.
+func startHttpServer(host string, port int, getCert func(*tls.ClientHelloInfo) (*tls.Certificate, error), clientAuth tls.ClientAuthType, clientCAs *x509.CertPool, router *gin.Engine, enableEnableHttp3 bool, ctx context.Context, state *health.State) (*runningServer, error) {
 	var srv *http.Server
 	var http3Server *http3.Server
 	tlsFlag := false
+	var tlsConfig *tls.Config
 
-	if cert.Certificate == nil || len(cert.Certificate) == 0 || cert.PrivateKey == nil {
+	if getCert == nil {
 		tlsFlag = false
 		logger.Info("start no secure http at ", port)
 		srv = &http.Server{
-			Addr:    fmt.Sprintf("%s:%d", host, port),
+			Addr:    listenAddress(host, port, false),
 			Handler: router,
 		}
 
 	} else {
 		tlsFlag = true
-		tlsConfig := &tls.Config{
-			Certificates:       []tls.Certificate{cert},
+		tlsConfig = &tls.Config{
+			GetCertificate:     getCert,
 			MinVersion:         tls.VersionTLS13,
 			InsecureSkipVerify: true,
-		}
-
-		if enableEnableHttp3 {
-			conf.Http3Enabled = true
-			conf.Http3Port = port
-			http3Server = &http3.Server{
-				Addr:       fmt.Sprintf(":%d", port),
-				Handler:    router,
-				TLSConfig:  tlsConfig,
-				QUICConfig: &quic.Config{},
-			}
-			logger.Info("start http3 server at ", port)
-
-			goroutine.RecoverGO(func() {
-				err := http3Server.ListenAndServe()
-				if err != nil {
-					logger.Error(err)
-				}
-			})
-
+			ClientAuth:         clientAuth,
+			ClientCAs:          clientCAs,
 		}
 
 		srv = &http.Server{
-			Addr:      fmt.Sprintf(":%d", port),
+			Addr:      listenAddress(host, port, true),
 			Handler:   router,
 			TLSConfig: tlsConfig,
 		}
 		logger.Info("start secure server at ", port)
 
 	}
+	listener, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		logger.Warnf("server errors: %s", err)
+		state.Set(health_schema.StatusDown, err)
+		return nil, err
+	}
+	var http3Conn net.PacketConn
+	if tlsFlag && enableEnableHttp3 {
+		http3Conn, err = net.ListenPacket("udp", srv.Addr)
+		if err != nil {
+			listener.Close()
+			logger.Warnf("http3 server errors: %s", err)
+			state.Set(health_schema.StatusDown, err)
+			return nil, err
+		}
+		http3Server = &http3.Server{
+			Addr:       srv.Addr,
+			Handler:    router,
+			TLSConfig:  tlsConfig,
+			QUICConfig: &quic.Config{},
+		}
+		logger.Info("start http3 server at ", port)
+	}
+	state.Set(health_schema.StatusUp, nil)
+
+	// released is done when the TCP listener and the UDP socket are closed
+	var released sync.WaitGroup
+	drained := make(chan struct{})
+	if http3Server != nil {
+		released.Add(1)
+		goroutine.RecoverGO(func() {
+			err := http3Server.Serve(http3Conn)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error(err)
+			}
+		})
+	}
 	goroutine.RecoverGO(func() {
+		defer close(drained)
 		<-ctx.Done()
 
-		_ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+		_ctx, cancel := context.WithTimeout(context.Background(), serverDrainTimeout)
 		defer cancel()
+		var wg sync.WaitGroup
+		if http3Server != nil {
+			wg.Add(1)
+			goroutine.RecoverGO(func() {
+				defer wg.Done()
+				defer released.Done()
+				if err := http3Server.Shutdown(_ctx); err != nil {
+					logger.Errorf("http3 server Shutdown: %s", err)
+				}
+				http3Conn.Close()
+			})
+		}
 		if err := srv.Shutdown(_ctx); err != nil {
 			logger.Errorf("server Shutdown: %s", err)
-
-		}
-		if http3Server == nil {
-			return
-		}
-		err := http3Server.Close()
-		if err != nil {
-			logger.Errorf("http3 server Close Err: %s", err)
-			return
+			srv.Close()
 		}
+		wg.Wait()
 	})
-	var err error
-	if tlsFlag || enableEnableHttp3 {
-		err = srv.ListenAndServeTLS("", "")
 
-	} else {
-		err = srv.ListenAndServe()
-	}
-
-	if err != nil {
-		logger.Warnf("server errors: %s", err)
-	}
+	released.Add(1)
+	goroutine.RecoverGO(func() {
+		defer released.Done()
+		var err error
+		if tlsFlag {
+			err = srv.ServeTLS(listener, "", "")
+		} else {
+			err = srv.Serve(listener)
+		}
 
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Warnf("server errors: %s", err)
+			state.Set(health_schema.StatusDown, err)
+		}
+	})
+	done := make(chan struct{})
+	goroutine.RecoverGO(func() {
+		released.Wait()
+		close(done)
+	})
+	return &runningServer{addr: listener.Addr().String(), tls: tlsFlag, http3: http3Server != nil, done: done, drained: drained}, nil
 }
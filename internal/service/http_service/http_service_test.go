@@ -0,0 +1,65 @@
+package http_service
+
+import (
+	"context"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/http_schema"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpdateHttpsConfigAutoCert(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "http.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.HttpConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	auto := true
+	config := &entity.HttpConfig{ServiceName: HttpsServiceApi, Enable: true, Host: "0.0.0.0", Port: 2092, Cer: "CERT", Key: "KEY", ClientAuthMode: "none", AutoCert: &auto}
+	if err := db.Create(config).Error; err != nil {
+		t.Fatal(err)
+	}
+	// the certificate of the service is set by the config file
+	c := conf.NewDefaultConf()
+	certFile := "server.pem"
+	c.Http = map[string]*conf.HttpServiceConf{"api": {CertFile: &certFile}}
+	s := NewHttpService(db, context.WithValue(context.Background(), constants.ConfKey, c), nil)
+
+	autoCert := func() *bool {
+		var got entity.HttpConfig
+		if err := db.First(&got, config.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		return got.AutoCert
+	}
+	request := func(auto *bool) *http_schema.HttpsConfigRequest {
+		return &http_schema.HttpsConfigRequest{Enable: true, Host: "0.0.0.0", Port: 2093, Cer: "CERT", Key: "KEY", ClientAuthMode: "none", AutoCert: auto}
+	}
+
+	// auto_cert is kept when it is left out
+	if err := s.UpdateHttpConfig(request(nil), HttpsServiceApi); err != nil {
+		t.Fatal(err)
+	}
+	if got := autoCert(); got == nil || !*got {
+		t.Errorf("auto_cert = %v, want it to be kept", got)
+	}
+	// an unchanged value is not a change of a managed field
+	if err := s.UpdateHttpConfig(request(&auto), HttpsServiceApi); err != nil {
+		t.Errorf("expected the unchanged auto_cert to be accepted, got %v", err)
+	}
+	off := false
+	err = s.UpdateHttpConfig(request(&off), HttpsServiceApi)
+	if ex, ok := err.(*exception.Exception); !ok || ex.Code != exception.ErrUserConfigManaged.Code {
+		t.Errorf("expected the managed auto_cert to be rejected, got %v", err)
+	}
+	if got := autoCert(); got == nil || !*got {
+		t.Errorf("auto_cert = %v after the rejected change", got)
+	}
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"fadacontrol/internal/base/conf"
 	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/events"
 	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/health"
 	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/base/metrics"
 	"fadacontrol/internal/schema"
+	"fadacontrol/internal/schema/health_schema"
 	"fadacontrol/internal/schema/internal_command"
 	"fadacontrol/pkg/goroutine"
 	"fadacontrol/pkg/sys"
@@ -34,6 +38,7 @@ type InternalMasterService struct {
 	slaveWorkDir string
 	startOnce    sync.Once
 	stopOnce     sync.Once
+	state        health.State
 }
 
 func NewInternalMasterService(ctx context.Context) *InternalMasterService {
@@ -63,6 +68,11 @@ func (s *InternalMasterService) Start() error {
 
 }
 
+// Health returns the state of the rpc server the slave programs connect to.
+func (s *InternalMasterService) Health() health_schema.ComponentHealth {
+	return s.state.Component("master_rpc")
+}
+
 func (s *InternalMasterService) Stop() error {
 	s.stopOnce.Do(func() {
 		s.StopServer()
@@ -112,6 +122,8 @@ func (r *rpcClient) Close() {
 		usernameClientMapLock.Lock()
 		delete(usernameClientMap, r.Username)
 		usernameClientMapLock.Unlock()
+		updateConnectedSlaves()
+		notifySlaveChange(r, false)
 		select {
 		case r.SendChan <- &schema.InternalCommand{
 			CommandType: schema.ExitCommandType,
@@ -133,6 +145,35 @@ var activeRpcClientMapLock sync.RWMutex
 var usernameClientMap = make(map[string]*rpcClient)
 var usernameClientMapLock sync.RWMutex
 
+var slaveChangeCallback []func(clientId, username string, connected bool)
+var slaveChangeCallbackLock sync.RWMutex
+
+// AddSlaveChangeCallback registers a callback that runs when a slave program connects or disconnects.
+func (s *InternalMasterService) AddSlaveChangeCallback(callback func(clientId, username string, connected bool)) {
+	slaveChangeCallbackLock.Lock()
+	defer slaveChangeCallbackLock.Unlock()
+	slaveChangeCallback = append(slaveChangeCallback, callback)
+}
+
+func notifySlaveChange(client *rpcClient, connected bool) {
+	topic := events.SlaveDisconnected
+	if connected {
+		topic = events.SlaveConnected
+	}
+	events.Publish(topic, events.SlaveData{ClientId: client.Id, Username: client.Username})
+	slaveChangeCallbackLock.RLock()
+	defer slaveChangeCallbackLock.RUnlock()
+	for _, callback := range slaveChangeCallback {
+		callback(client.Id, client.Username, connected)
+	}
+}
+
+func updateConnectedSlaves() {
+	activeRpcClientMapLock.RLock()
+	defer activeRpcClientMapLock.RUnlock()
+	metrics.SetConnectedSlaves(len(activeRpcClientMap))
+}
+
 func (s *internalRpcServer) RegisterClient(ctx context.Context, req *internal_command.ClientInfo) (*internal_command.RpcResponse, error) {
 	// Handle the RegisterClient RPC
 	logger.Debug("Registering client from ", req.Username)
@@ -174,6 +215,8 @@ func (s *internalRpcServer) RegisterClient(ctx context.Context, req *internal_co
 	usernameClientMapLock.Lock()
 	usernameClientMap[client.Username] = client
 	usernameClientMapLock.Unlock()
+	updateConnectedSlaves()
+	notifySlaveChange(client, true)
 
 	defer func() {
 		logger.Debug("success Registering client from ", req.Username)
@@ -310,11 +353,12 @@ func (s *InternalMasterService) StartServer() error {
 	host := "127.0.0.1"
 	addr := host + ":" + strconv.Itoa(port)
 	listener, err := net.Listen("tcp", addr)
-	defer listener.Close()
 	if err != nil {
 		logger.Errorf("failed to listen: %v", err)
+		s.state.Set(health_schema.StatusDown, err)
 		return err
 	}
+	defer listener.Close()
 	grpcServer := grpc.NewServer(
 		grpc.UnaryInterceptor(goroutine.UnaryServerInterceptor),
 		grpc.StreamInterceptor(goroutine.StreamServerInterceptor),
@@ -336,14 +380,17 @@ func (s *InternalMasterService) StartServer() error {
 	internal_command.RegisterBaseServer(grpcServer, &internalRpcServer{ProgramConf: _conf})
 	internal_command.RegisterExecuteCommandServer(grpcServer, &internalRpcServer{ProgramConf: _conf})
 	logger.Infof("rpc server listening on :%d", port)
+	s.state.Set(health_schema.StatusUp, nil)
 	goroutine.RecoverGO(func() {
 		err = grpcServer.Serve(listener)
 		if err != nil {
+			s.state.Set(health_schema.StatusDown, err)
 			logger.Fatal("rpc server failed to serve: %v", err)
 		}
 	})
 	select {
 	case <-s.ctx.Done():
+		s.state.Set(health_schema.StatusStopped, nil)
 		s.StopServer()
 		logger.Info("rpc server will be stopped")
 		grpcServer.GracefulStop()
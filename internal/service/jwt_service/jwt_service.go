@@ -1,61 +1,83 @@
 package jwt_service
 
 import (
-	"fadacontrol/internal/base/logger"
+	"errors"
 	"fadacontrol/internal/entity"
 	"fadacontrol/internal/schema"
 	"github.com/golang-jwt/jwt/v5"
 	"gorm.io/gorm"
+	"sync"
 	"time"
 )
 
-const ExpirationTime = 7 * 24 * time.Hour
-
-const DefaultJwtSecretKey = "undefined"
-
-var jwtSecretKey = DefaultJwtSecretKey
+// AccessTokenTTL is the lifetime of access tokens, clients renew them with the refresh token.
+const AccessTokenTTL = 15 * time.Minute
 
 type JwtService struct {
 	_db *gorm.DB
+
+	mu     sync.Mutex
+	loaded bool
+	keys   map[string]*signingKey
+	active *signingKey
 }
 
 func NewJwtService(_db *gorm.DB) *JwtService {
-	return &JwtService{_db}
-}
-func (j *JwtService) RefreshKey() {
-	if jwtSecretKey != DefaultJwtSecretKey {
-		return
-	}
-	user := &entity.User{}
-	if err := j._db.Where(&entity.User{Username: "root"}).Select("password").First(user).Error; err != nil {
-		logger.Error(err)
-	} else {
-		jwtSecretKey = user.Password
-	}
+	return &JwtService{_db: _db}
 }
-func (j *JwtService) GenerateToken(username string) (string, error) {
-	j.RefreshKey()
 
+func (j *JwtService) signAccessToken(session *entity.UserSession) (string, error) {
+	key, err := j.activeKey()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
 	claims := schema.JwtClaims{
-		Username: username,
+		Username:  session.Username,
+		SessionId: session.Sid,
+		TwoFactor: session.TwoFactor,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ExpirationTime)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.signKey)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(jwtSecretKey))
+// GenerateToken returns a short-lived access token for username that can not be refreshed, it is meant for
+// local requests of the service itself and therefore counts as two-factor authenticated.
+func (j *JwtService) GenerateToken(username string) (string, error) {
+	session, err := j.newSession(username, "", "", AccessTokenTTL, "", true)
+	if err != nil {
+		return "", err
+	}
+	return j.signAccessToken(session)
 }
+
+// ValidateToken checks the signature of the token and that its session has not been revoked.
 func (j *JwtService) ValidateToken(tokenString string) (*schema.JwtClaims, error) {
-	j.RefreshKey()
 	token, err := jwt.ParseWithClaims(tokenString, &schema.JwtClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(jwtSecretKey), nil
-	})
-
-	if claims, ok := token.Claims.(*schema.JwtClaims); ok && token.Valid {
-		return claims, nil
-	} else {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := j.verificationKey(kid)
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, errors.New("unexpected signing method")
+		}
+		return key.verifyKey, nil
+	}, jwt.WithValidMethods([]string{AlgorithmHS256, AlgorithmEdDSA}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(*schema.JwtClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if _, err := j.activeSession(claims.SessionId, claims.Username); err != nil {
 		return nil, err
 	}
+	return claims, nil
 }
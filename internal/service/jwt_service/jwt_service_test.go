@@ -0,0 +1,160 @@
+package jwt_service
+
+import (
+	"fadacontrol/internal/entity"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestJwtService(t *testing.T) *JwtService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "jwt.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.JwtKey{}, &entity.UserSession{}); err != nil {
+		t.Fatal(err)
+	}
+	return NewJwtService(db)
+}
+
+func TestRefreshRotation(t *testing.T) {
+	j := newTestJwtService(t)
+	first, err := j.CreateSession("alice", "10.0.0.1", "test", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := j.ValidateToken(first.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Username != "alice" || claims.SessionId == "" {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+
+	second, err := j.Refresh(first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("the refresh token was not rotated")
+	}
+	third, err := j.Refresh(second.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sid, _, _ := strings.Cut(first.RefreshToken, ".")
+	if claims, err := j.ValidateToken(third.Token); err != nil || claims.SessionId != sid {
+		t.Fatalf("the refreshed token does not belong to the session: %+v %v", claims, err)
+	}
+
+	// the first token was already exchanged, presenting it again revokes the session
+	if _, err := j.Refresh(first.RefreshToken); err == nil {
+		t.Fatal("a used refresh token was exchanged again")
+	}
+	if _, err := j.Refresh(third.RefreshToken); err == nil {
+		t.Error("the session can still be refreshed after the reuse")
+	}
+	for _, token := range []string{first.Token, third.Token} {
+		if _, err := j.ValidateToken(token); err == nil {
+			t.Error("an access token of the revoked session is still valid")
+		}
+	}
+
+	for _, token := range []string{"", "no-separator", "unknown.secret"} {
+		if _, err := j.Refresh(token); err == nil {
+			t.Errorf("refresh token %q was accepted", token)
+		}
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	j := newTestJwtService(t)
+	first, err := j.CreateSession("alice", "10.0.0.1", "test", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := j.CreateSession("alice", "10.0.0.2", "test", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := j.GenerateToken("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := j.ValidateToken(first.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := j.ListSessions("alice", claims.SessionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the sessions of local tokens can't be refreshed and are not listed
+	if len(sessions) != 2 || !sessions[0].Current || sessions[1].Current {
+		t.Fatalf("unexpected sessions %+v", sessions)
+	}
+
+	if err := j.RevokeSession(claims.SessionId, "bob"); err == nil {
+		t.Error("bob revoked a session of alice")
+	}
+	if err := j.RevokeSession(claims.SessionId, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.ValidateToken(first.Token); err == nil {
+		t.Error("the access token of the revoked session is still valid")
+	}
+	if _, err := j.Refresh(first.RefreshToken); err == nil {
+		t.Error("the revoked session was refreshed")
+	}
+	if _, err := j.ValidateToken(second.Token); err != nil {
+		t.Errorf("revoking one session revoked another one: %v", err)
+	}
+
+	if err := j.RevokeUserSessions("alice"); err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []string{second.Token, local} {
+		if _, err := j.ValidateToken(token); err == nil {
+			t.Error("a token is still valid after all the sessions were revoked")
+		}
+	}
+	if sessions, err := j.ListSessions("alice", ""); err != nil || len(sessions) != 0 {
+		t.Errorf("got sessions %+v, %v", sessions, err)
+	}
+}
+
+func TestRotateKey(t *testing.T) {
+	j := newTestJwtService(t)
+	before, err := j.CreateSession("alice", "", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.RotateKey(AlgorithmEdDSA); err != nil {
+		t.Fatal(err)
+	}
+	after, err := j.CreateSession("alice", "", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the retired key verifies the tokens it signed until they expire
+	for _, token := range []string{before.Token, after.Token} {
+		if _, err := j.ValidateToken(token); err != nil {
+			t.Error(err)
+		}
+	}
+	keys, err := j.ListKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0].Active || !keys[1].Active || keys[1].Algorithm != AlgorithmEdDSA {
+		t.Errorf("unexpected keys %+v", keys)
+	}
+	if _, err := j.RotateKey("RS256"); err == nil {
+		t.Error("an unsupported algorithm was accepted")
+	}
+}
@@ -0,0 +1,229 @@
+package jwt_service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema"
+	"fadacontrol/pkg/secure"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+	"sort"
+	"time"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	// KeyRotationInterval is the age after which the active key is replaced automatically.
+	KeyRotationInterval = 30 * 24 * time.Hour
+
+	hmacSecretLength = 32
+)
+
+type signingKey struct {
+	kid       string
+	algorithm string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	publicKey string
+	createdAt time.Time
+	retiredAt *time.Time
+}
+
+func newKid() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func newKeyEntity(algorithm string) (*entity.JwtKey, error) {
+	kid, err := newKid()
+	if err != nil {
+		return nil, err
+	}
+	key := &entity.JwtKey{Kid: kid, Algorithm: algorithm}
+	switch algorithm {
+	case AlgorithmHS256:
+		secret, err := secure.GenerateSalt(hmacSecretLength)
+		if err != nil {
+			return nil, err
+		}
+		key.Secret = base64.RawStdEncoding.EncodeToString(secret)
+	case AlgorithmEdDSA:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		key.Secret = base64.RawStdEncoding.EncodeToString(priv)
+		key.PublicKey = base64.RawStdEncoding.EncodeToString(pub)
+	default:
+		return nil, exception.ErrUserParameterError.SetMsg("the algorithm must be HS256 or EdDSA")
+	}
+	return key, nil
+}
+
+func toSigningKey(key *entity.JwtKey) (*signingKey, error) {
+	secret, err := base64.RawStdEncoding.DecodeString(key.Secret)
+	if err != nil {
+		return nil, err
+	}
+	ret := &signingKey{kid: key.Kid, algorithm: key.Algorithm, publicKey: key.PublicKey, createdAt: key.CreatedAt, retiredAt: key.RetiredAt}
+	switch key.Algorithm {
+	case AlgorithmHS256:
+		ret.method = jwt.SigningMethodHS256
+		ret.signKey = secret
+		ret.verifyKey = secret
+	case AlgorithmEdDSA:
+		if len(secret) != ed25519.PrivateKeySize {
+			return nil, errors.New("invalid ed25519 private key")
+		}
+		priv := ed25519.PrivateKey(secret)
+		ret.method = jwt.SigningMethodEdDSA
+		ret.signKey = priv
+		ret.verifyKey = priv.Public()
+	default:
+		return nil, errors.New("unknown signing algorithm " + key.Algorithm)
+	}
+	return ret, nil
+}
+
+// loadKeys reads the keys that can still verify tokens and drops the ones that can not, j.mu must be held.
+func (j *JwtService) loadKeys() error {
+	expired := time.Now().Add(-AccessTokenTTL)
+	if err := j._db.Unscoped().Where("retired_at IS NOT NULL AND retired_at < ?", expired).Delete(&entity.JwtKey{}).Error; err != nil {
+		return err
+	}
+	var keys []entity.JwtKey
+	if err := j._db.Order("id").Find(&keys).Error; err != nil {
+		return err
+	}
+	j.keys = make(map[string]*signingKey, len(keys))
+	j.active = nil
+	for i := range keys {
+		key, err := toSigningKey(&keys[i])
+		if err != nil {
+			logger.Errorf("failed to load jwt key %s: %v", keys[i].Kid, err)
+			continue
+		}
+		j.keys[key.kid] = key
+		if key.retiredAt == nil {
+			j.active = key
+		}
+	}
+	j.loaded = true
+	return nil
+}
+
+// rotateKey retires the active key and creates a new one, j.mu must be held.
+func (j *JwtService) rotateKey(algorithm string) (*signingKey, error) {
+	key, err := newKeyEntity(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	err = j._db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.JwtKey{}).Where("retired_at IS NULL").Update("retired_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(key).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("jwt signing key rotated, new key %s uses %s", key.Kid, key.Algorithm)
+	if err := j.loadKeys(); err != nil {
+		return nil, err
+	}
+	return j.keys[key.Kid], nil
+}
+
+// activeKey returns the key new tokens are signed with, creating or rotating it when necessary.
+func (j *JwtService) activeKey() (*signingKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if !j.loaded {
+		if err := j.loadKeys(); err != nil {
+			return nil, err
+		}
+	}
+	if j.active == nil {
+		return j.rotateKey(AlgorithmHS256)
+	}
+	if time.Since(j.active.createdAt) > KeyRotationInterval {
+		return j.rotateKey(j.active.algorithm)
+	}
+	return j.active, nil
+}
+
+func (j *JwtService) verificationKey(kid string) (*signingKey, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if !j.loaded {
+		if err := j.loadKeys(); err != nil {
+			logger.Errorf("failed to load jwt keys: %v", err)
+			return nil, false
+		}
+	}
+	key, ok := j.keys[kid]
+	if !ok || (key.retiredAt != nil && time.Since(*key.retiredAt) > AccessTokenTTL) {
+		return nil, false
+	}
+	return key, true
+}
+
+// RotateKey replaces the active signing key with a new one using algorithm, an empty algorithm keeps the current one.
+func (j *JwtService) RotateKey(algorithm string) (*schema.JwtKeyResponse, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if !j.loaded {
+		if err := j.loadKeys(); err != nil {
+			return nil, err
+		}
+	}
+	if algorithm == "" {
+		algorithm = AlgorithmHS256
+		if j.active != nil {
+			algorithm = j.active.algorithm
+		}
+	}
+	key, err := j.rotateKey(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return toKeyResponse(key, true), nil
+}
+
+func toKeyResponse(key *signingKey, active bool) *schema.JwtKeyResponse {
+	return &schema.JwtKeyResponse{
+		Kid:       key.kid,
+		Algorithm: key.algorithm,
+		PublicKey: key.publicKey,
+		Active:    active,
+		CreatedAt: key.createdAt,
+		RetiredAt: key.retiredAt,
+	}
+}
+
+// ListKeys returns the keys that are still able to verify tokens, secrets are never included.
+func (j *JwtService) ListKeys() ([]*schema.JwtKeyResponse, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.loadKeys(); err != nil {
+		return nil, err
+	}
+	ret := make([]*schema.JwtKeyResponse, 0, len(j.keys))
+	for _, key := range j.keys {
+		ret = append(ret, toKeyResponse(key, key == j.active))
+	}
+	sort.Slice(ret, func(a, b int) bool { return ret[a].CreatedAt.Before(ret[b].CreatedAt) })
+	return ret, nil
+}
@@ -0,0 +1,191 @@
+package jwt_service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema"
+	"fadacontrol/pkg/secure"
+	"strings"
+	"time"
+)
+
+const (
+	// SessionTTL is how long a login can be kept alive with refresh tokens.
+	SessionTTL = 30 * 24 * time.Hour
+
+	refreshSecretLength = 32
+	maxUserAgentLength  = 256
+)
+
+func hashRefreshSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// newSession stores a session for username, it can not be refreshed when refreshHash is empty.
+func (j *JwtService) newSession(username, clientIP, userAgent string, ttl time.Duration, refreshHash string, twoFactor bool) (*entity.UserSession, error) {
+	now := time.Now()
+	if err := j._db.Unscoped().Where("expires_at < ?", now).Delete(&entity.UserSession{}).Error; err != nil {
+		logger.Warnf("failed to remove expired sessions: %v", err)
+	}
+	sid, err := newKid()
+	if err != nil {
+		return nil, err
+	}
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	session := &entity.UserSession{
+		Sid:         sid,
+		Username:    username,
+		RefreshHash: refreshHash,
+		TwoFactor:   twoFactor,
+		ExpiresAt:   now.Add(ttl),
+		ClientIP:    clientIP,
+		UserAgent:   userAgent,
+	}
+	if err := j._db.Create(session).Error; err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (j *JwtService) newRefreshSecret() (string, string, error) {
+	secret, err := secure.GenerateRandomBase58Key(refreshSecretLength)
+	if err != nil {
+		return "", "", err
+	}
+	return secret, hashRefreshSecret(secret), nil
+}
+
+func (j *JwtService) tokenResponse(session *entity.UserSession, refreshSecret string) (*schema.TokenResponse, error) {
+	access, err := j.signAccessToken(session)
+	if err != nil {
+		return nil, err
+	}
+	return &schema.TokenResponse{
+		Token:        access,
+		ExpiresIn:    int64(AccessTokenTTL / time.Second),
+		RefreshToken: session.Sid + "." + refreshSecret,
+	}, nil
+}
+
+// CreateSession logs username in and returns an access token together with a refresh token,
+// twoFactor records whether the login was completed with a second factor.
+func (j *JwtService) CreateSession(username, clientIP, userAgent string, twoFactor bool) (*schema.TokenResponse, error) {
+	secret, hash, err := j.newRefreshSecret()
+	if err != nil {
+		return nil, err
+	}
+	session, err := j.newSession(username, clientIP, userAgent, SessionTTL, hash, twoFactor)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("session %s created for user %s from %s", session.Sid, username, clientIP)
+	return j.tokenResponse(session, secret)
+}
+
+func (j *JwtService) activeSession(sid, username string) (*entity.UserSession, error) {
+	if sid == "" {
+		return nil, exception.ErrUserUnauthorizedAccess
+	}
+	var session entity.UserSession
+	if err := j._db.Where("sid = ?", sid).First(&session).Error; err != nil {
+		return nil, exception.ErrUserUnauthorizedAccess
+	}
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) || (username != "" && session.Username != username) {
+		return nil, exception.ErrUserUnauthorizedAccess
+	}
+	return &session, nil
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh token.
+// Presenting a refresh token that was already exchanged revokes the whole session.
+func (j *JwtService) Refresh(refreshToken string) (*schema.TokenResponse, error) {
+	sid, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok {
+		return nil, exception.ErrUserUnauthorizedAccess
+	}
+	session, err := j.activeSession(sid, "")
+	if err != nil {
+		return nil, err
+	}
+	if session.RefreshHash == "" {
+		return nil, exception.ErrUserUnauthorizedAccess
+	}
+	if subtle.ConstantTimeCompare([]byte(hashRefreshSecret(secret)), []byte(session.RefreshHash)) != 1 {
+		logger.Warnf("reuse of a refresh token of session %s detected, revoking the session", sid)
+		_ = j.RevokeSession(sid, "")
+		return nil, exception.ErrUserUnauthorizedAccess
+	}
+	newSecret, newHash, err := j.newRefreshSecret()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	result := j._db.Model(&entity.UserSession{}).
+		Where("id = ? AND refresh_hash = ?", session.ID, session.RefreshHash).
+		Updates(map[string]interface{}{"refresh_hash": newHash, "refreshed_at": now})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		// another request exchanged the same token concurrently
+		return nil, exception.ErrUserUnauthorizedAccess
+	}
+	return j.tokenResponse(session, newSecret)
+}
+
+// RevokeSession revokes the session sid, a non-empty username restricts it to the sessions of that user.
+func (j *JwtService) RevokeSession(sid, username string) error {
+	tx := j._db.Model(&entity.UserSession{}).Where("sid = ? AND revoked_at IS NULL", sid)
+	if username != "" {
+		tx = tx.Where("username = ?", username)
+	}
+	result := tx.Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return exception.ErrUserResourceNotFound
+	}
+	logger.Infof("session %s revoked", sid)
+	return nil
+}
+
+// RevokeUserSessions revokes every session of username.
+func (j *JwtService) RevokeUserSessions(username string) error {
+	err := j._db.Model(&entity.UserSession{}).Where("username = ? AND revoked_at IS NULL", username).Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+	logger.Infof("all sessions of user %s revoked", username)
+	return nil
+}
+
+// ListSessions returns the active sessions of username, currentSid marks the session of the caller.
+func (j *JwtService) ListSessions(username, currentSid string) ([]*schema.SessionResponse, error) {
+	var sessions []entity.UserSession
+	err := j._db.Where("username = ? AND revoked_at IS NULL AND expires_at > ? AND refresh_hash <> ''", username, time.Now()).Order("id").Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*schema.SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		ret = append(ret, &schema.SessionResponse{
+			Sid:         s.Sid,
+			Username:    s.Username,
+			ClientIP:    s.ClientIP,
+			UserAgent:   s.UserAgent,
+			Current:     s.Sid == currentSid,
+			CreatedAt:   s.CreatedAt,
+			RefreshedAt: s.RefreshedAt,
+			ExpiresAt:   s.ExpiresAt,
+		})
+	}
+	return ret, nil
+}
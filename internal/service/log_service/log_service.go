@@ -0,0 +1,66 @@
+package log_service
+
+import (
+	"context"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/schema/log_schema"
+	"fadacontrol/pkg/logsearch"
+	"go.uber.org/zap/zapcore"
+	"math"
+)
+
+type LogService struct {
+	ctx context.Context
+}
+
+func NewLogService(ctx context.Context) *LogService {
+	return &LogService{ctx: ctx}
+}
+
+// Search reads the log file and the backups rotated by lumberjack, the entries are returned newest first.
+func (l *LogService) Search(q *log_schema.LogQuery) (*log_schema.LogSearchResponse, error) {
+	if q.Page < 1 || q.PageSize < 1 || q.Page-1 > math.MaxInt/q.PageSize {
+		return nil, exception.ErrUserParameterError.SetMsg("the page is out of range")
+	}
+	path := logger.GetLogPath()
+	if path == "" {
+		return nil, exception.ErrSystemServiceNotFullyStarted
+	}
+	filter := logsearch.Filter{Level: zapcore.DebugLevel, Module: q.Module, RequestId: q.RequestId}
+	if q.Level != "" {
+		level, err := zapcore.ParseLevel(q.Level)
+		if err != nil {
+			return nil, exception.ErrUserParameterError.SetMsg("the level must be debug, info, warn, error or fatal")
+		}
+		filter.Level = level
+	}
+	if q.From != nil {
+		filter.From = *q.From
+	}
+	if q.To != nil {
+		filter.To = *q.To
+	}
+	// the buffered entries are written before searching
+	logger.Sync()
+	entries, total, err := logsearch.Search(path, &filter, (q.Page-1)*q.PageSize, q.PageSize)
+	if err != nil {
+		logger.Errorf("failed to search the log files: %v", err)
+		return nil, err
+	}
+	resp := &log_schema.LogSearchResponse{Total: total, Entries: make([]*log_schema.LogEntry, 0, len(entries))}
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, &log_schema.LogEntry{
+			Time:      e.Time,
+			Level:     e.Level.String(),
+			Module:    e.Module(),
+			Caller:    e.Caller,
+			Message:   e.Message,
+			RequestId: e.RequestId(),
+			Fields:    e.Fields,
+			Stack:     e.Stack,
+			File:      e.File,
+		})
+	}
+	return resp, nil
+}
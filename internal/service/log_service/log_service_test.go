@@ -0,0 +1,24 @@
+package log_service
+
+import (
+	"context"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/schema/log_schema"
+	"math"
+	"testing"
+)
+
+func TestSearchPageOutOfRange(t *testing.T) {
+	l := NewLogService(context.Background())
+	for _, q := range []log_schema.LogQuery{
+		{Page: 0, PageSize: 100},
+		{Page: 1, PageSize: 0},
+		{Page: math.MaxInt/100 + 2, PageSize: 100},
+		{Page: math.MaxInt, PageSize: 1000},
+	} {
+		_, err := l.Search(&q)
+		if ex, ok := err.(*exception.Exception); !ok || ex.Code != exception.ErrUserParameterError.Code {
+			t.Errorf("page %d of size %d: expected a parameter error, got %v", q.Page, q.PageSize, err)
+		}
+	}
+}
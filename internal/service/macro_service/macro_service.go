@@ -0,0 +1,528 @@
+package macro_service
+
+import (
+	"context"
+	"errors"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/custom_command_schema"
+	"fadacontrol/internal/schema/macro_schema"
+	"fadacontrol/internal/service/auth_service"
+	"fadacontrol/internal/service/control_pc"
+	"fadacontrol/internal/service/custom_command_service"
+	"fadacontrol/pkg/goroutine"
+	"fadacontrol/pkg/sys"
+	"fadacontrol/pkg/utils"
+	"fadacontrol/pkg/wol"
+	"fmt"
+	"gorm.io/gorm"
+	"net"
+	"sync"
+	"time"
+)
+
+const maxWaitSeconds = 24 * 60 * 60
+
+type MacroService struct {
+	ctx  context.Context
+	db   *gorm.DB
+	co   *control_pc.ControlPCService
+	cu   *custom_command_service.CustomCommandService
+	auth *auth_service.AuthService
+	// running are the ids of the macros being run, a macro runs once at a time while other macros may run alongside.
+	running   map[uint]bool
+	runningMu sync.Mutex
+}
+
+func NewMacroService(ctx context.Context, db *gorm.DB, co *control_pc.ControlPCService, cu *custom_command_service.CustomCommandService, auth *auth_service.AuthService) *MacroService {
+	return &MacroService{ctx: ctx, db: db, co: co, cu: cu, auth: auth, running: make(map[uint]bool)}
+}
+
+func sourceName(source custom_command_schema.CommandSource) string {
+	switch source {
+	case custom_command_schema.LanSource:
+		return macro_schema.SourceHttp
+	case custom_command_schema.RemoteSource:
+		return macro_schema.SourceRemote
+	case custom_command_schema.SchedulerSource:
+		return macro_schema.SourceScheduler
+	default:
+		return ""
+	}
+}
+
+func validateSteps(steps []macro_schema.MacroStep) error {
+	if len(steps) == 0 || len(steps) > macro_schema.MaxMacroSteps {
+		return exception.ErrUserParameterError.SetMsg(fmt.Sprintf("a macro needs 1 to %d steps", macro_schema.MaxMacroSteps))
+	}
+	for i := range steps {
+		step := &steps[i]
+		switch step.Condition {
+		case "":
+			step.Condition = macro_schema.ConditionSuccess
+		case macro_schema.ConditionAlways, macro_schema.ConditionSuccess, macro_schema.ConditionFailure:
+		default:
+			return exception.ErrUserParameterError.SetMsg(fmt.Sprintf("step %d: invalid condition", i))
+		}
+		switch step.Type {
+		case macro_schema.StepLock, macro_schema.StepStandby:
+		case macro_schema.StepShutdown:
+			if step.ShutdownType < int(sys.Unknown) || step.ShutdownType > int(sys.S_EWX_HYBRID_SHUTDOWN_FORCE_RESTARTAPPS) {
+				return exception.ErrUserParameterError.SetMsg(fmt.Sprintf("step %d: invalid shutdown_type", i))
+			}
+		case macro_schema.StepCommand:
+			if step.CommandPath == "" || step.CommandName == "" {
+				return exception.ErrUserParameterError.SetMsg(fmt.Sprintf("step %d: command_path and command_name are required", i))
+			}
+		case macro_schema.StepWait:
+			if step.Seconds <= 0 || step.Seconds > maxWaitSeconds {
+				return exception.ErrUserParameterError.SetMsg(fmt.Sprintf("step %d: seconds must be between 1 and %d", i, maxWaitSeconds))
+			}
+		case macro_schema.StepWol:
+			if _, err := wol.NewMagicPacket(step.Mac); err != nil {
+				return exception.ErrUserParameterError.SetMsg(fmt.Sprintf("step %d: %v", i, err))
+			}
+			if step.Broadcast != "" {
+				if _, _, err := net.SplitHostPort(step.Broadcast); err != nil {
+					return exception.ErrUserParameterError.SetMsg(fmt.Sprintf("step %d: %v", i, err))
+				}
+			}
+		default:
+			return exception.ErrUserParameterError.SetMsg(fmt.Sprintf("step %d: invalid type", i))
+		}
+	}
+	return nil
+}
+
+func toStepEntity(macroId uint, index int, step *macro_schema.MacroStep) entity.MacroStep {
+	return entity.MacroStep{
+		MacroId:      macroId,
+		StepIndex:    index,
+		Type:         step.Type,
+		Condition:    step.Condition,
+		ShutdownType: step.ShutdownType,
+		CommandPath:  step.CommandPath,
+		CommandName:  step.CommandName,
+		Seconds:      step.Seconds,
+		Mac:          step.Mac,
+		Broadcast:    step.Broadcast,
+	}
+}
+func toStepSchema(step *entity.MacroStep) macro_schema.MacroStep {
+	return macro_schema.MacroStep{
+		Type:         step.Type,
+		Condition:    step.Condition,
+		ShutdownType: step.ShutdownType,
+		CommandPath:  step.CommandPath,
+		CommandName:  step.CommandName,
+		Seconds:      step.Seconds,
+		Mac:          step.Mac,
+		Broadcast:    step.Broadcast,
+	}
+}
+
+func (m *MacroService) loadSteps(macroId uint) ([]entity.MacroStep, error) {
+	var steps []entity.MacroStep
+	err := m.db.Where(&entity.MacroStep{MacroId: macroId}).Order("step_index").Find(&steps).Error
+	return steps, err
+}
+func (m *MacroService) toMacroResponse(macro *entity.Macro) (*macro_schema.MacroResponse, error) {
+	steps, err := m.loadSteps(macro.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &macro_schema.MacroResponse{
+		Id:          macro.ID,
+		Name:        macro.Name,
+		Description: macro.Description,
+		Steps:       make([]macro_schema.MacroStep, 0, len(steps)),
+		CreatedBy:   macro.CreatedBy,
+		CreatedAt:   macro.CreatedAt,
+		UpdatedAt:   macro.UpdatedAt,
+	}
+	for i := range steps {
+		resp.Steps = append(resp.Steps, toStepSchema(&steps[i]))
+	}
+	return resp, nil
+}
+
+func (m *MacroService) getMacro(id uint) (*entity.Macro, error) {
+	var macro entity.Macro
+	if err := m.db.First(&macro, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, exception.ErrUserResourceNotFound
+		}
+		return nil, err
+	}
+	return &macro, nil
+}
+func (m *MacroService) getMacroByName(name string) (*entity.Macro, error) {
+	var macro entity.Macro
+	if err := m.db.Where(&entity.Macro{Name: name}).First(&macro).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, exception.ErrUserResourceNotFound
+		}
+		return nil, err
+	}
+	return &macro, nil
+}
+
+func (m *MacroService) ListMacros() ([]*macro_schema.MacroResponse, error) {
+	var macros []entity.Macro
+	if err := m.db.Order("id").Find(&macros).Error; err != nil {
+		return nil, err
+	}
+	ret := make([]*macro_schema.MacroResponse, 0, len(macros))
+	for i := range macros {
+		resp, err := m.toMacroResponse(&macros[i])
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, resp)
+	}
+	return ret, nil
+}
+func (m *MacroService) GetMacro(id uint) (*macro_schema.MacroResponse, error) {
+	macro, err := m.getMacro(id)
+	if err != nil {
+		return nil, err
+	}
+	return m.toMacroResponse(macro)
+}
+
+func (m *MacroService) saveSteps(tx *gorm.DB, macroId uint, steps []macro_schema.MacroStep) error {
+	if err := tx.Unscoped().Where(&entity.MacroStep{MacroId: macroId}).Delete(&entity.MacroStep{}).Error; err != nil {
+		return err
+	}
+	records := make([]entity.MacroStep, 0, len(steps))
+	for i := range steps {
+		records = append(records, toStepEntity(macroId, i, &steps[i]))
+	}
+	return tx.Create(&records).Error
+}
+func (m *MacroService) CreateMacro(req *macro_schema.MacroRequest, username string) (*macro_schema.MacroResponse, error) {
+	if err := validateSteps(req.Steps); err != nil {
+		return nil, err
+	}
+	if _, err := m.getMacroByName(req.Name); err == nil {
+		return nil, exception.ErrUserParameterError.SetMsg("a macro with this name already exists")
+	}
+	macro := &entity.Macro{Name: req.Name, Description: req.Description, CreatedBy: username}
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(macro).Error; err != nil {
+			return err
+		}
+		return m.saveSteps(tx, macro.ID, req.Steps)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m.toMacroResponse(macro)
+}
+func (m *MacroService) UpdateMacro(id uint, req *macro_schema.MacroRequest) (*macro_schema.MacroResponse, error) {
+	if err := validateSteps(req.Steps); err != nil {
+		return nil, err
+	}
+	macro, err := m.getMacro(id)
+	if err != nil {
+		return nil, err
+	}
+	if other, err := m.getMacroByName(req.Name); err == nil && other.ID != macro.ID {
+		return nil, exception.ErrUserParameterError.SetMsg("a macro with this name already exists")
+	}
+	macro.Name = req.Name
+	macro.Description = req.Description
+	err = m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(macro).Error; err != nil {
+			return err
+		}
+		return m.saveSteps(tx, macro.ID, req.Steps)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m.toMacroResponse(macro)
+}
+
+// DeleteMacro deletes a macro and its steps, the execution log is kept.
+func (m *MacroService) DeleteMacro(id uint) error {
+	macro, err := m.getMacro(id)
+	if err != nil {
+		return err
+	}
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where(&entity.MacroStep{MacroId: macro.ID}).Delete(&entity.MacroStep{}).Error; err != nil {
+			return err
+		}
+		// hard delete so the name can be reused
+		return tx.Unscoped().Delete(macro).Error
+	})
+}
+
+// RunMacro starts the macro in the background and returns the created run.
+func (m *MacroService) RunMacro(id uint, requester string, source custom_command_schema.CommandSource) (*macro_schema.MacroRunResponse, error) {
+	macro, err := m.getMacro(id)
+	if err != nil {
+		return nil, err
+	}
+	return m.runAsync(macro, requester, source)
+}
+
+// RunMacroByName starts the macro named name in the background and returns the created run.
+func (m *MacroService) RunMacroByName(name, requester string, source custom_command_schema.CommandSource) (*macro_schema.MacroRunResponse, error) {
+	macro, err := m.getMacroByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.runAsync(macro, requester, source)
+}
+
+// RunMacroByNameSync runs the macro named name and waits for it to finish.
+func (m *MacroService) RunMacroByNameSync(name, requester string, source custom_command_schema.CommandSource) *exception.Exception {
+	macro, err := m.getMacroByName(name)
+	if err != nil {
+		return toException(err)
+	}
+	run, steps, ex := m.begin(macro, requester, source)
+	if ex != nil {
+		return ex
+	}
+	return m.execute(macro, run, steps, source)
+}
+
+func (m *MacroService) runAsync(macro *entity.Macro, requester string, source custom_command_schema.CommandSource) (*macro_schema.MacroRunResponse, error) {
+	run, steps, ex := m.begin(macro, requester, source)
+	if ex != nil {
+		return nil, ex
+	}
+	resp := toRunResponse(run)
+	goroutine.RecoverGO(func() {
+		m.execute(macro, run, steps, source)
+	})
+	return resp, nil
+}
+
+// begin marks the macro as running and records the run, execute marks it as finished.
+func (m *MacroService) begin(macro *entity.Macro, requester string, source custom_command_schema.CommandSource) (*entity.MacroRun, []entity.MacroStep, *exception.Exception) {
+	m.runningMu.Lock()
+	if m.running[macro.ID] {
+		m.runningMu.Unlock()
+		return nil, nil, exception.ErrUserMacroAlreadyRunning
+	}
+	m.running[macro.ID] = true
+	m.runningMu.Unlock()
+	steps, err := m.loadSteps(macro.ID)
+	if err != nil {
+		m.finish(macro)
+		return nil, nil, toException(err)
+	}
+	run := &entity.MacroRun{
+		MacroId:   macro.ID,
+		MacroName: macro.Name,
+		Source:    sourceName(source),
+		Requester: requester,
+		StartedAt: time.Now(),
+		Status:    macro_schema.RunStatusRunning,
+	}
+	if err := m.db.Create(run).Error; err != nil {
+		m.finish(macro)
+		return nil, nil, toException(err)
+	}
+	logger.Infof("macro %s started by %s from %s, run %d", macro.Name, requester, run.Source, run.ID)
+	return run, steps, nil
+}
+
+func (m *MacroService) finish(macro *entity.Macro) {
+	m.runningMu.Lock()
+	defer m.runningMu.Unlock()
+	delete(m.running, macro.ID)
+}
+
+func (m *MacroService) execute(macro *entity.Macro, run *entity.MacroRun, steps []entity.MacroStep, source custom_command_schema.CommandSource) *exception.Exception {
+	defer m.finish(macro)
+	result := exception.ErrSuccess
+	for i := range steps {
+		step := &steps[i]
+		record := &entity.MacroStepRun{RunId: run.ID, StepIndex: step.StepIndex, Type: step.Type}
+		if !conditionMet(step.Condition, result) {
+			record.Status = macro_schema.RunStatusSkipped
+			m.db.Create(record)
+			continue
+		}
+		started := time.Now()
+		record.StartedAt = &started
+		record.Status = macro_schema.RunStatusRunning
+		m.db.Create(record)
+
+		ex := m.executeStep(run, step, source)
+		if ex == nil {
+			ex = exception.ErrSuccess
+		}
+		finished := time.Now()
+		record.FinishedAt = &finished
+		record.Code = ex.Code
+		record.Msg = ex.Msg
+		record.Status = macro_schema.RunStatusSuccess
+		if ex.Code != exception.ErrSuccess.Code {
+			record.Status = macro_schema.RunStatusFailed
+			logger.Warnf("macro %s step %d (%s) failed: %s", macro.Name, step.StepIndex, step.Type, ex.ToString())
+		}
+		m.db.Save(record)
+		result = ex
+	}
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.Code = result.Code
+	run.Msg = result.Msg
+	run.Status = macro_schema.RunStatusSuccess
+	if result.Code != exception.ErrSuccess.Code {
+		run.Status = macro_schema.RunStatusFailed
+	}
+	if err := m.db.Save(run).Error; err != nil {
+		logger.Errorf("failed to record macro run: %v", err)
+	}
+	logger.Infof("macro %s run %d finished: %s", macro.Name, run.ID, run.Status)
+	return result
+}
+
+func conditionMet(condition string, previous *exception.Exception) bool {
+	success := previous.Code == exception.ErrSuccess.Code
+	switch condition {
+	case macro_schema.ConditionAlways:
+		return true
+	case macro_schema.ConditionFailure:
+		return !success
+	default:
+		return success
+	}
+}
+
+// executeStep runs a step on behalf of the requester of the run.
+func (m *MacroService) executeStep(run *entity.MacroRun, step *entity.MacroStep, source custom_command_schema.CommandSource) *exception.Exception {
+	switch step.Type {
+	case macro_schema.StepShutdown:
+		tpe := sys.ShutdownType(step.ShutdownType)
+		if tpe == sys.Unknown {
+			tpe = sys.S_E_FORCE_SHUTDOWN
+		}
+		return m.co.Shutdown(tpe)
+	case macro_schema.StepStandby:
+		return m.co.Standby()
+	case macro_schema.StepLock:
+		_conf := utils.GetValueFromContext(m.ctx, constants.ConfKey, conf.NewDefaultConf())
+		return m.co.LockWindows(_conf.StartMode == conf.ServiceMode)
+	case macro_schema.StepCommand:
+		return m.RunConfiguredCommand(step.CommandPath, step.CommandName, run.Requester, source)
+	case macro_schema.StepWait:
+		select {
+		case <-time.After(time.Duration(step.Seconds) * time.Second):
+			return exception.ErrSuccess
+		case <-m.ctx.Done():
+			return exception.ErrSystemRequestTimeout.SetMsg("service stopped")
+		}
+	case macro_schema.StepWol:
+		if err := wol.Send(step.Mac, step.Broadcast); err != nil {
+			return exception.ErrSystemUnknownException.SetMsg(err.Error())
+		}
+		return exception.ErrSuccess
+	default:
+		return exception.ErrUserParameterError
+	}
+}
+
+// RunConfiguredCommand runs the command name from the config file at path on behalf of username,
+// applying the command's authorization rules for source, and waits for it to exit.
+func (m *MacroService) RunConfiguredCommand(path, name, username string, source custom_command_schema.CommandSource) *exception.Exception {
+	cmds, err := m.cu.ReadConfig(path)
+	if err != nil {
+		return exception.ErrUserParameterError.SetMsg(err.Error())
+	}
+	cmd, ok := cmds[name]
+	if !ok {
+		return exception.ErrUserCommandNotFound
+	}
+	if !m.auth.CheckCommandPermission(username, cmd, source) {
+		return exception.ErrUserUnauthorizedAccess
+	}
+	code, err := m.cu.RunCommand(m.ctx, cmd, nil)
+	if err != nil {
+		return toException(err)
+	}
+	if code != 0 {
+		return exception.ErrSystemUnknownException.SetMsg(fmt.Sprintf("command exited with code %d", code))
+	}
+	return exception.ErrSuccess
+}
+
+func toException(err error) *exception.Exception {
+	var ex *exception.Exception
+	if errors.As(err, &ex) {
+		return ex
+	}
+	return exception.ErrSystemUnknownException.SetMsg(err.Error())
+}
+
+func toRunResponse(run *entity.MacroRun) *macro_schema.MacroRunResponse {
+	return &macro_schema.MacroRunResponse{
+		Id:         run.ID,
+		MacroId:    run.MacroId,
+		MacroName:  run.MacroName,
+		Source:     run.Source,
+		Requester:  run.Requester,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+		Status:     run.Status,
+		Code:       run.Code,
+		Msg:        run.Msg,
+	}
+}
+
+func (m *MacroService) ListRuns(macroId uint, page, pageSize int) ([]*macro_schema.MacroRunResponse, int64, error) {
+	var total int64
+	query := m.db.Model(&entity.MacroRun{}).Where(&entity.MacroRun{MacroId: macroId})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var runs []entity.MacroRun
+	if err := query.Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+	ret := make([]*macro_schema.MacroRunResponse, 0, len(runs))
+	for i := range runs {
+		ret = append(ret, toRunResponse(&runs[i]))
+	}
+	return ret, total, nil
+}
+
+// GetRun returns a run with its step-by-step execution log.
+func (m *MacroService) GetRun(runId uint) (*macro_schema.MacroRunResponse, error) {
+	var run entity.MacroRun
+	if err := m.db.First(&run, runId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, exception.ErrUserResourceNotFound
+		}
+		return nil, err
+	}
+	var steps []entity.MacroStepRun
+	if err := m.db.Where(&entity.MacroStepRun{RunId: run.ID}).Order("step_index").Find(&steps).Error; err != nil {
+		return nil, err
+	}
+	resp := toRunResponse(&run)
+	resp.Steps = make([]*macro_schema.MacroStepRunResponse, 0, len(steps))
+	for _, step := range steps {
+		resp.Steps = append(resp.Steps, &macro_schema.MacroStepRunResponse{
+			Index:      step.StepIndex,
+			Type:       step.Type,
+			Status:     step.Status,
+			StartedAt:  step.StartedAt,
+			FinishedAt: step.FinishedAt,
+			Code:       step.Code,
+			Msg:        step.Msg,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,98 @@
+package macro_service
+
+import (
+	"context"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/data"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/custom_command_schema"
+	"fadacontrol/internal/schema/macro_schema"
+	"fadacontrol/internal/service/auth_service"
+	"fadacontrol/internal/service/control_pc"
+	"fadacontrol/internal/service/custom_command_service"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestMacroService(t *testing.T) *MacroService {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "macro.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.Macro{}, &entity.MacroStep{}, &entity.MacroRun{}, &entity.MacroStepRun{}); err != nil {
+		t.Fatal(err)
+	}
+	adapter, err := data.NewAdapterByDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enforcer, err := data.NewEnforcer(adapter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := conf.NewDefaultConf()
+	c.StartMode = conf.CommonMode
+	ctx := context.WithValue(context.Background(), constants.ConfKey, c)
+	auth := auth_service.NewAuthService(enforcer)
+	return NewMacroService(ctx, db, control_pc.NewControlPCService(nil), custom_command_service.NewCustomCommandService(auth, ctx), auth)
+}
+
+func TestMacroRunsOnceAtATime(t *testing.T) {
+	m := newTestMacroService(t)
+	steps := []macro_schema.MacroStep{{Type: macro_schema.StepWait, Seconds: 1}}
+	first, err := m.CreateMacro(&macro_schema.MacroRequest{Name: "first", Steps: steps}, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := m.CreateMacro(&macro_schema.MacroRequest{Name: "second", Steps: steps}, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstMacro, _ := m.getMacro(first.Id)
+	secondMacro, _ := m.getMacro(second.Id)
+
+	if _, _, ex := m.begin(firstMacro, "alice", custom_command_schema.LanSource); ex != nil {
+		t.Fatal(ex.ToString())
+	}
+	if _, _, ex := m.begin(firstMacro, "alice", custom_command_schema.LanSource); ex != exception.ErrUserMacroAlreadyRunning {
+		t.Errorf("running the macro twice: got %v", ex)
+	}
+	// another macro is not blocked by the first one, which may be waiting
+	if _, _, ex := m.begin(secondMacro, "alice", custom_command_schema.LanSource); ex != nil {
+		t.Errorf("running another macro: got %v", ex.ToString())
+	}
+	m.finish(firstMacro)
+	if _, _, ex := m.begin(firstMacro, "alice", custom_command_schema.LanSource); ex != nil {
+		t.Errorf("running the macro again after it finished: got %v", ex.ToString())
+	}
+}
+
+func TestMacroCommandRunsAsRequester(t *testing.T) {
+	m := newTestMacroService(t)
+	path := filepath.Join(t.TempDir(), "commands.yml")
+	config := "commands:\n  - name: backup\n    cmd: fadacontrol-command-not-found\n    auth:\n      users: [alice]\n"
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	steps := []macro_schema.MacroStep{{Type: macro_schema.StepCommand, CommandPath: path, CommandName: "backup"}}
+	if _, err := m.CreateMacro(&macro_schema.MacroRequest{Name: "backup", Steps: steps}, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	// bob may run the macro but not the command of its creator
+	ex := m.RunMacroByNameSync("backup", "bob", custom_command_schema.LanSource)
+	if ex.Code != exception.ErrUserUnauthorizedAccess.Code {
+		t.Errorf("got %s, want unauthorized", ex.ToString())
+	}
+	ex = m.RunMacroByNameSync("backup", "alice", custom_command_schema.LanSource)
+	if ex.Code == exception.ErrUserUnauthorizedAccess.Code || ex.Code == exception.ErrSuccess.Code {
+		t.Errorf("got %s, want the command to be started and fail", ex.ToString())
+	}
+}
@@ -0,0 +1,268 @@
+package policy_service
+
+import (
+	"context"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/policy_schema"
+	"fadacontrol/internal/service/auth_service"
+	"fadacontrol/pkg/utils"
+	"gorm.io/gorm"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var subjectPattern = regexp.MustCompile(`^(\*|[A-Za-z0-9_.-]{1,32})$`)
+
+type PolicyService struct {
+	ctx  context.Context
+	db   *gorm.DB
+	auth *auth_service.AuthService
+}
+
+func NewPolicyService(ctx context.Context, db *gorm.DB, auth *auth_service.AuthService) *PolicyService {
+	return &PolicyService{ctx: ctx, db: db, auth: auth}
+}
+
+// CheckManagedRoles returns ErrUserConfigManaged when the roles of username are set in config.yml.
+func (p *PolicyService) CheckManagedRoles(username string) error {
+	_conf := utils.GetValueFromContext(p.ctx, constants.ConfKey, conf.NewDefaultConf())
+	if u := _conf.ManagedUser(username); u != nil && u.Roles != nil {
+		return exception.ErrUserConfigManaged.SetMsg("the roles of " + username + " are managed by config.yml")
+	}
+	return nil
+}
+
+// ValidateRoles checks that the roles assigned to username exist and are not users.
+func (p *PolicyService) ValidateRoles(username string, roles []string) error {
+	for _, role := range roles {
+		if !subjectPattern.MatchString(role) || role == "*" || role == username {
+			return exception.ErrUserParameterError.SetMsg("invalid role " + role)
+		}
+		var count int64
+		if err := p.db.Model(&entity.User{}).Where(&entity.User{Username: role}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return exception.ErrUserParameterError.SetMsg(role + " is a user, not a role")
+		}
+		if !p.auth.IsRole(role) {
+			return exception.ErrUserParameterError.SetMsg("role " + role + " does not exist")
+		}
+	}
+	return nil
+}
+
+// hasAdmin reports whether an enabled user other than exclude still has full access.
+func (p *PolicyService) hasAdmin(exclude string) (bool, error) {
+	var users []entity.User
+	if err := p.db.Where("disabled = ?", false).Find(&users).Error; err != nil {
+		return false, err
+	}
+	for _, user := range users {
+		if user.Username != exclude && p.auth.IsAdmin(user.Username) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Guard applies change and reverts it with undo when afterwards no enabled administrator other than exclude is left.
+// An empty exclude only checks the effect of change.
+func (p *PolicyService) Guard(exclude string, change, undo func() error) error {
+	if change != nil {
+		if err := change(); err != nil {
+			return err
+		}
+	}
+	ok, err := p.hasAdmin(exclude)
+	if err == nil && ok {
+		return nil
+	}
+	if undo != nil {
+		if undoErr := undo(); undoErr != nil {
+			logger.Errorf("failed to revert policy change: %v", undoErr)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return exception.ErrUserLastAdmin
+}
+
+func validatePolicy(policy *policy_schema.Policy) error {
+	if !subjectPattern.MatchString(policy.Subject) {
+		return exception.ErrUserParameterError.SetMsg("invalid subject")
+	}
+	if policy.Object != "*" && !strings.HasPrefix(policy.Object, auth_service.HttpPrefix+"/") && !strings.HasPrefix(policy.Object, auth_service.CommandPrefix) {
+		return exception.ErrUserParameterError.SetMsg("the object must be *, or start with api:/ or cmd:")
+	}
+	switch auth_service.Action(policy.Action) {
+	case auth_service.Read, auth_service.Write, auth_service.Execute, "*":
+	default:
+		return exception.ErrUserParameterError.SetMsg("the action must be one of r, w, x and *")
+	}
+	return nil
+}
+func toPolicy(rule []string) *policy_schema.Policy {
+	if len(rule) < 3 {
+		return nil
+	}
+	return &policy_schema.Policy{Subject: rule[0], Object: rule[1], Action: rule[2]}
+}
+
+func (p *PolicyService) ListPolicies() ([]*policy_schema.Policy, error) {
+	rules, err := p.auth.GetPolicies()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*policy_schema.Policy, 0, len(rules))
+	for _, rule := range rules {
+		if policy := toPolicy(rule); policy != nil {
+			ret = append(ret, policy)
+		}
+	}
+	return ret, nil
+}
+func (p *PolicyService) AddPolicy(policy *policy_schema.Policy) error {
+	if err := validatePolicy(policy); err != nil {
+		return err
+	}
+	if _, err := p.auth.AddPolicy(policy.Subject, policy.Object, policy.Action); err != nil {
+		return err
+	}
+	logger.Infof("policy added: %s %s %s", policy.Subject, policy.Object, policy.Action)
+	return nil
+}
+func (p *PolicyService) RemovePolicy(policy *policy_schema.Policy) error {
+	_conf := utils.GetValueFromContext(p.ctx, constants.ConfKey, conf.NewDefaultConf())
+	if _conf.IsManagedPolicy(policy.Subject, policy.Object, policy.Action) {
+		return exception.ErrUserConfigManaged.SetMsg("the policy is managed by config.yml")
+	}
+	var removed bool
+	err := p.Guard("", func() error {
+		var err error
+		removed, err = p.auth.RemovePolicy(policy.Subject, policy.Object, policy.Action)
+		return err
+	}, func() error {
+		if !removed {
+			return nil
+		}
+		_, err := p.auth.AddPolicy(policy.Subject, policy.Object, policy.Action)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return exception.ErrUserResourceNotFound
+	}
+	logger.Infof("policy removed: %s %s %s", policy.Subject, policy.Object, policy.Action)
+	return nil
+}
+
+func (p *PolicyService) ListRoleAssignments() ([]*policy_schema.RoleAssignment, error) {
+	rules, err := p.auth.GetRoleAssignments()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*policy_schema.RoleAssignment, 0, len(rules))
+	for _, rule := range rules {
+		if len(rule) >= 2 {
+			ret = append(ret, &policy_schema.RoleAssignment{Username: rule[0], Role: rule[1]})
+		}
+	}
+	return ret, nil
+}
+func (p *PolicyService) AddRoleAssignment(assignment *policy_schema.RoleAssignment) error {
+	if !subjectPattern.MatchString(assignment.Username) || assignment.Username == "*" {
+		return exception.ErrUserParameterError
+	}
+	if err := p.CheckManagedRoles(assignment.Username); err != nil {
+		return err
+	}
+	var count int64
+	if err := p.db.Model(&entity.User{}).Where(&entity.User{Username: assignment.Username}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return exception.ErrUserResourceNotFound
+	}
+	if err := p.ValidateRoles(assignment.Username, []string{assignment.Role}); err != nil {
+		return err
+	}
+	if _, err := p.auth.AddRole(assignment.Username, assignment.Role); err != nil {
+		return err
+	}
+	logger.Infof("role %s assigned to %s", assignment.Role, assignment.Username)
+	return nil
+}
+func (p *PolicyService) RemoveRoleAssignment(assignment *policy_schema.RoleAssignment) error {
+	if err := p.CheckManagedRoles(assignment.Username); err != nil {
+		return err
+	}
+	var removed bool
+	err := p.Guard("", func() error {
+		var err error
+		removed, err = p.auth.RemoveRole(assignment.Username, assignment.Role)
+		return err
+	}, func() error {
+		if !removed {
+			return nil
+		}
+		_, err := p.auth.AddRole(assignment.Username, assignment.Role)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return exception.ErrUserResourceNotFound
+	}
+	logger.Infof("role %s removed from %s", assignment.Role, assignment.Username)
+	return nil
+}
+
+// ListRoles returns the built-in roles and every role that has members, with their policies.
+func (p *PolicyService) ListRoles() ([]*policy_schema.RoleResponse, error) {
+	roles := map[string]*policy_schema.RoleResponse{}
+	for _, name := range auth_service.BuiltinRoles {
+		roles[name] = &policy_schema.RoleResponse{Name: name, Builtin: true, Policies: []*policy_schema.Policy{}, Members: []string{}}
+	}
+	assignments, err := p.ListRoleAssignments()
+	if err != nil {
+		return nil, err
+	}
+	for _, assignment := range assignments {
+		role, ok := roles[assignment.Role]
+		if !ok {
+			role = &policy_schema.RoleResponse{Name: assignment.Role, Policies: []*policy_schema.Policy{}, Members: []string{}}
+			roles[assignment.Role] = role
+		}
+		role.Members = append(role.Members, assignment.Username)
+	}
+	policies, err := p.ListPolicies()
+	if err != nil {
+		return nil, err
+	}
+	for _, policy := range policies {
+		if role, ok := roles[policy.Subject]; ok {
+			role.Policies = append(role.Policies, policy)
+		}
+	}
+	ret := make([]*policy_schema.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		ret = append(ret, role)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Builtin != ret[j].Builtin {
+			return ret[i].Builtin
+		}
+		return ret[i].Name < ret[j].Name
+	})
+	return ret, nil
+}
@@ -0,0 +1,90 @@
+package policy_service
+
+import (
+	"context"
+	"fadacontrol/internal/base/data"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/policy_schema"
+	"fadacontrol/internal/service/auth_service"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+)
+
+func newTestPolicyService(t *testing.T) *PolicyService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "policy.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.User{}); err != nil {
+		t.Fatal(err)
+	}
+	adapter, err := data.NewAdapterByDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enforcer, err := data.NewEnforcer(adapter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, username := range []string{"alice", "bob"} {
+		if err := db.Create(&entity.User{Username: username}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewPolicyService(context.Background(), db, auth_service.NewAuthService(enforcer))
+}
+
+func TestAddRoleAssignment(t *testing.T) {
+	p := newTestPolicyService(t)
+	if err := p.AddPolicy(&policy_schema.Policy{Subject: "backup", Object: "cmd:backup", Action: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		assignment policy_schema.RoleAssignment
+		err        *exception.Exception
+	}{
+		{policy_schema.RoleAssignment{Username: "alice", Role: auth_service.RoleOperator}, nil},
+		{policy_schema.RoleAssignment{Username: "alice", Role: "backup"}, nil},
+		{policy_schema.RoleAssignment{Username: "alice", Role: "bob"}, exception.ErrUserParameterError},
+		{policy_schema.RoleAssignment{Username: "alice", Role: "alice"}, exception.ErrUserParameterError},
+		{policy_schema.RoleAssignment{Username: "alice", Role: "missing"}, exception.ErrUserParameterError},
+		{policy_schema.RoleAssignment{Username: "alice", Role: "*"}, exception.ErrUserParameterError},
+		{policy_schema.RoleAssignment{Username: "carol", Role: auth_service.RoleViewer}, exception.ErrUserResourceNotFound},
+	}
+	for _, tt := range tests {
+		err := p.AddRoleAssignment(&tt.assignment)
+		if tt.err == nil {
+			if err != nil {
+				t.Errorf("%+v: got %v", tt.assignment, err)
+			}
+			continue
+		}
+		ex, ok := err.(*exception.Exception)
+		if !ok || ex.Code != tt.err.Code {
+			t.Errorf("%+v: got %v, want %s", tt.assignment, err, tt.err.Msg)
+		}
+	}
+
+	roles, err := p.ListRoles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	// the built-in roles come first, then the roles with members
+	want := []string{auth_service.RoleAdmin, auth_service.RoleOperator, auth_service.RoleViewer, "backup"}
+	if len(names) != len(want) {
+		t.Fatalf("got roles %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("got roles %v, want %v", names, want)
+		}
+	}
+}
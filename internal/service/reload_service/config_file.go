@@ -0,0 +1,207 @@
+package reload_service
+
+import (
+	"errors"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/entity"
+	"fadacontrol/pkg/secure"
+	"fadacontrol/pkg/utils"
+	"fmt"
+	"gorm.io/gorm"
+	"os"
+)
+
+// ApplyConfigFile writes the settings of config.yml to the database, the fields set in the file overwrite the
+// values changed through the API.
+func (r *ReloadService) ApplyConfigFile() error {
+	_conf := utils.GetValueFromContext(r.ctx, constants.ConfKey, conf.NewDefaultConf())
+	var errs []error
+	for key, h := range _conf.Http {
+		if h == nil {
+			continue
+		}
+		if err := r.applyHttpConfig(conf.HttpServiceNames[key], h); err != nil {
+			errs = append(errs, fmt.Errorf("http.%s: %v", key, err))
+		}
+	}
+	if _conf.Discovery != nil && _conf.Discovery.Enabled != nil {
+		err := r.db.Model(&entity.DiscoverConfig{}).Where("1 = 1").Update("enabled", *_conf.Discovery.Enabled).Error
+		if err != nil {
+			errs = append(errs, fmt.Errorf("discovery: %v", err))
+		}
+	}
+	if _conf.Remote != nil {
+		if err := r.applyRemoteConfig(_conf.Remote); err != nil {
+			errs = append(errs, fmt.Errorf("remote: %v", err))
+		}
+	}
+	// the roles defined by the policies of the file may be assigned to its users
+	for _, p := range _conf.Policies {
+		if _, err := r.auth.AddPolicy(p.Subject, p.Object, p.Action); err != nil {
+			errs = append(errs, fmt.Errorf("policy %s, %s, %s: %v", p.Subject, p.Object, p.Action, err))
+		}
+	}
+	for _, u := range _conf.Users {
+		if err := r.applyUser(&u); err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %v", u.Username, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// removedPolicies returns the policies of old that are not in current.
+func removedPolicies(old, current []conf.PolicyConf) []conf.PolicyConf {
+	var ret []conf.PolicyConf
+	for _, p := range old {
+		found := false
+		for _, q := range current {
+			if p == q {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+// removePolicies removes the policies that were removed from config.yml, they are restored when no administrator
+// would be left.
+func (r *ReloadService) removePolicies(policies []conf.PolicyConf) error {
+	if len(policies) == 0 {
+		return nil
+	}
+	return r.ps.Guard("", func() error {
+		for _, p := range policies {
+			if _, err := r.auth.RemovePolicy(p.Subject, p.Object, p.Action); err != nil {
+				return fmt.Errorf("policy %s, %s, %s: %v", p.Subject, p.Object, p.Action, err)
+			}
+		}
+		return nil
+	}, func() error {
+		var errs []error
+		for _, p := range policies {
+			if _, err := r.auth.AddPolicy(p.Subject, p.Object, p.Action); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+func (r *ReloadService) applyHttpConfig(serviceName string, h *conf.HttpServiceConf) error {
+	values := make(map[string]interface{})
+	if h.Enable != nil {
+		values["enable"] = *h.Enable
+	}
+	if h.Host != nil {
+		values["host"] = *h.Host
+	}
+	if h.Port != nil {
+		values["port"] = *h.Port
+	}
+	if h.EnableHttp3 != nil {
+		values["enable_http3"] = *h.EnableHttp3
+	}
+	if h.ClientAuthMode != nil {
+		values["client_auth_mode"] = *h.ClientAuthMode
+	}
+	if h.CertFile != nil && h.KeyFile != nil {
+		cer, err := os.ReadFile(*h.CertFile)
+		if err != nil {
+			return err
+		}
+		key, err := os.ReadFile(*h.KeyFile)
+		if err != nil {
+			return err
+		}
+		values["cer"] = string(cer)
+		values["key"] = string(key)
+		values["auto_cert"] = false
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return r.db.Model(&entity.HttpConfig{}).Where(&entity.HttpConfig{ServiceName: serviceName}).Updates(values).Error
+}
+
+func (r *ReloadService) applyRemoteConfig(remote *conf.RemoteConf) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var config entity.RemoteConnectConfig
+		if err := tx.First(&config).Error; err != nil {
+			return err
+		}
+		values := make(map[string]interface{})
+		if remote.Enable != nil {
+			values["enable"] = *remote.Enable
+		}
+		if remote.ClientId != nil {
+			values["client_id"] = *remote.ClientId
+		}
+		if remote.TimeStampCheck != nil {
+			values["time_stamp_check"] = *remote.TimeStampCheck
+		}
+		if remote.ApiServerUrl != nil {
+			values["api_server_url"] = *remote.ApiServerUrl
+		}
+		if len(values) > 0 {
+			if err := tx.Model(&config).Updates(values).Error; err != nil {
+				return err
+			}
+		}
+		if remote.MsgServerUrls == nil {
+			return nil
+		}
+		if err := tx.Unscoped().Where("1 = 1").Delete(&entity.RemoteMsgServer{}).Error; err != nil {
+			return err
+		}
+		for _, url := range remote.MsgServerUrls {
+			if err := tx.Create(&entity.RemoteMsgServer{MsgServerUrl: url, RemoteConnectConfigId: config.ID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// applyUser creates the user with the password of the file when it does not exist, the password of an existing
+// user is left alone.
+func (r *ReloadService) applyUser(u *conf.UserConf) error {
+	if err := r.ps.ValidateRoles(u.Username, u.Roles); err != nil {
+		return err
+	}
+	var user entity.User
+	err := r.db.Where(&entity.User{Username: u.Username}).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if u.Password == "" {
+			return errors.New("a password is required to create the user")
+		}
+		if r.auth.IsRole(u.Username) {
+			return errors.New(u.Username + " is the name of a role")
+		}
+		hash, err := secure.HashPassword(u.Password)
+		if err != nil {
+			return err
+		}
+		user = entity.User{Username: u.Username, Password: hash}
+		if err := r.db.Create(&user).Error; err != nil {
+			return err
+		}
+		logger.Infof("user %s created from the config file", u.Username)
+	} else if err != nil {
+		return err
+	}
+	if u.Disabled != nil && user.Disabled != *u.Disabled {
+		if err := r.db.Model(&user).Update("disabled", *u.Disabled).Error; err != nil {
+			return err
+		}
+	}
+	if u.Roles == nil {
+		return nil
+	}
+	return r.auth.SetRoles(u.Username, u.Roles)
+}
@@ -0,0 +1,196 @@
+package reload_service
+
+import (
+	"context"
+	"errors"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/events"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/schema"
+	"fadacontrol/internal/schema/health_schema"
+	"fadacontrol/internal/service/auth_service"
+	"fadacontrol/internal/service/discovery_service"
+	"fadacontrol/internal/service/http_service"
+	"fadacontrol/internal/service/policy_service"
+	"fadacontrol/internal/service/remote_service"
+	"fadacontrol/pkg/goroutine"
+	"fadacontrol/pkg/utils"
+	"gorm.io/gorm"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ReloadService applies config.yml to the running service, the file is watched for changes.
+type ReloadService struct {
+	ps     *policy_service.PolicyService
+	ctx    context.Context
+	db     *gorm.DB
+	auth   *auth_service.AuthService
+	hs     *http_service.HttpService
+	ds     *discovery_service.DiscoverService
+	rs     *remote_service.RemoteService
+	lock   sync.Mutex
+	cancel context.CancelFunc
+	// StartLock guards cancel.
+	StartLock sync.Mutex
+}
+
+func NewReloadService(ps *policy_service.PolicyService, ctx context.Context, db *gorm.DB, auth *auth_service.AuthService, hs *http_service.HttpService, ds *discovery_service.DiscoverService, rs *remote_service.RemoteService) *ReloadService {
+	return &ReloadService{ps: ps, ctx: ctx, db: db, auth: auth, hs: hs, ds: ds, rs: rs}
+}
+
+// Reload reads config.yml again and applies the log level, the discovery and remote settings and the HTTP
+// listeners. An invalid file is rejected and nothing is changed. The settings of the file are only written to the
+// database when the file changed, the policies removed from the file are removed, and the discovery and remote
+// services are only restarted when their settings in the file changed.
+func (r *ReloadService) Reload() (*schema.ReloadResponse, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	_conf := utils.GetValueFromContext(r.ctx, constants.ConfKey, conf.NewDefaultConf())
+	c := &conf.Conf{LogLevel: conf.DefaultLogLevel}
+	path, err := c.ReadConfigFile(_conf.GetWorkdir())
+	if err != nil {
+		logger.Errorf("failed to reload the config file:\n%v", err)
+		return nil, exception.ErrUserParameterError.SetMsg(err.Error())
+	}
+	c.SetPath(path)
+	// Reload is the only writer of the settings and runs under r.lock
+	discoveryChanged := !reflect.DeepEqual(_conf.Discovery, c.Discovery)
+	remoteChanged := !reflect.DeepEqual(_conf.Remote, c.Remote)
+	fileChanged := discoveryChanged || remoteChanged || !reflect.DeepEqual(_conf.Http, c.Http) ||
+		!reflect.DeepEqual(_conf.Users, c.Users) || !reflect.DeepEqual(_conf.Policies, c.Policies)
+	removed := removedPolicies(_conf.Policies, c.Policies)
+	_conf.Reload(c)
+	logger.Infof("reloading the configuration from %s", path)
+
+	resp := &schema.ReloadResponse{Success: true, Components: make([]schema.ReloadComponent, 0)}
+	add := func(name string, changed bool, err error) {
+		component := schema.ReloadComponent{Name: name, Status: schema.ReloadUnchanged}
+		if changed {
+			component.Status = schema.ReloadApplied
+		}
+		if err != nil {
+			logger.Errorf("failed to reload %s: %v", name, err)
+			component.Status = schema.ReloadFailed
+			component.Error = err.Error()
+			resp.Success = false
+		}
+		resp.Components = append(resp.Components, component)
+	}
+
+	if fileChanged {
+		err := r.ApplyConfigFile()
+		add("config_file", true, errors.Join(err, r.removePolicies(removed)))
+	} else {
+		add("config_file", false, nil)
+	}
+
+	if _conf.LogLevel == logger.GetLogLevel() {
+		add("log", false, nil)
+	} else {
+		add("log", true, logger.SetLogLevel(_conf.LogLevel))
+	}
+
+	if discoveryChanged {
+		err = r.ds.RestartService()
+		if err == nil {
+			err = healthError(r.ds.Health())
+		}
+		add("discovery", true, err)
+	} else {
+		add("discovery", false, nil)
+	}
+
+	if remoteChanged {
+		err = r.rs.RestartService()
+		if err == nil {
+			err = healthError(r.rs.Health())
+		}
+		add("remote", true, err)
+	} else {
+		add("remote", false, nil)
+	}
+
+	for _, name := range []string{http_service.HttpsServiceApi, http_service.HttpServiceAdmin} {
+		restarted, err := r.hs.RestartServer(name)
+		add(name, restarted, err)
+	}
+
+	events.PublishConfigChanged(events.SectionReload, path, nil)
+	return resp, nil
+}
+
+func healthError(h health_schema.ComponentHealth) error {
+	if h.Status == health_schema.StatusDown {
+		return errors.New(h.Error)
+	}
+	return nil
+}
+
+// fileState is what is compared to notice that config.yml changed.
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func (r *ReloadService) configFileState() fileState {
+	_conf := utils.GetValueFromContext(r.ctx, constants.ConfKey, conf.NewDefaultConf())
+	path := _conf.GetPath()
+	if path == "" {
+		path = filepath.Join(_conf.GetWorkdir(), "config.yml")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
+
+// StartService watches config.yml and reloads the configuration when the file is written.
+func (r *ReloadService) StartService() error {
+	if !r.StartLock.TryLock() {
+		return exception.ErrSystemServiceAlreadyRunning
+	}
+	defer r.StartLock.Unlock()
+	if r.cancel != nil {
+		return exception.ErrSystemServiceAlreadyRunning
+	}
+	ctx, cancel := context.WithCancel(r.ctx)
+	r.cancel = cancel
+	goroutine.RecoverGO(func() {
+		ticker := time.NewTicker(conf.ConfigWatchInterval)
+		defer ticker.Stop()
+		last := r.configFileState()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			state := r.configFileState()
+			if state == last {
+				continue
+			}
+			last = state
+			logger.Info("the config file changed")
+			r.Reload()
+		}
+	})
+	return nil
+}
+
+func (r *ReloadService) StopService() error {
+	r.StartLock.Lock()
+	defer r.StartLock.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package remote_service
+
+import (
+	"errors"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/schema/audit_schema"
+	"fadacontrol/internal/schema/custom_command_schema"
+	"fadacontrol/internal/schema/remote_schema"
+	RMTT "github.com/czqu/rmtt-go"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// runMacro starts a macro on behalf of the remote requester and replies with the run id, the step log is available over REST.
+func (r *RemoteService) runMacro(client RMTT.Client, msg *remote_schema.MacroMsg, requestId []byte) {
+	run, err := r.ms.RunMacroByName(msg.Name, remoteRequester, custom_command_schema.RemoteSource)
+	params := map[string]interface{}{"macro_name": msg.Name}
+	if run != nil {
+		params["run_id"] = run.Id
+	}
+	r.audit(audit_schema.ActionMacro, params, err)
+	if err != nil {
+		r.PushProtoRet(client, true, toException(err), requestId)
+		return
+	}
+	r.PushProtoMsg(client, true, &remote_schema.RemoteMsg{
+		Type:      remote_schema.MsgType_RunMacro,
+		Timestamp: timestamppb.New(time.Now()),
+		MsgBody:   &remote_schema.RemoteMsg_MacroRunMsg{MacroRunMsg: &remote_schema.MacroRunMsg{RunId: uint32(run.Id), Status: run.Status}},
+	}, requestId)
+}
+
+func toException(err error) *exception.Exception {
+	var ex *exception.Exception
+	if errors.As(err, &ex) {
+		return ex
+	}
+	return exception.ErrSystemUnknownException.SetMsg(err.Error())
+}
@@ -0,0 +1,83 @@
+package remote_service
+
+import (
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/schema"
+	"fadacontrol/internal/schema/audit_schema"
+	"fadacontrol/internal/schema/remote_schema"
+	"fadacontrol/pkg/sys"
+	RMTT "github.com/czqu/rmtt-go"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+const remoteRequester = "remote"
+
+func toPendingActionInfo(p *schema.PendingAction) *remote_schema.PendingActionInfo {
+	return &remote_schema.PendingActionInfo{
+		Id:           p.Id,
+		Action:       p.Action,
+		ShutdownType: sys.ShutdownTypeToProtoType(sys.ShutdownType(p.ShutdownType)),
+		Requester:    p.Requester,
+		Source:       p.Source,
+		ExecuteAt:    timestamppb.New(p.ExecuteAt),
+		Remaining:    p.Remaining,
+		Status:       p.Status,
+		Code:         int32(p.Code),
+		Msg:          p.Msg,
+	}
+}
+
+func (r *RemoteService) pushPendingActions(client RMTT.Client, actions []schema.PendingAction, requestId []byte) {
+	list := &remote_schema.PendingActionListMsg{Actions: make([]*remote_schema.PendingActionInfo, 0, len(actions))}
+	for i := range actions {
+		list.Actions = append(list.Actions, toPendingActionInfo(&actions[i]))
+	}
+	msg := &remote_schema.RemoteMsg{
+		Type:      remote_schema.MsgType_PendingAction,
+		Timestamp: timestamppb.New(time.Now()),
+		MsgBody:   &remote_schema.RemoteMsg_PendingActionListMsg{PendingActionListMsg: list},
+	}
+	r.PushProtoMsg(client, true, msg, requestId)
+}
+
+func (r *RemoteService) schedulePowerAction(client RMTT.Client, action string, tpe sys.ShutdownType, delay int32, requestId []byte) {
+	pending, ex := r.co.SchedulePowerAction(action, tpe, time.Duration(delay)*time.Second, remoteRequester, schema.PendingSourceRemote)
+	params := map[string]interface{}{"delay": delay}
+	if action == schema.PendingActionShutdown {
+		params["shutdown_type"] = int(tpe)
+	}
+	if pending != nil {
+		params["pending_id"] = pending.Id
+	}
+	r.audit(action, params, ex)
+	if ex != nil {
+		r.PushProtoRet(client, true, ex, requestId)
+		return
+	}
+	r.pushPendingActions(client, []schema.PendingAction{*pending}, requestId)
+}
+
+// handlePendingAction lists, cancels or postpones pending actions and replies with the affected actions.
+func (r *RemoteService) handlePendingAction(client RMTT.Client, msg *remote_schema.PendingActionMsg, requestId []byte) {
+	var pending *schema.PendingAction
+	var ex *exception.Exception
+	switch msg.Op {
+	case remote_schema.PendingActionOp_List:
+		r.pushPendingActions(client, r.co.ListPendingActions(), requestId)
+		return
+	case remote_schema.PendingActionOp_Cancel:
+		pending, ex = r.co.CancelPendingAction(msg.Id)
+		r.audit(audit_schema.ActionCancelPending, map[string]interface{}{"pending_id": msg.Id}, ex)
+	case remote_schema.PendingActionOp_Postpone:
+		pending, ex = r.co.PostponePendingAction(msg.Id, time.Duration(msg.Seconds)*time.Second)
+		r.audit(audit_schema.ActionPostponePending, map[string]interface{}{"pending_id": msg.Id, "seconds": msg.Seconds}, ex)
+	default:
+		ex = exception.ErrUserParameterError
+	}
+	if ex != nil {
+		r.PushProtoRet(client, true, ex, requestId)
+		return
+	}
+	r.pushPendingActions(client, []schema.PendingAction{*pending}, requestId)
+}
@@ -7,14 +7,26 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/events"
 	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/health"
 	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/base/metrics"
 	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema"
+	"fadacontrol/internal/schema/audit_schema"
+	"fadacontrol/internal/schema/health_schema"
 	"fadacontrol/internal/schema/remote_schema"
+	"fadacontrol/internal/service/audit_service"
 	"fadacontrol/internal/service/control_pc"
+	"fadacontrol/internal/service/macro_service"
+	"fadacontrol/internal/service/throttle_service"
 	"fadacontrol/internal/service/unlock"
 	"fadacontrol/pkg/secure"
 	"fadacontrol/pkg/sys"
+	"fadacontrol/pkg/utils"
 	"fmt"
 	RMTT "github.com/czqu/rmtt-go"
 	"google.golang.org/protobuf/proto"
@@ -27,8 +39,10 @@ import (
 )
 
 type RemoteService struct {
+	ms          *macro_service.MacroService
 	co          *control_pc.ControlPCService
 	un          *unlock.UnLockService
+	as          *audit_service.AuditService
 	ctx         context.Context
 	db          *gorm.DB
 	config      entity.RemoteConnectConfig
@@ -37,12 +51,13 @@ type RemoteService struct {
 	StartLock   sync.Mutex
 	StopLock    sync.Mutex
 	RestartLock sync.Mutex
+	state       health.State
 }
 
 const DefaultGenKeyLength = 48
 
-func NewRemoteService(co *control_pc.ControlPCService, un *unlock.UnLockService, ctx context.Context, db *gorm.DB) *RemoteService {
-	return &RemoteService{co: co, un: un, ctx: ctx, db: db, config: entity.RemoteConnectConfig{}}
+func NewRemoteService(as *audit_service.AuditService, ms *macro_service.MacroService, co *control_pc.ControlPCService, un *unlock.UnLockService, ctx context.Context, db *gorm.DB) *RemoteService {
+	return &RemoteService{as: as, ms: ms, co: co, un: un, ctx: ctx, db: db, config: entity.RemoteConnectConfig{}}
 }
 
 const (
@@ -57,13 +72,42 @@ const (
 	END
 )
 
+// invalidMessageType labels the messages that could not be decoded.
+const invalidMessageType = "Invalid"
+
+// countMessage counts a message received from the remote servers, result tells whether it was understood.
+func countMessage(tpe remote_schema.MsgType, result string) {
+	name := invalidMessageType
+	if _, ok := remote_schema.MsgType_name[int32(tpe)]; ok {
+		name = tpe.String()
+	}
+	metrics.RemoteMessage(name, result)
+}
+
+// audit records an action requested through the relay server, the actor is the client id of this computer.
+func (r *RemoteService) audit(action string, params map[string]interface{}, err error) {
+	r.as.Record(audit_schema.Actor{Type: audit_schema.ActorRemote, Name: r.config.ClientId}, audit_schema.SourceRelay, "", action, params, err)
+}
+
+// Health returns the state of the remote connection.
+func (r *RemoteService) Health() health_schema.ComponentHealth {
+	return r.state.Component("remote")
+}
+
 func (r *RemoteService) ProtoHandler(client RMTT.Client, data, requestId []byte) {
 
 	var msg = &remote_schema.RemoteMsg{}
 	err := proto.Unmarshal(data, msg)
 	if err != nil {
 		logger.Warn(err)
+		metrics.RemoteMessage(invalidMessageType, metrics.ResultFailure)
 		r.PushProtoRet(client, true, exception.ErrSystemMessageSerializationFailed, requestId)
+		return
+	}
+	if msg.Type == remote_schema.MsgType_Unknown {
+		countMessage(msg.Type, metrics.ResultFailure)
+	} else {
+		countMessage(msg.Type, metrics.ResultSuccess)
 	}
 	switch msg.Type {
 	case remote_schema.MsgType_Unknown:
@@ -75,12 +119,14 @@ func (r *RemoteService) ProtoHandler(client RMTT.Client, data, requestId []byte)
 				r.PushProtoRet(client, true, exception.ErrUserParameterError, requestId)
 				return
 			}
-			ret := r.un.UnlockPc(unlockMsg.Username, unlockMsg.Password)
+			ret := r.un.UnlockPc(throttle_service.RemoteSource, unlockMsg.Username, unlockMsg.Password)
+			r.audit(audit_schema.ActionUnlock, map[string]interface{}{"username": unlockMsg.Username}, ret)
 			r.PushProtoRet(client, true, ret, requestId)
 		}
 	case remote_schema.MsgType_LockScreen:
 		{
 			ret := r.co.LockWindows(true)
+			r.audit(audit_schema.ActionLock, nil, ret)
 			r.PushProtoRet(client, true, ret, requestId)
 		}
 	case remote_schema.MsgType_Shutdown:
@@ -91,14 +137,42 @@ func (r *RemoteService) ProtoHandler(client RMTT.Client, data, requestId []byte)
 				return
 			}
 			shutdownTpe := sys.ProtoTypeToShutdownType(shutdownMsg.Type)
+			if shutdownMsg.Delay > 0 {
+				r.schedulePowerAction(client, schema.PendingActionShutdown, shutdownTpe, shutdownMsg.Delay, requestId)
+				return
+			}
 			ret := r.co.Shutdown(shutdownTpe)
+			r.audit(audit_schema.ActionShutdown, map[string]interface{}{"shutdown_type": int(shutdownTpe)}, ret)
 			r.PushProtoRet(client, true, ret, requestId)
 		}
 	case remote_schema.MsgType_Standby:
 		{
+			if standbyMsg := msg.GetStandbyMsg(); standbyMsg != nil && standbyMsg.Delay > 0 {
+				r.schedulePowerAction(client, schema.PendingActionStandby, sys.Unknown, standbyMsg.Delay, requestId)
+				return
+			}
 			ret := r.co.Standby()
+			r.audit(audit_schema.ActionStandby, nil, ret)
 			r.PushProtoRet(client, true, ret, requestId)
 		}
+	case remote_schema.MsgType_PendingAction:
+		{
+			pendingMsg := msg.GetPendingActionMsg()
+			if pendingMsg == nil {
+				r.PushProtoRet(client, true, exception.ErrUserParameterError, requestId)
+				return
+			}
+			r.handlePendingAction(client, pendingMsg, requestId)
+		}
+	case remote_schema.MsgType_RunMacro:
+		{
+			macroMsg := msg.GetMacroMsg()
+			if macroMsg == nil {
+				r.PushProtoRet(client, true, exception.ErrUserParameterError, requestId)
+				return
+			}
+			r.runMacro(client, macroMsg, requestId)
+		}
 
 	}
 
@@ -115,6 +189,7 @@ func (r *RemoteService) RRFPMsgHandler(client RMTT.Client, msg RMTT.Message) {
 	err := packet.Unpack(dataSlice) //DecodeAesPack(r.config.Secret, dataSlice)
 	if err != nil {
 		logger.Warn(err)
+		metrics.RemoteMessage(invalidMessageType, metrics.ResultFailure)
 		r.PushTextRet(client, exception.ErrUserControlPacketStructureError, packet.RequestId)
 		return
 	}
@@ -129,6 +204,7 @@ func (r *RemoteService) RRFPMsgHandler(client RMTT.Client, msg RMTT.Message) {
 	decrpyData, err := secure.DecryptData(packet.EncryptionAlgorithm, packet.Data, key)
 	if err != nil {
 		logger.Warn(err)
+		metrics.RemoteMessage(invalidMessageType, metrics.ResultFailure)
 		r.PushTextRet(client, exception.ErrUserMessageDecryptionFailed, packet.RequestId)
 		return
 	}
@@ -136,6 +212,7 @@ func (r *RemoteService) RRFPMsgHandler(client RMTT.Client, msg RMTT.Message) {
 	case remote_schema.ProtoBuf:
 		r.ProtoHandler(client, decrpyData, packet.RequestId)
 	default:
+		metrics.RemoteMessage(invalidMessageType, metrics.ResultFailure)
 		r.PushTextRet(client, exception.ErrUserParameterError, packet.RequestId)
 	}
 
@@ -163,10 +240,6 @@ func (r *RemoteService) PushTextRet(client RMTT.Client, ret *exception.Exception
 }
 
 func (r *RemoteService) PushProtoRet(client RMTT.Client, encryptFlag bool, ex *exception.Exception, requestId []byte) {
-	if len(requestId) > 0xff {
-		return
-	}
-	requestIdLen := uint8(len(requestId))
 	msg := &remote_schema.RemoteMsg{
 		Type:      remote_schema.MsgType_CommonResponse,
 		Timestamp: timestamppb.New(time.Now()),
@@ -177,6 +250,14 @@ func (r *RemoteService) PushProtoRet(client RMTT.Client, encryptFlag bool, ex *e
 			},
 		},
 	}
+	r.PushProtoMsg(client, encryptFlag, msg, requestId)
+}
+
+func (r *RemoteService) PushProtoMsg(client RMTT.Client, encryptFlag bool, msg *remote_schema.RemoteMsg, requestId []byte) {
+	if len(requestId) > 0xff {
+		return
+	}
+	requestIdLen := uint8(len(requestId))
 	data, err := proto.Marshal(msg)
 	if err != nil {
 		logger.Error(err)
@@ -328,13 +409,28 @@ func (r *RemoteService) GetConfig() (*remote_schema.RemoteConnectConfigResponse,
 }
 
 func (r *RemoteService) UpdateRemoteConnectConfig(data *remote_schema.RemoteConnectConfigRequest) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
 		var config entity.RemoteConnectConfig
 
 		if err := tx.First(&config).Error; err != nil {
 
 			return fmt.Errorf("failed to find database: %v", err)
 		}
+		changed := make(map[string]interface{})
+		for field, differs := range map[string]bool{
+			"enable":           data.Enable != config.Enable,
+			"client_id":        data.ClientId != config.ClientId,
+			"time_stamp_check": data.TimeStampCheck != config.TimeStampCheck,
+			"api_server_url":   data.ApiServerUrl != config.ApiServerUrl,
+			"msg_server_urls":  len(data.MsgServerUrls) > 0,
+		} {
+			if differs {
+				changed[field] = true
+			}
+		}
+		if err := r.checkManaged(changed); err != nil {
+			return err
+		}
 
 		config.Enable = data.Enable
 		config.ClientId = data.ClientId
@@ -357,8 +453,19 @@ func (r *RemoteService) UpdateRemoteConnectConfig(data *remote_schema.RemoteConn
 
 		return nil
 	})
+	return events.PublishConfigChanged(events.SectionRemote, "", err)
+}
+
+// checkManaged rejects changes to the fields that are set in config.yml.
+func (r *RemoteService) checkManaged(changed map[string]interface{}) error {
+	_conf := utils.GetValueFromContext(r.ctx, constants.ConfKey, conf.NewDefaultConf())
+	return conf.CheckManaged(_conf.ManagedRemoteFields(), changed)
 }
+
 func (r *RemoteService) PatchRemoteConnectConfig(content map[string]interface{}) error {
+	if err := r.checkManaged(content); err != nil {
+		return err
+	}
 
 	var config entity.RemoteConnectConfig
 	if err := r.db.First(&config).Error; err != nil {
@@ -369,7 +476,7 @@ func (r *RemoteService) PatchRemoteConnectConfig(content map[string]interface{})
 		return err
 	}
 
-	return nil
+	return events.PublishConfigChanged(events.SectionRemote, "", nil)
 
 }
 func (r *RemoteService) RestartService() error {
@@ -442,6 +549,17 @@ func (r *RemoteService) StartService() error {
 	}
 	defer r.StartLock.Unlock()
 	r.done = make(chan struct{})
+	var config entity.RemoteConnectConfig
+	if err := r.db.First(&config).Error; err != nil {
+		r.state.Set(health_schema.StatusDown, err)
+		return err
+	}
+	if !config.Enable {
+		r.state.Set(health_schema.StatusDisabled, nil)
+		return nil
+	}
+	r.state.Set(health_schema.StatusUp, nil)
+	events.Publish(events.RemoteConnected, events.RemoteData{ClientId: config.ClientId})
 	return nil
 	//logger.Debug("starting service")
 	//
@@ -511,6 +629,12 @@ func (r *RemoteService) StopService() error {
 	defer r.StopLock.Unlock()
 
 	logger.Debug("stopping service")
+	if r.state.Component("remote").Status == health_schema.StatusUp {
+		var config entity.RemoteConnectConfig
+		r.db.First(&config)
+		events.Publish(events.RemoteDisconnected, events.RemoteData{ClientId: config.ClientId})
+	}
+	r.state.Set(health_schema.StatusStopped, nil)
 	r.done <- struct{}{}
 	return nil
 }
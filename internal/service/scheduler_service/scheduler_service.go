@@ -0,0 +1,480 @@
+package scheduler_service
+
+import (
+	"context"
+	"errors"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/audit_schema"
+	"fadacontrol/internal/schema/custom_command_schema"
+	"fadacontrol/internal/schema/schedule_schema"
+	"fadacontrol/internal/service/audit_service"
+	"fadacontrol/internal/service/control_pc"
+	"fadacontrol/internal/service/macro_service"
+	"fadacontrol/pkg/cron"
+	"fadacontrol/pkg/goroutine"
+	"fadacontrol/pkg/sys"
+	"fadacontrol/pkg/utils"
+	"fmt"
+	"gorm.io/gorm"
+	"strconv"
+	"sync"
+	"time"
+	_ "time/tzdata"
+)
+
+// missedRunGrace is how late a run may start before it is treated as missed,
+// e.g. because the machine was powered off or suspended.
+const missedRunGrace = time.Minute
+const tickInterval = time.Second
+const maxNextRuns = 50
+
+type SchedulerService struct {
+	ctx       context.Context
+	db        *gorm.DB
+	co        *control_pc.ControlPCService
+	ms        *macro_service.MacroService
+	as        *audit_service.AuditService
+	mu        sync.Mutex
+	cancel    context.CancelFunc
+	StartLock sync.Mutex
+}
+
+func NewSchedulerService(as *audit_service.AuditService, ctx context.Context, db *gorm.DB, co *control_pc.ControlPCService, ms *macro_service.MacroService) *SchedulerService {
+	return &SchedulerService{as: as, ctx: ctx, db: db, co: co, ms: ms}
+}
+
+func (s *SchedulerService) StartService() error {
+	if !s.StartLock.TryLock() {
+		return exception.ErrSystemServiceAlreadyRunning
+	}
+	defer s.StartLock.Unlock()
+	if s.cancel != nil {
+		return exception.ErrSystemServiceAlreadyRunning
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.cancel = cancel
+	s.refreshNextRuns()
+
+	logger.Info("scheduler started")
+	goroutine.RecoverGO(func() {
+		ticker := time.NewTicker(tickInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Info("scheduler stopped")
+				return
+			case now := <-ticker.C:
+				s.tick(now)
+			}
+		}
+	})
+	return nil
+}
+func (s *SchedulerService) StopService() error {
+	s.StartLock.Lock()
+	defer s.StartLock.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	return nil
+}
+
+// refreshNextRuns fills in the next activation of enabled tasks that have none.
+func (s *SchedulerService) refreshNextRuns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var tasks []entity.ScheduledTask
+	if err := s.db.Where("enabled = ? AND next_run_at IS NULL", true).Find(&tasks).Error; err != nil {
+		logger.Errorf("failed to load scheduled tasks: %v", err)
+		return
+	}
+	now := time.Now()
+	for i := range tasks {
+		task := &tasks[i]
+		next, err := nextRun(task.CronExpr, task.RunAt, task.Timezone, now)
+		if err != nil {
+			logger.Warnf("scheduled task %d is invalid: %v", task.ID, err)
+			continue
+		}
+		task.NextRunAt = inUTC(next)
+		s.db.Save(task)
+	}
+}
+
+func (s *SchedulerService) tick(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var tasks []entity.ScheduledTask
+	if err := s.db.Where("enabled = ? AND next_run_at <= ?", true, now.UTC()).Find(&tasks).Error; err != nil {
+		logger.Errorf("failed to load scheduled tasks: %v", err)
+		return
+	}
+	for i := range tasks {
+		task := tasks[i]
+		due := *task.NextRunAt
+		missed := now.Sub(due) > missedRunGrace
+
+		next, err := nextRun(task.CronExpr, nil, task.Timezone, now)
+		if err != nil || task.CronExpr == "" {
+			next = nil
+		}
+		task.NextRunAt = inUTC(next)
+		if task.CronExpr == "" {
+			task.Enabled = false
+		}
+
+		if missed && task.MissedRunPolicy != schedule_schema.MissedRunOnce {
+			logger.Warnf("scheduled task %d missed its run at %v", task.ID, due)
+			s.db.Save(&task)
+			s.db.Create(&entity.ScheduledTaskRun{TaskId: task.ID, ScheduledAt: due, Missed: true, Code: exception.ErrSystemRequestTimeout.Code, Msg: "missed"})
+			continue
+		}
+		task.LastRunAt = &now
+		s.db.Save(&task)
+		goroutine.RecoverGO(func() {
+			s.runTask(task, due, missed)
+		})
+	}
+}
+
+func (s *SchedulerService) runTask(task entity.ScheduledTask, scheduledAt time.Time, missed bool) *exception.Exception {
+	started := time.Now()
+	run := entity.ScheduledTaskRun{TaskId: task.ID, ScheduledAt: scheduledAt, StartedAt: &started, Missed: missed}
+	if err := s.db.Create(&run).Error; err != nil {
+		logger.Errorf("failed to record scheduled task run: %v", err)
+	}
+	logger.Infof("running scheduled task %d (%s), action %s", task.ID, task.Name, task.Action)
+	ex := s.executeAction(&task)
+	if ex == nil {
+		ex = exception.ErrSuccess
+	}
+	s.as.Record(audit_schema.Actor{Type: audit_schema.ActorScheduler, Name: task.Name, Id: strconv.FormatUint(uint64(task.ID), 10)},
+		audit_schema.SourceScheduler, "", task.Action, taskParams(&task, missed), ex)
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.Code = ex.Code
+	run.Msg = ex.Msg
+	if err := s.db.Save(&run).Error; err != nil {
+		logger.Errorf("failed to record scheduled task run: %v", err)
+	}
+	if ex.Code != exception.ErrSuccess.Code {
+		logger.Warnf("scheduled task %d failed: %s", task.ID, ex.ToString())
+	}
+	return ex
+}
+
+func (s *SchedulerService) executeAction(task *entity.ScheduledTask) *exception.Exception {
+	switch task.Action {
+	case schedule_schema.ActionShutdown:
+		tpe := sys.ShutdownType(task.ShutdownType)
+		if tpe == sys.Unknown {
+			tpe = sys.S_E_FORCE_SHUTDOWN
+		}
+		return s.co.Shutdown(tpe)
+	case schedule_schema.ActionStandby:
+		return s.co.Standby()
+	case schedule_schema.ActionLock:
+		_conf := utils.GetValueFromContext(s.ctx, constants.ConfKey, conf.NewDefaultConf())
+		return s.co.LockWindows(_conf.StartMode == conf.ServiceMode)
+	case schedule_schema.ActionCommand:
+		return s.ms.RunConfiguredCommand(task.CommandPath, task.CommandName, task.CreatedBy, custom_command_schema.SchedulerSource)
+	case schedule_schema.ActionMacro:
+		return s.ms.RunMacroByNameSync(task.MacroName, task.CreatedBy, custom_command_schema.SchedulerSource)
+	default:
+		return exception.ErrUserParameterError
+	}
+}
+
+// taskParams returns the parameters of the action of a task for the audit log.
+func taskParams(task *entity.ScheduledTask, missed bool) map[string]interface{} {
+	params := map[string]interface{}{"missed": missed}
+	switch task.Action {
+	case schedule_schema.ActionShutdown:
+		params["shutdown_type"] = task.ShutdownType
+	case schedule_schema.ActionCommand:
+		params["command_path"] = task.CommandPath
+		params["command_name"] = task.CommandName
+	case schedule_schema.ActionMacro:
+		params["macro_name"] = task.MacroName
+	}
+	return params
+}
+
+// nextRun returns the first activation after t, nil when the task will not run again.
+func nextRun(cronExpr string, runAt *time.Time, timezone string, t time.Time) (*time.Time, error) {
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+	if cronExpr == "" {
+		if runAt == nil || !runAt.After(t) {
+			return nil, nil
+		}
+		next := runAt.In(loc)
+		return &next, nil
+	}
+	schedule, err := cron.Parse(cronExpr)
+	if err != nil {
+		return nil, err
+	}
+	next := schedule.Next(t.In(loc))
+	if next.IsZero() {
+		return nil, nil
+	}
+	return &next, nil
+}
+
+// inUTC returns t in UTC. The next runs are stored in UTC, the database compares them as text and the offsets of the
+// time zones of the tasks would not be taken into account.
+func inUTC(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	ret := t.UTC()
+	return &ret
+}
+func loadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(timezone)
+}
+
+func validateTask(req *schedule_schema.ScheduledTaskRequest) error {
+	if (req.CronExpr == "") == (req.RunAt == nil) {
+		return exception.ErrUserParameterError.SetMsg("exactly one of cron_expr and run_at is required")
+	}
+	if req.CronExpr != "" {
+		if _, err := cron.Parse(req.CronExpr); err != nil {
+			return exception.ErrUserParameterError.SetMsg(fmt.Sprintf("invalid cron expression: %v", err))
+		}
+	}
+	if _, err := loadLocation(req.Timezone); err != nil {
+		return exception.ErrUserParameterError.SetMsg(fmt.Sprintf("invalid timezone: %v", err))
+	}
+	switch req.MissedRunPolicy {
+	case "":
+		req.MissedRunPolicy = schedule_schema.MissedRunSkip
+	case schedule_schema.MissedRunSkip, schedule_schema.MissedRunOnce:
+	default:
+		return exception.ErrUserParameterError.SetMsg("invalid missed_run_policy")
+	}
+	switch req.Action {
+	case schedule_schema.ActionStandby, schedule_schema.ActionLock:
+	case schedule_schema.ActionShutdown:
+		if req.ShutdownType < int(sys.Unknown) || req.ShutdownType > int(sys.S_EWX_HYBRID_SHUTDOWN_FORCE_RESTARTAPPS) {
+			return exception.ErrUserParameterError.SetMsg("invalid shutdown_type")
+		}
+	case schedule_schema.ActionCommand:
+		if req.CommandPath == "" || req.CommandName == "" {
+			return exception.ErrUserParameterError.SetMsg("command_path and command_name are required")
+		}
+	case schedule_schema.ActionMacro:
+		if req.MacroName == "" {
+			return exception.ErrUserParameterError.SetMsg("macro_name is required")
+		}
+	default:
+		return exception.ErrUserParameterError.SetMsg("invalid action")
+	}
+	return nil
+}
+
+func toTaskResponse(task *entity.ScheduledTask) *schedule_schema.ScheduledTaskResponse {
+	return &schedule_schema.ScheduledTaskResponse{
+		Id:              task.ID,
+		Name:            task.Name,
+		CronExpr:        task.CronExpr,
+		RunAt:           task.RunAt,
+		Timezone:        task.Timezone,
+		Action:          task.Action,
+		ShutdownType:    task.ShutdownType,
+		CommandPath:     task.CommandPath,
+		CommandName:     task.CommandName,
+		MacroName:       task.MacroName,
+		Enabled:         task.Enabled,
+		MissedRunPolicy: task.MissedRunPolicy,
+		CreatedBy:       task.CreatedBy,
+		LastRunAt:       task.LastRunAt,
+		NextRunAt:       task.NextRunAt,
+	}
+}
+
+func (s *SchedulerService) ListTasks() ([]*schedule_schema.ScheduledTaskResponse, error) {
+	var tasks []entity.ScheduledTask
+	if err := s.db.Order("id").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	ret := make([]*schedule_schema.ScheduledTaskResponse, 0, len(tasks))
+	for i := range tasks {
+		ret = append(ret, toTaskResponse(&tasks[i]))
+	}
+	return ret, nil
+}
+func (s *SchedulerService) getTask(id uint) (*entity.ScheduledTask, error) {
+	var task entity.ScheduledTask
+	if err := s.db.First(&task, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, exception.ErrUserResourceNotFound
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+func (s *SchedulerService) GetTask(id uint) (*schedule_schema.ScheduledTaskResponse, error) {
+	task, err := s.getTask(id)
+	if err != nil {
+		return nil, err
+	}
+	return toTaskResponse(task), nil
+}
+func (s *SchedulerService) applyRequest(task *entity.ScheduledTask, req *schedule_schema.ScheduledTaskRequest) error {
+	if err := validateTask(req); err != nil {
+		return err
+	}
+	task.Name = req.Name
+	task.CronExpr = req.CronExpr
+	task.RunAt = req.RunAt
+	task.Timezone = req.Timezone
+	task.Action = req.Action
+	task.ShutdownType = req.ShutdownType
+	task.CommandPath = req.CommandPath
+	task.CommandName = req.CommandName
+	task.MacroName = req.MacroName
+	task.Enabled = req.Enabled
+	task.MissedRunPolicy = req.MissedRunPolicy
+	task.NextRunAt = nil
+	if task.Enabled {
+		next, err := nextRun(task.CronExpr, task.RunAt, task.Timezone, time.Now())
+		if err != nil {
+			return exception.ErrUserParameterError.SetMsg(err.Error())
+		}
+		task.NextRunAt = inUTC(next)
+	}
+	return nil
+}
+func (s *SchedulerService) CreateTask(req *schedule_schema.ScheduledTaskRequest, username string) (*schedule_schema.ScheduledTaskResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task := &entity.ScheduledTask{CreatedBy: username}
+	if err := s.applyRequest(task, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(task).Error; err != nil {
+		return nil, err
+	}
+	return toTaskResponse(task), nil
+}
+func (s *SchedulerService) UpdateTask(id uint, req *schedule_schema.ScheduledTaskRequest) (*schedule_schema.ScheduledTaskResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task, err := s.getTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyRequest(task, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(task).Error; err != nil {
+		return nil, err
+	}
+	return toTaskResponse(task), nil
+}
+func (s *SchedulerService) DeleteTask(id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task, err := s.getTask(id)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(&entity.ScheduledTaskRun{TaskId: task.ID}).Delete(&entity.ScheduledTaskRun{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(task).Error
+	})
+}
+
+// RunTask runs the task immediately, independent of its schedule.
+func (s *SchedulerService) RunTask(id uint) *exception.Exception {
+	task, err := s.getTask(id)
+	if err != nil {
+		if ex, ok := err.(*exception.Exception); ok {
+			return ex
+		}
+		return exception.ErrSystemUnknownException.SetMsg(err.Error())
+	}
+	return s.runTask(*task, time.Now(), false)
+}
+
+// PreviewNextRuns returns the next activations of a schedule without saving it.
+func (s *SchedulerService) PreviewNextRuns(req *schedule_schema.NextRunsRequest) ([]time.Time, error) {
+	if (req.CronExpr == "") == (req.RunAt == nil) {
+		return nil, exception.ErrUserParameterError.SetMsg("exactly one of cron_expr and run_at is required")
+	}
+	count := req.Count
+	if count <= 0 {
+		count = 5
+	}
+	if count > maxNextRuns {
+		count = maxNextRuns
+	}
+	ret := make([]time.Time, 0, count)
+	t := time.Now()
+	for len(ret) < count {
+		next, err := nextRun(req.CronExpr, req.RunAt, req.Timezone, t)
+		if err != nil {
+			return nil, exception.ErrUserParameterError.SetMsg(err.Error())
+		}
+		if next == nil {
+			break
+		}
+		ret = append(ret, *next)
+		t = *next
+	}
+	return ret, nil
+}
+func (s *SchedulerService) TaskNextRuns(id uint, count int) ([]time.Time, error) {
+	task, err := s.getTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if !task.Enabled {
+		return []time.Time{}, nil
+	}
+	return s.PreviewNextRuns(&schedule_schema.NextRunsRequest{CronExpr: task.CronExpr, RunAt: task.RunAt, Timezone: task.Timezone, Count: count})
+}
+
+func (s *SchedulerService) ListRuns(id uint, page, pageSize int) ([]*schedule_schema.ScheduledTaskRunResponse, int64, error) {
+	if _, err := s.getTask(id); err != nil {
+		return nil, 0, err
+	}
+	var total int64
+	query := s.db.Model(&entity.ScheduledTaskRun{}).Where(&entity.ScheduledTaskRun{TaskId: id})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var runs []entity.ScheduledTaskRun
+	if err := query.Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+	ret := make([]*schedule_schema.ScheduledTaskRunResponse, 0, len(runs))
+	for _, run := range runs {
+		ret = append(ret, &schedule_schema.ScheduledTaskRunResponse{
+			Id:          run.ID,
+			TaskId:      run.TaskId,
+			ScheduledAt: run.ScheduledAt,
+			StartedAt:   run.StartedAt,
+			FinishedAt:  run.FinishedAt,
+			Missed:      run.Missed,
+			Code:        run.Code,
+			Msg:         run.Msg,
+		})
+	}
+	return ret, total, nil
+}
@@ -0,0 +1,72 @@
+package scheduler_service
+
+import (
+	"context"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/schedule_schema"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTickOtherTimezone(t *testing.T) {
+	// the service runs in UTC and the task in Asia/Shanghai
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "scheduler.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.ScheduledTask{}, &entity.ScheduledTaskRun{}); err != nil {
+		t.Fatal(err)
+	}
+	task := entity.ScheduledTask{Name: "lock", CronExpr: "30 7 * * *", Timezone: "Asia/Shanghai", Action: schedule_schema.ActionLock,
+		Enabled: true, MissedRunPolicy: schedule_schema.MissedRunSkip}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), constants.ConfKey, conf.NewDefaultConf())
+	s := NewSchedulerService(nil, ctx, db, nil, nil)
+	s.refreshNextRuns()
+
+	if err := db.First(&task, task.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	due := task.NextRunAt.In(shanghai)
+	if due.Hour() != 7 || due.Minute() != 30 {
+		t.Fatalf("next run = %v, want 07:30 in Asia/Shanghai", due)
+	}
+
+	// the ticker passes the local time of the service
+	// one minute before the activation the task is not due
+	s.tick(task.NextRunAt.Add(-time.Minute).Local())
+	var runs int64
+	db.Model(&entity.ScheduledTaskRun{}).Count(&runs)
+	if runs != 0 {
+		t.Fatalf("the task ran %d times before it was due", runs)
+	}
+
+	// long after the activation it is due and missed, the skip policy records the run without executing it
+	s.tick(task.NextRunAt.Add(missedRunGrace + time.Minute).Local())
+	var run entity.ScheduledTaskRun
+	if err := db.First(&run).Error; err != nil {
+		t.Fatalf("expected the missed run to be recorded: %v", err)
+	}
+	if !run.Missed || !run.ScheduledAt.Equal(*task.NextRunAt) {
+		t.Errorf("run = %+v, want a missed run at %v", run, task.NextRunAt)
+	}
+	var updated entity.ScheduledTask
+	if err := db.First(&updated, task.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if updated.NextRunAt == nil || !updated.NextRunAt.Equal(task.NextRunAt.Add(24*time.Hour)) {
+		t.Errorf("next run = %v, want the next day", updated.NextRunAt)
+	}
+}
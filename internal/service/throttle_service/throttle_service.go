@@ -0,0 +1,95 @@
+package throttle_service
+
+import (
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/pkg/throttle"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindLogin  Kind = "login"
+	KindUnlock Kind = "unlock"
+)
+
+// RemoteSource is the source of attempts arriving over the remote connection, which carries no client address.
+const RemoteSource = "remote"
+
+var sourceConfig = throttle.Config{
+	Threshold:        5,
+	BaseDelay:        time.Second,
+	MaxDelay:         5 * time.Minute,
+	LockoutThreshold: 20,
+	LockoutDuration:  15 * time.Minute,
+	Window:           time.Hour,
+	MaxEntries:       10000,
+}
+
+var accountConfig = throttle.Config{
+	Threshold:        3,
+	BaseDelay:        time.Second,
+	MaxDelay:         5 * time.Minute,
+	LockoutThreshold: 10,
+	LockoutDuration:  15 * time.Minute,
+	Window:           time.Hour,
+	MaxEntries:       10000,
+}
+
+// ThrottleService slows down and temporarily locks out password guessing, failures are tracked per source
+// address across login and unlock, and per account separately for each kind.
+type ThrottleService struct {
+	sources  *throttle.Tracker
+	accounts *throttle.Tracker
+}
+
+func NewThrottleService() *ThrottleService {
+	return &ThrottleService{
+		sources:  throttle.NewTracker(sourceConfig),
+		accounts: throttle.NewTracker(accountConfig),
+	}
+}
+
+func accountKey(kind Kind, account string) string {
+	return string(kind) + ":" + strings.ToLower(account)
+}
+
+func tooManyRequests(wait time.Duration) *exception.Exception {
+	seconds := int(math.Ceil(wait.Seconds()))
+	return exception.ErrUserTooManyRequests.SetMsg(fmt.Sprintf("Too many failed attempts, please try again in %d seconds", seconds))
+}
+
+// Check returns ErrUserTooManyRequests while source or account has to wait before the next attempt.
+func (s *ThrottleService) Check(kind Kind, source, account string) *exception.Exception {
+	wait := s.sources.Check(source)
+	if w := s.accounts.Check(accountKey(kind, account)); w > wait {
+		wait = w
+	}
+	if wait > 0 {
+		return tooManyRequests(wait)
+	}
+	return nil
+}
+
+// Failure records a failed attempt and raises an alert when it locks out the source or the account.
+func (s *ThrottleService) Failure(kind Kind, source, account string) {
+	status := s.sources.Failure(source)
+	if status.Locked {
+		logger.Warnf("security alert: %s locked out for %v after %d failed %s attempts", source, sourceConfig.LockoutDuration, status.Failures, kind)
+	}
+	status = s.accounts.Failure(accountKey(kind, account))
+	if status.Locked {
+		logger.Warnf("security alert: %s account %q locked out for %v after %d failed attempts, the last from %s", kind, account, accountConfig.LockoutDuration, status.Failures, source)
+	}
+	logger.Infof("failed %s attempt for account %q from %s", kind, account, source)
+}
+
+// Success forgets the failures of the account, the failures of the source expire on their own
+// so that a valid account can not be used to reset the counter while guessing others.
+func (s *ThrottleService) Success(kind Kind, account string) {
+	s.accounts.Reset(accountKey(kind, account))
+}
@@ -0,0 +1,45 @@
+package token_service
+
+import (
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/entity"
+	"fadacontrol/pkg/secure"
+	"strings"
+)
+
+// LocalTokenName is the name of the token the service issues at startup for the ctl commands.
+const LocalTokenName = "local control"
+
+// localTokenAddresses restricts the local token to the loopback interface.
+var localTokenAddresses = []string{"127.0.0.1", "::1"}
+
+// IssueLocalToken replaces the local token of username with a new one that is only accepted from the loopback
+// interface, the previous token stops working.
+func (s *TokenService) IssueLocalToken(username string) (string, error) {
+	if err := s.RevokeLocalToken(username); err != nil {
+		return "", err
+	}
+	secret, err := secure.GenerateRandomBase58Key(secretLength)
+	if err != nil {
+		return "", err
+	}
+	raw := TokenPrefix + secret
+	token := &entity.ApiToken{
+		Username:   username,
+		Name:       LocalTokenName,
+		Prefix:     raw[:displayPrefixLength],
+		Hash:       hashToken(raw),
+		Scopes:     ScopeAll,
+		AllowedIPs: strings.Join(localTokenAddresses, ","),
+	}
+	if err := s.db.Create(token).Error; err != nil {
+		return "", err
+	}
+	logger.Infof("local api token %d issued for user %s", token.ID, username)
+	return raw, nil
+}
+
+// RevokeLocalToken removes the local token of username.
+func (s *TokenService) RevokeLocalToken(username string) error {
+	return s.db.Unscoped().Where("username = ? AND name = ?", username, LocalTokenName).Delete(&entity.ApiToken{}).Error
+}
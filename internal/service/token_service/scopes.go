@@ -0,0 +1,127 @@
+package token_service
+
+import (
+	"fadacontrol/internal/schema/token_schema"
+	"fadacontrol/internal/service/auth_service"
+	"github.com/casbin/casbin/v2/util"
+	"sort"
+	"strings"
+)
+
+// ScopeAll grants every endpoint the owner of the token may access, except the self-service ones.
+const ScopeAll = "*"
+
+// apiPrefixes are stripped from the request path before it is matched against the scope rules.
+var apiPrefixes = []string{"/admin/api/v1", "/api/v1"}
+
+type scopeRule struct {
+	path   string
+	action auth_service.Action
+}
+
+type scope struct {
+	description string
+	rules       []scopeRule
+}
+
+var scopes = map[string]scope{
+	"power:lock": {"Lock the computer", []scopeRule{
+		{"/control-pc/lock", auth_service.Write},
+	}},
+	"power:shutdown": {"Shut down the computer", []scopeRule{
+		{"/control-pc/shutdown", auth_service.Write},
+	}},
+	"power:standby": {"Put the computer to sleep", []scopeRule{
+		{"/control-pc/standby", auth_service.Write},
+	}},
+	"power:pending": {"View, cancel and postpone pending power actions", []scopeRule{
+		{"/control-pc/pending", auth_service.Read},
+		{"/control-pc/pending/:id", auth_service.Read},
+		{"/control-pc/pending/:id", auth_service.Write},
+		{"/control-pc/pending/:id/postpone", auth_service.Write},
+	}},
+	"info:read": {"Read software, network interface and power saving information", []scopeRule{
+		{"/info", auth_service.Read},
+		{"/info/*", auth_service.Read},
+		{"/interface/*", auth_service.Read},
+		{"/power-saving/status", auth_service.Read},
+	}},
+	"metrics:read": {"Read the Prometheus metrics", []scopeRule{
+		{"/metrics", auth_service.Read},
+	}},
+	"audit:read": {"Read and export the audit log", []scopeRule{
+		{"/audit", auth_service.Read},
+		{"/audit/export", auth_service.Read},
+	}},
+	"events:read": {"Stream the events of the service", []scopeRule{
+		{"/events", auth_service.Read},
+		{"/events/topics", auth_service.Read},
+	}},
+	"logs:read": {"Read the logs", []scopeRule{
+		{"/logs", auth_service.Read},
+		{"/logs/:module", auth_service.Read},
+		{"/logs/:module/search", auth_service.Read},
+	}},
+	"schedule:read": {"View scheduled tasks and their history", []scopeRule{
+		{"/schedule/tasks", auth_service.Read},
+		{"/schedule/tasks/*", auth_service.Read},
+	}},
+	"schedule:run": {"Run scheduled tasks immediately", []scopeRule{
+		{"/schedule/tasks/:id/run", auth_service.Write},
+	}},
+	"macros:read": {"View macros and their runs", []scopeRule{
+		{"/macros", auth_service.Read},
+		{"/macros/*", auth_service.Read},
+		{"/macro-runs/:id", auth_service.Read},
+	}},
+	"macros:run": {"Run macros", []scopeRule{
+		{"/macros/:id/run", auth_service.Write},
+	}},
+}
+
+func isValidScope(name string) bool {
+	if name == ScopeAll {
+		return true
+	}
+	_, ok := scopes[name]
+	return ok
+}
+
+// ListScopes returns the scopes a token can be created with.
+func ListScopes() []*token_schema.ScopeResponse {
+	ret := []*token_schema.ScopeResponse{{Name: ScopeAll, Description: "Everything the owner of the token may access"}}
+	names := make([]string, 0, len(scopes))
+	for name := range scopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ret = append(ret, &token_schema.ScopeResponse{Name: name, Description: scopes[name].description})
+	}
+	return ret
+}
+
+// ScopesPermit reports whether one of the granted scopes covers the request.
+func ScopesPermit(granted []string, path string, act auth_service.Action) bool {
+	trimmed := ""
+	for _, prefix := range apiPrefixes {
+		if strings.HasPrefix(path, prefix+"/") {
+			trimmed = strings.TrimPrefix(path, prefix)
+			break
+		}
+	}
+	if trimmed == "" {
+		return false
+	}
+	for _, name := range granted {
+		if name == ScopeAll {
+			return true
+		}
+		for _, rule := range scopes[name].rules {
+			if rule.action == act && util.KeyMatch2(trimmed, rule.path) {
+				return true
+			}
+		}
+	}
+	return false
+}
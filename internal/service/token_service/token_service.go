@@ -0,0 +1,218 @@
+package token_service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/token_schema"
+	"fadacontrol/pkg/secure"
+	"gorm.io/gorm"
+	"net"
+	"strings"
+	"time"
+)
+
+// TokenPrefix marks a bearer token as an API token rather than a JWT.
+const TokenPrefix = "fct_"
+
+const (
+	secretLength = 32
+	// displayPrefixLength is the number of characters of the token kept in clear text.
+	displayPrefixLength = len(TokenPrefix) + 6
+	// lastUsedInterval limits how often the last-used information is written to the database.
+	lastUsedInterval = time.Minute
+)
+
+type TokenService struct {
+	db *gorm.DB
+}
+
+func NewTokenService(db *gorm.DB) *TokenService {
+	return &TokenService{db: db}
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
+
+func toTokenResponse(token *entity.ApiToken) *token_schema.TokenResponse {
+	return &token_schema.TokenResponse{
+		Id:         token.ID,
+		Username:   token.Username,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     splitList(token.Scopes),
+		AllowedIPs: splitList(token.AllowedIPs),
+		ExpiresAt:  token.ExpiresAt,
+		RevokedAt:  token.RevokedAt,
+		LastUsedAt: token.LastUsedAt,
+		LastUsedIP: token.LastUsedIP,
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+func validateCreateRequest(req *token_schema.CreateTokenRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > token_schema.MaxTokenNameLength {
+		return exception.ErrUserParameterError.SetMsg("the token name must be 1 to 64 characters long")
+	}
+	if len(req.Scopes) == 0 {
+		return exception.ErrUserParameterError.SetMsg("at least one scope is required")
+	}
+	for _, s := range req.Scopes {
+		if !isValidScope(s) {
+			return exception.ErrUserParameterError.SetMsg("unknown scope " + s)
+		}
+	}
+	for _, ip := range req.AllowedIPs {
+		if net.ParseIP(ip) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(ip); err != nil {
+			return exception.ErrUserParameterError.SetMsg("invalid address " + ip)
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return exception.ErrUserParameterError.SetMsg("the expiry time must be in the future")
+	}
+	return nil
+}
+
+// CreateToken creates a token owned by username, the secret is only part of this response.
+func (s *TokenService) CreateToken(username string, req *token_schema.CreateTokenRequest) (*token_schema.CreateTokenResponse, error) {
+	if err := validateCreateRequest(req); err != nil {
+		return nil, err
+	}
+	var count int64
+	if err := s.db.Model(&entity.ApiToken{}).Where("username = ? AND revoked_at IS NULL", username).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= token_schema.MaxTokensPerUser {
+		return nil, exception.ErrUserParameterError.SetMsg("too many tokens, please revoke unused ones")
+	}
+	secret, err := secure.GenerateRandomBase58Key(secretLength)
+	if err != nil {
+		return nil, err
+	}
+	raw := TokenPrefix + secret
+	token := &entity.ApiToken{
+		Username:   username,
+		Name:       strings.TrimSpace(req.Name),
+		Prefix:     raw[:displayPrefixLength],
+		Hash:       hashToken(raw),
+		Scopes:     strings.Join(req.Scopes, ","),
+		AllowedIPs: strings.Join(req.AllowedIPs, ","),
+		ExpiresAt:  req.ExpiresAt,
+	}
+	if err := s.db.Create(token).Error; err != nil {
+		return nil, err
+	}
+	logger.Infof("api token %d (%s) created for user %s", token.ID, token.Name, username)
+	return &token_schema.CreateTokenResponse{TokenResponse: *toTokenResponse(token), Token: raw}, nil
+}
+
+// ListTokens returns the tokens of username, or of every user when username is empty.
+func (s *TokenService) ListTokens(username string) ([]*token_schema.TokenResponse, error) {
+	var tokens []entity.ApiToken
+	tx := s.db.Order("id")
+	if username != "" {
+		tx = tx.Where("username = ?", username)
+	}
+	if err := tx.Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	ret := make([]*token_schema.TokenResponse, 0, len(tokens))
+	for i := range tokens {
+		ret = append(ret, toTokenResponse(&tokens[i]))
+	}
+	return ret, nil
+}
+
+// RevokeToken revokes the token with id, an empty username allows revoking the tokens of every user.
+func (s *TokenService) RevokeToken(id uint, username string) error {
+	var token entity.ApiToken
+	tx := s.db.Where("id = ?", id)
+	if username != "" {
+		tx = tx.Where("username = ?", username)
+	}
+	if err := tx.First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return exception.ErrUserResourceNotFound
+		}
+		return err
+	}
+	if token.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	if err := s.db.Model(&token).Update("revoked_at", &now).Error; err != nil {
+		return err
+	}
+	logger.Infof("api token %d of user %s revoked", token.ID, token.Username)
+	return nil
+}
+
+// DeleteUserTokens removes every token owned by username.
+func (s *TokenService) DeleteUserTokens(username string) error {
+	return s.db.Unscoped().Where("username = ?", username).Delete(&entity.ApiToken{}).Error
+}
+
+func ipAllowed(allowed []string, ip net.IP) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+	for _, a := range allowed {
+		if strings.Contains(a, "/") {
+			if _, network, err := net.ParseCIDR(a); err == nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if allowedIP := net.ParseIP(a); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticate looks up the raw token and checks that it is usable from remoteIP.
+func (s *TokenService) Authenticate(raw string, remoteIP string) (*entity.ApiToken, error) {
+	var token entity.ApiToken
+	if err := s.db.Where("hash = ?", hashToken(raw)).First(&token).Error; err != nil {
+		return nil, exception.ErrUserUnauthorizedAccess
+	}
+	now := time.Now()
+	if token.RevokedAt != nil || (token.ExpiresAt != nil && now.After(*token.ExpiresAt)) {
+		return nil, exception.ErrUserUnauthorizedAccess
+	}
+	if !ipAllowed(splitList(token.AllowedIPs), net.ParseIP(remoteIP)) {
+		logger.Warnf("api token %d of user %s used from disallowed address %s", token.ID, token.Username, remoteIP)
+		return nil, exception.ErrUserUnauthorizedAccess
+	}
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > lastUsedInterval || token.LastUsedIP != remoteIP {
+		err := s.db.Model(&token).UpdateColumns(map[string]interface{}{"last_used_at": now, "last_used_ip": remoteIP}).Error
+		if err != nil {
+			logger.Warnf("failed to record the use of api token %d: %v", token.ID, err)
+		}
+	}
+	return &token, nil
+}
+
+// Scopes returns the scopes granted to token.
+func (s *TokenService) Scopes(token *entity.ApiToken) []string {
+	return splitList(token.Scopes)
+}
@@ -0,0 +1,143 @@
+package token_service
+
+import (
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/token_schema"
+	"fadacontrol/internal/service/auth_service"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScopesPermit(t *testing.T) {
+	tests := []struct {
+		granted []string
+		path    string
+		act     auth_service.Action
+		allowed bool
+	}{
+		{[]string{"power:lock"}, "/api/v1/control-pc/lock", auth_service.Write, true},
+		{[]string{"power:lock"}, "/admin/api/v1/control-pc/lock", auth_service.Write, true},
+		{[]string{"power:lock"}, "/api/v1/control-pc/lock", auth_service.Read, false},
+		{[]string{"power:lock"}, "/api/v1/control-pc/shutdown", auth_service.Write, false},
+		{[]string{"power:pending"}, "/api/v1/control-pc/pending/42/postpone", auth_service.Write, true},
+		{[]string{"power:pending"}, "/api/v1/control-pc/pending/42/postpone/now", auth_service.Write, false},
+		{[]string{"info:read"}, "/api/v1/info/software", auth_service.Read, true},
+		{[]string{"macros:read", "macros:run"}, "/admin/api/v1/macros/3/run", auth_service.Write, true},
+		{[]string{"macros:read"}, "/admin/api/v1/macros/3/run", auth_service.Write, false},
+		{[]string{"logs:read"}, "/admin/api/v1/logs/app/search", auth_service.Read, true},
+		{[]string{"logs:read"}, "/admin/api/v1/logs/app/search", auth_service.Write, false},
+		{[]string{ScopeAll}, "/admin/api/v1/users", auth_service.Write, true},
+		// paths outside of the api are never covered
+		{[]string{ScopeAll}, "/control-pc/lock", auth_service.Write, false},
+		{[]string{"power:lock"}, "/api/v1x/control-pc/lock", auth_service.Write, false},
+		{[]string{"unknown"}, "/api/v1/control-pc/lock", auth_service.Write, false},
+		{nil, "/api/v1/control-pc/lock", auth_service.Write, false},
+	}
+	for _, tt := range tests {
+		if got := ScopesPermit(tt.granted, tt.path, tt.act); got != tt.allowed {
+			t.Errorf("%v %s %s: got %v, want %v", tt.granted, tt.act, tt.path, got, tt.allowed)
+		}
+	}
+}
+
+func TestIpAllowed(t *testing.T) {
+	allowed := []string{"192.168.1.10", "10.0.0.0/8", "fd00::/64", "invalid"}
+	tests := []struct {
+		ip      string
+		allowed bool
+	}{
+		{"192.168.1.10", true},
+		{"192.168.1.11", false},
+		{"10.200.3.4", true},
+		{"11.0.0.1", false},
+		{"fd00::1", true},
+		{"fd01::1", false},
+		{"::ffff:10.1.1.1", true},
+		{"not an ip", false},
+	}
+	for _, tt := range tests {
+		if got := ipAllowed(allowed, net.ParseIP(tt.ip)); got != tt.allowed {
+			t.Errorf("%s: got %v, want %v", tt.ip, got, tt.allowed)
+		}
+	}
+	if !ipAllowed(nil, nil) {
+		t.Error("a token without allowed addresses is usable from everywhere")
+	}
+}
+
+func newTestTokenService(t *testing.T) *TokenService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "token.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.ApiToken{}); err != nil {
+		t.Fatal(err)
+	}
+	return NewTokenService(db)
+}
+
+func TestAuthenticate(t *testing.T) {
+	s := newTestTokenService(t)
+	resp, err := s.CreateToken("alice", &token_schema.CreateTokenRequest{Name: "backup", Scopes: []string{"power:lock"}, AllowedIPs: []string{"10.0.0.0/8"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := s.Authenticate(resp.Token, "10.1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Username != "alice" || len(s.Scopes(token)) != 1 {
+		t.Errorf("unexpected token %+v", token)
+	}
+	if _, err := s.Authenticate(resp.Token, "192.168.1.1"); err == nil {
+		t.Error("the token was accepted from a disallowed address")
+	}
+	if _, err := s.Authenticate(resp.Token+"x", "10.1.2.3"); err == nil {
+		t.Error("an unknown token was accepted")
+	}
+	if err := s.RevokeToken(resp.Id, "bob"); err == nil {
+		t.Error("bob revoked the token of alice")
+	}
+	if err := s.RevokeToken(resp.Id, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Authenticate(resp.Token, "10.1.2.3"); err == nil {
+		t.Error("a revoked token was accepted")
+	}
+
+	expires := time.Now().Add(time.Hour)
+	resp, err = s.CreateToken("alice", &token_schema.CreateTokenRequest{Name: "expiring", Scopes: []string{ScopeAll}, ExpiresAt: &expires})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.db.Model(&entity.ApiToken{}).Where("id = ?", resp.Id).Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Authenticate(resp.Token, "10.1.2.3"); err == nil {
+		t.Error("an expired token was accepted")
+	}
+}
+
+func TestValidateCreateRequest(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	tests := []*token_schema.CreateTokenRequest{
+		{Name: " ", Scopes: []string{"power:lock"}},
+		{Name: "backup"},
+		{Name: "backup", Scopes: []string{"power:reboot"}},
+		{Name: "backup", Scopes: []string{"power:lock"}, AllowedIPs: []string{"10.0.0.0/33"}},
+		{Name: "backup", Scopes: []string{"power:lock"}, ExpiresAt: &past},
+	}
+	for _, req := range tests {
+		if err := validateCreateRequest(req); err == nil {
+			t.Errorf("%+v was accepted", req)
+		}
+	}
+	if err := validateCreateRequest(&token_schema.CreateTokenRequest{Name: "backup", Scopes: []string{"power:lock", ScopeAll}, AllowedIPs: []string{"::1", "10.0.0.0/8"}}); err != nil {
+		t.Error(err)
+	}
+}
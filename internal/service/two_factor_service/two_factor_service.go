@@ -0,0 +1,353 @@
+package two_factor_service
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fadacontrol/internal/base/events"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/base/version"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/two_factor_schema"
+	"fadacontrol/internal/service/auth_service"
+	"fadacontrol/pkg/secure"
+	"fadacontrol/pkg/totp"
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// codeSkew accepts the codes of the neighbouring time steps to tolerate clock drift.
+	codeSkew = 1
+
+	challengeTTL         = 5 * time.Minute
+	maxChallengeAttempts = 5
+	qrCodeSize           = 256
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type challenge struct {
+	username  string
+	expiresAt time.Time
+	attempts  int
+}
+
+type TwoFactorService struct {
+	db   *gorm.DB
+	auth *auth_service.AuthService
+
+	// verifyMu serializes code checks so that a code can not be accepted twice by concurrent requests.
+	verifyMu sync.Mutex
+
+	policyMu     sync.Mutex
+	policyLoaded bool
+	required     bool
+
+	challengeMu sync.Mutex
+	challenges  map[string]*challenge
+}
+
+func NewTwoFactorService(db *gorm.DB, auth *auth_service.AuthService) *TwoFactorService {
+	return &TwoFactorService{db: db, auth: auth, challenges: make(map[string]*challenge)}
+}
+
+// Required reports whether the policy requires two-factor authentication for privileged accounts.
+func (s *TwoFactorService) Required() bool {
+	s.policyMu.Lock()
+	defer s.policyMu.Unlock()
+	if !s.policyLoaded {
+		var config entity.SysConfig
+		if err := s.db.First(&config).Error; err != nil {
+			logger.Errorf("failed to get config %v", err)
+			return s.required
+		}
+		s.required = config.RequireTwoFactor
+		s.policyLoaded = true
+	}
+	return s.required
+}
+
+func (s *TwoFactorService) SetRequired(required bool) error {
+	s.policyMu.Lock()
+	defer s.policyMu.Unlock()
+	var config entity.SysConfig
+	if err := s.db.First(&config).Error; err != nil {
+		return err
+	}
+	config.RequireTwoFactor = required
+	if err := s.db.Save(&config).Error; err != nil {
+		return err
+	}
+	s.required = required
+	s.policyLoaded = true
+	logger.Infof("two-factor authentication required for privileged accounts: %v", required)
+	return events.PublishConfigChanged(events.SectionTwoFactor, "", nil)
+}
+
+// Applies reports whether username has to log in with a second factor.
+func (s *TwoFactorService) Applies(username string) bool {
+	return s.Required() && s.auth.IsPrivileged(username)
+}
+
+func (s *TwoFactorService) get(username string) (*entity.TwoFactor, error) {
+	var tf entity.TwoFactor
+	if err := s.db.Where("username = ?", username).First(&tf).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tf, nil
+}
+
+// IsEnabled reports whether username has confirmed a TOTP enrollment.
+func (s *TwoFactorService) IsEnabled(username string) bool {
+	tf, err := s.get(username)
+	if err != nil {
+		logger.Errorf("failed to get two-factor settings of %s: %v", username, err)
+		return false
+	}
+	return tf != nil && tf.Enabled
+}
+
+func (s *TwoFactorService) Status(username string) (*two_factor_schema.StatusResponse, error) {
+	tf, err := s.get(username)
+	if err != nil {
+		return nil, err
+	}
+	resp := &two_factor_schema.StatusResponse{Required: s.Applies(username)}
+	if tf == nil {
+		return resp, nil
+	}
+	resp.Enabled = tf.Enabled
+	resp.Pending = !tf.Enabled
+	var left int64
+	if err := s.db.Model(&entity.RecoveryCode{}).Where("username = ? AND used_at IS NULL", username).Count(&left).Error; err != nil {
+		return nil, err
+	}
+	resp.RecoveryCodesLeft = int(left)
+	return resp, nil
+}
+
+// BeginEnrollment creates a new secret for username, it is used once the enrollment is confirmed.
+func (s *TwoFactorService) BeginEnrollment(username string) (*two_factor_schema.EnrollmentResponse, error) {
+	tf, err := s.get(username)
+	if err != nil {
+		return nil, err
+	}
+	if tf != nil && tf.Enabled {
+		return nil, exception.ErrUserParameterError.SetMsg("two-factor authentication is already enabled, disable it first")
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil {
+		tf = &entity.TwoFactor{Username: username}
+	}
+	tf.Secret = secret
+	tf.LastCounter = 0
+	if err := s.db.Save(tf).Error; err != nil {
+		return nil, err
+	}
+	return &two_factor_schema.EnrollmentResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(version.ProductName, username, secret),
+	}, nil
+}
+
+// QrCode returns the provisioning URI of the pending enrollment of username as a PNG image.
+func (s *TwoFactorService) QrCode(username string) ([]byte, error) {
+	tf, err := s.get(username)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil || tf.Enabled {
+		return nil, exception.ErrUserResourceNotFound.SetMsg("no pending two-factor enrollment")
+	}
+	return qrcode.Encode(totp.ProvisioningURI(version.ProductName, username, tf.Secret), qrcode.Medium, qrCodeSize)
+}
+
+// ConfirmEnrollment enables two-factor authentication once code proves the authenticator was set up,
+// the returned recovery codes are only shown this time.
+func (s *TwoFactorService) ConfirmEnrollment(username, code string) ([]string, error) {
+	s.verifyMu.Lock()
+	defer s.verifyMu.Unlock()
+	tf, err := s.get(username)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil || tf.Enabled {
+		return nil, exception.ErrUserResourceNotFound.SetMsg("no pending two-factor enrollment")
+	}
+	counter, ok := totp.Validate(tf.Secret, code, time.Now(), codeSkew)
+	if !ok {
+		return nil, exception.ErrUserInvalidTwoFactorCode
+	}
+	tf.Enabled = true
+	tf.LastCounter = counter
+	if err := s.db.Save(tf).Error; err != nil {
+		return nil, err
+	}
+	codes, err := s.replaceRecoveryCodes(username)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("two-factor authentication enabled for user %s", username)
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ReplaceAll(code, "-", "")
+	code = strings.ReplaceAll(code, " ", "")
+	return strings.ToLower(code)
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(normalizeRecoveryCode(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *TwoFactorService) replaceRecoveryCodes(username string) ([]string, error) {
+	codes := make([]string, 0, two_factor_schema.RecoveryCodeCount)
+	rows := make([]entity.RecoveryCode, 0, two_factor_schema.RecoveryCodeCount)
+	for i := 0; i < two_factor_schema.RecoveryCodeCount; i++ {
+		b, err := secure.GenerateSalt(5)
+		if err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(recoveryEncoding.EncodeToString(b))
+		code = code[:4] + "-" + code[4:]
+		codes = append(codes, code)
+		rows = append(rows, entity.RecoveryCode{Username: username, Hash: hashRecoveryCode(code)})
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("username = ?", username).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Verify checks a TOTP code or an unused recovery code of username, each code is accepted only once.
+func (s *TwoFactorService) Verify(username, code string) error {
+	s.verifyMu.Lock()
+	defer s.verifyMu.Unlock()
+	tf, err := s.get(username)
+	if err != nil {
+		return err
+	}
+	if tf == nil || !tf.Enabled {
+		return exception.ErrUserInvalidTwoFactorCode
+	}
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		counter, ok := totp.Validate(tf.Secret, code, time.Now(), codeSkew)
+		if !ok || counter <= tf.LastCounter {
+			return exception.ErrUserInvalidTwoFactorCode
+		}
+		return s.db.Model(tf).Update("last_counter", counter).Error
+	}
+	var rc entity.RecoveryCode
+	if err := s.db.Where("username = ? AND hash = ? AND used_at IS NULL", username, hashRecoveryCode(code)).First(&rc).Error; err != nil {
+		return exception.ErrUserInvalidTwoFactorCode
+	}
+	if err := s.db.Model(&rc).Update("used_at", time.Now()).Error; err != nil {
+		return err
+	}
+	logger.Warnf("recovery code used by user %s", username)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of username after verifying code.
+func (s *TwoFactorService) RegenerateRecoveryCodes(username, code string) ([]string, error) {
+	if err := s.Verify(username, code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(username)
+}
+
+// Disable turns two-factor authentication off for username after verifying code.
+func (s *TwoFactorService) Disable(username, code string) error {
+	if err := s.Verify(username, code); err != nil {
+		return err
+	}
+	return s.Reset(username)
+}
+
+// Reset removes the two-factor settings of username without verification, for administrators and deleted users.
+func (s *TwoFactorService) Reset(username string) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("username = ?", username).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("username = ?", username).Delete(&entity.TwoFactor{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	logger.Infof("two-factor authentication disabled for user %s", username)
+	return nil
+}
+
+// NewChallenge remembers that username passed the password check and has to provide a code next.
+func (s *TwoFactorService) NewChallenge(username string) (string, error) {
+	id, err := secure.GenerateRandomBase58Key(24)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	s.challengeMu.Lock()
+	defer s.challengeMu.Unlock()
+	for k, c := range s.challenges {
+		if now.After(c.expiresAt) {
+			delete(s.challenges, k)
+		}
+	}
+	s.challenges[id] = &challenge{username: username, expiresAt: now.Add(challengeTTL)}
+	return id, nil
+}
+
+// ChallengeUser returns the user a challenge was issued for.
+func (s *TwoFactorService) ChallengeUser(id string) (string, error) {
+	s.challengeMu.Lock()
+	defer s.challengeMu.Unlock()
+	c, ok := s.challenges[id]
+	if !ok || time.Now().After(c.expiresAt) {
+		delete(s.challenges, id)
+		return "", exception.ErrUserUnauthorizedAccess.SetMsg("the login challenge is invalid or expired")
+	}
+	return c.username, nil
+}
+
+// CompleteChallenge verifies code for the challenge and returns its user, a challenge is dropped after
+// it succeeded or failed too often.
+func (s *TwoFactorService) CompleteChallenge(id, code string) (string, error) {
+	username, err := s.ChallengeUser(id)
+	if err != nil {
+		return "", err
+	}
+	verifyErr := s.Verify(username, code)
+	s.challengeMu.Lock()
+	defer s.challengeMu.Unlock()
+	if c, ok := s.challenges[id]; ok {
+		c.attempts++
+		if verifyErr == nil || c.attempts >= maxChallengeAttempts {
+			delete(s.challenges, id)
+		}
+	}
+	if verifyErr != nil {
+		return "", verifyErr
+	}
+	return username, nil
+}
@@ -3,21 +3,63 @@ package unlock
 import (
 	"fadacontrol/internal/base/exception"
 	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/base/metrics"
 	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/webhook_schema"
 	"fadacontrol/internal/service/credential_provider_service"
+	"fadacontrol/internal/service/throttle_service"
+	"fadacontrol/internal/service/webhook_service"
 	"fadacontrol/pkg/sys"
 	"fadacontrol/pkg/utils"
 )
 
 type UnLockService struct {
 	cp *credential_provider_service.CredentialProviderService
+	th *throttle_service.ThrottleService
+	wh *webhook_service.WebhookService
 }
 
-func NewUnLockService(cp *credential_provider_service.CredentialProviderService) *UnLockService {
-	return &UnLockService{cp: cp}
+func NewUnLockService(wh *webhook_service.WebhookService, cp *credential_provider_service.CredentialProviderService, th *throttle_service.ThrottleService) *UnLockService {
+	return &UnLockService{wh: wh, cp: cp, th: th}
 }
 
-func (u *UnLockService) UnlockPc(username string, password string) *exception.Exception {
+// metricSource maps the source of an attempt to the label of the failure counter, the client addresses of the
+// http API would create a series per address.
+func metricSource(source string) string {
+	if source == throttle_service.RemoteSource {
+		return source
+	}
+	return "http"
+}
+
+// failed counts a failed attempt and notifies the webhooks, source is the client address or the remote source.
+func (u *UnLockService) failed(source, username string, ex *exception.Exception) {
+	metrics.UnlockFailure(metricSource(source))
+	u.wh.Publish(webhook_schema.EventUnlockFailed, webhook_schema.UnlockFailedEventData{Source: source, Username: username, Code: ex.Code, Msg: ex.Msg})
+}
+
+// UnlockPc unlocks the computer with the windows credentials, repeated failures from source or for the
+// account are throttled.
+func (u *UnLockService) UnlockPc(source string, username string, password string) *exception.Exception {
+	if ex := u.th.Check(throttle_service.KindUnlock, source, username); ex != nil {
+		u.failed(source, username, ex)
+		return ex
+	}
+	ret := u.unlockPc(username, password)
+	switch {
+	case exception.ErrSuccess.Equal(ret), exception.ErrUserUnlockNotInLockScreenState.Equal(ret):
+		// the credentials were verified in both cases
+		u.th.Success(throttle_service.KindUnlock, username)
+	case ret.Code >= exception.UserErrorStart && ret.Code <= exception.UserErrorEnd:
+		u.th.Failure(throttle_service.KindUnlock, source, username)
+		u.failed(source, username, ret)
+	default:
+		u.failed(source, username, ret)
+	}
+	return ret
+}
+
+func (u *UnLockService) unlockPc(username string, password string) *exception.Exception {
 	data := []byte("\x01" + "\x00" + username + "\x00" + password + "\x00")
 	var packet = entity.PipePacket{}
 	packet.Tpe = entity.UnlockReq
@@ -3,6 +3,7 @@ package update_service
 import (
 	"encoding/json"
 	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/events"
 	"fadacontrol/internal/base/logger"
 	"fadacontrol/internal/base/version"
 	"fadacontrol/internal/entity"
@@ -27,7 +28,7 @@ func (u *UpdateService) SetRegion(region version.ProductRegion) error {
 		return err
 	}
 	config.Region = int(region)
-	return u._db.Save(&config).Error
+	return events.PublishConfigChanged(events.SectionUpdate, "region", u._db.Save(&config).Error)
 }
 func (u *UpdateService) GetRegion() string {
 	var config entity.SysConfig
@@ -62,7 +63,7 @@ func (u *UpdateService) SetLanguage(language string) error {
 		return err
 	}
 	config.Language = string(conf.ProductLanguageFromString(language))
-	return u._db.Save(&config).Error
+	return events.PublishConfigChanged(events.SectionUpdate, "language", u._db.Save(&config).Error)
 }
 func (u *UpdateService) CheckUpdate(lang string) (*schema.UpdateInfoClientResp, error) {
 
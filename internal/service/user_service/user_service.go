@@ -1,33 +1,355 @@
 package user_service
 
 import (
+	"context"
 	"errors"
+	"fadacontrol/internal/base/conf"
+	"fadacontrol/internal/base/constants"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
 	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/user_schema"
+	"fadacontrol/internal/service/auth_service"
+	"fadacontrol/internal/service/cert_service"
+	"fadacontrol/internal/service/jwt_service"
+	"fadacontrol/internal/service/policy_service"
+	"fadacontrol/internal/service/token_service"
+	"fadacontrol/internal/service/two_factor_service"
 	"fadacontrol/pkg/secure"
+	"fadacontrol/pkg/utils"
 	"gorm.io/gorm"
+	"regexp"
 )
 
+const RootUsername = "root"
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,32}$`)
+
 type UserService struct {
-	db *gorm.DB
+	ctx  context.Context
+	db   *gorm.DB
+	auth *auth_service.AuthService
+	ps   *policy_service.PolicyService
+	ts   *token_service.TokenService
+	jw   *jwt_service.JwtService
+	tf   *two_factor_service.TwoFactorService
+	cs   *cert_service.CertService
 }
 
-func NewUserService(db *gorm.DB) *UserService {
-	return &UserService{db: db}
+func NewUserService(ctx context.Context, db *gorm.DB, auth *auth_service.AuthService, ps *policy_service.PolicyService, ts *token_service.TokenService, jw *jwt_service.JwtService, tf *two_factor_service.TwoFactorService, cs *cert_service.CertService) *UserService {
+	return &UserService{ctx: ctx, db: db, auth: auth, ps: ps, ts: ts, jw: jw, tf: tf, cs: cs}
 }
+
+// dummyHash is verified for unknown users so that the response time does not reveal which users exist.
+var dummyHash, _ = secure.HashPassword("fadacontrol")
+
 func (s *UserService) Login(username, password string) (*entity.User, error) {
 	var user entity.User
 	s.db.Model(&entity.User{}).First(&user, "username = ?", username)
 
 	if user.Username == "" {
+		secure.CheckPassword(password, dummyHash, "")
 		return nil, errors.New("username is empty")
 	}
 	if user.Password == "" {
 		return nil, errors.New("password is empty")
 	}
 	// 验证密码
-	if secure.VerifyPassword(password, user.Salt, user.Password) {
-		return &user, nil
+	ok, needsRehash := secure.CheckPassword(password, user.Password, user.Salt)
+	if !ok {
+		return &user, errors.New("password is wrong")
+	}
+	if needsRehash {
+		s.rehashPassword(&user, password)
+	}
+	if user.Disabled {
+		return nil, exception.ErrUserAccountDisabled
+	}
+	return &user, nil
+}
+
+// rehashPassword upgrades the stored hash of user to the current algorithm after a successful login.
+func (s *UserService) rehashPassword(user *entity.User, password string) {
+	if err := hashPassword(user, password); err != nil {
+		logger.Errorf("failed to rehash the password of user %s: %v", user.Username, err)
+		return
+	}
+	err := s.db.Model(user).Updates(map[string]interface{}{"password": user.Password, "salt": user.Salt}).Error
+	if err != nil {
+		logger.Errorf("failed to save the rehashed password of user %s: %v", user.Username, err)
+		return
+	}
+	logger.Infof("password hash of user %s upgraded", user.Username)
+}
+
+func (s *UserService) getUser(username string) (*entity.User, error) {
+	var user entity.User
+	if err := s.db.Where(&entity.User{Username: username}).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, exception.ErrUserResourceNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetActiveUser returns the user when it exists and is not disabled.
+func (s *UserService) GetActiveUser(username string) (*entity.User, error) {
+	user, err := s.getUser(username)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, exception.ErrUserAccountDisabled
+	}
+	return user, nil
+}
+
+// managedUser returns the entry of the user in config.yml, or nil.
+func (s *UserService) managedUser(username string) *conf.UserConf {
+	_conf := utils.GetValueFromContext(s.ctx, constants.ConfKey, conf.NewDefaultConf())
+	return _conf.ManagedUser(username)
+}
+
+func validatePassword(password string) error {
+	if len(password) < user_schema.MinPasswordLength || len(password) > user_schema.MaxPasswordLength {
+		return exception.ErrUserParameterError.SetMsg("the password must be 8 to 128 characters long")
+	}
+	return nil
+}
+
+// hashPassword stores an Argon2id hash of password in user, the salt is part of the PHC string.
+func hashPassword(user *entity.User, password string) error {
+	hash, err := secure.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.Salt = ""
+	user.Password = hash
+	return nil
+}
+
+func (s *UserService) toUserResponse(user *entity.User) (*user_schema.UserResponse, error) {
+	roles, err := s.auth.GetRoles(user.Username)
+	if err != nil {
+		return nil, err
+	}
+	return &user_schema.UserResponse{
+		Username:           user.Username,
+		Roles:              roles,
+		Disabled:           user.Disabled,
+		MustChangePassword: user.MustChangePassword,
+		CreatedAt:          user.CreatedAt,
+		UpdatedAt:          user.UpdatedAt,
+	}, nil
+}
+
+func (s *UserService) ListUsers() ([]*user_schema.UserResponse, error) {
+	var users []entity.User
+	if err := s.db.Order("id").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	ret := make([]*user_schema.UserResponse, 0, len(users))
+	for i := range users {
+		resp, err := s.toUserResponse(&users[i])
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, resp)
+	}
+	return ret, nil
+}
+func (s *UserService) GetUser(username string) (*user_schema.UserResponse, error) {
+	user, err := s.getUser(username)
+	if err != nil {
+		return nil, err
+	}
+	return s.toUserResponse(user)
+}
+
+func (s *UserService) CreateUser(req *user_schema.CreateUserRequest) (*user_schema.UserResponse, error) {
+	if !namePattern.MatchString(req.Username) {
+		return nil, exception.ErrUserIllegalCharacter.SetMsg("the username may only contain letters, digits, '_', '.' and '-'")
+	}
+	if err := validatePassword(req.Password); err != nil {
+		return nil, err
+	}
+	if _, err := s.getUser(req.Username); err == nil {
+		return nil, exception.ErrUserAlreadyExists
+	}
+	if s.auth.IsRole(req.Username) {
+		return nil, exception.ErrUserParameterError.SetMsg(req.Username + " is the name of a role")
+	}
+	if err := s.ps.ValidateRoles(req.Username, req.Roles); err != nil {
+		return nil, err
+	}
+	user := &entity.User{Username: req.Username, MustChangePassword: req.MustChangePassword}
+	if err := hashPassword(user, req.Password); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(user).Error; err != nil {
+		return nil, err
+	}
+	if err := s.auth.SetRoles(user.Username, req.Roles); err != nil {
+		return nil, err
+	}
+	logger.Infof("user %s created", user.Username)
+	return s.toUserResponse(user)
+}
+
+func (s *UserService) SetDisabled(username string, disabled bool) error {
+	if username == RootUsername {
+		return exception.ErrUserRootAccountProtected
+	}
+	if u := s.managedUser(username); u != nil && u.Disabled != nil {
+		return exception.ErrUserConfigManaged.SetMsg("the state of " + username + " is managed by config.yml")
+	}
+	user, err := s.getUser(username)
+	if err != nil {
+		return err
+	}
+	if disabled {
+		if err := s.ps.Guard(username, nil, nil); err != nil {
+			return err
+		}
+	}
+	user.Disabled = disabled
+	if err := s.db.Save(user).Error; err != nil {
+		return err
+	}
+	if disabled {
+		if err := s.jw.RevokeUserSessions(username); err != nil {
+			return err
+		}
+	}
+	logger.Infof("user %s disabled: %v", username, disabled)
+	return nil
+}
+
+// DeleteUser removes the user together with its role assignments, policies, sessions, api tokens and two-factor
+// settings, its client certificates are revoked.
+func (s *UserService) DeleteUser(username string) error {
+	if username == RootUsername {
+		return exception.ErrUserRootAccountProtected
+	}
+	if s.managedUser(username) != nil {
+		return exception.ErrUserConfigManaged.SetMsg(username + " is managed by config.yml")
+	}
+	user, err := s.getUser(username)
+	if err != nil {
+		return err
+	}
+	if err := s.ps.Guard(username, nil, nil); err != nil {
+		return err
+	}
+	if err := s.db.Unscoped().Delete(user).Error; err != nil {
+		return err
+	}
+	if err := s.auth.DeleteSubject(username); err != nil {
+		return err
+	}
+	if err := s.ts.DeleteUserTokens(username); err != nil {
+		return err
+	}
+	if err := s.jw.RevokeUserSessions(username); err != nil {
+		return err
+	}
+	if err := s.tf.Reset(username); err != nil {
+		return err
+	}
+	if err := s.cs.RevokeUserCerts(username); err != nil {
+		return err
+	}
+	logger.Infof("user %s deleted", username)
+	return nil
+}
+
+// ChangePassword changes the password of username after checking the old one, all sessions of the user are signed out.
+func (s *UserService) ChangePassword(username, oldPassword, newPassword string) error {
+	user, err := s.GetActiveUser(username)
+	if err != nil {
+		return err
+	}
+	if ok, _ := secure.CheckPassword(oldPassword, user.Password, user.Salt); !ok {
+		return exception.ErrUserWrongPassword
 	}
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+	if err := hashPassword(user, newPassword); err != nil {
+		return err
+	}
+	user.MustChangePassword = false
+	if err := s.db.Save(user).Error; err != nil {
+		return err
+	}
+	if err := s.jw.RevokeUserSessions(username); err != nil {
+		return err
+	}
+	logger.Infof("user %s changed password", username)
+	return nil
+}
+
+// ResetPassword sets a new password for username and signs out its sessions, the user has to change it on the next login.
+func (s *UserService) ResetPassword(username, password string) error {
+	user, err := s.getUser(username)
+	if err != nil {
+		return err
+	}
+	if err := validatePassword(password); err != nil {
+		return err
+	}
+	if err := hashPassword(user, password); err != nil {
+		return err
+	}
+	user.MustChangePassword = true
+	if err := s.db.Save(user).Error; err != nil {
+		return err
+	}
+	if err := s.jw.RevokeUserSessions(username); err != nil {
+		return err
+	}
+	logger.Infof("password of user %s reset", username)
+	return nil
+}
 
-	return &user, errors.New("password is wrong")
+// RevokeSessions signs out every session of username.
+func (s *UserService) RevokeSessions(username string) error {
+	if _, err := s.getUser(username); err != nil {
+		return err
+	}
+	return s.jw.RevokeUserSessions(username)
+}
+
+// ResetTwoFactor turns off two-factor authentication of username, for users who lost their authenticator
+// and recovery codes. The sessions of the user are signed out.
+func (s *UserService) ResetTwoFactor(username string) error {
+	if _, err := s.getUser(username); err != nil {
+		return err
+	}
+	if err := s.tf.Reset(username); err != nil {
+		return err
+	}
+	return s.jw.RevokeUserSessions(username)
+}
+
+func (s *UserService) SetRoles(username string, roles []string) error {
+	if _, err := s.getUser(username); err != nil {
+		return err
+	}
+	if err := s.ps.CheckManagedRoles(username); err != nil {
+		return err
+	}
+	if err := s.ps.ValidateRoles(username, roles); err != nil {
+		return err
+	}
+	old, err := s.auth.GetRoles(username)
+	if err != nil {
+		return err
+	}
+	return s.ps.Guard("", func() error {
+		return s.auth.SetRoles(username, roles)
+	}, func() error {
+		return s.auth.SetRoles(username, old)
+	})
 }
@@ -0,0 +1,103 @@
+package user_service
+
+import (
+	"context"
+	"fadacontrol/internal/base/data"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/user_schema"
+	"fadacontrol/internal/service/auth_service"
+	"fadacontrol/internal/service/policy_service"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+)
+
+func newTestUserService(t *testing.T) *UserService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "user.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.User{}); err != nil {
+		t.Fatal(err)
+	}
+	adapter, err := data.NewAdapterByDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enforcer, err := data.NewEnforcer(adapter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	auth := auth_service.NewAuthService(enforcer)
+	for _, p := range auth_service.BuiltinRolePolicies() {
+		if _, err := auth.AddPolicy(p[0], p[1], p[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ps := policy_service.NewPolicyService(ctx, db, auth)
+	s := NewUserService(ctx, db, auth, ps, nil, nil, nil, nil)
+	if _, err := s.CreateUser(&user_schema.CreateUserRequest{Username: RootUsername, Password: "correct horse", Roles: []string{auth_service.RoleAdmin}}); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCreateUserRoles(t *testing.T) {
+	s := newTestUserService(t)
+	if _, err := s.auth.AddPolicy("backup", auth_service.CommandPrefix+"backup", string(auth_service.Execute)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		roles    []string
+		ok       bool
+	}{
+		{"built-in role as username", auth_service.RoleOperator, nil, false},
+		{"role with policies as username", "backup", nil, false},
+		{"user as role", "bob", []string{RootUsername}, false},
+		{"itself as role", "bob", []string{"bob"}, false},
+		{"unknown role", "bob", []string{"missing"}, false},
+		{"built-in role", "bob", []string{auth_service.RoleViewer}, true},
+		{"role with policies", "carol", []string{"backup"}, true},
+		// roles with members are roles as well
+		{"role with members as username", auth_service.RoleViewer, nil, false},
+		{"existing user", "bob", nil, false},
+	}
+	for _, tt := range tests {
+		_, err := s.CreateUser(&user_schema.CreateUserRequest{Username: tt.username, Password: "correct horse", Roles: tt.roles})
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: got %v", tt.name, err)
+		}
+	}
+	if _, err := s.CreateUser(&user_schema.CreateUserRequest{Username: "bob", Password: "correct horse"}); err != exception.ErrUserAlreadyExists {
+		t.Errorf("creating bob twice: got %v", err)
+	}
+}
+
+func TestSetRoles(t *testing.T) {
+	s := newTestUserService(t)
+	if _, err := s.CreateUser(&user_schema.CreateUserRequest{Username: "bob", Password: "correct horse"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetRoles("bob", []string{auth_service.RoleOperator}); err != nil {
+		t.Fatal(err)
+	}
+	for _, roles := range [][]string{{RootUsername}, {"missing"}, {"bob"}, {"*"}} {
+		if err := s.SetRoles("bob", roles); err == nil {
+			t.Errorf("roles %v were assigned", roles)
+		}
+	}
+	roles, err := s.auth.GetRoles("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) != 1 || roles[0] != auth_service.RoleOperator {
+		t.Errorf("got roles %v after the rejected changes", roles)
+	}
+}
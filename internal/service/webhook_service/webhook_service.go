@@ -0,0 +1,415 @@
+package webhook_service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/base/logger"
+	"fadacontrol/internal/entity"
+	"fadacontrol/internal/schema/webhook_schema"
+	"fadacontrol/internal/service/control_pc"
+	"fadacontrol/internal/service/internal_master_service"
+	"fadacontrol/pkg/goroutine"
+	"fadacontrol/pkg/sys"
+	"fadacontrol/pkg/webhook"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	deliveryTimeout  = 10 * time.Second
+	maxAttempts      = 5
+	retryBaseBackoff = 5 * time.Second
+	retryMaxBackoff  = 5 * time.Minute
+	// powerEventWait is how long a shutdown or standby waits for its webhooks before the computer goes down.
+	powerEventWait = 3 * time.Second
+	// maxDeliveries is the number of delivery attempts kept per webhook.
+	maxDeliveries = 200
+	secretLength  = 32
+)
+
+type WebhookService struct {
+	ctx       context.Context
+	db        *gorm.DB
+	co        *control_pc.ControlPCService
+	master    *internal_master_service.InternalMasterService
+	sender    *webhook.Sender
+	cancel    context.CancelFunc
+	runCtx    context.Context
+	startOnce sync.Once
+	StartLock sync.Mutex
+}
+
+func NewWebhookService(ctx context.Context, db *gorm.DB, co *control_pc.ControlPCService, master *internal_master_service.InternalMasterService) *WebhookService {
+	client := &http.Client{Timeout: deliveryTimeout}
+	return &WebhookService{ctx: ctx, db: db, co: co, master: master, runCtx: ctx,
+		sender: webhook.NewSender(client, maxAttempts, webhook.ExponentialBackoff(retryBaseBackoff, retryMaxBackoff))}
+}
+
+// StartService subscribes to the events of the other services, the deliveries in flight are abandoned when the
+// service stops.
+func (w *WebhookService) StartService() error {
+	w.StartLock.Lock()
+	defer w.StartLock.Unlock()
+	if w.cancel != nil {
+		return exception.ErrSystemServiceAlreadyRunning
+	}
+	w.runCtx, w.cancel = context.WithCancel(w.ctx)
+	w.startOnce.Do(func() {
+		sys.AddSessionChangeCallback(func(event uint32) {
+			switch event {
+			case sys.SessionLock:
+				w.Publish(webhook_schema.EventSessionLock, nil)
+			case sys.SessionUnlock:
+				w.Publish(webhook_schema.EventSessionUnlock, nil)
+			}
+		})
+		w.co.AddPowerActionCallback(func(action string, tpe sys.ShutdownType) {
+			event := webhook_schema.EventPowerStandby
+			if action == webhook_schema.PowerShutdown {
+				event = webhook_schema.EventPowerShutdown
+			}
+			w.PublishWait(event, webhook_schema.PowerEventData{Action: action, ShutdownType: int(tpe)}, powerEventWait)
+		})
+		w.master.AddSlaveChangeCallback(func(clientId, username string, connected bool) {
+			event := webhook_schema.EventSlaveDisconnected
+			if connected {
+				event = webhook_schema.EventSlaveConnected
+			}
+			w.Publish(event, webhook_schema.SlaveEventData{ClientId: clientId, Username: username})
+		})
+	})
+	return nil
+}
+
+func (w *WebhookService) StopService() error {
+	w.StartLock.Lock()
+	defer w.StartLock.Unlock()
+	if w.cancel != nil {
+		w.cancel()
+		w.cancel = nil
+	}
+	return nil
+}
+
+func (w *WebhookService) context() context.Context {
+	w.StartLock.Lock()
+	defer w.StartLock.Unlock()
+	return w.runCtx
+}
+
+// Publish sends an event to the enabled webhooks subscribed to it in the background.
+func (w *WebhookService) Publish(event string, data interface{}) {
+	w.dispatch(event, data)
+}
+
+// PublishWait sends an event like Publish and waits until it is delivered or timeout passes.
+func (w *WebhookService) PublishWait(event string, data interface{}, timeout time.Duration) {
+	done := w.dispatch(event, data)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		logger.Warnf("webhooks of %s were not delivered within %v", event, timeout)
+	}
+}
+
+// dispatch starts the deliveries of an event, the returned channel is closed when all of them are finished.
+func (w *WebhookService) dispatch(event string, data interface{}) <-chan struct{} {
+	done := make(chan struct{})
+	var hooks []entity.Webhook
+	if err := w.db.Where("enabled = ?", true).Find(&hooks).Error; err != nil {
+		logger.Errorf("failed to load webhooks: %v", err)
+		close(done)
+		return done
+	}
+	ctx := w.context()
+	var wg sync.WaitGroup
+	for i := range hooks {
+		hook := hooks[i]
+		if !webhook_schema.Matches(splitEvents(hook.Events), event) {
+			continue
+		}
+		delivery, err := newDelivery(&hook, event, data)
+		if err != nil {
+			logger.Errorf("failed to encode webhook event %s: %v", event, err)
+			continue
+		}
+		wg.Add(1)
+		goroutine.RecoverGO(func() {
+			defer wg.Done()
+			if err := w.sender.Send(ctx, delivery, w.recordAttempt(&hook, delivery)); err != nil {
+				logger.Warnf("failed to deliver %s to webhook %s: %v", event, hook.Name, err)
+			}
+		})
+	}
+	goroutine.RecoverGO(func() {
+		wg.Wait()
+		close(done)
+	})
+	return done
+}
+
+func newDelivery(hook *entity.Webhook, event string, data interface{}) (*webhook.Delivery, error) {
+	id := uuid.NewString()
+	body, err := json.Marshal(webhook_schema.Payload{Id: id, Event: event, Time: time.Now(), Data: data})
+	if err != nil {
+		return nil, err
+	}
+	return &webhook.Delivery{Id: id, Url: hook.Url, Secret: hook.Secret, Event: event, Body: body}, nil
+}
+
+// recordAttempt returns a callback that saves every attempt to the delivery log of the webhook.
+func (w *WebhookService) recordAttempt(hook *entity.Webhook, d *webhook.Delivery) func(a *webhook.Attempt) {
+	return func(a *webhook.Attempt) {
+		record := toDeliveryEntity(hook, d, a)
+		if err := w.db.Create(record).Error; err != nil {
+			logger.Errorf("failed to record webhook delivery: %v", err)
+			return
+		}
+		w.pruneDeliveries(hook.ID)
+	}
+}
+
+func toDeliveryEntity(hook *entity.Webhook, d *webhook.Delivery, a *webhook.Attempt) *entity.WebhookDelivery {
+	record := &entity.WebhookDelivery{
+		WebhookId:  hook.ID,
+		DeliveryId: d.Id,
+		Event:      d.Event,
+		Attempt:    a.Number,
+		StatusCode: a.StatusCode,
+		Success:    a.Success(),
+		Response:   a.Response,
+		DurationMs: a.Duration.Milliseconds(),
+	}
+	if a.Err != nil {
+		record.Error = a.Err.Error()
+	}
+	return record
+}
+
+func (w *WebhookService) pruneDeliveries(webhookId uint) {
+	var ids []uint
+	err := w.db.Model(&entity.WebhookDelivery{}).Where(&entity.WebhookDelivery{WebhookId: webhookId}).
+		Order("id desc").Offset(maxDeliveries).Limit(1).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return
+	}
+	if err := w.db.Where("webhook_id = ? AND id <= ?", webhookId, ids[0]).Delete(&entity.WebhookDelivery{}).Error; err != nil {
+		logger.Errorf("failed to prune webhook deliveries: %v", err)
+	}
+}
+
+func splitEvents(events string) []string {
+	if events == "" {
+		return nil
+	}
+	return strings.Split(events, ",")
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, secretLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func validateRequest(req *webhook_schema.WebhookRequest) error {
+	u, err := url.Parse(req.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return exception.ErrUserParameterError.SetMsg("url must be an absolute http or https url")
+	}
+	for _, event := range req.Events {
+		if !webhook_schema.IsValidEvent(event) {
+			return exception.ErrUserParameterError.SetMsg("unknown event " + event)
+		}
+	}
+	return nil
+}
+
+func toWebhookResponse(hook *entity.Webhook) *webhook_schema.WebhookResponse {
+	return &webhook_schema.WebhookResponse{
+		Id:        hook.ID,
+		Name:      hook.Name,
+		Url:       hook.Url,
+		Events:    splitEvents(hook.Events),
+		Enabled:   hook.Enabled,
+		CreatedAt: hook.CreatedAt,
+	}
+}
+
+func (w *WebhookService) getWebhook(id uint) (*entity.Webhook, error) {
+	var hook entity.Webhook
+	if err := w.db.First(&hook, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, exception.ErrUserResourceNotFound
+		}
+		return nil, err
+	}
+	return &hook, nil
+}
+
+func (w *WebhookService) nameTaken(name string, id uint) bool {
+	var cnt int64
+	w.db.Model(&entity.Webhook{}).Where("name = ? AND id <> ?", name, id).Count(&cnt)
+	return cnt > 0
+}
+
+func (w *WebhookService) ListWebhooks() ([]*webhook_schema.WebhookResponse, error) {
+	var hooks []entity.Webhook
+	if err := w.db.Order("id").Find(&hooks).Error; err != nil {
+		return nil, err
+	}
+	ret := make([]*webhook_schema.WebhookResponse, 0, len(hooks))
+	for i := range hooks {
+		ret = append(ret, toWebhookResponse(&hooks[i]))
+	}
+	return ret, nil
+}
+
+func (w *WebhookService) GetWebhook(id uint) (*webhook_schema.WebhookResponse, error) {
+	hook, err := w.getWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+	return toWebhookResponse(hook), nil
+}
+
+// CreateWebhook saves a webhook, the response carries the secret which is not returned again.
+func (w *WebhookService) CreateWebhook(req *webhook_schema.WebhookRequest) (*webhook_schema.WebhookResponse, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+	if w.nameTaken(req.Name, 0) {
+		return nil, exception.ErrUserParameterError.SetMsg("a webhook with this name already exists")
+	}
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateSecret(); err != nil {
+			return nil, err
+		}
+	}
+	hook := &entity.Webhook{Name: req.Name, Url: req.Url, Events: strings.Join(req.Events, ","), Secret: secret, Enabled: true}
+	if req.Enabled != nil {
+		hook.Enabled = *req.Enabled
+	}
+	if err := w.db.Create(hook).Error; err != nil {
+		return nil, err
+	}
+	resp := toWebhookResponse(hook)
+	resp.Secret = secret
+	return resp, nil
+}
+
+func (w *WebhookService) UpdateWebhook(id uint, req *webhook_schema.WebhookRequest) (*webhook_schema.WebhookResponse, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+	hook, err := w.getWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+	if w.nameTaken(req.Name, hook.ID) {
+		return nil, exception.ErrUserParameterError.SetMsg("a webhook with this name already exists")
+	}
+	hook.Name = req.Name
+	hook.Url = req.Url
+	hook.Events = strings.Join(req.Events, ",")
+	if req.Secret != "" {
+		hook.Secret = req.Secret
+	}
+	if req.Enabled != nil {
+		hook.Enabled = *req.Enabled
+	}
+	if err := w.db.Save(hook).Error; err != nil {
+		return nil, err
+	}
+	return toWebhookResponse(hook), nil
+}
+
+// DeleteWebhook deletes a webhook and its delivery log.
+func (w *WebhookService) DeleteWebhook(id uint) error {
+	hook, err := w.getWebhook(id)
+	if err != nil {
+		return err
+	}
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(&entity.WebhookDelivery{WebhookId: hook.ID}).Delete(&entity.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		// hard delete so the name can be reused
+		return tx.Unscoped().Delete(hook).Error
+	})
+}
+
+// ListDeliveries returns the delivery attempts of a webhook, newest first.
+func (w *WebhookService) ListDeliveries(id uint, page, pageSize int) ([]*webhook_schema.WebhookDeliveryResponse, int64, error) {
+	if _, err := w.getWebhook(id); err != nil {
+		return nil, 0, err
+	}
+	var total int64
+	query := w.db.Model(&entity.WebhookDelivery{}).Where(&entity.WebhookDelivery{WebhookId: id})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var deliveries []entity.WebhookDelivery
+	if err := query.Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	ret := make([]*webhook_schema.WebhookDeliveryResponse, 0, len(deliveries))
+	for i := range deliveries {
+		ret = append(ret, toDeliveryResponse(&deliveries[i]))
+	}
+	return ret, total, nil
+}
+
+func toDeliveryResponse(d *entity.WebhookDelivery) *webhook_schema.WebhookDeliveryResponse {
+	return &webhook_schema.WebhookDeliveryResponse{
+		Id:         d.ID,
+		WebhookId:  d.WebhookId,
+		DeliveryId: d.DeliveryId,
+		Event:      d.Event,
+		Attempt:    d.Attempt,
+		StatusCode: d.StatusCode,
+		Success:    d.Success,
+		Error:      d.Error,
+		Response:   d.Response,
+		DurationMs: d.DurationMs,
+		Time:       d.CreatedAt,
+	}
+}
+
+// TestWebhook sends the test event once, also to a disabled webhook, and returns the attempt.
+func (w *WebhookService) TestWebhook(id uint) (*webhook_schema.WebhookDeliveryResponse, error) {
+	hook, err := w.getWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+	delivery, err := newDelivery(hook, webhook_schema.EventTest, map[string]string{"webhook": hook.Name})
+	if err != nil {
+		return nil, err
+	}
+	var record *entity.WebhookDelivery
+	sender := webhook.NewSender(w.sender.Client, 1, nil)
+	sender.Send(w.ctx, delivery, func(a *webhook.Attempt) {
+		record = toDeliveryEntity(hook, delivery, a)
+		if err := w.db.Create(record).Error; err != nil {
+			logger.Errorf("failed to record webhook delivery: %v", err)
+		}
+	})
+	if record == nil {
+		return nil, exception.ErrSystemUnknownException
+	}
+	return toDeliveryResponse(record), nil
+}
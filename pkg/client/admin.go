@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"fadacontrol/internal/schema"
+	"fadacontrol/internal/schema/backup_schema"
+	"fadacontrol/internal/schema/cert_schema"
+	"fadacontrol/internal/schema/http_schema"
+	"fadacontrol/internal/schema/remote_schema"
+	"net/http"
+	"net/url"
+)
+
+// Services of the HTTP configuration.
+const (
+	HttpServiceApi  = "HTTP_SERVICE_API"
+	HttpsServiceApi = "HTTPS_SERVICE_API"
+)
+
+// The endpoints below are only served by the administration API.
+
+// DiscoveryConfig returns the configuration of the discovery service.
+func (c *Client) DiscoveryConfig(ctx context.Context) (*schema.DiscoverSchema, error) {
+	var resp schema.DiscoverSchema
+	if err := c.do(ctx, http.MethodGet, "/discovery/config", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PatchDiscoveryConfig updates the fields of the discovery configuration present in data.
+func (c *Client) PatchDiscoveryConfig(ctx context.Context, data map[string]interface{}) error {
+	return c.do(ctx, http.MethodPatch, "/discovery/config", nil, data, nil)
+}
+
+// RestartDiscoveryService restarts the discovery service.
+func (c *Client) RestartDiscoveryService(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/discovery/restart", nil, nil, nil)
+}
+
+// RemoteConfig returns the configuration of the remote connection.
+func (c *Client) RemoteConfig(ctx context.Context) (*remote_schema.RemoteConnectConfigResponse, error) {
+	var resp remote_schema.RemoteConnectConfigResponse
+	if err := c.do(ctx, http.MethodGet, "/remote/config", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateRemoteConfig replaces the configuration of the remote connection.
+func (c *Client) UpdateRemoteConfig(ctx context.Context, config *remote_schema.RemoteConnectConfigRequest) error {
+	return c.do(ctx, http.MethodPut, "/remote/config", nil, config, nil)
+}
+
+// PatchRemoteConfig updates the fields of the remote configuration present in data.
+func (c *Client) PatchRemoteConfig(ctx context.Context, data map[string]interface{}) error {
+	return c.do(ctx, http.MethodPatch, "/remote/config", nil, data, nil)
+}
+
+// RestartRemoteService restarts the remote connection.
+func (c *Client) RestartRemoteService(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/remote/restart", nil, nil, nil)
+}
+
+// HttpConfig returns the configuration of the HTTP API.
+func (c *Client) HttpConfig(ctx context.Context) (*http_schema.HttpConfigResponse, error) {
+	var resp http_schema.HttpConfigResponse
+	if err := c.do(ctx, http.MethodGet, "/http/config", url.Values{"type": {HttpServiceApi}}, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// HttpsConfig returns the configuration of the HTTPS API.
+func (c *Client) HttpsConfig(ctx context.Context) (*http_schema.HttpsConfigResponse, error) {
+	var resp http_schema.HttpsConfigResponse
+	if err := c.do(ctx, http.MethodGet, "/http/config", url.Values{"type": {HttpsServiceApi}}, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateHttpConfig replaces the configuration of the HTTP API.
+func (c *Client) UpdateHttpConfig(ctx context.Context, config *http_schema.HttpConfigRequest) error {
+	return c.do(ctx, http.MethodPut, "/http/config", url.Values{"type": {HttpServiceApi}}, config, nil)
+}
+
+// UpdateHttpsConfig replaces the configuration of the HTTPS API.
+func (c *Client) UpdateHttpsConfig(ctx context.Context, config *http_schema.HttpsConfigRequest) error {
+	return c.do(ctx, http.MethodPut, "/http/config", url.Values{"type": {HttpsServiceApi}}, config, nil)
+}
+
+// PatchHttpConfig updates the fields present in data of the service, HttpServiceApi or HttpsServiceApi.
+func (c *Client) PatchHttpConfig(ctx context.Context, service string, data map[string]interface{}) error {
+	return c.do(ctx, http.MethodPatch, "/http/config", url.Values{"type": {service}}, data, nil)
+}
+
+// HttpBindings returns the addresses the servers of the service are listening on.
+func (c *Client) HttpBindings(ctx context.Context) ([]http_schema.HttpBinding, error) {
+	var resp []http_schema.HttpBinding
+	if err := c.do(ctx, http.MethodGet, "/http/bindings", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RenewServerCertificate reissues the certificate of the HTTPS API with the local authority.
+func (c *Client) RenewServerCertificate(ctx context.Context) (*cert_schema.CertificatesResponse, error) {
+	var resp cert_schema.CertificatesResponse
+	if err := c.do(ctx, http.MethodPost, "/http/cert/renew", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// StopService stops the service.
+func (c *Client) StopService(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/sys/stop", nil, nil, nil)
+}
+
+// Reload applies config.yml to the running service.
+func (c *Client) Reload(ctx context.Context) (*schema.ReloadResponse, error) {
+	var resp schema.ReloadResponse
+	if err := c.do(ctx, http.MethodPost, "/sys/reload", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ExportBackup exports the configuration, the secrets of the archive are encrypted under the passphrase.
+func (c *Client) ExportBackup(ctx context.Context, passphrase string) (*backup_schema.Archive, error) {
+	var resp backup_schema.Archive
+	if err := c.do(ctx, http.MethodPost, "/backup/export", nil, &backup_schema.ExportRequest{Passphrase: passphrase}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ImportBackup restores an archive, or only returns the changes it would make when req.DryRun is set.
+func (c *Client) ImportBackup(ctx context.Context, req *backup_schema.ImportRequest) (*backup_schema.ImportResponse, error) {
+	var resp backup_schema.ImportResponse
+	if err := c.do(ctx, http.MethodPost, "/backup/import", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"fadacontrol/internal/schema"
+	"fadacontrol/internal/schema/two_factor_schema"
+	"fadacontrol/internal/schema/user_schema"
+	"net/http"
+)
+
+// Login signs in with a password and keeps the returned tokens. When two-factor authentication is enabled and
+// code is empty, the response only carries the challenge for CompleteTwoFactor.
+func (c *Client) Login(ctx context.Context, username, password, code string) (*schema.TokenResponse, error) {
+	var resp schema.TokenResponse
+	err := c.doPublic(ctx, http.MethodPost, "/login", schema.LoginRequest{Username: username, Password: password, Code: code}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.TwoFactorRequired {
+		c.setTokens(resp.Token, resp.RefreshToken, resp.ExpiresIn)
+	}
+	return &resp, nil
+}
+
+// CompleteTwoFactor finishes a login with the challenge returned by Login and a TOTP or recovery code.
+func (c *Client) CompleteTwoFactor(ctx context.Context, challenge, code string) (*schema.TokenResponse, error) {
+	var resp schema.TokenResponse
+	err := c.doPublic(ctx, http.MethodPost, "/login/2fa", two_factor_schema.LoginChallengeRequest{Challenge: challenge, Code: code}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	c.setTokens(resp.Token, resp.RefreshToken, resp.ExpiresIn)
+	return &resp, nil
+}
+
+// Refresh exchanges the refresh token of the last login for new tokens. It is called automatically before the
+// access token expires.
+func (c *Client) Refresh(ctx context.Context) error {
+	_, refresh, _ := c.tokens()
+	if refresh == "" {
+		return ErrNoRefreshToken
+	}
+	return c.refresh(ctx, refresh)
+}
+
+// refresh uses the refresh token that was current when the caller looked, it does nothing when another
+// request has refreshed the tokens in the meantime.
+func (c *Client) refresh(ctx context.Context, refresh string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	if _, current, _ := c.tokens(); current != refresh {
+		return nil
+	}
+	var resp schema.TokenResponse
+	if err := c.doPublic(ctx, http.MethodPost, "/auth/refresh", schema.RefreshTokenRequest{RefreshToken: refresh}, &resp); err != nil {
+		return err
+	}
+	c.setTokens(resp.Token, resp.RefreshToken, resp.ExpiresIn)
+	return nil
+}
+
+// Logout revokes the session of the current access token and forgets the tokens.
+func (c *Client) Logout(ctx context.Context) error {
+	if err := c.do(ctx, http.MethodPost, "/auth/logout", nil, nil, nil); err != nil {
+		return err
+	}
+	c.setTokens("", "", 0)
+	return nil
+}
+
+// ChangePassword changes the password of the current user. The server signs out every session of the user, the
+// client continues with the new session that is returned.
+func (c *Client) ChangePassword(ctx context.Context, oldPassword, newPassword string) (*schema.TokenResponse, error) {
+	var resp schema.TokenResponse
+	err := c.do(ctx, http.MethodPut, "/user/password", nil, user_schema.ChangePasswordRequest{OldPassword: oldPassword, NewPassword: newPassword}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	c.setTokens(resp.Token, resp.RefreshToken, resp.ExpiresIn)
+	return &resp, nil
+}
@@ -0,0 +1,304 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/schema"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the HTTPS API that is reachable from the network.
+	DefaultBaseURL = "https://localhost:2091/api/v1"
+	// DefaultAdminBaseURL is the administration API that only listens on the loopback interface.
+	DefaultAdminBaseURL = "http://127.0.0.1:2093/admin/api/v1"
+
+	defaultTimeout = 30 * time.Second
+	// refreshBefore is the remaining lifetime at which the access token is refreshed before a request.
+	refreshBefore = 30 * time.Second
+)
+
+type ClientBuilder struct {
+	baseURL    string
+	timeout    time.Duration
+	token      string
+	pins       []string
+	rootCAs    *x509.CertPool
+	clientCert *tls.Certificate
+	httpClient *http.Client
+}
+
+// NewClientBuilder returns a builder for the API at baseURL, for example DefaultBaseURL or DefaultAdminBaseURL.
+func NewClientBuilder(baseURL string) *ClientBuilder {
+	return &ClientBuilder{baseURL: baseURL, timeout: defaultTimeout}
+}
+
+// SetTimeout limits every request except log streams, zero disables the limit.
+func (b *ClientBuilder) SetTimeout(timeout time.Duration) *ClientBuilder {
+	b.timeout = timeout
+	return b
+}
+
+// SetToken authenticates with an API token or an access token instead of logging in.
+func (b *ClientBuilder) SetToken(token string) *ClientBuilder {
+	b.token = token
+	return b
+}
+
+// SetPinnedCertificates accepts the server only if one of its certificates matches a pin, either the hex encoded
+// SHA-256 fingerprint or the base64 encoded SHA-256 hash of the public key as returned by /certs. Without root
+// certificates the pins replace the verification of the certificate chain and host name.
+func (b *ClientBuilder) SetPinnedCertificates(pins ...string) *ClientBuilder {
+	b.pins = append(b.pins, pins...)
+	return b
+}
+
+// SetRootCAs trusts the server certificates issued by the authorities in pool, usually the local authority
+// downloaded from /certs/ca.
+func (b *ClientBuilder) SetRootCAs(pool *x509.CertPool) *ClientBuilder {
+	b.rootCAs = pool
+	return b
+}
+
+// SetClientCertificate authenticates with a client certificate issued by the local authority.
+func (b *ClientBuilder) SetClientCertificate(cert tls.Certificate) *ClientBuilder {
+	b.clientCert = &cert
+	return b
+}
+
+// SetHTTPClient replaces the HTTP client, the TLS settings of the builder are ignored.
+func (b *ClientBuilder) SetHTTPClient(httpClient *http.Client) *ClientBuilder {
+	b.httpClient = httpClient
+	return b
+}
+
+func (b *ClientBuilder) Build() (*Client, error) {
+	base, err := url.Parse(strings.TrimSuffix(b.baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q", base.Scheme)
+	}
+	httpClient := b.httpClient
+	if httpClient == nil {
+		tlsConfig, err := b.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		httpClient = &http.Client{Transport: transport}
+	}
+	return &Client{
+		baseURL:     base,
+		timeout:     b.timeout,
+		httpClient:  httpClient,
+		accessToken: b.token,
+	}, nil
+}
+
+// Client calls the FadaControl REST API. It keeps the tokens of the last login and refreshes the access token
+// before it expires, it is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	timeout    time.Duration
+	httpClient *http.Client
+
+	mu           sync.Mutex
+	accessToken  string
+	refreshToken string
+	expiresAt    time.Time
+	// refreshMu serializes refreshes, every refresh token can only be used once.
+	refreshMu sync.Mutex
+}
+
+// Token returns the current access token.
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.accessToken
+}
+
+// SetToken replaces the access token and forgets the refresh token.
+func (c *Client) SetToken(token string) {
+	c.setTokens(token, "", 0)
+}
+
+func (c *Client) setTokens(access, refresh string, expiresIn int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accessToken = access
+	c.refreshToken = refresh
+	c.expiresAt = time.Time{}
+	if expiresIn > 0 {
+		c.expiresAt = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+}
+
+func (c *Client) tokens() (access, refresh string, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.accessToken, c.refreshToken, c.expiresAt
+}
+
+func (c *Client) url(path string, query url.Values) string {
+	u := *c.baseURL
+	u.Path += path
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// newRequest builds a request to path below the base URL, body is encoded as JSON.
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body interface{}, auth bool) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(buf)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.url(path, query), reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if auth {
+		if token, _, _ := c.tokens(); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	return req, nil
+}
+
+// send performs the request with authentication, the access token is refreshed when it expires soon and once
+// more when the server rejects it.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Response, error) {
+	if _, refresh, expiresAt := c.tokens(); refresh != "" && !expiresAt.IsZero() && time.Until(expiresAt) < refreshBefore {
+		if err := c.refresh(ctx, refresh); err != nil {
+			return nil, err
+		}
+	}
+	req, err := c.newRequest(ctx, method, path, query, body, true)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+	_, refresh, _ := c.tokens()
+	if refresh == "" {
+		return resp, nil
+	}
+	resp.Body.Close()
+	if err := c.refresh(ctx, refresh); err != nil {
+		return nil, err
+	}
+	req, err = c.newRequest(ctx, method, path, query, body, true)
+	if err != nil {
+		return nil, err
+	}
+	return c.httpClient.Do(req)
+}
+
+// do calls an authenticated endpoint and decodes the data of the response into out when it is not nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	resp, err := c.send(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeResponse(resp, out)
+}
+
+// doPublic calls an endpoint that needs no authentication.
+func (c *Client) doPublic(ctx context.Context, method, path string, body, out interface{}) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	req, err := c.newRequest(ctx, method, path, nil, body, false)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeResponse(resp, out)
+}
+
+// decodeResponse unwraps the schema.ResponseData envelope, a non-zero code is returned as *Error.
+func decodeResponse(resp *http.Response, out interface{}) error {
+	var data json.RawMessage
+	envelope := schema.ResponseData{Data: &data}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected response status %s", resp.Status)
+		}
+		return fmt.Errorf("failed to decode the response: %v", err)
+	}
+	if envelope.Code != exception.ErrSuccess.Code {
+		return &Error{StatusCode: resp.StatusCode, RequestId: envelope.RequestId, Code: envelope.Code, Msg: envelope.Msg}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// stream calls an authenticated endpoint that streams its response, the caller has to close the returned body.
+func (c *Client) stream(ctx context.Context, path string, query url.Values) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK || strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		defer resp.Body.Close()
+		if err := decodeResponse(resp, nil); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// download calls an authenticated endpoint that returns a file instead of the envelope.
+func (c *Client) download(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	body, err := c.stream(ctx, path, query)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
@@ -0,0 +1,283 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fadacontrol/internal/base/exception"
+	"fadacontrol/internal/schema"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func writeEnvelope(w http.ResponseWriter, status int, ex *exception.Exception, data interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(schema.ResponseData{RequestId: "req-1", Code: ex.Code, Msg: ex.Msg, Data: data})
+}
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c, err := NewClientBuilder(server.URL + "/api/v1").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestErrorMapping(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/info/language", func(w http.ResponseWriter, r *http.Request) {
+		writeEnvelope(w, http.StatusOK, exception.ErrSuccess, "en")
+	})
+	mux.HandleFunc("/api/v1/control-pc/pending/missing", func(w http.ResponseWriter, r *http.Request) {
+		writeEnvelope(w, http.StatusNotFound, exception.ErrUserResourceNotFound, nil)
+	})
+	mux.HandleFunc("/api/v1/unlock", func(w http.ResponseWriter, r *http.Request) {
+		writeEnvelope(w, http.StatusTooManyRequests, exception.ErrUserTooManyRequests.SetMsg("retry in 30 seconds"), nil)
+	})
+	mux.HandleFunc("/api/v1/ping", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	})
+	c := newTestClient(t, mux)
+	ctx := context.Background()
+
+	lang, err := c.Language(ctx)
+	if err != nil || lang != "en" {
+		t.Errorf("Language() = %q, %v", lang, err)
+	}
+
+	tests := []struct {
+		name       string
+		call       func() error
+		want       *exception.Exception
+		wantStatus int
+		wantMsg    string
+	}{
+		{"not found", func() error { _, err := c.GetPendingAction(ctx, "missing"); return err },
+			exception.ErrUserResourceNotFound, http.StatusNotFound, exception.ErrUserResourceNotFound.Msg},
+		{"custom message", func() error { return c.Unlock(ctx, "user", "password") },
+			exception.ErrUserTooManyRequests, http.StatusTooManyRequests, "retry in 30 seconds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error %v does not match %v", err, tt.want)
+			}
+			var apiErr *Error
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus || apiErr.RequestId != "req-1" {
+				t.Errorf("unexpected error details %+v", apiErr)
+			}
+			var ex *exception.Exception
+			if !errors.As(err, &ex) || ex.Code != tt.want.Code || ex.Msg != tt.wantMsg {
+				t.Errorf("unexpected exception %+v", ex)
+			}
+			if errors.Is(err, exception.ErrUnknownException) {
+				t.Errorf("error matches an unrelated exception")
+			}
+		})
+	}
+
+	t.Run("no envelope", func(t *testing.T) {
+		err := c.Ping(ctx)
+		var apiErr *Error
+		if err == nil || errors.As(err, &apiErr) {
+			t.Errorf("Ping() error = %v, want a plain error", err)
+		}
+	})
+}
+
+// tokenServer issues single use refresh tokens and accepts only the latest access token.
+type tokenServer struct {
+	mu         sync.Mutex
+	generation int
+	refreshes  int
+}
+
+func (s *tokenServer) tokens() (string, string) {
+	return fmt.Sprintf("access-%d", s.generation), fmt.Sprintf("refresh-%d", s.generation)
+}
+
+func (s *tokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.URL.Path {
+	case "/api/v1/login":
+		var req schema.LoginRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Password != "secret" {
+			writeEnvelope(w, http.StatusBadRequest, exception.ErrUserWrongPassword, nil)
+			return
+		}
+		s.generation++
+		access, refresh := s.tokens()
+		writeEnvelope(w, http.StatusOK, exception.ErrSuccess, schema.TokenResponse{Token: access, RefreshToken: refresh, ExpiresIn: 3600})
+	case "/api/v1/auth/refresh":
+		var req schema.RefreshTokenRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if _, refresh := s.tokens(); req.RefreshToken != refresh {
+			writeEnvelope(w, http.StatusBadRequest, exception.ErrUserUnauthorizedAccess, nil)
+			return
+		}
+		s.generation++
+		s.refreshes++
+		access, refresh := s.tokens()
+		writeEnvelope(w, http.StatusOK, exception.ErrSuccess, schema.TokenResponse{Token: access, RefreshToken: refresh, ExpiresIn: 3600})
+	case "/api/v1/power-saving/status":
+		if access, _ := s.tokens(); r.Header.Get("Authorization") != "Bearer "+access {
+			writeEnvelope(w, http.StatusUnauthorized, exception.ErrUserUnauthorizedAccess, nil)
+			return
+		}
+		writeEnvelope(w, http.StatusOK, exception.ErrSuccess, true)
+	default:
+		writeEnvelope(w, http.StatusNotFound, exception.ErrUserResourceNotFound, nil)
+	}
+}
+
+// expire invalidates the access token as if it had expired on the server.
+func (s *tokenServer) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation += 10
+}
+
+func TestTokenRefresh(t *testing.T) {
+	ts := &tokenServer{}
+	c := newTestClient(t, ts)
+	ctx := context.Background()
+
+	if _, err := c.PowerSavingModeStatus(ctx); !errors.Is(err, exception.ErrUserUnauthorizedAccess) {
+		t.Fatalf("expected an unauthorized error before login, got %v", err)
+	}
+	if _, err := c.Login(ctx, "root", "wrong", ""); !errors.Is(err, exception.ErrUserWrongPassword) {
+		t.Fatalf("expected a wrong password error, got %v", err)
+	}
+	if _, err := c.Login(ctx, "root", "secret", ""); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if on, err := c.PowerSavingModeStatus(ctx); err != nil || !on {
+		t.Fatalf("PowerSavingModeStatus() = %v, %v", on, err)
+	}
+
+	// the server rejects the access token, the client refreshes once and retries
+	c.mu.Lock()
+	c.accessToken = "stale"
+	c.mu.Unlock()
+	if _, err := c.PowerSavingModeStatus(ctx); err != nil {
+		t.Fatalf("request after refresh failed: %v", err)
+	}
+	if ts.refreshes != 1 {
+		t.Errorf("expected 1 refresh, got %d", ts.refreshes)
+	}
+
+	// concurrent requests share a single refresh of the single use token
+	c.mu.Lock()
+	c.accessToken = "stale"
+	c.mu.Unlock()
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.PowerSavingModeStatus(ctx)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent request failed: %v", err)
+		}
+	}
+	if ts.refreshes != 2 {
+		t.Errorf("expected 2 refreshes, got %d", ts.refreshes)
+	}
+
+	// an invalid refresh token surfaces as the error of the refresh
+	ts.expire()
+	if _, err := c.PowerSavingModeStatus(ctx); !errors.Is(err, exception.ErrUserUnauthorizedAccess) {
+		t.Errorf("expected an unauthorized error after the session ended, got %v", err)
+	}
+}
+
+func TestPinnedCertificates(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeEnvelope(w, http.StatusOK, exception.ErrSuccess, nil)
+	}))
+	defer server.Close()
+	cert := server.Certificate()
+	sum := sha256.Sum256(cert.Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+	spki := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	var colons []string
+	for _, b := range sum {
+		colons = append(colons, strings.ToUpper(hex.EncodeToString([]byte{b})))
+	}
+	other := sha256.Sum256([]byte("other"))
+
+	tests := []struct {
+		name    string
+		pins    []string
+		wantErr bool
+	}{
+		{"fingerprint", []string{fingerprint}, false},
+		{"fingerprint with colons", []string{strings.Join(colons, ":")}, false},
+		{"spki", []string{"sha256/" + base64.StdEncoding.EncodeToString(spki[:])}, false},
+		{"one of several", []string{hex.EncodeToString(other[:]), fingerprint}, false},
+		{"mismatch", []string{hex.EncodeToString(other[:])}, true},
+		{"no pin", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClientBuilder(server.URL).SetPinnedCertificates(tt.pins...).Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = c.Ping(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Ping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStreamServiceLogs(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/logs/service", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("first line\nsecond line\n"))
+	})
+	c := newTestClient(t, mux)
+	body, err := c.StreamServiceLogs(context.Background())
+	if err != nil {
+		t.Fatalf("StreamServiceLogs failed: %v", err)
+	}
+	defer body.Close()
+	var lines []string
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if strings.Join(lines, ",") != "first line,second line" {
+		t.Errorf("unexpected log lines %q", lines)
+	}
+
+	c = newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeEnvelope(w, http.StatusBadRequest, exception.ErrUserParameterError, nil)
+	}))
+	if _, err := c.StreamServiceLogs(context.Background()); !errors.Is(err, exception.ErrUserParameterError) {
+		t.Errorf("expected a parameter error, got %v", err)
+	}
+}
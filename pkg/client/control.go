@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"fadacontrol/internal/schema"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Interface is a network interface of the computer.
+type Interface struct {
+	MACAddr       string   `json:"mac_addr"`
+	InterfaceName string   `json:"interface_name"`
+	IPAddresses   []net.IP `json:"ip_addresses"`
+}
+
+// Ping checks that the API is reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/ping", nil, nil, nil)
+}
+
+// schedulePowerAction schedules a shutdown or standby after delay, shutdownType is one of the sys.ShutdownType
+// values and a negative value keeps the default of the server.
+func (c *Client) schedulePowerAction(ctx context.Context, action string, delay time.Duration, shutdownType int) (*schema.PendingAction, error) {
+	query := url.Values{"delay": {strconv.Itoa(int(delay / time.Second))}}
+	if shutdownType >= 0 {
+		query.Set("shutdown_type", strconv.Itoa(shutdownType))
+	}
+	var resp schema.PendingAction
+	if err := c.do(ctx, http.MethodPost, "/control-pc/"+action, query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Shutdown shuts the computer down after delay, shutdownType is one of the sys.ShutdownType values or -1 for
+// the default forced shutdown.
+func (c *Client) Shutdown(ctx context.Context, delay time.Duration, shutdownType int) (*schema.PendingAction, error) {
+	return c.schedulePowerAction(ctx, "shutdown", delay, shutdownType)
+}
+
+// Standby puts the computer to sleep after delay.
+func (c *Client) Standby(ctx context.Context, delay time.Duration) (*schema.PendingAction, error) {
+	return c.schedulePowerAction(ctx, "standby", delay, -1)
+}
+
+// Lock locks the session of the computer.
+func (c *Client) Lock(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/control-pc/lock", nil, nil, nil)
+}
+
+// ListPendingActions lists the delayed shutdown and standby actions that have not started yet.
+func (c *Client) ListPendingActions(ctx context.Context) ([]schema.PendingAction, error) {
+	var resp []schema.PendingAction
+	if err := c.do(ctx, http.MethodGet, "/control-pc/pending", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetPendingAction returns a pending action or the outcome of a recently finished one.
+func (c *Client) GetPendingAction(ctx context.Context, id string) (*schema.PendingAction, error) {
+	var resp schema.PendingAction
+	if err := c.do(ctx, http.MethodGet, "/control-pc/pending/"+url.PathEscape(id), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CancelPendingAction cancels a delayed action before it starts.
+func (c *Client) CancelPendingAction(ctx context.Context, id string) (*schema.PendingAction, error) {
+	var resp schema.PendingAction
+	if err := c.do(ctx, http.MethodDelete, "/control-pc/pending/"+url.PathEscape(id), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PostponePendingAction moves a delayed action later by d.
+func (c *Client) PostponePendingAction(ctx context.Context, id string, d time.Duration) (*schema.PendingAction, error) {
+	query := url.Values{"seconds": {strconv.Itoa(int(d / time.Second))}}
+	var resp schema.PendingAction
+	if err := c.do(ctx, http.MethodPost, "/control-pc/pending/"+url.PathEscape(id)+"/postpone", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Unlock unlocks the computer with the credentials of a Windows user.
+func (c *Client) Unlock(ctx context.Context, username, password string) error {
+	return c.do(ctx, http.MethodPost, "/unlock", nil, schema.PcUserInfo{UserName: username, Password: password}, nil)
+}
+
+// Interfaces lists the valid network interfaces with IPv4 or IPv6 addresses.
+func (c *Client) Interfaces(ctx context.Context, ipv6 bool) ([]Interface, error) {
+	query := url.Values{"type": {"4"}}
+	if ipv6 {
+		query.Set("type", "6")
+	}
+	var resp []Interface
+	if err := c.do(ctx, http.MethodGet, "/interface/", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// InterfaceByIP returns the interface that has the address ip.
+func (c *Client) InterfaceByIP(ctx context.Context, ip string) (*Interface, error) {
+	var resp Interface
+	if err := c.do(ctx, http.MethodGet, "/interface/"+url.PathEscape(ip)+"/all", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// MACAddress returns the MAC address of the interface that has the address ip.
+func (c *Client) MACAddress(ctx context.Context, ip string) (string, error) {
+	var resp string
+	if err := c.do(ctx, http.MethodGet, "/interface/"+url.PathEscape(ip), nil, nil, &resp); err != nil {
+		return "", err
+	}
+	return resp, nil
+}
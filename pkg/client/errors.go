@@ -0,0 +1,32 @@
+package client
+
+import (
+	"errors"
+	"fadacontrol/internal/base/exception"
+	"fmt"
+)
+
+var ErrNoRefreshToken = errors.New("no refresh token, login first")
+
+// Error is a response of the API with a non-zero code. It matches the exception with the same code, so callers
+// can check it with errors.Is(err, exception.ErrUserResourceNotFound).
+type Error struct {
+	StatusCode int
+	RequestId  string
+	Code       int
+	Msg        string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d: %s", e.Code, e.Msg)
+}
+
+func (e *Error) Is(target error) bool {
+	ex, ok := target.(*exception.Exception)
+	return ok && ex.Code == e.Code
+}
+
+// Unwrap returns the exception of the code with the message sent by the server.
+func (e *Error) Unwrap() error {
+	return &exception.Exception{Code: e.Code, Msg: e.Msg}
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"crypto/x509"
+	"fadacontrol/internal/schema"
+	"fadacontrol/internal/schema/cert_schema"
+	"fadacontrol/internal/schema/log_schema"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Power saving modes accepted by SetPowerSavingMode.
+const (
+	PowerSavingEnable  = "enable"
+	PowerSavingDisable = "disable"
+	PowerSavingAuto    = "auto"
+)
+
+// SoftwareInfo returns the version, edition and paths of the service.
+func (c *Client) SoftwareInfo(ctx context.Context) (*schema.SoftwareInfo, error) {
+	var resp schema.SoftwareInfo
+	if err := c.do(ctx, http.MethodGet, "/info", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CheckUpdate returns the latest update with release notes in lang, it is only served by the administration API.
+func (c *Client) CheckUpdate(ctx context.Context, lang string) (*schema.UpdateInfoClientResp, error) {
+	query := url.Values{}
+	if lang != "" {
+		query.Set("lang", lang)
+	}
+	var resp schema.UpdateInfoClientResp
+	if err := c.do(ctx, http.MethodGet, "/info/check_update", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Language returns the language of the service.
+func (c *Client) Language(ctx context.Context) (string, error) {
+	var resp string
+	if err := c.do(ctx, http.MethodGet, "/info/language", nil, nil, &resp); err != nil {
+		return "", err
+	}
+	return resp, nil
+}
+
+// SetLanguage changes the language of the service.
+func (c *Client) SetLanguage(ctx context.Context, lang string) error {
+	return c.do(ctx, http.MethodPatch, "/info/language", nil, map[string]string{"lang": lang}, nil)
+}
+
+// StreamServiceLogs follows the log of the service until ctx is cancelled or the returned reader is closed.
+func (c *Client) StreamServiceLogs(ctx context.Context) (io.ReadCloser, error) {
+	return c.stream(ctx, "/logs/service", nil)
+}
+
+// SearchServiceLogs searches the log of the service and its rotated backups, the entries are returned newest first.
+func (c *Client) SearchServiceLogs(ctx context.Context, q *log_schema.LogQuery) (*log_schema.LogSearchResponse, error) {
+	query := url.Values{}
+	if q.From != nil {
+		query.Set("from", q.From.Format(time.RFC3339Nano))
+	}
+	if q.To != nil {
+		query.Set("to", q.To.Format(time.RFC3339Nano))
+	}
+	for key, value := range map[string]string{"level": q.Level, "module": q.Module, "request_id": q.RequestId} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if q.Page > 0 {
+		query.Set("page", strconv.Itoa(q.Page))
+	}
+	if q.PageSize > 0 {
+		query.Set("page_size", strconv.Itoa(q.PageSize))
+	}
+	var resp log_schema.LogSearchResponse
+	if err := c.do(ctx, http.MethodGet, "/logs/service/search", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SetPowerSavingMode turns the power saving mode on or off, mode is PowerSavingEnable, PowerSavingDisable or
+// PowerSavingAuto.
+func (c *Client) SetPowerSavingMode(ctx context.Context, mode string) error {
+	return c.do(ctx, http.MethodPost, "/power-saving", url.Values{"mode": {mode}}, nil, nil)
+}
+
+// PowerSavingModeStatus reports whether the power saving mode is on.
+func (c *Client) PowerSavingModeStatus(ctx context.Context) (bool, error) {
+	var resp bool
+	if err := c.do(ctx, http.MethodGet, "/power-saving/status", nil, nil, &resp); err != nil {
+		return false, err
+	}
+	return resp, nil
+}
+
+// Certificates returns the fingerprints of the local authority and of the server certificate, they can be
+// passed to ClientBuilder.SetPinnedCertificates.
+func (c *Client) Certificates(ctx context.Context) (*cert_schema.CertificatesResponse, error) {
+	var resp cert_schema.CertificatesResponse
+	if err := c.do(ctx, http.MethodGet, "/certs", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CACertificate downloads the certificate of the local authority for ClientBuilder.SetRootCAs.
+func (c *Client) CACertificate(ctx context.Context) (*x509.Certificate, error) {
+	der, err := c.download(ctx, "/certs/ca", url.Values{"format": {"der"}})
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// normalizePin accepts fingerprints with colons and in upper case as printed by most tools, and SPKI pins with
+// the sha256/ prefix of HTTP public key pinning.
+func normalizePin(pin string) string {
+	pin = strings.TrimPrefix(strings.TrimSpace(pin), "sha256/")
+	if fingerprint := strings.ToLower(strings.ReplaceAll(pin, ":", "")); len(fingerprint) == sha256.Size*2 {
+		if _, err := hex.DecodeString(fingerprint); err == nil {
+			return fingerprint
+		}
+	}
+	return pin
+}
+
+// matchesPin reports whether cert matches one of the normalized pins.
+func matchesPin(cert *x509.Certificate, pins map[string]bool) bool {
+	sum := sha256.Sum256(cert.Raw)
+	if pins[hex.EncodeToString(sum[:])] {
+		return true
+	}
+	spki := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return pins[base64.StdEncoding.EncodeToString(spki[:])]
+}
+
+// verifyPins returns a check that accepts a connection when one of the certificates presented by the server
+// matches a pin.
+func verifyPins(pins []string) func(tls.ConnectionState) error {
+	set := make(map[string]bool, len(pins))
+	for _, pin := range pins {
+		set[normalizePin(pin)] = true
+	}
+	return func(state tls.ConnectionState) error {
+		for _, cert := range state.PeerCertificates {
+			if matchesPin(cert, set) {
+				return nil
+			}
+		}
+		return errors.New("the server certificate does not match any pinned certificate")
+	}
+}
+
+func (b *ClientBuilder) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    b.rootCAs,
+	}
+	if b.clientCert != nil {
+		config.Certificates = []tls.Certificate{*b.clientCert}
+	}
+	if len(b.pins) == 0 {
+		return config, nil
+	}
+	for _, pin := range b.pins {
+		if normalizePin(pin) == "" {
+			return nil, errors.New("empty certificate pin")
+		}
+	}
+	if b.rootCAs == nil {
+		// the certificates of the service are issued by a local authority, the pin is the only trust anchor
+		config.InsecureSkipVerify = true
+	}
+	config.VerifyConnection = verifyPins(b.pins)
+	return config, nil
+}
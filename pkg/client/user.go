@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"fadacontrol/internal/schema/user_schema"
+	"net/http"
+	"net/url"
+)
+
+// ListUsers lists the users of the service.
+func (c *Client) ListUsers(ctx context.Context) ([]user_schema.UserResponse, error) {
+	var resp []user_schema.UserResponse
+	if err := c.do(ctx, http.MethodGet, "/users", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetUser returns the user named username.
+func (c *Client) GetUser(ctx context.Context, username string) (*user_schema.UserResponse, error) {
+	var resp user_schema.UserResponse
+	if err := c.do(ctx, http.MethodGet, "/users/"+url.PathEscape(username), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateUser creates a user with the roles of the request.
+func (c *Client) CreateUser(ctx context.Context, req *user_schema.CreateUserRequest) (*user_schema.UserResponse, error) {
+	var resp user_schema.UserResponse
+	if err := c.do(ctx, http.MethodPost, "/users", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteUser deletes the user named username with its tokens and sessions.
+func (c *Client) DeleteUser(ctx context.Context, username string) error {
+	return c.do(ctx, http.MethodDelete, "/users/"+url.PathEscape(username), nil, nil, nil)
+}
@@ -0,0 +1,150 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	}
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := Parse(expr); err == nil {
+				t.Errorf("Parse(%q) expected error", expr)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	loc := time.UTC
+	tests := []struct {
+		name     string
+		expr     string
+		from     time.Time
+		expected time.Time
+	}{
+		{
+			name:     "every minute",
+			expr:     "* * * * *",
+			from:     time.Date(2024, 1, 1, 10, 0, 30, 0, loc),
+			expected: time.Date(2024, 1, 1, 10, 1, 0, 0, loc),
+		},
+		{
+			name:     "strictly after",
+			expr:     "30 23 * * *",
+			from:     time.Date(2024, 1, 1, 23, 30, 0, 0, loc),
+			expected: time.Date(2024, 1, 2, 23, 30, 0, 0, loc),
+		},
+		{
+			name:     "weekdays at 23:30 from friday",
+			expr:     "30 23 * * 1-5",
+			from:     time.Date(2024, 1, 5, 23, 31, 0, 0, loc), // friday
+			expected: time.Date(2024, 1, 8, 23, 30, 0, 0, loc), // monday
+		},
+		{
+			name:     "named weekdays",
+			expr:     "0 18 * * mon-fri",
+			from:     time.Date(2024, 1, 6, 12, 0, 0, 0, loc), // saturday
+			expected: time.Date(2024, 1, 8, 18, 0, 0, 0, loc),
+		},
+		{
+			name:     "step",
+			expr:     "*/15 * * * *",
+			from:     time.Date(2024, 1, 1, 10, 16, 0, 0, loc),
+			expected: time.Date(2024, 1, 1, 10, 30, 0, 0, loc),
+		},
+		{
+			name:     "month wrap",
+			expr:     "0 0 1 * *",
+			from:     time.Date(2024, 12, 15, 0, 0, 0, 0, loc),
+			expected: time.Date(2025, 1, 1, 0, 0, 0, 0, loc),
+		},
+		{
+			name:     "leap day",
+			expr:     "0 0 29 2 *",
+			from:     time.Date(2024, 3, 1, 0, 0, 0, 0, loc),
+			expected: time.Date(2028, 2, 29, 0, 0, 0, 0, loc),
+		},
+		{
+			name:     "day of month or day of week",
+			expr:     "0 0 13 * 5",
+			from:     time.Date(2024, 1, 1, 0, 0, 0, 0, loc),
+			expected: time.Date(2024, 1, 5, 0, 0, 0, 0, loc),
+		},
+		{
+			name:     "sunday as 7",
+			expr:     "0 9 * * 7",
+			from:     time.Date(2024, 1, 1, 0, 0, 0, 0, loc),
+			expected: time.Date(2024, 1, 7, 9, 0, 0, 0, loc),
+		},
+		{
+			name:     "descriptor",
+			expr:     "@daily",
+			from:     time.Date(2024, 1, 1, 0, 0, 0, 0, loc),
+			expected: time.Date(2024, 1, 2, 0, 0, 0, 0, loc),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.expr, err)
+			}
+			got := s.Next(tt.from)
+			if !got.Equal(tt.expected) {
+				t.Errorf("Next(%v) = %v, expected %v", tt.from, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNextInLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	s, err := Parse("0 8 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).In(loc))
+	expected := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestNextImpossible(t *testing.T) {
+	s, err := Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Errorf("expected zero time, got %v", got)
+	}
+}
+
+func TestNextN(t *testing.T) {
+	s, err := Parse("0 */6 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.NextN(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 4)
+	if len(got) != 4 {
+		t.Fatalf("expected 4 times, got %d", len(got))
+	}
+	for i, h := range []int{6, 12, 18, 0} {
+		if got[i].Hour() != h {
+			t.Errorf("expected hour %d at %d, got %v", h, i, got[i])
+		}
+	}
+}
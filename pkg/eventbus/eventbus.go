@@ -0,0 +1,133 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TopicAll matches every topic, "name.*" matches the topics starting with "name.".
+const TopicAll = "*"
+
+// Event is a message published on a topic, Id increases with every event of the bus.
+type Event struct {
+	Id    uint64      `json:"id"`
+	Topic string      `json:"topic"`
+	Time  time.Time   `json:"time"`
+	Data  interface{} `json:"data,omitempty"`
+}
+
+// Bus delivers the published events to the subscriptions of their topic. Publishing never blocks, the events a
+// subscription is too slow to receive are dropped and counted.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+	seq  atomic.Uint64
+	now  func() time.Time
+}
+
+func New() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{}), now: time.Now}
+}
+
+// Publish sends an event to the matching subscriptions and returns it.
+func (b *Bus) Publish(topic string, data interface{}) Event {
+	e := Event{Id: b.seq.Add(1), Topic: topic, Time: b.now(), Data: data}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.subs {
+		if !s.matches(topic) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+	return e
+}
+
+// Subscribe returns a subscription buffering up to size events of the topics, every topic when none is given.
+func (b *Bus) Subscribe(size int, topics ...string) *Subscription {
+	if size < 1 {
+		size = 1
+	}
+	s := &Subscription{bus: b, ch: make(chan Event, size), topics: topics}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[s] = struct{}{}
+	return s
+}
+
+// Subscribers returns the number of open subscriptions.
+func (b *Bus) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+type Subscription struct {
+	bus     *Bus
+	ch      chan Event
+	topics  []string
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+// C returns the channel of the events, it is closed by Close.
+func (s *Subscription) C() <-chan Event {
+	return s.ch
+}
+
+// Dropped returns the number of events lost because the buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close removes the subscription from the bus, it is safe to call more than once.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		defer s.bus.mu.Unlock()
+		delete(s.bus.subs, s)
+		close(s.ch)
+	})
+}
+
+func (s *Subscription) matches(topic string) bool {
+	if len(s.topics) == 0 {
+		return true
+	}
+	for _, t := range s.topics {
+		if Match(t, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether the topic is selected by the pattern.
+func Match(pattern, topic string) bool {
+	if pattern == TopicAll || pattern == topic {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, ".*"); ok {
+		return strings.HasPrefix(topic, prefix+".")
+	}
+	return false
+}
+
+// WriteSSE writes the event as a Server-Sent Events message.
+func WriteSSE(w io.Writer, e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Id, e.Topic, data)
+	return err
+}
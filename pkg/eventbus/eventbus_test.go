@@ -0,0 +1,128 @@
+package eventbus
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, topic string
+		want           bool
+	}{
+		{"*", "slave.connected", true},
+		{"slave.connected", "slave.connected", true},
+		{"slave.connected", "slave.disconnected", false},
+		{"slave.*", "slave.connected", true},
+		{"slave.*", "slave", false},
+		{"slave.*", "slaves.connected", false},
+		{"action.*", "action.requested", true},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.topic); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	b := New()
+	all := b.Subscribe(10)
+	slaves := b.Subscribe(10, "slave.*")
+	config := b.Subscribe(10, "config.changed")
+
+	b.Publish("slave.connected", "a")
+	b.Publish("config.changed", "b")
+	b.Publish("network.changed", nil)
+
+	want := map[*Subscription][]string{
+		all:    {"slave.connected", "config.changed", "network.changed"},
+		slaves: {"slave.connected"},
+		config: {"config.changed"},
+	}
+	for s, topics := range want {
+		if len(s.C()) != len(topics) {
+			t.Fatalf("got %d events, want %v", len(s.C()), topics)
+		}
+		var last uint64
+		for _, topic := range topics {
+			e := <-s.C()
+			if e.Topic != topic {
+				t.Errorf("got topic %q, want %q", e.Topic, topic)
+			}
+			if e.Id <= last {
+				t.Errorf("id %d does not increase", e.Id)
+			}
+			last = e.Id
+		}
+	}
+}
+
+func TestSlowSubscriber(t *testing.T) {
+	b := New()
+	s := b.Subscribe(2)
+	for i := 0; i < 5; i++ {
+		b.Publish("t", i)
+	}
+	if s.Dropped() != 3 {
+		t.Errorf("Dropped = %d, want 3", s.Dropped())
+	}
+	if e := <-s.C(); e.Data != 0 {
+		t.Errorf("expected the oldest events to be kept, got %v", e.Data)
+	}
+}
+
+func TestClose(t *testing.T) {
+	b := New()
+	s := b.Subscribe(1)
+	if b.Subscribers() != 1 {
+		t.Fatalf("Subscribers = %d", b.Subscribers())
+	}
+	s.Close()
+	s.Close()
+	if b.Subscribers() != 0 {
+		t.Errorf("Subscribers = %d after Close", b.Subscribers())
+	}
+	b.Publish("t", nil)
+	if _, ok := <-s.C(); ok {
+		t.Errorf("expected the channel to be closed")
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	b := New()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				b.Publish("t", j)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				b.Subscribe(1).Close()
+			}
+		}()
+	}
+	wg.Wait()
+	if b.Subscribers() != 0 {
+		t.Errorf("Subscribers = %d", b.Subscribers())
+	}
+}
+
+func TestWriteSSE(t *testing.T) {
+	var buf bytes.Buffer
+	e := Event{Id: 7, Topic: "slave.connected", Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Data: map[string]string{"username": "bob"}}
+	if err := WriteSSE(&buf, e); err != nil {
+		t.Fatal(err)
+	}
+	want := "id: 7\nevent: slave.connected\ndata: {\"id\":7,\"topic\":\"slave.connected\",\"time\":\"2024-01-01T00:00:00Z\",\"data\":{\"username\":\"bob\"}}\n\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,124 @@
+// Package limits starts processes with the resource limits of a custom command. On linux the service re-executes
+// itself as a wrapper that applies the limits to itself and replaces itself with the command.
+package limits
+
+import (
+	"errors"
+	"fadacontrol/internal/schema/custom_command_schema"
+	"fmt"
+	"golang.org/x/sys/unix"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"unsafe"
+)
+
+// limitsWrapperArg is the first argument of the service re-executed to apply the resource limits of a command, the
+// wrapper applies them to itself and replaces itself with the command, so the command never runs without them.
+const limitsWrapperArg = "__fadacontrol_limits"
+
+func init() {
+	if len(os.Args) > 1 && os.Args[1] == limitsWrapperArg {
+		os.Exit(execWithLimits(os.Args[2:]))
+	}
+}
+
+// Supported reports whether the resource limits are applied on this OS.
+const Supported = true
+
+// Start starts command through the limits wrapper when it has resource limits.
+func Start(command *exec.Cmd, limits custom_command_schema.ResourceLimits) error {
+	if limits.IsEmpty() {
+		return command.Start()
+	}
+	if command.Err != nil {
+		return command.Err
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	nnp := "0"
+	if limits.NoNewPrivileges {
+		nnp = "1"
+	}
+	args := []string{self, limitsWrapperArg, strconv.FormatUint(limits.CpuTime, 10), strconv.FormatUint(limits.Memory, 10), nnp, command.Path}
+	command.Path = self
+	command.Args = append(args, command.Args...)
+	return command.Start()
+}
+
+// execWithLimits runs in the wrapper with the arguments cpu time, memory, no_new_privs, path and argv of the command,
+// it only returns when the command could not be executed.
+func execWithLimits(args []string) int {
+	if len(args) < 5 {
+		fmt.Fprintln(os.Stderr, "invalid arguments of the limits wrapper")
+		return 127
+	}
+	cpuTime, err1 := strconv.ParseUint(args[0], 10, 64)
+	memory, err2 := strconv.ParseUint(args[1], 10, 64)
+	if err := errors.Join(err1, err2); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 127
+	}
+	path, argv := args[3], args[4:]
+	// the arguments are converted before the address space is limited, nothing is allocated afterwards
+	pathp, err := unix.BytePtrFromString(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 127
+	}
+	argvp, err := cStrings(argv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 127
+	}
+	envp, err := cStrings(os.Environ())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 127
+	}
+
+	// no_new_privs is set on the thread that calls execve
+	runtime.LockOSThread()
+	if args[2] == "1" {
+		if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set no_new_privs: %v\n", err)
+			return 126
+		}
+	}
+	if cpuTime > 0 {
+		if err := unix.Setrlimit(unix.RLIMIT_CPU, &unix.Rlimit{Cur: cpuTime, Max: cpuTime}); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to limit the cpu time: %v\n", err)
+			return 126
+		}
+	}
+	if memory > 0 {
+		bytes := memory * 1024 * 1024
+		if err := unix.Setrlimit(unix.RLIMIT_AS, &unix.Rlimit{Cur: bytes, Max: bytes}); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to limit the memory: %v\n", err)
+			return 126
+		}
+	}
+	_, _, errno := unix.RawSyscall(unix.SYS_EXECVE, uintptr(unsafe.Pointer(pathp)),
+		uintptr(unsafe.Pointer(&argvp[0])), uintptr(unsafe.Pointer(&envp[0])))
+	fmt.Fprintf(os.Stderr, "failed to execute %s: %v\n", path, errno)
+	if errno == unix.ENOENT {
+		return 127
+	}
+	return 126
+}
+
+// cStrings converts ss to the NULL terminated array of strings expected by execve.
+func cStrings(ss []string) ([]*byte, error) {
+	ret := make([]*byte, len(ss)+1)
+	for i, s := range ss {
+		p, err := unix.BytePtrFromString(s)
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = p
+	}
+	return ret, nil
+}
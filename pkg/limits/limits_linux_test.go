@@ -0,0 +1,39 @@
+package limits
+
+import (
+	"bytes"
+	"fadacontrol/internal/schema/custom_command_schema"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestStart(t *testing.T) {
+	command := exec.Command("sh", "-c", "ulimit -t; ulimit -v; grep NoNewPrivs /proc/self/status")
+	var out bytes.Buffer
+	command.Stdout = &out
+	limits := custom_command_schema.ResourceLimits{CpuTime: 7, Memory: 512, NoNewPrivileges: true}
+	if err := Start(command, limits); err != nil {
+		t.Fatal(err)
+	}
+	if err := command.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := []string{"7", "524288", "NoNewPrivs:\t1"}
+	if len(lines) != len(want) {
+		t.Fatalf("got %q", out.String())
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("got %q, want %q", lines[i], want[i])
+		}
+	}
+}
+
+func TestStartNotFound(t *testing.T) {
+	command := exec.Command("fadacontrol-command-not-found")
+	if err := Start(command, custom_command_schema.ResourceLimits{CpuTime: 1}); err == nil {
+		t.Fatal("expected an error for a missing command")
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package limits
+
+import (
+	"fadacontrol/internal/schema/custom_command_schema"
+	"os/exec"
+)
+
+// Supported reports whether the resource limits are applied on this OS.
+const Supported = false
+
+// Start starts command, the resource limits are ignored.
+func Start(command *exec.Cmd, limits custom_command_schema.ResourceLimits) error {
+	return command.Start()
+}
@@ -0,0 +1,142 @@
+package logsearch
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		level   zapcore.Level
+		module  string
+		message string
+		request string
+	}{
+		{
+			line:    "2024-05-01T22:10:03.512+0800\t\x1b[34mINFO\x1b[0m\tunlock/unlock.go:42\tunlocking the session",
+			level:   zapcore.InfoLevel,
+			module:  "unlock",
+			message: "unlocking the session",
+		},
+		{
+			line:    "2024-05-01T22:10:03.600+0800\tERROR\thttp_service/binding.go:7\tfailed\tto unlock\t{\"request_id\": \"abc\"}",
+			level:   zapcore.ErrorLevel,
+			module:  "http_service",
+			message: "failed\tto unlock",
+			request: "abc",
+		},
+		{
+			line:    `{"level":"warn","ts":1714572603.5,"logger":"remote","msg":"reconnecting","request_id":"def"}`,
+			level:   zapcore.WarnLevel,
+			module:  "remote",
+			message: "reconnecting",
+			request: "def",
+		},
+	}
+	for _, tt := range tests {
+		e, ok := ParseLine(tt.line)
+		if !ok {
+			t.Errorf("failed to parse %q", tt.line)
+			continue
+		}
+		if e.Level != tt.level || e.Module() != tt.module || e.Message != tt.message || e.RequestId() != tt.request {
+			t.Errorf("%q: got %+v", tt.line, e)
+		}
+	}
+	for _, line := range []string{"", "goroutine 1 [running]:", "\tmain.go:12", `{"msg":"no time"}`} {
+		if _, ok := ParseLine(line); ok {
+			t.Errorf("expected %q not to start an entry", line)
+		}
+	}
+}
+
+func writeLog(t *testing.T, path, content string, compress bool) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if !compress {
+		if _, err := f.WriteString(content); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	gz := gzip.NewWriter(f)
+	if _, err := gz.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	writeLog(t, filepath.Join(dir, "app-2024-05-01T00-00-00.000.log.gz"),
+		"2024-04-30T23:00:00.000Z\tINFO\tunlock/unlock.go:1\toldest\n", true)
+	writeLog(t, filepath.Join(dir, "app-2024-05-02T00-00-00.000.log"),
+		"2024-05-01T22:00:00.000Z\tINFO\tunlock/unlock.go:1\tunlock requested\n"+
+			"2024-05-01T22:00:01.000Z\tERROR\tunlock/unlock.go:2\tunlock failed\t{\"request_id\":\"r1\"}\n"+
+			"main.unlock()\n"+
+			"\t/src/unlock.go:2\n", false)
+	writeLog(t, path,
+		"2024-05-02T08:00:00.000Z\tDEBUG\thttp_service/http_service.go:1\tstarted\n"+
+			"2024-05-02T08:00:01.000Z\tWARN\tunlock/unlock.go:3\tretrying\n", false)
+	writeLog(t, filepath.Join(dir, "other-2024-05-02T00-00-00.000.log"),
+		"2024-05-01T22:00:00.000Z\tERROR\tunlock/unlock.go:1\tnot searched\n", false)
+
+	files, err := Files(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 || files[0].Path != path || !files[2].Compressed {
+		t.Fatalf("unexpected files %+v", files)
+	}
+
+	entries, total, err := Search(path, &Filter{Level: zapcore.DebugLevel}, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Message)
+	}
+	want := []string{"retrying", "started", "unlock failed", "unlock requested", "oldest"}
+	if total != len(want) || len(got) != len(want) {
+		t.Fatalf("got %v, total %d", got, total)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+	if entries[2].Stack != "main.unlock()\n\t/src/unlock.go:2" {
+		t.Errorf("unexpected stack %q", entries[2].Stack)
+	}
+
+	from := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	entries, total, err = Search(path, &Filter{From: from, To: to, Level: zapcore.WarnLevel, Module: "unlock", RequestId: "r1"}, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || entries[0].Message != "unlock failed" {
+		t.Errorf("got %+v, total %d", entries, total)
+	}
+
+	entries, total, err = Search(path, &Filter{Level: zapcore.DebugLevel}, 3, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 5 || len(entries) != 2 || entries[0].Message != "unlock requested" {
+		t.Errorf("got %+v, total %d", entries, total)
+	}
+}
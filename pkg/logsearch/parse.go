@@ -0,0 +1,148 @@
+package logsearch
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Entry is a line written by zap, the stack trace is the lines that follow it.
+type Entry struct {
+	Time    time.Time
+	Level   zapcore.Level
+	Logger  string
+	Caller  string
+	Message string
+	Fields  map[string]interface{}
+	Stack   string
+	// File is the name of the log file the entry was read from.
+	File string
+}
+
+// Module is the name of the logger, or the package of the caller when the logger is not named.
+func (e *Entry) Module() string {
+	if e.Logger != "" {
+		return e.Logger
+	}
+	dir := path.Dir(strings.ReplaceAll(e.Caller, "\\", "/"))
+	if dir == "." {
+		return ""
+	}
+	return path.Base(dir)
+}
+
+// RequestId is the request_id field of the entry.
+func (e *Entry) RequestId() string {
+	if id, ok := e.Fields["request_id"].(string); ok {
+		return id
+	}
+	return ""
+}
+
+// timeLayouts are the layouts of the time encoders of zap.
+var timeLayouts = []string{
+	"2006-01-02T15:04:05.000Z0700",
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.000",
+}
+
+var colorCodes = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+var callerPattern = regexp.MustCompile(`^\S+\.go:\d+$`)
+
+func parseTime(s string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseLevel(s string) (zapcore.Level, bool) {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(strings.ToLower(colorCodes.ReplaceAllString(s, "")))); err != nil {
+		return level, false
+	}
+	return level, true
+}
+
+// ParseLine parses a line written by the JSON or the console encoder of zap, ok is false for the lines that don't
+// start an entry, such as the lines of a stack trace.
+func ParseLine(line string) (e *Entry, ok bool) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.HasPrefix(line, "{") {
+		return parseJSONLine(line)
+	}
+	return parseConsoleLine(line)
+}
+
+func parseJSONLine(line string) (*Entry, bool) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return nil, false
+	}
+	e := &Entry{Fields: fields}
+	switch ts := fields["ts"].(type) {
+	case float64:
+		sec := int64(ts)
+		e.Time = time.Unix(sec, int64((ts-float64(sec))*float64(time.Second)))
+	case string:
+		t, ok := parseTime(ts)
+		if !ok {
+			return nil, false
+		}
+		e.Time = t
+	default:
+		return nil, false
+	}
+	level, _ := fields["level"].(string)
+	var ok bool
+	if e.Level, ok = parseLevel(level); !ok {
+		return nil, false
+	}
+	e.Logger, _ = fields["logger"].(string)
+	e.Caller, _ = fields["caller"].(string)
+	e.Message, _ = fields["msg"].(string)
+	e.Stack, _ = fields["stacktrace"].(string)
+	for _, key := range []string{"ts", "level", "logger", "caller", "msg", "stacktrace"} {
+		delete(fields, key)
+	}
+	return e, true
+}
+
+func parseConsoleLine(line string) (*Entry, bool) {
+	parts := strings.Split(line, "\t")
+	if len(parts) < 3 {
+		return nil, false
+	}
+	t, ok := parseTime(parts[0])
+	if !ok {
+		return nil, false
+	}
+	level, ok := parseLevel(parts[1])
+	if !ok {
+		return nil, false
+	}
+	e := &Entry{Time: t, Level: level}
+	parts = parts[2:]
+	// the name of the logger comes before the caller, both are optional
+	if len(parts) > 2 && !callerPattern.MatchString(parts[0]) && callerPattern.MatchString(parts[1]) {
+		e.Logger, parts = parts[0], parts[1:]
+	}
+	if len(parts) > 1 && callerPattern.MatchString(parts[0]) {
+		e.Caller, parts = parts[0], parts[1:]
+	}
+	if n := len(parts); n > 1 && strings.HasPrefix(parts[n-1], "{") {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(parts[n-1]), &fields); err == nil {
+			e.Fields, parts = fields, parts[:n-1]
+		}
+	}
+	e.Message = strings.Join(parts, "\t")
+	return e, true
+}
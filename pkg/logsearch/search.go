@@ -0,0 +1,188 @@
+// Package logsearch reads the log file written by zap and the backups rotated by lumberjack.
+package logsearch
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// backupTimeFormat is the time format lumberjack puts in the name of the rotated files.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// maxLineSize is the longest line that is read, longer lines end the file.
+const maxLineSize = 1024 * 1024
+
+// LogFile is the current log file or a backup.
+type LogFile struct {
+	Path string
+	// Rotated is when the backup was rotated, it is zero for the current file.
+	Rotated    time.Time
+	Compressed bool
+}
+
+// Files returns the log file at path and its backups, newest first.
+func Files(path string) ([]LogFile, error) {
+	dir := filepath.Dir(path)
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	prefix := strings.TrimSuffix(name, ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var ret []LogFile
+	var backups []LogFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		f := entry.Name()
+		if f == name {
+			ret = append(ret, LogFile{Path: filepath.Join(dir, f)})
+			continue
+		}
+		compressed := strings.HasSuffix(f, ".gz")
+		stem := strings.TrimSuffix(f, ".gz")
+		if !strings.HasPrefix(stem, prefix) || !strings.HasSuffix(stem, ext) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimPrefix(stem, prefix), ext)
+		// lumberjack uses UTC unless it is configured with the local time
+		rotated, err := time.Parse(backupTimeFormat, ts)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, LogFile{Path: filepath.Join(dir, f), Rotated: rotated, Compressed: compressed})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Rotated.After(backups[j].Rotated) })
+	return append(ret, backups...), nil
+}
+
+// Filter selects the entries of a search, the zero values match every entry.
+type Filter struct {
+	From time.Time
+	To   time.Time
+	// Level is the minimum level, use zapcore.DebugLevel for every entry.
+	Level zapcore.Level
+	// Module is matched against Entry.Module.
+	Module string
+	// RequestId is matched against the request_id field, or found in the message.
+	RequestId string
+}
+
+func (f *Filter) match(e *Entry) bool {
+	if !f.From.IsZero() && e.Time.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !e.Time.Before(f.To) {
+		return false
+	}
+	if e.Level < f.Level {
+		return false
+	}
+	if f.Module != "" && !strings.EqualFold(e.Module(), f.Module) {
+		return false
+	}
+	if f.RequestId != "" && e.RequestId() != f.RequestId && !strings.Contains(e.Message, f.RequestId) {
+		return false
+	}
+	return true
+}
+
+// Read calls fn with the entries of the file in the order they were written.
+func Read(file LogFile, fn func(*Entry)) error {
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if file.Compressed {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	name := filepath.Base(file.Path)
+	var last *Entry
+	var stack []string
+	flush := func() {
+		if last == nil {
+			return
+		}
+		if len(stack) > 0 && last.Stack == "" {
+			last.Stack = strings.Join(stack, "\n")
+		}
+		fn(last)
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if e, ok := ParseLine(line); ok {
+			flush()
+			e.File = name
+			last, stack = e, nil
+			continue
+		}
+		// the lines before the first entry were written before the file was rotated
+		if last != nil && line != "" {
+			stack = append(stack, line)
+		}
+	}
+	flush()
+	if err := scanner.Err(); err != nil && !errors.Is(err, bufio.ErrTooLong) {
+		return err
+	}
+	return nil
+}
+
+// Search returns the entries of the log file at path and its backups that match the filter, newest first, skipping
+// offset entries and returning at most limit. total is the number of the matching entries.
+func Search(path string, filter *Filter, offset, limit int) (entries []*Entry, total int, err error) {
+	files, err := Files(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	entries = make([]*Entry, 0)
+	for _, file := range files {
+		// a backup only has the entries written before it was rotated
+		if !filter.From.IsZero() && !file.Rotated.IsZero() && file.Rotated.Before(filter.From) {
+			continue
+		}
+		var matched []*Entry
+		err := Read(file, func(e *Entry) {
+			if filter.match(e) {
+				matched = append(matched, e)
+			}
+		})
+		// the old backups are removed by lumberjack while they are searched
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		for i := len(matched) - 1; i >= 0; i-- {
+			if total >= offset && len(entries) < limit {
+				entries = append(entries, matched[i])
+			}
+			total++
+		}
+	}
+	return entries, total, nil
+}
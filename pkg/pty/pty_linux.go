@@ -0,0 +1,85 @@
+package pty
+
+import (
+	"fadacontrol/internal/schema/custom_command_schema"
+	"fadacontrol/pkg/limits"
+	"golang.org/x/sys/unix"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+func open() (*os.File, *os.File, error) {
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var n int
+	err = control(ptmx, func(fd int) error {
+		if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+			return err
+		}
+		var err error
+		n, err = unix.IoctlGetInt(fd, unix.TIOCGPTN)
+		return err
+	})
+	if err != nil {
+		ptmx.Close()
+		return nil, nil, err
+	}
+	tty, err := os.OpenFile("/dev/pts/"+strconv.Itoa(n), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		ptmx.Close()
+		return nil, nil, err
+	}
+	return ptmx, tty, nil
+}
+
+// control runs f with the raw descriptor without switching the file to blocking mode,
+// so that Close still interrupts a pending Read.
+func control(f *os.File, fn func(fd int) error) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var fnErr error
+	if err := conn.Control(func(fd uintptr) {
+		fnErr = fn(int(fd))
+	}); err != nil {
+		return err
+	}
+	return fnErr
+}
+func resize(pty *os.File, rows, cols uint16) error {
+	return control(pty, func(fd int) error {
+		return unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Row: rows, Col: cols})
+	})
+}
+
+// Start starts command with its resource limits in a new session, whose controlling terminal is a new
+// pseudo-terminal of rows and cols.
+func Start(command *exec.Cmd, l custom_command_schema.ResourceLimits, rows, cols uint16) (*Terminal, error) {
+	ptmx, tty, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer tty.Close()
+	if rows > 0 && cols > 0 {
+		if err := resize(ptmx, rows, cols); err != nil {
+			ptmx.Close()
+			return nil, err
+		}
+	}
+	command.Stdin = tty
+	command.Stdout = tty
+	command.Stderr = tty
+	command.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+	if err := limits.Start(command, l); err != nil {
+		ptmx.Close()
+		return nil, err
+	}
+	t := &Terminal{pty: ptmx, command: command, done: make(chan struct{})}
+	go t.wait()
+	return t, nil
+}
@@ -0,0 +1,90 @@
+package pty
+
+import (
+	"bytes"
+	"fadacontrol/internal/schema/custom_command_schema"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// readUntil reads the output of term until it contains want.
+func readUntil(t *testing.T, term *Terminal, want string) string {
+	t.Helper()
+	var out bytes.Buffer
+	buf := make([]byte, 256)
+	for !bytes.Contains(out.Bytes(), []byte(want)) {
+		n, err := term.Read(buf)
+		out.Write(buf[:n])
+		if err != nil {
+			break
+		}
+	}
+	if !bytes.Contains(out.Bytes(), []byte(want)) {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+	return out.String()
+}
+
+func TestExitCode(t *testing.T) {
+	term, err := Start(exec.Command("sh", "-c", "echo ready; exit 3"), custom_command_schema.ResourceLimits{}, 24, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readUntil(t, term, "ready")
+	select {
+	case <-term.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the process did not exit")
+	}
+	if code := term.ExitCode(); code != 3 {
+		t.Errorf("got exit code %d, want 3", code)
+	}
+	if err := term.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestResize(t *testing.T) {
+	term, err := Start(exec.Command("sh", "-c", "stty size; read line; stty size"), custom_command_schema.ResourceLimits{}, 24, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer term.Close()
+	readUntil(t, term, "24 80")
+	if err := term.Resize(40, 120); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := term.Write([]byte("\n")); err != nil {
+		t.Fatal(err)
+	}
+	readUntil(t, term, "40 120")
+}
+
+func TestClose(t *testing.T) {
+	term, err := Start(exec.Command("sleep", "60"), custom_command_schema.ResourceLimits{}, 24, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ExitCode is read concurrently with the process being killed
+	codes := make(chan int, 1)
+	go func() {
+		codes <- term.ExitCode()
+	}()
+	if err := term.Close(); err != nil {
+		t.Error(err)
+	}
+	if code := <-codes; code != -1 {
+		t.Errorf("got exit code %d for the killed process, want -1", code)
+	}
+}
+
+func TestStartWithLimits(t *testing.T) {
+	limits := custom_command_schema.ResourceLimits{CpuTime: 7}
+	term, err := Start(exec.Command("sh", "-c", "echo limit $(ulimit -t)"), limits, 24, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer term.Close()
+	readUntil(t, term, "limit 7")
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package pty
+
+import (
+	"errors"
+	"fadacontrol/internal/schema/custom_command_schema"
+	"os"
+	"os/exec"
+)
+
+func resize(pty *os.File, rows, cols uint16) error {
+	return errors.ErrUnsupported
+}
+
+// Start is not supported on this OS, it returns errors.ErrUnsupported.
+func Start(command *exec.Cmd, limits custom_command_schema.ResourceLimits, rows, cols uint16) (*Terminal, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,69 @@
+// Package pty runs processes attached to a pseudo-terminal, only supported on linux.
+package pty
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// Terminal is a process attached to a pseudo-terminal.
+type Terminal struct {
+	pty       *os.File
+	command   *exec.Cmd
+	done      chan struct{}
+	exitCode  int
+	closeOnce sync.Once
+}
+
+func (t *Terminal) Read(p []byte) (int, error) {
+	return t.pty.Read(p)
+}
+func (t *Terminal) Write(p []byte) (int, error) {
+	return t.pty.Write(p)
+}
+func (t *Terminal) Resize(rows, cols uint16) error {
+	return resize(t.pty, rows, cols)
+}
+
+// Done is closed when the process has exited.
+func (t *Terminal) Done() <-chan struct{} {
+	return t.done
+}
+
+// ExitCode waits for the process to exit and returns its exit code, -1 when it was killed.
+func (t *Terminal) ExitCode() int {
+	<-t.done
+	return t.exitCode
+}
+
+// Close kills the process if it is still running, releases the pseudo-terminal and waits for the process to exit.
+func (t *Terminal) Close() error {
+	var err error
+	t.closeOnce.Do(func() {
+		select {
+		case <-t.done:
+		default:
+			if t.command.Process != nil {
+				_ = t.command.Process.Kill()
+			}
+		}
+		err = t.pty.Close()
+		<-t.done
+	})
+	return err
+}
+func (t *Terminal) wait() {
+	err := t.command.Wait()
+	t.exitCode = 0
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			t.exitCode = exitErr.ExitCode()
+		} else {
+			t.exitCode = -1
+		}
+	}
+	close(t.done)
+}
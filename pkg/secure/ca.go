@@ -0,0 +1,219 @@
+package secure
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+const caValidTime = 10 * 365 * 24 * time.Hour
+
+// CertificateAuthority signs client and server certificates and revocation lists with a local root certificate.
+type CertificateAuthority struct {
+	Cert *x509.Certificate
+	Key  *ecdsa.PrivateKey
+}
+
+// RandomSerial returns a random 128 bit certificate serial number.
+func RandomSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// Fingerprint returns the hex encoded SHA-256 hash of the DER encoded certificate.
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// SpkiPin returns the base64 encoded SHA-256 hash of the public key of the certificate, the format used for public key pinning.
+func SpkiPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func encodeKeyPair(certDER []byte, priv *ecdsa.PrivateKey) (certPEM, keyPEM []byte, err error) {
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// GenerateCA creates a self-signed root certificate named commonName and returns it with its key in PEM format.
+func GenerateCA(commonName string) (certPEM, keyPEM []byte, err error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := RandomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   commonName,
+			Organization: []string{organization},
+		},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidTime),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encodeKeyPair(certDER, priv)
+}
+
+// ParseCertificatePEM parses the first certificate of a PEM block.
+func ParseCertificatePEM(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("failed to parse certificate PEM")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// LoadCA parses a root certificate and its key created by GenerateCA.
+func LoadCA(certPEM, keyPEM []byte) (*CertificateAuthority, error) {
+	cert, err := ParseCertificatePEM(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	if !cert.IsCA {
+		return nil, errors.New("the certificate is not a certificate authority")
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("failed to parse private key PEM")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %v", err)
+	}
+	if !key.PublicKey.Equal(cert.PublicKey) {
+		return nil, errors.New("the private key does not match the certificate")
+	}
+	return &CertificateAuthority{Cert: cert, Key: key}, nil
+}
+
+// CertPEM returns the root certificate in PEM format.
+func (ca *CertificateAuthority) CertPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Cert.Raw})
+}
+
+// CertPool returns a pool that trusts only this authority.
+func (ca *CertificateAuthority) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	return pool
+}
+
+// issue signs a new key with the subject, usages and names of template, the validity never exceeds the one of the authority.
+func (ca *CertificateAuthority) issue(template *x509.Certificate, validFor time.Duration) (cert *x509.Certificate, certPEM, keyPEM []byte, err error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	template.SerialNumber, err = RandomSerial()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	now := time.Now()
+	template.NotBefore = now.Add(-5 * time.Minute)
+	template.NotAfter = now.Add(validFor)
+	if template.NotAfter.After(ca.Cert.NotAfter) {
+		template.NotAfter = ca.Cert.NotAfter
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	template.BasicConstraintsValid = true
+	certDER, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, &priv.PublicKey, ca.Key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cert, err = x509.ParseCertificate(certDER)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	certPEM, keyPEM, err = encodeKeyPair(certDER, priv)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return cert, certPEM, keyPEM, nil
+}
+
+// IssueClientCert creates a client authentication certificate for commonName that is valid for validFor.
+func (ca *CertificateAuthority) IssueClientCert(commonName string, validFor time.Duration) (cert *x509.Certificate, certPEM, keyPEM []byte, err error) {
+	return ca.issue(&x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:   commonName,
+			Organization: []string{organization},
+		},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, validFor)
+}
+
+// IssueServerCert creates a server authentication certificate for the host names and addresses that is valid for validFor.
+func (ca *CertificateAuthority) IssueServerCert(commonName string, dnsNames []string, ips []net.IP, validFor time.Duration) (cert *x509.Certificate, certPEM, keyPEM []byte, err error) {
+	return ca.issue(&x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:   commonName,
+			Organization: []string{organization},
+		},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:    dnsNames,
+		IPAddresses: ips,
+	}, validFor)
+}
+
+// VerifyServerCert checks that cert was issued by this authority for server authentication.
+func (ca *CertificateAuthority) VerifyServerCert(cert *x509.Certificate) error {
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:     ca.CertPool(),
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	return err
+}
+
+// VerifyClientCert checks that cert was issued by this authority for client authentication.
+func (ca *CertificateAuthority) VerifyClientCert(cert *x509.Certificate) error {
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:     ca.CertPool(),
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err
+}
+
+// CreateCRL signs a PEM encoded certificate revocation list of the revoked entries, number has to grow with every list.
+func (ca *CertificateAuthority) CreateCRL(revoked []x509.RevocationListEntry, number *big.Int, validFor time.Duration) ([]byte, error) {
+	now := time.Now()
+	template := &x509.RevocationList{
+		RevokedCertificateEntries: revoked,
+		Number:                    number,
+		ThisUpdate:                now,
+		NextUpdate:                now.Add(validFor),
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, template, ca.Cert, ca.Key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), nil
+}
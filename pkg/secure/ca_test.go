@@ -0,0 +1,174 @@
+package secure
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+func newTestCA(t *testing.T) *CertificateAuthority {
+	certPEM, keyPEM, err := GenerateCA("test ca")
+	if err != nil {
+		t.Fatalf("GenerateCA failed: %v", err)
+	}
+	ca, err := LoadCA(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("LoadCA failed: %v", err)
+	}
+	return ca
+}
+
+func TestLoadCA(t *testing.T) {
+	certPEM, keyPEM, err := GenerateCA("test ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherKey, err := GenerateCA("other ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafCert, leafKey, err := GenerateX509Cert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		cert    []byte
+		key     []byte
+		wantErr bool
+	}{
+		{"valid", certPEM, keyPEM, false},
+		{"mismatched key", certPEM, otherKey, true},
+		{"not a ca", leafCert, leafKey, true},
+		{"garbage", []byte("garbage"), keyPEM, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadCA(tt.cert, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadCA() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIssueClientCert(t *testing.T) {
+	ca := newTestCA(t)
+	other := newTestCA(t)
+
+	cert, certPEM, keyPEM, err := ca.IssueClientCert("alice", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("IssueClientCert failed: %v", err)
+	}
+	if cert.Subject.CommonName != "alice" {
+		t.Errorf("unexpected common name %s", cert.Subject.CommonName)
+	}
+	if _, err := LoadX509KeyPairFromMemory(certPEM, keyPEM); err != nil {
+		t.Errorf("the issued key pair can not be loaded: %v", err)
+	}
+	if err := ca.VerifyClientCert(cert); err != nil {
+		t.Errorf("the issued certificate is not trusted: %v", err)
+	}
+	if err := other.VerifyClientCert(cert); err == nil {
+		t.Errorf("a certificate of another authority was trusted")
+	}
+
+	long, _, _, err := ca.IssueClientCert("bob", 100*365*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if long.NotAfter.After(ca.Cert.NotAfter) {
+		t.Errorf("the certificate outlives the authority")
+	}
+}
+
+func TestCreateCRL(t *testing.T) {
+	ca := newTestCA(t)
+	cert, _, _, err := ca.IssueClientCert("alice", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked := []x509.RevocationListEntry{{SerialNumber: cert.SerialNumber, RevocationTime: time.Now()}}
+	crlPEM, err := ca.CreateCRL(revoked, big.NewInt(2), time.Hour)
+	if err != nil {
+		t.Fatalf("CreateCRL failed: %v", err)
+	}
+	block, _ := pem.Decode(crlPEM)
+	if block == nil || block.Type != "X509 CRL" {
+		t.Fatalf("unexpected CRL encoding")
+	}
+	crl, err := x509.ParseRevocationList(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crl.CheckSignatureFrom(ca.Cert); err != nil {
+		t.Errorf("CRL signature invalid: %v", err)
+	}
+	if len(crl.RevokedCertificateEntries) != 1 || crl.RevokedCertificateEntries[0].SerialNumber.Cmp(cert.SerialNumber) != 0 {
+		t.Errorf("the revoked certificate is missing from the CRL")
+	}
+	if crl.Number.Cmp(big.NewInt(2)) != 0 {
+		t.Errorf("unexpected CRL number %v", crl.Number)
+	}
+}
+
+func TestIssueServerCert(t *testing.T) {
+	ca := newTestCA(t)
+	ips := []net.IP{net.ParseIP("192.168.1.10"), net.IPv6loopback}
+	cert, certPEM, keyPEM, err := ca.IssueServerCert("desktop", []string{"desktop", "localhost"}, ips, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("IssueServerCert failed: %v", err)
+	}
+	if _, err := LoadX509KeyPairFromMemory(certPEM, keyPEM); err != nil {
+		t.Errorf("the issued key pair can not be loaded: %v", err)
+	}
+	if err := ca.VerifyServerCert(cert); err != nil {
+		t.Errorf("the issued certificate is not trusted: %v", err)
+	}
+	if err := ca.VerifyClientCert(cert); err == nil {
+		t.Errorf("a server certificate was accepted for client authentication")
+	}
+	for _, host := range []string{"desktop", "localhost", "192.168.1.10", "::1"} {
+		if err := cert.VerifyHostname(host); err != nil {
+			t.Errorf("the certificate does not cover %s: %v", host, err)
+		}
+	}
+	if err := cert.VerifyHostname("192.168.1.11"); err == nil {
+		t.Errorf("the certificate covers an address it was not issued for")
+	}
+}
+
+func TestIsGeneratedX509Cert(t *testing.T) {
+	ca := newTestCA(t)
+	generatedPEM, _, err := GenerateX509Cert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := ParseCertificatePEM(generatedPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issued, _, _, err := ca.IssueServerCert("desktop", []string{"desktop"}, nil, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		cert *x509.Certificate
+		want bool
+	}{
+		{"generated", generated, true},
+		{"issued by the authority", issued, false},
+		{"authority", ca.Cert, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsGeneratedX509Cert(tt.cert); got != tt.want {
+				t.Errorf("IsGeneratedX509Cert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
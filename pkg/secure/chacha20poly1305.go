@@ -8,6 +8,12 @@ import (
 
 // EncryptChaCha20Poly1305 encrypts the plaintext using ChaCha20-Poly1305.
 func EncryptChaCha20Poly1305(key, plaintext []byte) ([]byte, error) {
+	return EncryptChaCha20Poly1305WithAD(key, plaintext, nil)
+}
+
+// EncryptChaCha20Poly1305WithAD encrypts the plaintext using ChaCha20-Poly1305 and authenticates additionalData with
+// it, the same additional data must be passed to decrypt the ciphertext.
+func EncryptChaCha20Poly1305WithAD(key, plaintext, additionalData []byte) ([]byte, error) {
 	aead, err := chacha20poly1305.New(key)
 	if err != nil {
 		return nil, err
@@ -18,12 +24,18 @@ func EncryptChaCha20Poly1305(key, plaintext []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	ciphertext := aead.Seal(nonce, nonce, plaintext, nil)
+	ciphertext := aead.Seal(nonce, nonce, plaintext, additionalData)
 	return ciphertext, nil
 }
 
 // DecryptChaCha20Poly1305 decrypts the ciphertext using ChaCha20-Poly1305.
 func DecryptChaCha20Poly1305(key, ciphertext []byte) ([]byte, error) {
+	return DecryptChaCha20Poly1305WithAD(key, ciphertext, nil)
+}
+
+// DecryptChaCha20Poly1305WithAD decrypts the ciphertext using ChaCha20-Poly1305, it fails when additionalData is not
+// the one the ciphertext was encrypted with.
+func DecryptChaCha20Poly1305WithAD(key, ciphertext, additionalData []byte) ([]byte, error) {
 	aead, err := chacha20poly1305.New(key)
 	if err != nil {
 		return nil, err
@@ -35,7 +47,7 @@ func DecryptChaCha20Poly1305(key, ciphertext []byte) ([]byte, error) {
 	}
 
 	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
 	if err != nil {
 		return nil, err
 	}
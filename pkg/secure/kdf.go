@@ -3,6 +3,7 @@ package secure
 import (
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
 	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
@@ -82,8 +83,10 @@ func HashPasswordByKDFBase64(password string, salt string) string {
 	hash := pbkdf2.Key([]byte(password), saltBytes, 4096, 32, sha256.New)
 	return base64.RawStdEncoding.EncodeToString(hash)
 }
+
+// VerifyPassword checks a legacy PBKDF2 hash, new hashes are verified with CheckPassword.
 func VerifyPassword(password, salt, hashedPassword string) bool {
 	saltBytes, _ := base64.RawStdEncoding.DecodeString(salt)
 	hash := pbkdf2.Key([]byte(password), saltBytes, 4096, 32, sha256.New)
-	return base64.RawStdEncoding.EncodeToString(hash) == hashedPassword
+	return subtle.ConstantTimeCompare([]byte(base64.RawStdEncoding.EncodeToString(hash)), []byte(hashedPassword)) == 1
 }
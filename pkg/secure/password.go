@@ -0,0 +1,101 @@
+package secure
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"strings"
+)
+
+// Argon2Params are the cost parameters of an Argon2id password hash, Memory is in KiB.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params follow the OWASP recommendation for Argon2id.
+var DefaultArgon2Params = Argon2Params{
+	Memory:      19 * 1024,
+	Iterations:  2,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// maxArgon2Memory rejects hashes that would need an unreasonable amount of memory to verify.
+const maxArgon2Memory = 1024 * 1024
+
+var ErrInvalidPasswordHash = errors.New("invalid password hash")
+
+// HashPassword hashes password with Argon2id and the default parameters, the result is a PHC string
+// such as $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>.
+func HashPassword(password string) (string, error) {
+	return HashPasswordWithParams(password, DefaultArgon2Params)
+}
+
+func HashPasswordWithParams(password string, p Argon2Params) (string, error) {
+	salt, err := GenerateSalt(int(p.SaltLength))
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func decodeArgon2Hash(encoded string) (Argon2Params, []byte, []byte, error) {
+	var p Argon2Params
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+	if p.Memory == 0 || p.Memory > maxArgon2Memory || p.Iterations == 0 || p.Parallelism == 0 {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+	return p, salt, key, nil
+}
+
+// IsPHCHash reports whether encoded is a PHC formatted hash rather than a legacy PBKDF2 one.
+func IsPHCHash(encoded string) bool {
+	return strings.HasPrefix(encoded, "$")
+}
+
+// CheckPassword verifies password against encoded in constant time. A hash without the PHC prefix is
+// a legacy PBKDF2 hash using legacySalt. needsRehash is true when the password matched but the hash is
+// not an Argon2id hash with the default parameters.
+func CheckPassword(password, encoded, legacySalt string) (ok bool, needsRehash bool) {
+	if !IsPHCHash(encoded) {
+		return VerifyPassword(password, legacySalt, encoded), true
+	}
+	p, salt, key, err := decodeArgon2Hash(encoded)
+	if err != nil {
+		return false, false
+	}
+	actual := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	if subtle.ConstantTimeCompare(actual, key) != 1 {
+		return false, false
+	}
+	return true, p != DefaultArgon2Params
+}
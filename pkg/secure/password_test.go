@@ -0,0 +1,61 @@
+package secure
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword failed: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=19456,t=2,p=1$") {
+		t.Fatalf("unexpected hash format %s", hash)
+	}
+	other, _ := HashPassword("correct horse")
+	if hash == other {
+		t.Errorf("hashes of the same password should use different salts")
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	current, err := HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	weak, err := HashPasswordWithParams("secret", Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	salt, _ := GenerateSaltBase64(10)
+	legacy := HashPasswordByKDFBase64("secret", salt)
+
+	tests := []struct {
+		name       string
+		password   string
+		hash       string
+		salt       string
+		wantOk     bool
+		wantRehash bool
+	}{
+		{name: "argon2id", password: "secret", hash: current, wantOk: true},
+		{name: "argon2id wrong password", password: "Secret", hash: current},
+		{name: "weaker params", password: "secret", hash: weak, wantOk: true, wantRehash: true},
+		{name: "legacy pbkdf2", password: "secret", hash: legacy, salt: salt, wantOk: true, wantRehash: true},
+		{name: "legacy wrong password", password: "wrong", hash: legacy, salt: salt, wantRehash: true},
+		{name: "malformed", password: "secret", hash: "$argon2id$v=19$m=x$salt$hash"},
+		{name: "unknown algorithm", password: "secret", hash: "$scrypt$ln=16,r=8,p=1$c2FsdA$aGFzaA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, rehash := CheckPassword(tt.password, tt.hash, tt.salt)
+			if ok != tt.wantOk {
+				t.Errorf("ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && rehash != tt.wantRehash {
+				t.Errorf("needsRehash = %v, want %v", rehash, tt.wantRehash)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package secure
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -10,20 +11,25 @@ import (
 	"encoding/base64"
 	"encoding/pem"
 	"fmt"
-	"math/big"
+	"net"
 	"time"
 )
 
 const validTime = 5 * 365 * 24 * time.Hour
 const organization = "Fada Control"
 
+// GenerateX509Cert creates a self-signed server certificate, it is only used when the local authority is unavailable.
 func GenerateX509Cert() (certPEM, keyPEM []byte, err error) {
 	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
 		return nil, nil, err
 	}
+	serial, err := RandomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
 	template := x509.Certificate{
-		SerialNumber: big.NewInt(1),
+		SerialNumber: serial,
 		Subject: pkix.Name{
 			Organization: []string{organization},
 		},
@@ -32,6 +38,8 @@ func GenerateX509Cert() (certPEM, keyPEM []byte, err error) {
 		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
 	}
 
 	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
@@ -47,6 +55,18 @@ func GenerateX509Cert() (certPEM, keyPEM []byte, err error) {
 	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
 	return certPEM, keyPEM, nil
 }
+
+// IsGeneratedX509Cert reports whether cert is a self-signed certificate created by GenerateX509Cert.
+func IsGeneratedX509Cert(cert *x509.Certificate) bool {
+	if cert.IsCA || !bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+		return false
+	}
+	// CheckSignatureFrom refuses parents that are not authorities, the generated certificates are not
+	if cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) != nil {
+		return false
+	}
+	return len(cert.Subject.Organization) == 1 && cert.Subject.Organization[0] == organization
+}
 func LoadBaseX509KeyPair(certPEM, keyPEM string) (tls.Certificate, error) {
 	cert, err := base64.StdEncoding.DecodeString(certPEM)
 	if err != nil {
@@ -66,3 +66,38 @@ func ProtoTypeToShutdownType(protoType remote_schema.ShutdownType) ShutdownType
 		return Unknown
 	}
 }
+
+func ShutdownTypeToProtoType(tpe ShutdownType) remote_schema.ShutdownType {
+	switch tpe {
+	case S_E_FORCE_REBOOT:
+		return remote_schema.ShutdownType_E_FORCE_REBOOT
+	case S_E_LOGOFF:
+		return remote_schema.ShutdownType_E_LOGOFF
+	case S_E_FORCE_SHUTDOWN:
+		return remote_schema.ShutdownType_E_FORCE_SHUTDOWN
+	case S_EWX_FORCE_POWEROFF:
+		return remote_schema.ShutdownType_EWX_FORCE_POWEROFF
+	case S_EWX_REBOOT:
+		return remote_schema.ShutdownType_EWX_REBOOT
+	case S_EWX_REBOOT_RESTARTAPPS:
+		return remote_schema.ShutdownType_EWX_REBOOT_RESTARTAPPS
+	case S_EWX_POWEROFF:
+		return remote_schema.ShutdownType_EWX_POWEROFF
+	case S_EWX_HYBRID_SHUTDOWN:
+		return remote_schema.ShutdownType_EWX_HYBRID_SHUTDOWN
+	case S_EWX_HYBRID_SHUTDOWN_FORCE:
+		return remote_schema.ShutdownType_EWX_HYBRID_SHUTDOWN_FORCE
+	case S_EWX_HYBRID_SHUTDOWN_RESTARTAPPS:
+		return remote_schema.ShutdownType_EWX_HYBRID_SHUTDOWN_RESTARTAPPS
+	case S_EWX_HYBRID_SHUTDOWN_FORCE_RESTARTAPPS:
+		return remote_schema.ShutdownType_EWX_HYBRID_SHUTDOWN_FORCE_RESTARTAPPS
+	case S_EWX_SHUTDOWN_RESTARTAPPS:
+		return remote_schema.ShutdownType_EWX_SHUTDOWN_RESTARTAPPS
+	case S_EWX_SHUTDOWN:
+		return remote_schema.ShutdownType_EWX_SHUTDOWN
+	case S_EWX_FORCE_REBOOT_RESTARTAPPS:
+		return remote_schema.ShutdownType_EWX_FORCE_REBOOT_RESTARTAPPS
+	default:
+		return remote_schema.ShutdownType_E_UNKNOWN
+	}
+}
@@ -177,6 +177,16 @@ func ReceiveFromNamedPipe(pipeName string, pipeCacheSize int) ([]byte, error) {
 }
 
 func ListenNamedPipeWithHandler(pipeName string, handler func(conn net.Conn), inputBufferSize, outputBufferSize int32) error {
+	pipeHandle, err := NewNamedPipeListener(pipeName, inputBufferSize, outputBufferSize)
+	if err != nil {
+		return err
+	}
+	return ServeNamedPipe(pipeHandle, handler)
+}
+
+// NewNamedPipeListener creates the named pipe, only the administrators, the system account and the current user may
+// connect to it.
+func NewNamedPipeListener(pipeName string, inputBufferSize, outputBufferSize int32) (net.Listener, error) {
 	securityDescriptor := "D:(A;;GA;;;S-1-5-32-544)(A;;GA;;;S-1-5-18)"
 	sid, err := getCurrentUserSid()
 	if err == nil {
@@ -191,8 +201,13 @@ func ListenNamedPipeWithHandler(pipeName string, handler func(conn net.Conn), in
 	pipeHandle, err := winio.ListenPipe(pipeName, config)
 
 	if err != nil {
-		return fmt.Errorf("failed to connect to named pipe: %v", err)
+		return nil, fmt.Errorf("failed to connect to named pipe: %v", err)
 	}
+	return pipeHandle, nil
+}
+
+// ServeNamedPipe runs handler for every connection accepted on the pipe until accepting fails.
+func ServeNamedPipe(pipeHandle net.Listener, handler func(conn net.Conn)) error {
 	for {
 		conn, err := pipeHandle.Accept()
 		if err != nil {
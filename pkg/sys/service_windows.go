@@ -188,6 +188,30 @@ func (w *windowsSvc) stopWait(s *mgr.Service) error {
 
 var interactive = false
 
+const (
+	SessionLock   = windows.WTS_SESSION_LOCK
+	SessionUnlock = windows.WTS_SESSION_UNLOCK
+)
+
+var sessionChangeCallback []func(event uint32)
+var sessionChangeCallbackLock sync.RWMutex
+
+// AddSessionChangeCallback registers a callback for the session changes reported to the service, e.g. SessionLock
+// and SessionUnlock. The callbacks are not called when the program does not run as a service.
+func AddSessionChangeCallback(callback func(event uint32)) {
+	sessionChangeCallbackLock.Lock()
+	defer sessionChangeCallbackLock.Unlock()
+	sessionChangeCallback = append(sessionChangeCallback, callback)
+}
+
+func notifySessionChange(event uint32) {
+	sessionChangeCallbackLock.RLock()
+	defer sessionChangeCallbackLock.RUnlock()
+	for _, callback := range sessionChangeCallback {
+		callback(event)
+	}
+}
+
 func (w *windowsSvc) Run() error {
 	w.setError(nil)
 	if !interactive {
@@ -220,7 +244,7 @@ func (w *windowsSvc) Run() error {
 }
 
 func (w *windowsSvc) Execute(args []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
-	const cmdsAccepted = svc.AcceptStop | svc.AcceptShutdown
+	const cmdsAccepted = svc.AcceptStop | svc.AcceptShutdown | svc.AcceptSessionChange
 	changes <- svc.Status{State: svc.StartPending}
 
 	if err := w.handler.Start(w); err != nil {
@@ -235,6 +259,8 @@ loop:
 		switch c.Cmd {
 		case svc.Interrogate:
 			changes <- c.CurrentStatus
+		case svc.SessionChange:
+			notifySessionChange(c.EventType)
 		case svc.Stop:
 			changes <- svc.Status{State: svc.StopPending}
 			if err := w.handler.Stop(w); err != nil {
@@ -0,0 +1,154 @@
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+// Config controls when a key is slowed down and when it is locked out.
+type Config struct {
+	// Threshold is the number of failures allowed before the backoff starts.
+	Threshold int
+	// BaseDelay is the first backoff delay, it doubles with every further failure up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockoutThreshold is the number of failures after which the key is locked out for LockoutDuration.
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	// Window is the time after the last failure at which the failures of a key are forgotten.
+	Window time.Duration
+	// MaxEntries bounds the number of tracked keys, the least recently failed key is evicted first.
+	MaxEntries int
+}
+
+// Status describes a key after a failure was recorded.
+type Status struct {
+	Failures   int
+	RetryAfter time.Duration
+	// Locked is true when this failure started a new lockout.
+	Locked bool
+}
+
+type entry struct {
+	failures     int
+	lastFailure  time.Time
+	blockedUntil time.Time
+	lockedUntil  time.Time
+}
+
+// Tracker counts failed attempts per key and computes exponential backoff and lockouts.
+type Tracker struct {
+	mu      sync.Mutex
+	cfg     Config
+	entries map[string]*entry
+	now     func() time.Time
+}
+
+func NewTracker(cfg Config) *Tracker {
+	return &Tracker{cfg: cfg, entries: make(map[string]*entry), now: time.Now}
+}
+
+func (t *Tracker) stale(e *entry, now time.Time) bool {
+	return now.After(e.blockedUntil) && now.Sub(e.lastFailure) > t.cfg.Window
+}
+
+// Check returns how long key has to wait before the next attempt, zero when it may proceed.
+func (t *Tracker) Check(key string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.entries[key]
+	if !ok {
+		return 0
+	}
+	now := t.now()
+	if t.stale(e, now) {
+		delete(t.entries, key)
+		return 0
+	}
+	if now.Before(e.blockedUntil) {
+		return e.blockedUntil.Sub(now)
+	}
+	return 0
+}
+
+func (t *Tracker) backoff(failures int) time.Duration {
+	if failures < t.cfg.Threshold {
+		return 0
+	}
+	delay := t.cfg.BaseDelay
+	for i := t.cfg.Threshold; i < failures && delay < t.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > t.cfg.MaxDelay {
+		delay = t.cfg.MaxDelay
+	}
+	return delay
+}
+
+// Failure records a failed attempt of key.
+func (t *Tracker) Failure(key string) Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	e, ok := t.entries[key]
+	if ok && t.stale(e, now) {
+		ok = false
+	}
+	if !ok {
+		t.evict(now)
+		e = &entry{}
+		t.entries[key] = e
+	}
+	e.failures++
+	e.lastFailure = now
+	status := Status{Failures: e.failures}
+	if t.cfg.LockoutThreshold > 0 && e.failures >= t.cfg.LockoutThreshold {
+		// a failure recorded while the key is still locked out extends the lockout without starting a new one
+		status.Locked = !now.Before(e.lockedUntil)
+		e.lockedUntil = now.Add(t.cfg.LockoutDuration)
+		e.blockedUntil = e.lockedUntil
+	} else if delay := t.backoff(e.failures); delay > 0 {
+		e.blockedUntil = now.Add(delay)
+	}
+	status.RetryAfter = e.blockedUntil.Sub(now)
+	if status.RetryAfter < 0 {
+		status.RetryAfter = 0
+	}
+	return status
+}
+
+// Reset forgets the failures of key.
+func (t *Tracker) Reset(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.entries, key)
+}
+
+// Len returns the number of tracked keys.
+func (t *Tracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.entries)
+}
+
+// evict makes room for a new key, t.mu must be held.
+func (t *Tracker) evict(now time.Time) {
+	if t.cfg.MaxEntries <= 0 || len(t.entries) < t.cfg.MaxEntries {
+		return
+	}
+	for key, e := range t.entries {
+		if t.stale(e, now) {
+			delete(t.entries, key)
+		}
+	}
+	for len(t.entries) >= t.cfg.MaxEntries {
+		var oldestKey string
+		var oldest time.Time
+		for key, e := range t.entries {
+			if oldestKey == "" || e.lastFailure.Before(oldest) {
+				oldestKey, oldest = key, e.lastFailure
+			}
+		}
+		delete(t.entries, oldestKey)
+	}
+}
@@ -0,0 +1,110 @@
+package throttle
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newTestTracker(cfg Config) (*Tracker, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t := NewTracker(cfg)
+	t.now = func() time.Time { return now }
+	return t, &now
+}
+
+var testConfig = Config{
+	Threshold:        3,
+	BaseDelay:        time.Second,
+	MaxDelay:         8 * time.Second,
+	LockoutThreshold: 10,
+	LockoutDuration:  time.Minute,
+	Window:           time.Hour,
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: 0},
+		{failures: 2, want: 0},
+		{failures: 3, want: time.Second},
+		{failures: 4, want: 2 * time.Second},
+		{failures: 5, want: 4 * time.Second},
+		{failures: 6, want: 8 * time.Second},
+		{failures: 9, want: 8 * time.Second},
+		{failures: 10, want: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.failures), func(t *testing.T) {
+			tr, _ := newTestTracker(testConfig)
+			var status Status
+			for i := 0; i < tt.failures; i++ {
+				status = tr.Failure("k")
+			}
+			if status.RetryAfter != tt.want {
+				t.Errorf("RetryAfter = %v, want %v", status.RetryAfter, tt.want)
+			}
+			if got := tr.Check("k"); got != tt.want {
+				t.Errorf("Check = %v, want %v", got, tt.want)
+			}
+			if status.Locked != (tt.failures == testConfig.LockoutThreshold) {
+				t.Errorf("Locked = %v", status.Locked)
+			}
+		})
+	}
+}
+
+func TestLockoutExpiresAndWindow(t *testing.T) {
+	tr, now := newTestTracker(testConfig)
+	for i := 0; i < testConfig.LockoutThreshold; i++ {
+		tr.Failure("k")
+	}
+	*now = now.Add(testConfig.LockoutDuration + time.Second)
+	if got := tr.Check("k"); got != 0 {
+		t.Fatalf("expected the lockout to expire, got %v", got)
+	}
+	if status := tr.Failure("k"); !status.Locked {
+		t.Errorf("a failure after the lockout within the window should lock again")
+	}
+	*now = now.Add(testConfig.LockoutDuration + testConfig.Window + time.Second)
+	if status := tr.Failure("k"); status.Failures != 1 || status.RetryAfter != 0 {
+		t.Errorf("expected the failures to be forgotten, got %+v", status)
+	}
+}
+
+func TestReset(t *testing.T) {
+	tr, _ := newTestTracker(testConfig)
+	for i := 0; i < 5; i++ {
+		tr.Failure("k")
+	}
+	tr.Failure("other")
+	tr.Reset("k")
+	if got := tr.Check("k"); got != 0 {
+		t.Errorf("Check after Reset = %v", got)
+	}
+	if tr.Len() != 1 {
+		t.Errorf("Len = %d, want 1", tr.Len())
+	}
+}
+
+func TestMaxEntries(t *testing.T) {
+	cfg := testConfig
+	cfg.MaxEntries = 3
+	tr, now := newTestTracker(cfg)
+	for i := 0; i < 5; i++ {
+		tr.Failure(strconv.Itoa(i))
+		*now = now.Add(time.Second)
+	}
+	if tr.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", tr.Len())
+	}
+	tr.mu.Lock()
+	_, oldest := tr.entries["0"]
+	_, newest := tr.entries["4"]
+	tr.mu.Unlock()
+	if oldest || !newest {
+		t.Errorf("expected the least recently failed keys to be evicted")
+	}
+}
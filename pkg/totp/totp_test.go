@@ -0,0 +1,93 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 key of the RFC 6238 test vectors.
+var rfc6238Secret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestHotpRFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "94287082"},
+		{unix: 1111111109, want: "07081804"},
+		{unix: 1111111111, want: "14050471"},
+		{unix: 1234567890, want: "89005924"},
+		{unix: 2000000000, want: "69279037"},
+		{unix: 20000000000, want: "65353130"},
+	}
+	key, err := decodeSecret(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got := hotp(key, uint64(Counter(time.Unix(tt.unix, 0))), 8)
+			if got != tt.want {
+				t.Errorf("hotp at %d = %s, want %s", tt.unix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, err := GenerateCode(rfc6238Secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != "005924" {
+		t.Fatalf("GenerateCode = %s, want 005924", code)
+	}
+	tests := []struct {
+		name string
+		at   time.Time
+		code string
+		want bool
+	}{
+		{name: "current", at: now, code: code, want: true},
+		{name: "previous step", at: now.Add(Period), code: code, want: true},
+		{name: "next step", at: now.Add(-Period), code: code, want: true},
+		{name: "too old", at: now.Add(2 * Period), code: code, want: false},
+		{name: "wrong code", at: now, code: "123456", want: false},
+		{name: "wrong length", at: now, code: "05924", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter, ok := Validate(rfc6238Secret, tt.code, tt.at, 1)
+			if ok != tt.want {
+				t.Fatalf("Validate = %v, want %v", ok, tt.want)
+			}
+			if ok && counter != Counter(now) {
+				t.Errorf("counter = %d, want %d", counter, Counter(now))
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key, err := decodeSecret(secret); err != nil || len(key) != SecretLength {
+		t.Errorf("invalid secret %q", secret)
+	}
+	if _, err := GenerateCode("not base32!", time.Now()); err == nil {
+		t.Errorf("expected an error for an invalid secret")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("FadaControl", "root", "JBSWY3DPEHPK3PXP")
+	for _, part := range []string{"otpauth://totp/FadaControl:root?", "secret=JBSWY3DPEHPK3PXP", "issuer=FadaControl", "digits=6", "period=30"} {
+		if !strings.Contains(uri, part) {
+			t.Errorf("%s does not contain %s", uri, part)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"golang.org/x/sys/windows"
+	"os"
+)
+
+// privateFileSDDL grants access to SYSTEM, the administrators and the owner of the file only, inherited
+// permissions are removed.
+const privateFileSDDL = "D:P(A;;FA;;;SY)(A;;FA;;;BA)(A;;FA;;;OW)"
+
+// WritePrivateFile writes data to a file that only the service account, the administrators and the owner can
+// read. The permissions are set before the content is written.
+func WritePrivateFile(name string, data []byte) error {
+	if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sd, err := windows.SecurityDescriptorFromString(privateFileSDDL)
+	if err != nil {
+		return err
+	}
+	dacl, _, err := sd.DACL()
+	if err != nil {
+		return err
+	}
+	err = windows.SetNamedSecurityInfo(name, windows.SE_FILE_OBJECT,
+		windows.DACL_SECURITY_INFORMATION|windows.PROTECTED_DACL_SECURITY_INFORMATION, nil, nil, dacl, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	return f.Sync()
+}
@@ -0,0 +1,164 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Fadacontrol-Event"
+	HeaderDelivery  = "X-Fadacontrol-Delivery"
+	HeaderTimestamp = "X-Fadacontrol-Timestamp"
+	HeaderSignature = "X-Fadacontrol-Signature"
+)
+
+const signaturePrefix = "sha256="
+
+// maxResponseSize is the number of bytes of the response body kept in an attempt.
+const maxResponseSize = 1024
+
+// Sign returns the signature header of a delivery, the hex encoded HMAC-SHA256 of "<timestamp>.<body>" keyed by
+// the secret of the subscription.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header in constant time, receivers should also reject old timestamps.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Delivery is an event sent to a subscription, Id stays the same across the attempts.
+type Delivery struct {
+	Id     string
+	Url    string
+	Secret string
+	Event  string
+	Body   []byte
+}
+
+// Attempt is the outcome of sending a delivery once.
+type Attempt struct {
+	Number     int
+	StatusCode int
+	// Response is the beginning of the response body.
+	Response string
+	Err      error
+	Duration time.Duration
+}
+
+func (a *Attempt) Success() bool {
+	return a.Err == nil && a.StatusCode >= 200 && a.StatusCode < 300
+}
+
+// retryable reports whether a failed attempt may succeed later, client errors other than timeouts and rate limits
+// are permanent.
+func (a *Attempt) retryable() bool {
+	if a.Err != nil {
+		return true
+	}
+	return a.StatusCode >= 500 || a.StatusCode == http.StatusTooManyRequests || a.StatusCode == http.StatusRequestTimeout
+}
+
+// Sender posts deliveries and retries the failed attempts.
+type Sender struct {
+	Client      *http.Client
+	MaxAttempts int
+	// Backoff returns the wait after the failed attempt number n.
+	Backoff func(n int) time.Duration
+	now     func() time.Time
+}
+
+func NewSender(client *http.Client, maxAttempts int, backoff func(n int) time.Duration) *Sender {
+	return &Sender{Client: client, MaxAttempts: maxAttempts, Backoff: backoff, now: time.Now}
+}
+
+// ExponentialBackoff waits base after the first attempt and doubles the wait after every further attempt up to max.
+func ExponentialBackoff(base, max time.Duration) func(n int) time.Duration {
+	return func(n int) time.Duration {
+		d := base
+		for i := 1; i < n && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+// Send delivers d, onAttempt is called after every attempt. It returns the error of the last attempt, or the error
+// of ctx when it is done before the delivery succeeds.
+func (s *Sender) Send(ctx context.Context, d *Delivery, onAttempt func(a *Attempt)) error {
+	for n := 1; ; n++ {
+		a := s.attempt(ctx, d, n)
+		if onAttempt != nil {
+			onAttempt(a)
+		}
+		if a.Success() {
+			return nil
+		}
+		err := a.Err
+		if err == nil {
+			err = fmt.Errorf("the receiver responded with status %d", a.StatusCode)
+		}
+		if n >= s.MaxAttempts || !a.retryable() {
+			return err
+		}
+		timer := time.NewTimer(s.Backoff(n))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *Sender) attempt(ctx context.Context, d *Delivery, n int) *Attempt {
+	a := &Attempt{Number: n}
+	start := s.now()
+	defer func() {
+		a.Duration = s.now().Sub(start)
+	}()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.Url, bytes.NewReader(d.Body))
+	if err != nil {
+		a.Err = err
+		return a
+	}
+	timestamp := start.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderDelivery, d.Id)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(d.Secret, timestamp, d.Body))
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		a.Err = err
+		return a
+	}
+	defer resp.Body.Close()
+	a.StatusCode = resp.StatusCode
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil && !errors.Is(err, io.EOF) {
+		a.Err = err
+		return a
+	}
+	a.Response = string(body)
+	// drain the rest so that the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	return a
+}
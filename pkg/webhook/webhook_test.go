@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"event":"session.lock"}`)
+	sig := Sign("secret", 1700000000, body)
+	if sig != Sign("secret", 1700000000, body) {
+		t.Fatalf("Sign() is not deterministic")
+	}
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		want      bool
+	}{
+		{"valid", "secret", 1700000000, body, true},
+		{"wrong secret", "other", 1700000000, body, false},
+		{"wrong timestamp", "secret", 1700000001, body, false},
+		{"changed body", "secret", 1700000000, []byte(`{"event":"session.unlock"}`), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.timestamp, tt.body, sig); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(time.Second, 5*time.Second)
+	for n, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := backoff(n); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		attempts int
+		wantErr  bool
+	}{
+		{"success", []int{http.StatusOK}, 1, false},
+		{"retry server errors", []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusNoContent}, 3, false},
+		{"client error is permanent", []int{http.StatusBadRequest}, 1, true},
+		{"give up", []int{http.StatusBadGateway}, 4, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(calls.Add(1))
+				body, _ := io.ReadAll(r.Body)
+				timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+				if !Verify("secret", timestamp, body, r.Header.Get(HeaderSignature)) {
+					t.Errorf("invalid signature %q", r.Header.Get(HeaderSignature))
+				}
+				if r.Header.Get(HeaderEvent) != "session.lock" || r.Header.Get(HeaderDelivery) != "d1" {
+					t.Errorf("unexpected headers %v", r.Header)
+				}
+				status := tt.statuses[len(tt.statuses)-1]
+				if n <= len(tt.statuses) {
+					status = tt.statuses[n-1]
+				}
+				w.WriteHeader(status)
+				io.WriteString(w, "ok")
+			}))
+			defer server.Close()
+
+			sender := NewSender(server.Client(), 4, func(int) time.Duration { return time.Millisecond })
+			var attempts []*Attempt
+			err := sender.Send(context.Background(), &Delivery{Id: "d1", Url: server.URL, Secret: "secret", Event: "session.lock", Body: []byte(`{}`)},
+				func(a *Attempt) { attempts = append(attempts, a) })
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(attempts) != tt.attempts || int(calls.Load()) != tt.attempts {
+				t.Fatalf("got %d attempts and %d calls, want %d", len(attempts), calls.Load(), tt.attempts)
+			}
+			for i, a := range attempts {
+				if a.Number != i+1 || (a.StatusCode != http.StatusNoContent && a.Response != "ok") {
+					t.Errorf("attempt %d = %+v", i, a)
+				}
+			}
+		})
+	}
+}
+
+func TestSendCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	sender := NewSender(server.Client(), 10, func(int) time.Duration { return time.Hour })
+	err := sender.Send(ctx, &Delivery{Id: "d1", Url: server.URL, Secret: "secret", Event: "session.lock"}, func(a *Attempt) { cancel() })
+	if err != context.Canceled {
+		t.Errorf("Send() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestSendUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+	sender := NewSender(http.DefaultClient, 2, func(int) time.Duration { return time.Millisecond })
+	var attempts int
+	err := sender.Send(context.Background(), &Delivery{Id: "d1", Url: url, Secret: "secret", Event: "session.lock"}, func(a *Attempt) {
+		attempts++
+		if a.Err == nil || a.Success() {
+			t.Errorf("attempt %+v succeeded", a)
+		}
+	})
+	if err == nil || attempts != 2 {
+		t.Errorf("Send() error = %v after %d attempts", err, attempts)
+	}
+}
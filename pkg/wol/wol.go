@@ -0,0 +1,47 @@
+package wol
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+)
+
+const DefaultBroadcastAddr = "255.255.255.255:9"
+
+// NewMagicPacket builds a wake-on-lan magic packet: six 0xFF bytes followed by the MAC address repeated 16 times.
+func NewMagicPacket(mac string) ([]byte, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return nil, err
+	}
+	if len(hw) != 6 {
+		return nil, fmt.Errorf("invalid MAC-48 address %q", mac)
+	}
+	packet := bytes.Repeat([]byte{0xFF}, 6)
+	for i := 0; i < 16; i++ {
+		packet = append(packet, hw...)
+	}
+	return packet, nil
+}
+
+// Send broadcasts a magic packet for mac. broadcast is a host:port address, DefaultBroadcastAddr is used when empty.
+func Send(mac, broadcast string) error {
+	packet, err := NewMagicPacket(mac)
+	if err != nil {
+		return err
+	}
+	if broadcast == "" {
+		broadcast = DefaultBroadcastAddr
+	}
+	addr, err := net.ResolveUDPAddr("udp4", broadcast)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialUDP("udp4", nil, addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write(packet)
+	return err
+}
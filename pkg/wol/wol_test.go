@@ -0,0 +1,68 @@
+package wol
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestNewMagicPacket(t *testing.T) {
+	tests := []struct {
+		name    string
+		mac     string
+		wantErr bool
+	}{
+		{name: "colon", mac: "01:23:45:67:89:ab"},
+		{name: "dash", mac: "01-23-45-67-89-AB"},
+		{name: "invalid", mac: "01:23:45", wantErr: true},
+		{name: "eui64", mac: "01:23:45:67:89:ab:cd:ef", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packet, err := NewMagicPacket(tt.mac)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for %q", tt.mac)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(packet) != 102 {
+				t.Fatalf("expected 102 bytes, got %d", len(packet))
+			}
+			if !bytes.Equal(packet[:6], bytes.Repeat([]byte{0xFF}, 6)) {
+				t.Errorf("invalid header %x", packet[:6])
+			}
+			hw := []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab}
+			for i := 0; i < 16; i++ {
+				if !bytes.Equal(packet[6+i*6:12+i*6], hw) {
+					t.Errorf("invalid repetition %d: %x", i, packet[6+i*6:12+i*6])
+				}
+			}
+		})
+	}
+}
+
+func TestSend(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+	if err := Send("01:23:45:67:89:ab", conn.LocalAddr().String()); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 200)
+	n, _, err := conn.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := NewMagicPacket("01:23:45:67:89:ab")
+	if !bytes.Equal(buf[:n], expected) {
+		t.Errorf("received unexpected packet %x", buf[:n])
+	}
+}